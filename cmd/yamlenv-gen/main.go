@@ -0,0 +1,389 @@
+// Command yamlenv-gen writes BindEnv methods that apply yamlenv's
+// environment overrides to a config struct without reflection. Run it with
+// go generate next to the struct:
+//
+//	//go:generate go run github.com/tendant/yamlenv/cmd/yamlenv-gen -type Config
+//
+// It writes config_yamlenv.go, or config_yamlenv_test.go for types declared
+// in test files. Fields of basic types, time.Duration and structs declared
+// in the package are bound by the generated code; other fields (pointers,
+// slices, maps, types from other packages) are handed to yamlenv to bind
+// with reflection. Rerun the generator after changing the struct.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stderr))
+}
+
+func run(args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("yamlenv-gen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	typeNames := fs.String("type", "", "comma-separated struct type names (required)")
+	output := fs.String("output", "", "output file (default <type>_yamlenv.go in the package directory)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: yamlenv-gen -type T[,T...] [-output file] [dir]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *typeNames == "" || fs.NArg() > 1 {
+		fs.Usage()
+		return 2
+	}
+	dir := "."
+	if fs.NArg() == 1 {
+		dir = fs.Arg(0)
+	}
+
+	pkg, err := parsePackage(dir)
+	if err != nil {
+		fmt.Fprintf(stderr, "yamlenv-gen: %v\n", err)
+		return 1
+	}
+	src, file, err := generate(pkg, strings.Split(*typeNames, ","))
+	if err != nil {
+		fmt.Fprintf(stderr, "yamlenv-gen: %v\n", err)
+		return 1
+	}
+	if *output == "" {
+		*output = filepath.Join(dir, file)
+	}
+	if err := os.WriteFile(*output, src, 0o644); err != nil {
+		fmt.Fprintf(stderr, "yamlenv-gen: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// typeDecl is a type declared in the package being generated for
+type typeDecl struct {
+	spec *ast.TypeSpec
+	file *ast.File
+	test bool // declared in a _test.go file
+}
+
+// parsePackage collects the type declarations of the Go files in dir,
+// skipping generated files
+func parsePackage(dir string) (map[string]typeDecl, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	types := map[string]typeDecl{}
+	for _, path := range paths {
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		if ast.IsGenerated(file) {
+			continue
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				spec := spec.(*ast.TypeSpec)
+				types[spec.Name.Name] = typeDecl{spec: spec, file: file, test: strings.HasSuffix(path, "_test.go")}
+			}
+		}
+	}
+	return types, nil
+}
+
+// generator writes the BindEnv methods of one output file
+type generator struct {
+	types   map[string]typeDecl
+	pkgName string
+	buf     bytes.Buffer
+	imports map[string]bool
+}
+
+// generate returns the formatted source of the BindEnv methods for the
+// named types and the default name of the file to write it to
+func generate(types map[string]typeDecl, names []string) ([]byte, string, error) {
+	g := &generator{types: types, imports: map[string]bool{}}
+	test := false
+	for i, name := range names {
+		decl, ok := types[name]
+		if !ok {
+			return nil, "", fmt.Errorf("type %s not found", name)
+		}
+		if decl.spec.TypeParams != nil {
+			return nil, "", fmt.Errorf("type %s: generic types are not supported", name)
+		}
+		st, ok := decl.spec.Type.(*ast.StructType)
+		if !ok {
+			return nil, "", fmt.Errorf("type %s is not a struct", name)
+		}
+		if i == 0 {
+			g.pkgName = decl.file.Name.Name
+			test = decl.test
+		} else if decl.file.Name.Name != g.pkgName {
+			return nil, "", fmt.Errorf("types %s and %s are in different packages", names[0], name)
+		}
+
+		fmt.Fprintf(&g.buf, "\n// BindEnv applies environment overrides to c without reflection\n")
+		fmt.Fprintf(&g.buf, "func (c *%s) BindEnv(env *%s) error {\n", name, g.qualify("Env"))
+		if err := g.fields(st, "c", ""); err != nil {
+			return nil, "", fmt.Errorf("type %s: %w", name, err)
+		}
+		g.buf.WriteString("\treturn nil\n}\n")
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by yamlenv-gen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", g.pkgName)
+	if !g.self() {
+		g.imports["github.com/tendant/yamlenv/pkg/yamlenv"] = true
+	}
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	out.WriteString("import (\n")
+	for _, path := range paths {
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	out.WriteString(")\n")
+	out.Write(g.buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, "", fmt.Errorf("format generated code: %w", err)
+	}
+	file := strings.ToLower(names[0]) + "_yamlenv.go"
+	if test {
+		file = strings.ToLower(names[0]) + "_yamlenv_test.go"
+	}
+	return src, file, nil
+}
+
+// self reports whether the code is generated into package yamlenv itself,
+// which refers to Env unqualified
+func (g *generator) self() bool {
+	return g.pkgName == "yamlenv"
+}
+
+func (g *generator) qualify(name string) string {
+	if g.self() {
+		return name
+	}
+	return "yamlenv." + name
+}
+
+// fields writes the bindings for the exported fields of st, which is
+// reached through expr at path
+func (g *generator) fields(st *ast.StructType, expr, path string) error {
+	for _, field := range st.Fields.List {
+		names := field.Names
+		if len(names) == 0 {
+			// Embedded fields are named after their type
+			names = []*ast.Ident{embeddedName(field.Type)}
+		}
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			unquoted, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return err
+			}
+			tag = reflect.StructTag(unquoted)
+		}
+		yamlTag := tag.Get("yaml")
+		if yamlTag == "-" {
+			continue
+		}
+		inline := false
+		if idx := strings.Index(yamlTag, ","); idx >= 0 {
+			inline = strings.Contains(yamlTag[idx:], ",inline")
+			yamlTag = yamlTag[:idx]
+		}
+
+		for _, name := range names {
+			if name == nil || !name.IsExported() {
+				continue
+			}
+			fieldPath := yamlTag
+			if fieldPath == "" {
+				fieldPath = strings.ToLower(name.Name)
+			}
+			if inline {
+				fieldPath = path
+			} else if path != "" {
+				fieldPath = path + "." + fieldPath
+			}
+			if err := g.field(field.Type, expr+"."+name.Name, fieldPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// embeddedName returns the field name of an embedded field of type t
+func embeddedName(t ast.Expr) *ast.Ident {
+	switch t := t.(type) {
+	case *ast.Ident:
+		return t
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel
+	case *ast.IndexExpr:
+		return embeddedName(t.X)
+	case *ast.IndexListExpr:
+		return embeddedName(t.X)
+	}
+	return nil
+}
+
+// kind is how generated code parses a field's value
+type kind struct {
+	parse string // "string", "bool", "int", "uint", "float" or "duration"
+	conv  string // named type the parsed value is converted to, if any
+}
+
+// basicKinds maps predeclared types to the way their values are parsed
+var basicKinds = map[string]string{
+	"string": "string",
+	"bool":   "bool",
+	"int":    "int", "int8": "int", "int16": "int", "int32": "int", "int64": "int", "rune": "int",
+	"uint": "uint", "uint8": "uint", "uint16": "uint", "uint32": "uint", "uint64": "uint", "uintptr": "uint", "byte": "uint",
+	"float32": "float", "float64": "float",
+}
+
+// field writes the binding for one field of type t
+func (g *generator) field(t ast.Expr, expr, path string) error {
+	if st, ok := t.(*ast.StructType); ok {
+		return g.fields(st, expr, path)
+	}
+	if ident, ok := t.(*ast.Ident); ok {
+		if decl, ok := g.types[ident.Name]; ok && decl.spec.TypeParams == nil {
+			if st, ok := decl.spec.Type.(*ast.StructType); ok {
+				return g.fields(st, expr, path)
+			}
+		}
+	}
+
+	k, ok := g.kindOf(t, nil)
+	if !ok {
+		// Leave everything else to reflection
+		fmt.Fprintf(&g.buf, "\tif err := env.Field(%q, &%s); err != nil {\n\t\treturn err\n\t}\n", path, expr)
+		return nil
+	}
+
+	const value = "v"
+	fmt.Fprintf(&g.buf, "\tif %s, ok := env.Lookup(%q); ok {\n", value, path)
+	conv := func(s string) string {
+		if k.conv == "" {
+			return s
+		}
+		return k.conv + "(" + s + ")"
+	}
+	parsed := func(call, what, typ string) {
+		g.imports["fmt"] = true
+		// env.Error returns nil for fields that only warn, which keep their value
+		fmt.Fprintf(&g.buf, "\t\tif parsed, err := %s; err != nil {\n", call)
+		fmt.Fprintf(&g.buf, "\t\t\tif err := env.Error(%q, fmt.Errorf(\"parse %s %%q: %%w\", %s, err)); err != nil {\n\t\t\t\treturn err\n\t\t\t}\n", path, what, value)
+		fmt.Fprintf(&g.buf, "\t\t} else {\n")
+		if typ != "" {
+			fmt.Fprintf(&g.buf, "\t\t\t%s = %s\n", expr, conv(typ+"(parsed)"))
+		} else {
+			fmt.Fprintf(&g.buf, "\t\t\t%s = %s\n", expr, conv("parsed"))
+		}
+		fmt.Fprintf(&g.buf, "\t\t}\n")
+	}
+	switch k.parse {
+	case "string":
+		fmt.Fprintf(&g.buf, "\t\t%s = %s\n", expr, conv(value))
+	case "bool":
+		g.imports["strconv"] = true
+		parsed("strconv.ParseBool("+value+")", "bool", "")
+	case "int":
+		parsed("env.ParseInt("+value+")", "int", k.typ(t))
+	case "uint":
+		parsed("env.ParseUint("+value+")", "uint", k.typ(t))
+	case "float":
+		g.imports["strconv"] = true
+		parsed("strconv.ParseFloat("+value+", 64)", "float", k.typ(t))
+	case "duration":
+		g.imports["time"] = true
+		parsed("time.ParseDuration("+value+")", "duration", "")
+	}
+	g.buf.WriteString("\t}\n")
+	return nil
+}
+
+// typ returns the type parsed values are converted to before any named
+// type conversion, or "" when no conversion is needed
+func (k kind) typ(t ast.Expr) string {
+	if k.conv != "" {
+		// Named types convert straight from the parsed 64-bit value
+		return ""
+	}
+	ident, ok := t.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	switch ident.Name {
+	case "int64", "uint64", "float64":
+		return ""
+	}
+	return ident.Name
+}
+
+// kindOf reports how values of type t are parsed, following named types
+// declared in the package down to a predeclared type or time.Duration
+func (g *generator) kindOf(t ast.Expr, seen map[string]bool) (kind, bool) {
+	switch t := t.(type) {
+	case *ast.Ident:
+		if parse, ok := basicKinds[t.Name]; ok {
+			return kind{parse: parse}, true
+		}
+		decl, ok := g.types[t.Name]
+		if !ok || decl.spec.TypeParams != nil || seen[t.Name] {
+			return kind{}, false
+		}
+		if seen == nil {
+			seen = map[string]bool{}
+		}
+		seen[t.Name] = true
+		k, ok := g.kindOf(decl.spec.Type, seen)
+		if !ok {
+			return kind{}, false
+		}
+		if !decl.spec.Assign.IsValid() {
+			// The outermost named type is the one assigned to
+			k.conv = t.Name
+		}
+		return k, true
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok && pkg.Name == "time" && t.Sel.Name == "Duration" {
+			return kind{parse: "duration"}, true
+		}
+	case *ast.ParenExpr:
+		return g.kindOf(t.X, seen)
+	}
+	return kind{}, false
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tendant/yamlenv/pkg/yamlenv"
+	"gopkg.in/yaml.v3"
+)
+
+// runSet sets keys in a YAML override file, keeping its comments and key order
+func runSet(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("set", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: yamlenv set [flags] <key>=<value>...")
+		flags.PrintDefaults()
+	}
+	file := flags.String("file", "config.local.yaml", "YAML file to edit; created if missing")
+	assignments, err := parseInterspersed(flags, args)
+	if err != nil {
+		return 2
+	}
+	if len(assignments) == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	doc, err := yamlenv.ReadDocument(*file)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	for _, assignment := range assignments {
+		path, value, ok := strings.Cut(assignment, "=")
+		if !ok || path == "" {
+			fmt.Fprintf(stderr, "invalid assignment %q: expected key=value\n", assignment)
+			return 2
+		}
+		node, err := valueNode(value)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", path, err)
+			return 1
+		}
+		if err := doc.Set(path, node); err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", path, err)
+			return 1
+		}
+	}
+	if err := doc.WriteFile(*file); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// runGet prints the value of each key: from the effective config by default,
+// or from a single file with -file
+func runGet(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("get", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: yamlenv get [flags] <key>...")
+		flags.PrintDefaults()
+	}
+	var sources sourceFlags
+	sources.register(flags)
+	file := flags.String("file", "", "read this YAML file alone instead of the effective config")
+	paths, err := parseInterspersed(flags, args)
+	if err != nil {
+		return 2
+	}
+	if len(paths) == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	var lookup func(path string) (any, bool)
+	if *file != "" {
+		doc, err := yamlenv.ReadDocument(*file)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		lookup = func(path string) (any, bool) {
+			var value any
+			ok, err := doc.Decode(path, &value)
+			return value, ok && err == nil
+		}
+	} else {
+		values, err := yamlenv.LoadValues(sources.options())
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		lookup = values.Get
+	}
+
+	status := 0
+	for _, path := range paths {
+		value, ok := lookup(path)
+		if !ok {
+			fmt.Fprintf(stderr, "%s is not set\n", path)
+			status = 1
+			continue
+		}
+		if err := printValue(stdout, value); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	}
+	return status
+}
+
+// parseInterspersed parses flags that may follow positional arguments, as
+// in "set db.host=x -file f", and returns the positional arguments
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+// valueNode parses a command-line value like a YAML scalar, so "8080" is an
+// int and "[a, b]" a list. A value that parses to nothing, like "", " " or
+// "#secret", is kept as a literal string.
+func valueNode(value string) (*yaml.Node, error) {
+	if value == "" {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Style: yaml.DoubleQuotedStyle}, nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &doc); err != nil {
+		return nil, fmt.Errorf("parse value: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, Style: yaml.DoubleQuotedStyle}, nil
+	}
+	return doc.Content[0], nil
+}
+
+// printValue prints scalars as plain text and everything else as YAML
+func printValue(w io.Writer, value any) error {
+	switch value.(type) {
+	case map[string]any, []any:
+		data, err := yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	case nil:
+		_, err := fmt.Fprintln(w, "null")
+		return err
+	default:
+		_, err := fmt.Fprintln(w, value)
+		return err
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that set edits a file in place, keeping comments, and types values
+// like YAML
+func TestSet(t *testing.T) {
+	file := writeConfig(t, "config.local.yaml", "# local overrides\ndb:\n  host: localhost # dev\n")
+
+	code, _, stderr := runCLI(t, "set", "db.host=dev-db", "db.port=5433", "-file", file, "app.tags=[a, b]")
+	require.Equal(t, 0, code, stderr)
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "# local overrides\ndb:\n  host: dev-db # dev\n  port: 5433\napp:\n  tags: [a, b]\n", string(data))
+}
+
+// Test values that parse to an empty YAML document
+func TestSet_LiteralStrings(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.local.yaml")
+
+	code, _, stderr := runCLI(t, "set", "db.pass=#secret", "db.host= ", "db.name=", "-file", file)
+	require.Equal(t, 0, code, stderr)
+
+	code, stdout, stderr := runCLI(t, "get", "-file", file, "db.pass", "db.host", "db.name")
+	require.Equal(t, 0, code, stderr)
+	assert.Equal(t, "#secret\n \n\n", stdout)
+}
+
+func TestSet_Errors(t *testing.T) {
+	file := writeConfig(t, "config.local.yaml", "db:\n  host: localhost\n")
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stderr string
+	}{
+		{"no assignments", []string{"-file", file}, 2, "usage: yamlenv set"},
+		{"no equals sign", []string{"db.host", "-file", file}, 2, `invalid assignment "db.host": expected key=value`},
+		{"bad value", []string{"db.host=[a", "-file", file}, 1, "db.host: parse value"},
+		{"scalar parent", []string{"db.host.name=x", "-file", file}, 1, "db.host.name: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, stderr := runCLI(t, append([]string{"set"}, tt.args...)...)
+			assert.Equal(t, tt.code, code)
+			assert.Contains(t, stderr, tt.stderr)
+		})
+	}
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "db:\n  host: localhost\n", string(data))
+}
+
+// Test that get reads the effective config, or a single file with -file
+func TestGet(t *testing.T) {
+	t.Setenv("GETCLI_DB__PORT", "6543")
+	base := writeConfig(t, "config.yaml", "db:\n  host: localhost\n  port: 5432\n  tags: [a, b]\n")
+	local := writeConfig(t, "config.local.yaml", "db:\n  host: dev-db\n")
+
+	code, stdout, stderr := runCLI(t, "get", "-base", base, "-local", local, "-prefix", "GETCLI_", "db.host", "db.port", "db.tags")
+	require.Equal(t, 0, code, stderr)
+	assert.Equal(t, "dev-db\n6543\n- a\n- b\n", stdout)
+
+	code, stdout, stderr = runCLI(t, "get", "db.host", "-file", base)
+	require.Equal(t, 0, code, stderr)
+	assert.Equal(t, "localhost\n", stdout)
+}
+
+func TestGet_Errors(t *testing.T) {
+	base := writeConfig(t, "config.yaml", "db:\n  host: localhost\n")
+
+	code, _, stderr := runCLI(t, "get")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "usage: yamlenv get")
+
+	code, stdout, stderr := runCLI(t, "get", "-base", base, "db.host", "db.port")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "localhost\n", stdout)
+	assert.Equal(t, "db.port is not set\n", stderr)
+
+	code, _, stderr = runCLI(t, "get", "-base", "missing.yaml", "db.host")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "load base config")
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// runEnv prints the merged config as environment variable assignments, for
+// sourcing in shells and CI jobs
+func runEnv(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("env", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var sources sourceFlags
+	sources.register(flags)
+	format := flags.String("format", "shell", "output format: shell (export lines), dotenv or json")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	var write func(w io.Writer, environ []string) error
+	switch *format {
+	case "shell":
+		write = writeShellEnv
+	case "dotenv":
+		write = writeDotenv
+	case "json":
+		write = writeJSONEnv
+	default:
+		fmt.Fprintf(stderr, "unknown format %q: want shell, dotenv or json\n", *format)
+		return 2
+	}
+
+	environ, err := loadEnviron(sources)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if err := write(stdout, environ); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// validName matches the variable names shells and .env files accept. Names
+// come from config keys, so a key like "$(id)" must not reach a script.
+var validName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// checkNames rejects assignments whose names aren't valid variable names,
+// before anything is written, so no partial script is left behind
+func checkNames(environ []string) error {
+	for _, assignment := range environ {
+		if name, _, _ := strings.Cut(assignment, "="); !validName.MatchString(name) {
+			return fmt.Errorf("invalid variable name %q: rename the config key or use -format json", name)
+		}
+	}
+	return nil
+}
+
+// writeShellEnv writes "export NAME='value'" lines for POSIX shells
+func writeShellEnv(w io.Writer, environ []string) error {
+	if err := checkNames(environ); err != nil {
+		return err
+	}
+	for _, assignment := range environ {
+		name, value, _ := strings.Cut(assignment, "=")
+		quoted := "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+		if _, err := fmt.Fprintf(w, "export %s=%s\n", name, quoted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dotenvEscaper escapes values for double quotes in a .env file
+var dotenvEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "\n", `\n`, "\r", `\r`)
+
+// writeDotenv writes NAME="value" lines in the .env format
+func writeDotenv(w io.Writer, environ []string) error {
+	if err := checkNames(environ); err != nil {
+		return err
+	}
+	for _, assignment := range environ {
+		name, value, _ := strings.Cut(assignment, "=")
+		if _, err := fmt.Fprintf(w, "%s=\"%s\"\n", name, dotenvEscaper.Replace(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeJSONEnv writes a JSON object of names to values
+func writeJSONEnv(w io.Writer, environ []string) error {
+	vars := make(map[string]string, len(environ))
+	for _, assignment := range environ {
+		name, value, _ := strings.Cut(assignment, "=")
+		vars[name] = value
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(vars)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test the output formats, with values that need quoting
+func TestEnv(t *testing.T) {
+	base := writeConfig(t, "config.yaml", "app:\n  name: \"it's $HOME\"\n  port: 8080\n")
+
+	code, stdout, _ := runCLI(t, "env", "-base", base, "-prefix", "ENVCLI_")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "export ENVCLI_APP__NAME='it'\\''s $HOME'\nexport ENVCLI_APP__PORT='8080'\n", stdout)
+
+	code, stdout, _ = runCLI(t, "env", "-base", base, "-prefix", "ENVCLI_", "-format", "dotenv")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "ENVCLI_APP__NAME=\"it's \\$HOME\"\nENVCLI_APP__PORT=\"8080\"\n", stdout)
+
+	code, stdout, _ = runCLI(t, "env", "-base", base, "-prefix", "ENVCLI_", "-format", "json")
+	assert.Equal(t, 0, code)
+	assert.JSONEq(t, `{"ENVCLI_APP__NAME": "it's $HOME", "ENVCLI_APP__PORT": "8080"}`, stdout)
+}
+
+// Test that keys that don't make valid variable names are rejected by the
+// shell and dotenv formats, before anything is written
+func TestEnv_InvalidName(t *testing.T) {
+	base := writeConfig(t, "config.yaml", "app:\n  name: svc\n\"$(touch pwned)\": x\n")
+
+	for _, format := range []string{"shell", "dotenv"} {
+		code, stdout, stderr := runCLI(t, "env", "-base", base, "-format", format)
+		assert.Equal(t, 1, code, format)
+		assert.Empty(t, stdout, format)
+		assert.Contains(t, stderr, `invalid variable name "$(TOUCH PWNED)"`, format)
+	}
+
+	code, stdout, _ := runCLI(t, "env", "-base", base, "-format", "json")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, `"$(TOUCH PWNED)": "x"`)
+}
+
+func TestEnv_Errors(t *testing.T) {
+	code, _, stderr := runCLI(t, "env", "-format", "xml")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown format "xml": want shell, dotenv or json`)
+
+	code, _, _ = runCLI(t, "env", "-bogus")
+	assert.Equal(t, 2, code)
+
+	code, stdout, stderr := runCLI(t, "env", "-base", "missing.yaml")
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "missing.yaml")
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/tendant/yamlenv/pkg/yamlenv"
+)
+
+// runExec loads the config, exports it as environment variables named with
+// -prefix and -delimiter, and runs a command with them, like envdir or
+// dotenv-cli. Signals are passed on to the command, and its exit code
+// becomes ours.
+func runExec(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("exec", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: yamlenv exec [flags] -- <command> [args]...")
+		flags.PrintDefaults()
+	}
+	var sources sourceFlags
+	sources.register(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	environ, err := loadEnviron(sources)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	cmd := exec.Command(flags.Arg(0), flags.Args()[1:]...)
+	cmd.Env = append(os.Environ(), environ...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, stdout, stderr
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 127
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer func() {
+		signal.Stop(signals)
+		close(signals)
+	}()
+	go func() {
+		for sig := range signals {
+			_ = cmd.Process.Signal(sig)
+		}
+	}()
+
+	if err := cmd.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// loadEnviron loads the merged config for the flags and converts it into
+// variable assignments, named as -prefix and -delimiter read them. Names
+// keep the -section path, as loading expects.
+func loadEnviron(sources sourceFlags) ([]string, error) {
+	values, err := yamlenv.LoadValues(sources.options())
+	if err != nil {
+		return nil, err
+	}
+	var cfg any = values.All()
+	if sources.section != "" {
+		segments := strings.Split(sources.section, ".")
+		for i := len(segments) - 1; i >= 0; i-- {
+			cfg = map[string]any{segments[i]: cfg}
+		}
+	}
+	return yamlenv.ToEnviron(cfg, sources.prefix, sources.delimiter), nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that the command gets the config as environment variables and its
+// exit code becomes ours
+func TestExec(t *testing.T) {
+	base := writeConfig(t, "config.yaml", "app:\n  name: svc\n  port: 8080\n")
+
+	code, stdout, _ := runCLI(t, "exec", "-base", base, "-prefix", "EXECCLI_", "--",
+		"sh", "-c", `echo "$EXECCLI_APP__NAME:$EXECCLI_APP__PORT"; exit 3`)
+	assert.Equal(t, 3, code)
+	assert.Equal(t, "svc:8080\n", stdout)
+
+	code, stdout, _ = runCLI(t, "exec", "-base", base, "-prefix", "EXECCLI_", "-section", "app", "--",
+		"sh", "-c", `echo "$EXECCLI_APP__NAME"`)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "svc\n", stdout, "names keep the section path")
+}
+
+func TestExec_Errors(t *testing.T) {
+	base := writeConfig(t, "config.yaml", "app:\n  name: svc\n")
+
+	code, _, stderr := runCLI(t, "exec", "-base", base)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "usage: yamlenv exec [flags] -- <command> [args]...")
+
+	code, _, _ = runCLI(t, "exec", "-bogus", "--", "true")
+	assert.Equal(t, 2, code)
+
+	code, stdout, stderr := runCLI(t, "exec", "-base", "missing.yaml", "--", "true")
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "missing.yaml")
+
+	code, _, stderr = runCLI(t, "exec", "-base", base, "--", "yamlenv-no-such-command")
+	assert.Equal(t, 127, code)
+	assert.Contains(t, stderr, "executable file not found")
+}
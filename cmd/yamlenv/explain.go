@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/tendant/yamlenv/pkg/yamlenv"
+)
+
+// runExplain prints how each given key was resolved
+func runExplain(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: yamlenv explain [flags] <key>...")
+		fs.PrintDefaults()
+	}
+	var sources sourceFlags
+	sources.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	for _, path := range fs.Args() {
+		e, err := yamlenv.Explain(sources.options(), path)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		fmt.Fprint(stdout, e)
+	}
+	return 0
+}
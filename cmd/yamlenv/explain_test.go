@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that explain shows every layer's value and the winner
+func TestExplain(t *testing.T) {
+	t.Setenv("EXPLAINCLI_APP__NAME", "from-env")
+	base := writeConfig(t, "config.yaml", "app:\n  name: svc\n  port: 8080\n")
+
+	code, stdout, stderr := runCLI(t, "explain", "-base", base, "-prefix", "EXPLAINCLI_", "app.name", "app.port")
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, `app.name = from-env (from env:EXPLAINCLI_APP__NAME)
+  base: svc
+  env:  EXPLAINCLI_APP__NAME=from-env
+app.port = 8080 (from base)
+  base: 8080
+  env:  EXPLAINCLI_APP__PORT (not set)
+`, stdout)
+}
+
+func TestExplain_Errors(t *testing.T) {
+	code, _, stderr := runCLI(t, "explain")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "usage: yamlenv explain [flags] <key>...")
+
+	code, stdout, stderr := runCLI(t, "explain", "-base", "missing.yaml", "app.name")
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "load base config")
+}
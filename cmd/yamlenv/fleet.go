@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fleetResult is the checksum read from one instance
+type fleetResult struct {
+	target   string
+	checksum string
+	err      error
+}
+
+// runFleet reads the config checksum of every target, either a debug
+// handler URL or an effective-config artifact, and reports the ones that
+// differ from -expect, or without it, from the most common checksum. It
+// only reads, so it is safe to point at production.
+func runFleet(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fleet", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: yamlenv fleet [flags] <url|file>...")
+		flags.PrintDefaults()
+	}
+	expect := flags.String("expect", "", "expected checksum; default: the most common one")
+	hostsFile := flags.String("hosts", "", "file listing targets, one per line (# starts a comment)")
+	timeout := flags.Duration("timeout", 10*time.Second, "timeout per HTTP request")
+	targets, err := parseInterspersed(flags, args)
+	if err != nil {
+		return 2
+	}
+	if *hostsFile != "" {
+		listed, err := readTargets(*hostsFile)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		targets = append(targets, listed...)
+	}
+	if len(targets) == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	client := &http.Client{Timeout: *timeout}
+	results := make([]fleetResult, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checksum, err := readChecksum(client, target)
+			results[i] = fleetResult{target: target, checksum: checksum, err: err}
+		}()
+	}
+	wg.Wait()
+
+	want := *expect
+	if want == "" {
+		want = mostCommonChecksum(results)
+	}
+	status := 0
+	for _, r := range results {
+		switch {
+		case r.err != nil:
+			fmt.Fprintf(stdout, "%s\terror: %v\n", r.target, r.err)
+			status = 1
+		case r.checksum != want:
+			fmt.Fprintf(stdout, "%s\t%s\tDIFFERS\n", r.target, r.checksum)
+			status = 1
+		default:
+			fmt.Fprintf(stdout, "%s\t%s\tok\n", r.target, r.checksum)
+		}
+	}
+	return status
+}
+
+// readTargets reads a hosts file, skipping blank lines and comments
+func readTargets(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var targets []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		if line = strings.TrimSpace(line); line != "" {
+			targets = append(targets, line)
+		}
+	}
+	return targets, nil
+}
+
+// readChecksum reads the checksum of a target: the "checksum" field of a
+// yamlenv.Handler response, or the header of a WriteEffectiveConfig artifact,
+// fetched over HTTP or read from a file
+func readChecksum(client *http.Client, target string) (string, error) {
+	if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+		f, err := os.Open(target)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		return artifactChecksum(f)
+	}
+
+	resp, err := client.Get(target)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		return artifactChecksum(resp.Body)
+	}
+	var debug struct {
+		Checksum string `json:"checksum"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&debug); err != nil {
+		return "", fmt.Errorf("decode debug response: %w", err)
+	}
+	if debug.Checksum == "" {
+		return "", errors.New("debug response has no checksum")
+	}
+	return debug.Checksum, nil
+}
+
+// artifactChecksum finds the "# checksum: ..." line in the comment header
+// WriteEffectiveConfig writes
+func artifactChecksum(r io.Reader) (string, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		if checksum, ok := strings.CutPrefix(line, "# checksum: "); ok {
+			return strings.TrimSpace(checksum), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", errors.New("no checksum header; not an effective-config artifact")
+}
+
+// mostCommonChecksum returns the checksum most targets report, preferring
+// the smallest on a tie so the result is stable
+func mostCommonChecksum(results []fleetResult) string {
+	counts := map[string]int{}
+	for _, r := range results {
+		if r.err == nil {
+			counts[r.checksum]++
+		}
+	}
+	checksums := make([]string, 0, len(counts))
+	for checksum := range counts {
+		checksums = append(checksums, checksum)
+	}
+	sort.Slice(checksums, func(i, j int) bool {
+		if counts[checksums[i]] != counts[checksums[j]] {
+			return counts[checksums[i]] > counts[checksums[j]]
+		}
+		return checksums[i] < checksums[j]
+	})
+	if len(checksums) == 0 {
+		return ""
+	}
+	return checksums[0]
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// debugServer serves a yamlenv.Handler-like response with checksum
+func debugServer(t *testing.T, checksum string) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"checksum":"` + checksum + `","config":{}}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// artifact writes an effective-config artifact with checksum in its header
+func artifact(t *testing.T, checksum string) string {
+	return writeConfig(t, "effective.yaml", "# effective config\n# checksum: "+checksum+"\napp:\n  port: 1\n")
+}
+
+// Test that targets agreeing with the majority pass and the others are
+// reported
+func TestFleet(t *testing.T) {
+	a, b, c := debugServer(t, "aaa"), debugServer(t, "aaa"), artifact(t, "bbb")
+
+	code, stdout, _ := runCLI(t, "fleet", a, b)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, a+"\taaa\tok\n"+b+"\taaa\tok\n", stdout)
+
+	code, stdout, _ = runCLI(t, "fleet", a, c, b)
+	assert.Equal(t, 1, code)
+	assert.Equal(t, a+"\taaa\tok\n"+c+"\tbbb\tDIFFERS\n"+b+"\taaa\tok\n", stdout)
+}
+
+// Test that -expect overrides the majority and -hosts adds targets
+func TestFleet_ExpectAndHosts(t *testing.T) {
+	a, b := debugServer(t, "aaa"), artifact(t, "bbb")
+	hosts := writeConfig(t, "hosts", "# production\n"+a+"\n\n"+b+" # canary\n")
+
+	code, stdout, _ := runCLI(t, "fleet", "-expect", "bbb", "-hosts", hosts)
+	assert.Equal(t, 1, code)
+	assert.Equal(t, a+"\taaa\tDIFFERS\n"+b+"\tbbb\tok\n", stdout)
+}
+
+// Test that unreadable targets are reported as errors
+func TestFleet_Errors(t *testing.T) {
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+	noChecksum := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"config":{}}`))
+	}))
+	defer noChecksum.Close()
+	plain := writeConfig(t, "config.yaml", "app:\n  port: 1\n")
+	missing := filepath.Join(t.TempDir(), "missing.yaml")
+
+	code, stdout, _ := runCLI(t, "fleet", debugServer(t, "aaa"), notFound.URL, noChecksum.URL, plain, missing)
+	assert.Equal(t, 1, code)
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	assert.Len(t, lines, 5)
+	assert.Contains(t, lines[0], "\taaa\tok")
+	assert.Equal(t, notFound.URL+"\terror: unexpected status 404 Not Found", lines[1])
+	assert.Equal(t, noChecksum.URL+"\terror: debug response has no checksum", lines[2])
+	assert.Equal(t, plain+"\terror: no checksum header; not an effective-config artifact", lines[3])
+	assert.Contains(t, lines[4], missing+"\terror: open "+missing)
+}
+
+func TestFleet_Usage(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "fleet")
+	assert.Equal(t, 2, code)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "usage: yamlenv fleet [flags] <url|file>...")
+
+	code, _, stderr = runCLI(t, "fleet", "-hosts", filepath.Join(t.TempDir(), "missing"))
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "no such file")
+
+	code, _, _ = runCLI(t, "fleet", "-bogus")
+	assert.Equal(t, 2, code)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/tendant/yamlenv/pkg/yamlenv"
+)
+
+// runLint prints every lint issue and fails if any is an error, or with
+// -strict, a warning
+func runLint(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var sources sourceFlags
+	sources.register(fs)
+	profile := fs.String("profile", "", "deployment profile, e.g. prod")
+	strict := fs.Bool("strict", false, "fail on warnings too")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	issues := yamlenv.Lint(yamlenv.LintOptions{LoaderOptions: sources.options(), Profile: *profile})
+	failed := false
+	for _, issue := range issues {
+		fmt.Fprintln(stdout, issue)
+		if issue.Severity == yamlenv.SeverityError || *strict {
+			failed = true
+		}
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	clean := writeConfig(t, "config.yaml", "app:\n  name: svc\n")
+	secret := writeConfig(t, "config.yaml", "db:\n  password: hunter2\n")
+	localhost := writeConfig(t, "config.yaml", "db:\n  host: localhost\n")
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string
+	}{
+		{"clean", []string{"-base", clean}, 0, ""},
+		{"error", []string{"-base", secret}, 1, "error: db.password (base): secret value in plain YAML; move it to SecretsSource or the environment [secret-in-yaml]\n"},
+		{"warning", []string{"-base", localhost, "-profile", "prod"}, 0, "warning: db.host (base): \"localhost\" points at localhost in prod [localhost-in-prod]\n"},
+		{"missing base", []string{"-base", "missing.yaml"}, 1, "error: load base config: open config source: open missing.yaml: no such file or directory [load]\n"},
+		{"strict warning", []string{"-base", localhost, "-profile", "prod", "-strict"}, 1, "warning: db.host (base): \"localhost\" points at localhost in prod [localhost-in-prod]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, _ := runCLI(t, append([]string{"lint"}, tt.args...)...)
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.stdout, stdout)
+		})
+	}
+}
+
+func TestLint_BadFlag(t *testing.T) {
+	code, _, stderr := runCLI(t, "lint", "-nope")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "flag provided but not defined: -nope")
+}
@@ -0,0 +1,92 @@
+// Command yamlenv inspects layered YAML + env configuration from the shell.
+//
+//	yamlenv lint -base config.yaml -local config.local.yaml -prefix APP_ -profile prod
+//	yamlenv explain -base config.yaml -prefix APP_ db.host
+//	yamlenv set db.host=dev-db -file config.local.yaml
+//	yamlenv get -base config.yaml -local config.local.yaml db.host
+//	yamlenv exec -base config.yaml -prefix APP_ -- ./legacy-binary
+//	yamlenv env -base config.yaml -prefix APP_ -format dotenv
+//	yamlenv fleet -expect 3f2a... http://app-1:8081/debug/config http://app-2:8081/debug/config
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tendant/yamlenv/pkg/yamlenv"
+)
+
+// command is a yamlenv subcommand
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+var commands = []command{
+	{name: "lint", summary: "check the effective config against the built-in lint rules", run: runLint},
+	{name: "explain", summary: "show the value each layer gives a key and which one wins", run: runExplain},
+	{name: "get", summary: "print the value of a key", run: runGet},
+	{name: "set", summary: "set keys in an override file, keeping its comments", run: runSet},
+	{name: "exec", summary: "run a command with the config exported as env vars", run: runExec},
+	{name: "env", summary: "print the config as shell, dotenv or JSON env assignments", run: runEnv},
+	{name: "fleet", summary: "report instances whose config checksum differs", run: runFleet},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 {
+		for _, cmd := range commands {
+			if cmd.name == args[0] {
+				return cmd.run(args[1:], stdout, stderr)
+			}
+		}
+	}
+	fmt.Fprintln(stderr, "usage: yamlenv <command> [flags]")
+	fmt.Fprintln(stderr, "\ncommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(stderr, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	return 2
+}
+
+// sourceFlags are the layer and env flags shared by every command
+type sourceFlags struct {
+	base      string
+	local     string
+	secrets   string
+	prefix    string
+	delimiter string
+	section   string
+}
+
+func (f *sourceFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.base, "base", "config.yaml", "base YAML file")
+	fs.StringVar(&f.local, "local", "", "optional local override file")
+	fs.StringVar(&f.secrets, "secrets", "", "optional secrets directory (one file per key, \"__\" nests)")
+	fs.StringVar(&f.prefix, "prefix", "", "environment variable prefix, e.g. APP_")
+	fs.StringVar(&f.delimiter, "delimiter", "__", "environment variable nesting delimiter")
+	fs.StringVar(&f.section, "section", "", "dot-separated subtree to load")
+}
+
+// options returns LoaderOptions for the flags, without a Target
+func (f *sourceFlags) options() yamlenv.LoaderOptions {
+	opts := yamlenv.LoaderOptions{
+		BaseSource: yamlenv.FileSource(f.base),
+		EnvPrefix:  f.prefix,
+		Delimiter:  f.delimiter,
+		Section:    f.section,
+	}
+	if f.local != "" {
+		opts.LocalSource = yamlenv.FileSource(f.local)
+	}
+	if f.secrets != "" {
+		opts.SecretsSource = yamlenv.DirSource(f.secrets, "__")
+	}
+	return opts
+}
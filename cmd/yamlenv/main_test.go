@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runCLI runs a yamlenv command line and returns its exit code and output
+func runCLI(t *testing.T, args ...string) (code int, stdout, stderr string) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	code = run(args, &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+// writeConfig writes content to name in a temporary directory and returns
+// its path
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+// Test that unknown commands print the usage
+func TestRun_Usage(t *testing.T) {
+	for _, args := range [][]string{nil, {"frobnicate"}} {
+		code, stdout, stderr := runCLI(t, args...)
+		assert.Equal(t, 2, code)
+		assert.Empty(t, stdout)
+		assert.Contains(t, stderr, "usage: yamlenv <command> [flags]")
+		assert.Contains(t, stderr, "lint ")
+	}
+}
@@ -0,0 +1,163 @@
+package yamlenv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// aliasTag marks placeholder nodes that stand in for aliases while layers
+// are merged with ResolveAliasesAfterMerge
+const aliasTag = "!yamlenv.alias"
+
+// checkAliases fails if an alias refers to one of its own ancestors, which
+// would make the document infinitely deep
+func checkAliases(n *yaml.Node) error {
+	return walkAliases(n, map[*yaml.Node]bool{}, map[*yaml.Node]bool{})
+}
+
+func walkAliases(n *yaml.Node, active, done map[*yaml.Node]bool) error {
+	if n == nil || done[n] {
+		return nil
+	}
+	if active[n] {
+		return fmt.Errorf("alias cycle: anchor %q contains an alias to itself", n.Anchor)
+	}
+	active[n] = true
+	if n.Kind == yaml.AliasNode {
+		if err := walkAliases(n.Alias, active, done); err != nil {
+			return err
+		}
+	}
+	for _, child := range n.Content {
+		if err := walkAliases(child, active, done); err != nil {
+			return err
+		}
+	}
+	delete(active, n)
+	done[n] = true
+	return nil
+}
+
+// resolveDocument returns a copy of n with every alias replaced by a copy
+// of its anchored node and merge keys ("<<") expanded into explicit keys, so
+// layers merge as plain trees. Aliases always refer to the anchor in their
+// own document.
+func resolveDocument(n *yaml.Node) *yaml.Node {
+	n = resolveAlias(n)
+	if n == nil {
+		return nil
+	}
+	out := *n
+	out.Anchor = ""
+	content := n.Content
+	if n.Kind == yaml.MappingNode {
+		content = flattenMapping(n)
+	}
+	out.Content = make([]*yaml.Node, len(content))
+	for i, child := range content {
+		out.Content[i] = resolveDocument(child)
+	}
+	return &out
+}
+
+// deferredAliases merges layers whose aliases are kept as placeholders and
+// resolves them against the merged tree, so an alias sees later layers'
+// changes to its anchored node
+type deferredAliases struct {
+	paths     map[string]string     // anchor -> path of the anchored node; the last layer to define it wins
+	originals map[string]*yaml.Node // anchor -> anchored node, used when the path no longer resolves
+}
+
+func newDeferredAliases() *deferredAliases {
+	return &deferredAliases{paths: map[string]string{}, originals: map[string]*yaml.Node{}}
+}
+
+// deferLayer returns a copy of a layer's root with aliases replaced by
+// placeholders, recording the layer's anchors
+func (d *deferredAliases) deferLayer(n *yaml.Node, path string) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	if n.Kind == yaml.AliasNode {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: aliasTag, Value: n.Value, Line: n.Line, Column: n.Column}
+	}
+	if n.Anchor != "" {
+		d.paths[n.Anchor] = path
+		d.originals[n.Anchor] = n
+	}
+	out := *n
+	out.Anchor = ""
+	out.Content = make([]*yaml.Node, len(n.Content))
+	for i, child := range n.Content {
+		childPath := path
+		switch {
+		case n.Kind == yaml.MappingNode && i%2 == 1:
+			childPath = joinPath(path, n.Content[i-1].Value)
+		case n.Kind == yaml.SequenceNode:
+			childPath = joinPath(path, strconv.Itoa(i))
+		}
+		out.Content[i] = d.deferLayer(child, childPath)
+	}
+	return &out
+}
+
+// resolve replaces the placeholders below n with copies of the merged nodes
+// their anchors point to, and expands merge keys
+func (d *deferredAliases) resolve(root, n *yaml.Node, visiting map[string]bool) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	if n.Tag == aliasTag {
+		name := n.Value
+		target := lookupNodePath(root, d.paths[name])
+		if target == nil || visiting[name] {
+			return resolveDocument(d.originals[name])
+		}
+		visiting[name] = true
+		defer delete(visiting, name)
+		return d.resolve(root, target, visiting)
+	}
+
+	out := *n
+	out.Content = make([]*yaml.Node, len(n.Content))
+	for i, child := range n.Content {
+		out.Content[i] = d.resolve(root, child, visiting)
+	}
+	if out.Kind == yaml.MappingNode {
+		out.Content = flattenMapping(&out)
+	}
+	return &out
+}
+
+// lookupNodePath is like lookupPath but also accepts sequence indexes
+func lookupNodePath(root *yaml.Node, path string) *yaml.Node {
+	if path == "" {
+		return root
+	}
+	node := root
+	for _, key := range strings.Split(path, ".") {
+		node = resolveAlias(node)
+		switch {
+		case node == nil:
+			return nil
+		case node.Kind == yaml.MappingNode:
+			idx := mappingIndex(node, key)
+			if idx < 0 {
+				return nil
+			}
+			node = node.Content[idx+1]
+		case node.Kind == yaml.SequenceNode:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(node.Content) {
+				return nil
+			}
+			node = node.Content[idx]
+		default:
+			return nil
+		}
+	}
+	return node
+}
@@ -0,0 +1,89 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type AliasTestConfig struct {
+	Defaults ServiceDefaults            `yaml:"defaults"`
+	Services map[string]ServiceDefaults `yaml:"services"`
+}
+
+type ServiceDefaults struct {
+	Timeout int    `yaml:"timeout"`
+	Retries int    `yaml:"retries"`
+	Name    string `yaml:"name"`
+}
+
+const aliasBaseYAML = `
+defaults: &defaults
+  timeout: 5
+  retries: 3
+services:
+  api:
+    <<: *defaults
+    name: api
+  web: *defaults
+`
+
+func loadAliasTest(t *testing.T, local string, reresolve bool) AliasTestConfig {
+	var cfg AliasTestConfig
+	require.NoError(t, LoadConfig(LoaderOptions{
+		BaseSource:               ReaderSource(strings.NewReader(aliasBaseYAML)),
+		LocalSource:              ReaderSource(strings.NewReader(local)),
+		ResolveAliasesAfterMerge: reresolve,
+		Target:                   &cfg,
+	}))
+	return cfg
+}
+
+// Test that aliases resolve within their own document by default
+func TestLoadConfig_AliasesResolvePerDocument(t *testing.T) {
+	cfg := loadAliasTest(t, "defaults:\n  timeout: 10\n", false)
+
+	assert.Equal(t, 10, cfg.Defaults.Timeout)
+	assert.Equal(t, ServiceDefaults{Timeout: 5, Retries: 3, Name: "api"}, cfg.Services["api"])
+	assert.Equal(t, ServiceDefaults{Timeout: 5, Retries: 3}, cfg.Services["web"])
+}
+
+// Test that merge keys in a local layer override explicit base values
+func TestLoadConfig_LocalMergeKeysOverrideBase(t *testing.T) {
+	local := "fast: &fast\n  timeout: 1\nservices:\n  api:\n    <<: *fast\n"
+	cfg := loadAliasTest(t, local, false)
+
+	assert.Equal(t, ServiceDefaults{Timeout: 1, Retries: 3, Name: "api"}, cfg.Services["api"])
+}
+
+// Test that ResolveAliasesAfterMerge lets local changes reach every alias
+func TestLoadConfig_ResolveAliasesAfterMerge(t *testing.T) {
+	cfg := loadAliasTest(t, "defaults:\n  timeout: 10\nservices:\n  web:\n    name: web\n", true)
+
+	assert.Equal(t, 10, cfg.Defaults.Timeout)
+	assert.Equal(t, ServiceDefaults{Timeout: 10, Retries: 3, Name: "api"}, cfg.Services["api"])
+	assert.Equal(t, ServiceDefaults{Timeout: 10, Retries: 3, Name: "web"}, cfg.Services["web"])
+}
+
+// Test that aliases fall back to their original node when the anchor is removed
+func TestLoadConfig_ResolveAliasesAfterMergeRemovedAnchor(t *testing.T) {
+	cfg := loadAliasTest(t, "defaults: !unset\n", true)
+
+	assert.Equal(t, ServiceDefaults{}, cfg.Defaults)
+	assert.Equal(t, ServiceDefaults{Timeout: 5, Retries: 3}, cfg.Services["web"])
+}
+
+// Test that self-referencing anchors are rejected instead of recursing forever
+func TestLoadConfig_AliasCycle(t *testing.T) {
+	var cfg AliasTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("services: &loop\n  api:\n    nested: *loop\n")),
+		Target:     &cfg,
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "load base config")
+	assert.Contains(t, err.Error(), `alias cycle: anchor "loop"`)
+}
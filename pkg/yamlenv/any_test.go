@@ -0,0 +1,90 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type PluginTestConfig struct {
+	Plugins map[string]any `yaml:"plugins"`
+	Extra   any            `yaml:"extra"`
+	Hosts   []string       `yaml:"hosts"`
+	Ports   [2]int         `yaml:"ports"`
+}
+
+const pluginYAML = `
+plugins:
+  cache:
+    size: 10
+extra:
+  nested: [1, 2]
+hosts: [a]
+`
+
+// Test that YAML populates any fields with their natural types
+func TestLoadConfig_AnyFieldsFromYAML(t *testing.T) {
+	var cfg PluginTestConfig
+	require.NoError(t, LoadConfig(LoaderOptions{BaseSource: ReaderSource(strings.NewReader(pluginYAML)), Target: &cfg}))
+
+	assert.Equal(t, map[string]any{"cache": map[string]any{"size": 10}}, cfg.Plugins)
+	assert.Equal(t, map[string]any{"nested": []any{1, 2}}, cfg.Extra)
+}
+
+// Test that EnvJSON overrides any, map, slice and array fields
+func TestLoadConfig_EnvJSON(t *testing.T) {
+	setEnvVar(t, "JSON_PLUGINS", `{"auth": {"enabled": true, "ttl": 30}}`)
+	setEnvVar(t, "JSON_EXTRA", `[1, "two"]`)
+	setEnvVar(t, "JSON_HOSTS", `["b", "c"]`)
+	setEnvVar(t, "JSON_PORTS", `[80, 443]`)
+
+	var cfg PluginTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader(pluginYAML)),
+		EnvPrefix:  "JSON_",
+		Delimiter:  "__",
+		EnvJSON:    true,
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"auth": map[string]any{"enabled": true, "ttl": 30}}, cfg.Plugins, "env replaces the whole map")
+	assert.Equal(t, []any{1, "two"}, cfg.Extra)
+	assert.Equal(t, []string{"b", "c"}, cfg.Hosts)
+	assert.Equal(t, [2]int{80, 443}, cfg.Ports)
+}
+
+// Test that any fields take the raw string without EnvJSON
+func TestLoadConfig_AnyFieldRawString(t *testing.T) {
+	setEnvVar(t, "RAWANY_EXTRA", `{"a": 1}`)
+
+	var cfg PluginTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader(pluginYAML)),
+		EnvPrefix:  "RAWANY_",
+		Delimiter:  "__",
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, `{"a": 1}`, cfg.Extra)
+}
+
+// Test that invalid JSON is reported with the field path
+func TestLoadConfig_EnvJSONInvalid(t *testing.T) {
+	setEnvVar(t, "BADJSON_HOSTS", `["unterminated`)
+
+	var cfg PluginTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader(pluginYAML)),
+		EnvPrefix:  "BADJSON_",
+		Delimiter:  "__",
+		EnvJSON:    true,
+		Target:     &cfg,
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `set field hosts from BADJSON_HOSTS="[\"unterminated" ([]string): parse JSON`)
+}
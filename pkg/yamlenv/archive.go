@@ -0,0 +1,108 @@
+package yamlenv
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+// ZipSource creates a ConfigSource from the file innerPath inside the zip
+// archive at archivePath, so config packed in a deployment bundle can be
+// loaded without extracting it. A missing entry is reported as
+// fs.ErrNotExist, so a LocalSource in an archive is optional as usual.
+//
+//	local := yamlenv.ZipSource("bundle.zip", "config/config.local.yaml")
+func ZipSource(archivePath, innerPath string) ConfigSource {
+	return func() (io.ReadCloser, error) {
+		archive, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return nil, err
+		}
+		file, err := archive.Open(archiveEntryName(innerPath))
+		if err != nil {
+			archive.Close()
+			return nil, fmt.Errorf("%s: %w", archivePath, err)
+		}
+		if info, err := file.Stat(); err == nil && info.IsDir() {
+			file.Close()
+			archive.Close()
+			return nil, fmt.Errorf("%s: %s is a directory", archivePath, innerPath)
+		}
+		return &multiCloser{Reader: file, closers: []io.Closer{file, archive}}, nil
+	}
+}
+
+// TarSource creates a ConfigSource from the file innerPath inside the tar
+// archive at archivePath, which may be gzip-compressed (.tar.gz, .tgz). A
+// missing entry is reported as fs.ErrNotExist.
+//
+//	base := yamlenv.TarSource("bundle.tar.gz", "config/config.yaml")
+func TarSource(archivePath, innerPath string) ConfigSource {
+	return func() (io.ReadCloser, error) {
+		f, err := os.Open(archivePath)
+		if err != nil {
+			return nil, err
+		}
+		closers := []io.Closer{f}
+		fail := func(err error) (io.ReadCloser, error) {
+			for _, c := range closers {
+				c.Close()
+			}
+			return nil, fmt.Errorf("%s: %w", archivePath, err)
+		}
+
+		buffered := bufio.NewReader(f)
+		var r io.Reader = buffered
+		if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+			gz, err := gzip.NewReader(buffered)
+			if err != nil {
+				return fail(err)
+			}
+			closers = append([]io.Closer{gz}, closers...)
+			r = gz
+		}
+
+		name := archiveEntryName(innerPath)
+		tr := tar.NewReader(r)
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				return fail(&fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist})
+			}
+			if err != nil {
+				return fail(err)
+			}
+			if archiveEntryName(hdr.Name) == name && hdr.Typeflag != tar.TypeDir {
+				return &multiCloser{Reader: tr, closers: closers}, nil
+			}
+		}
+	}
+}
+
+// archiveEntryName normalizes a path inside an archive: slash-separated,
+// without a leading "/" or "./"
+func archiveEntryName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(name, "\\", "/")), "/")
+}
+
+// multiCloser is a reader whose Close closes several underlying closers
+type multiCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (m *multiCloser) Close() error {
+	var errs []error
+	for _, c := range m.closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,88 @@
+package yamlenv
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var archiveTestFiles = map[string]string{
+	"config/config.yaml": "app:\n  name: bundled\n  port: 8080\n",
+	"assets/logo.txt":    "logo",
+}
+
+func writeTestZip(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "bundle.zip")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, content := range archiveTestFiles {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = io.WriteString(w, content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return path
+}
+
+func writeTestTar(t *testing.T, name string, compress bool) string {
+	path := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var w io.Writer = f
+	if compress {
+		gz := gzip.NewWriter(f)
+		defer gz.Close()
+		w = gz
+	}
+	tw := tar.NewWriter(w)
+	defer tw.Close()
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "./config/", Typeflag: tar.TypeDir, Mode: 0o755}))
+	for name, content := range archiveTestFiles {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: "./" + name, Mode: 0o644, Size: int64(len(content))}))
+		_, err := io.WriteString(tw, content)
+		require.NoError(t, err)
+	}
+	return path
+}
+
+// Test that config is read from zip, tar and gzipped tar archives, and that
+// a missing entry leaves an optional local source out
+func TestArchiveSources(t *testing.T) {
+	sources := map[string]func(inner string) ConfigSource{
+		"zip":    func(inner string) ConfigSource { return ZipSource(writeTestZip(t), inner) },
+		"tar":    func(inner string) ConfigSource { return TarSource(writeTestTar(t, "bundle.tar", false), inner) },
+		"tar.gz": func(inner string) ConfigSource { return TarSource(writeTestTar(t, "bundle.tgz", true), inner) },
+	}
+	for name, source := range sources {
+		t.Run(name, func(t *testing.T) {
+			var cfg TestConfig
+			require.NoError(t, LoadConfig(LoaderOptions{
+				BaseSource:  source("/config/config.yaml"),
+				LocalSource: source("config/config.local.yaml"),
+				Target:      &cfg,
+			}))
+			assert.Equal(t, "bundled", cfg.App.Name)
+			assert.Equal(t, 8080, cfg.App.Port)
+
+			_, err := source("config/missing.yaml")()
+			assert.ErrorIs(t, err, fs.ErrNotExist)
+			_, err = source("config")()
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := TarSource(filepath.Join(t.TempDir(), "none.tar"), "config.yaml")()
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
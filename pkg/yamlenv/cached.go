@@ -0,0 +1,100 @@
+package yamlenv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cacheFile is the file Cached keeps the last successful fetch in
+const cacheFile = "source.cache"
+
+// Cached wraps a remote source with an on-disk cache in dir, so an outage of
+// the config server doesn't block restarts. Every successful fetch is saved;
+// while the saved copy is younger than ttl it is served without fetching,
+// and once it is older the source is fetched again. If that fetch fails the
+// stale copy is served and the load reports a warning in LoadResult.Warnings
+// and to LoaderOptions.Logger. Without a saved copy, errors are returned as
+// usual. A ttl of 0 always fetches and uses the cache only when the source
+// fails.
+//
+// dir holds the cache of one source and is created if needed. The cache file
+// is written with mode 0600, as it may contain secrets.
+//
+//	base := yamlenv.Cached(yamlenv.HTTPSource(url), "/var/cache/myapp/config", 5*time.Minute)
+func Cached(source ConfigSource, dir string, ttl time.Duration) ConfigSource {
+	path := filepath.Join(dir, cacheFile)
+	return func() (io.ReadCloser, error) {
+		cached, modTime, cacheErr := readCache(path)
+		age := time.Since(modTime)
+		if cacheErr == nil && ttl > 0 && age < ttl {
+			return io.NopCloser(bytes.NewReader(cached)), nil
+		}
+
+		data, err := readSource(source)
+		if err != nil {
+			if cacheErr != nil {
+				return nil, err
+			}
+			return staleReader{
+				ReadCloser: io.NopCloser(bytes.NewReader(cached)),
+				err:        fmt.Errorf("config source unavailable, using cached copy from %s ago: %w", age.Round(time.Second), err),
+			}, nil
+		}
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("create cache dir: %w", err)
+		}
+		if err := writeFileAtomic(path, data, 0o600); err != nil {
+			return nil, fmt.Errorf("write cache: %w", err)
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+}
+
+// staleReader carries the reason Cached served a stale copy to the loader
+type staleReader struct {
+	io.ReadCloser
+	err error
+}
+
+// captureStale returns source, recording in warning why a Cached source
+// served a stale copy, also through a Named label
+func captureStale(source ConfigSource, warning *error) ConfigSource {
+	return func() (io.ReadCloser, error) {
+		rc, err := source()
+		inner := rc
+		if r, ok := rc.(labeledReader); ok {
+			inner = r.ReadCloser
+		}
+		if r, ok := inner.(staleReader); ok {
+			*warning = r.err
+		}
+		return rc, err
+	}
+}
+
+// readCache returns the cached data and when it was saved
+func readCache(path string) ([]byte, time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return data, info.ModTime(), nil
+}
+
+// readSource reads a source in full
+func readSource(source ConfigSource) ([]byte, error) {
+	reader, err := source()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,98 @@
+package yamlenv
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakySource serves body, or fails while down is set, counting fetches
+type flakySource struct {
+	body    string
+	down    bool
+	fetches int
+}
+
+func (s *flakySource) source() (io.ReadCloser, error) {
+	s.fetches++
+	if s.down {
+		return nil, errors.New("connection refused")
+	}
+	return io.NopCloser(strings.NewReader(s.body)), nil
+}
+
+// Test that fresh copies are served without fetching and stale ones only
+// when the source fails
+func TestCached(t *testing.T) {
+	logger := &recordingLogger{}
+	dir := filepath.Join(t.TempDir(), "cache")
+	remote := &flakySource{body: "app:\n  name: v1\n"}
+	source := Cached(remote.source, dir, time.Hour)
+	var warnings []error
+	load := func() (string, error) {
+		var cfg TestConfig
+		result, err := Load(LoaderOptions{BaseSource: source, Target: &cfg, Logger: logger})
+		if result != nil {
+			warnings = result.Warnings
+		}
+		return cfg.App.Name, err
+	}
+
+	name, err := load()
+	require.NoError(t, err)
+	assert.Equal(t, "v1", name)
+	info, err := os.Stat(filepath.Join(dir, cacheFile))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// Within the TTL the cache is served without fetching
+	remote.body = "app:\n  name: v2\n"
+	name, err = load()
+	require.NoError(t, err)
+	assert.Equal(t, "v1", name)
+	assert.Equal(t, 1, remote.fetches)
+
+	// Once stale, the source is fetched again
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, cacheFile), old, old))
+	name, err = load()
+	require.NoError(t, err)
+	assert.Equal(t, "v2", name)
+
+	// A failing source falls back to the stale copy with a warning
+	require.NoError(t, os.Chtimes(filepath.Join(dir, cacheFile), old, old))
+	remote.down = true
+	name, err = load()
+	require.NoError(t, err)
+	assert.Equal(t, "v2", name)
+	want := "load base config: config source unavailable, using cached copy from 2h0m0s ago: connection refused"
+	require.Len(t, warnings, 1)
+	assert.EqualError(t, warnings[0], want)
+	assert.Equal(t, []string{"[yamlenv] warning: " + want}, logger.lines)
+
+	// Without a cached copy the error is returned
+	_, err = Cached(remote.source, t.TempDir(), time.Hour)()
+	assert.EqualError(t, err, "connection refused")
+}
+
+// Test that a stale copy is reported through a Named label too
+func TestCached_NamedStale(t *testing.T) {
+	dir := t.TempDir()
+	remote := &flakySource{body: "app:\n  name: v1\n"}
+	source := Named("https://config/app.yaml", Cached(remote.source, dir, 0))
+	var cfg TestConfig
+	require.NoError(t, LoadConfig(LoaderOptions{BaseSource: source, Target: &cfg}))
+
+	remote.down = true
+	result, err := Load(LoaderOptions{BaseSource: source, Target: &cfg, Logger: &recordingLogger{}})
+	require.NoError(t, err)
+	require.Len(t, result.Warnings, 1)
+	assert.ErrorContains(t, result.Warnings[0], "load base config (https://config/app.yaml): config source unavailable, using cached copy")
+}
@@ -0,0 +1,29 @@
+package yamlenv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+)
+
+// Checksum returns a stable SHA-256 fingerprint of a config, as 64 hex
+// digits. It covers the values by YAML name, so field order and map
+// iteration don't affect it. Secret values are excluded: rotating a
+// password doesn't change the checksum, and the checksum can't be used to
+// guess it.
+//
+//	log.Printf("config loaded, checksum %s", yamlenv.Checksum(&cfg))
+func Checksum(cfg any) string {
+	// plainValue produces maps, slices and scalars, which always encode;
+	// encoding/json sorts map keys
+	data, _ := json.Marshal(plainValue(reflect.ValueOf(cfg), false))
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Checksum returns the Checksum of the current configuration, for logging
+// at startup and telling whether a reload actually changed anything
+func (l *Loader) Checksum() string {
+	return Checksum(l.snapshot().Interface())
+}
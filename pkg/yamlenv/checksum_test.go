@@ -0,0 +1,56 @@
+package yamlenv
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ChecksumTestConfig has plain, secret and map values
+type ChecksumTestConfig struct {
+	App struct {
+		Name string         `yaml:"name"`
+		Tags map[string]int `yaml:"tags"`
+	} `yaml:"app"`
+	Password string         `yaml:"password" secret:"true"`
+	Token    Secret[string] `yaml:"token"`
+}
+
+// Test that the checksum follows values but not secrets or map order
+func TestChecksum(t *testing.T) {
+	load := func(yaml string) ChecksumTestConfig {
+		var cfg ChecksumTestConfig
+		require.NoError(t, LoadConfig(LoaderOptions{BaseSource: ReaderSource(strings.NewReader(yaml)), Target: &cfg}))
+		return cfg
+	}
+
+	a := load("app:\n  name: x\n  tags: {a: 1, b: 2, c: 3}\npassword: p1\ntoken: t1\n")
+	b := load("token: t2\npassword: p2\napp:\n  tags: {c: 3, b: 2, a: 1}\n  name: x\n")
+	c := load("app:\n  name: y\n  tags: {a: 1, b: 2, c: 3}\n")
+
+	assert.Len(t, Checksum(&a), 64)
+	assert.Equal(t, Checksum(&a), Checksum(&b), "secrets and key order don't count")
+	assert.Equal(t, Checksum(&a), Checksum(a), "pointers and values agree")
+	assert.NotEqual(t, Checksum(&a), Checksum(&c))
+}
+
+// Test that Loader.Checksum changes only when a reload changes the config
+func TestLoader_Checksum(t *testing.T) {
+	path := createTempYAML(t, "app:\n  port: 1\n")
+	var cfg LoaderTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(path), Target: &cfg})
+	require.NoError(t, err)
+	first := loader.Checksum()
+	assert.Equal(t, Checksum(&cfg), first)
+
+	require.NoError(t, os.WriteFile(path, []byte("# comment\napp:\n  port: 1\n"), 0o644))
+	require.NoError(t, loader.Reload())
+	assert.Equal(t, first, loader.Checksum())
+
+	require.NoError(t, os.WriteFile(path, []byte("app:\n  port: 2\n"), 0o644))
+	require.NoError(t, loader.Reload())
+	assert.NotEqual(t, first, loader.Checksum())
+}
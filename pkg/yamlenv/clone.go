@@ -0,0 +1,26 @@
+package yamlenv
+
+import "reflect"
+
+// Clone returns a deep copy of cfg. Maps, slices and pointers are duplicated,
+// so the copy can be modified without affecting cfg; unexported fields are
+// copied shallowly. Clone(nil) returns nil.
+func Clone[T any](cfg *T) *T {
+	if cfg == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(cfg)).Interface().(*T)
+}
+
+// With returns a deep copy of cfg with fn applied to it, leaving cfg
+// untouched:
+//
+//	testCfg := yamlenv.With(&cfg, func(c *Config) { c.DB.Host = "localhost" })
+func With[T any](cfg *T, fn func(*T)) *T {
+	out := Clone(cfg)
+	if out == nil {
+		out = new(T)
+	}
+	fn(out)
+	return out
+}
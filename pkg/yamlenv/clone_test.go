@@ -0,0 +1,75 @@
+package yamlenv
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that clones share no mutable state with the original
+func TestClone(t *testing.T) {
+	cfg := &DiffTestConfig{Hosts: []string{"a"}, Labels: map[string]string{"env": "prod"}}
+	cfg.Backup = &struct {
+		Dir string `yaml:"dir"`
+	}{Dir: "/backup"}
+
+	clone := Clone(cfg)
+	clone.Hosts[0] = "b"
+	clone.Labels["env"] = "dev"
+	clone.Backup.Dir = "/tmp"
+
+	assert.Equal(t, []string{"a"}, cfg.Hosts)
+	assert.Equal(t, "prod", cfg.Labels["env"])
+	assert.Equal(t, "/backup", cfg.Backup.Dir)
+	assert.Nil(t, Clone[DiffTestConfig](nil))
+}
+
+// cyclicNode is a config type whose pointers may form a cycle
+type cyclicNode struct {
+	Name string      `yaml:"name"`
+	Next *cyclicNode `yaml:"next"`
+	Prev *cyclicNode `yaml:"-"`
+}
+
+// Test that pointer cycles are copied as cycles and shared pointers stay
+// shared, instead of recursing forever
+func TestClone_Cycles(t *testing.T) {
+	a := &cyclicNode{Name: "a"}
+	b := &cyclicNode{Name: "b", Next: a, Prev: a}
+	a.Next, a.Prev = b, b
+
+	clone := Clone(a)
+	assert.NotSame(t, a, clone)
+	assert.NotSame(t, b, clone.Next)
+	assert.Equal(t, "b", clone.Next.Name)
+	assert.Same(t, clone, clone.Next.Next, "the cycle is kept")
+	assert.Same(t, clone.Next, clone.Prev, "shared pointers stay shared")
+
+	labels := map[string]any{"env": "prod"}
+	labels["self"] = labels
+	copied := copyValue(reflect.ValueOf(labels)).Interface().(map[string]any)
+	copied["env"] = "dev"
+	assert.Equal(t, "prod", labels["env"])
+	assert.Equal(t, "dev", copied["self"].(map[string]any)["env"])
+}
+
+// Test that With applies modifications to a copy only
+func TestWith(t *testing.T) {
+	cfg := &LoaderTestConfig{}
+	cfg.App.Name = "svc"
+	cfg.DB.Options = map[string]string{"ssl": "on"}
+
+	tweaked := With(cfg, func(c *LoaderTestConfig) {
+		c.App.Name = "tenant-a"
+		c.DB.Options["ssl"] = "off"
+	})
+
+	assert.Equal(t, "tenant-a", tweaked.App.Name)
+	assert.Equal(t, "off", tweaked.DB.Options["ssl"])
+	assert.Equal(t, "svc", cfg.App.Name)
+	assert.Equal(t, "on", cfg.DB.Options["ssl"])
+
+	fresh := With(nil, func(c *LoaderTestConfig) { c.App.Port = 1 })
+	assert.Equal(t, 1, fresh.App.Port)
+}
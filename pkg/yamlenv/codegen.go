@@ -0,0 +1,142 @@
+package yamlenv
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// EnvBindable is implemented by config types with generated env override
+// code (see cmd/yamlenv-gen). LoadConfig calls BindEnv instead of walking
+// the target with reflection; targets that don't implement it, and loads
+// with EnvTagCompat set, use reflection as before.
+type EnvBindable interface {
+	BindEnv(env *Env) error
+}
+
+// Env gives generated BindEnv methods access to the environment overrides
+// of a load. Paths are relative to the target, as in its yaml tags; Env
+// adds the Section and derives variable names with the loader's options.
+type Env struct {
+	binder  *envBinder
+	section string
+	target  reflect.Type
+}
+
+// Lookup returns the override for the field at path, if its variable is
+// set, and records it as the field's source
+func (e *Env) Lookup(path string) (string, bool) {
+	relative := path
+	path = e.path(path)
+	name := e.varName(path)
+	value, exists := e.binder.lookupEnv(name)
+	if !exists {
+		return "", false
+	}
+	if e.binder.debugKeys {
+		e.binder.logOverride(path, value, name, e.secret(relative))
+	}
+	e.binder.applied[path] = name
+	return value, true
+}
+
+// ParseInt parses the value of an integer field, honoring LenientNumbers
+func (e *Env) ParseInt(value string) (int64, error) {
+	if e.binder.lenient {
+		return parseLenientInt(value)
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// ParseUint parses the value of an unsigned integer field, honoring
+// LenientNumbers
+func (e *Env) ParseUint(value string) (uint64, error) {
+	if e.binder.lenient {
+		return parseLenientUint(value)
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+
+// Field applies overrides to the field ptr points to with reflection, for
+// types generated code doesn't handle itself: pointers to structs, slices,
+// maps, any and types with their own env parsing like Secret
+func (e *Env) Field(path string, ptr any) error {
+	field := reflect.ValueOf(ptr).Elem()
+	info := fieldInfo{
+		Nested:    isNestedStruct(field.Type()),
+		NestedPtr: field.Kind() == reflect.Ptr && isNestedStruct(field.Type().Elem()),
+		Secret:    e.secret(path),
+	}
+	return e.binder.field(field, info, e.path(path), 0, map[uintptr]bool{})
+}
+
+// Error reports a value at path that couldn't be parsed, in the same form
+// as reflective binding. It returns nil, after logging the error, for
+// fields tagged `envinvalid:"warn"`; generated code then leaves the field
+// unchanged.
+func (e *Env) Error(path string, err error) error {
+	fields, t := fieldsAlong(e.target, path)
+	var info fieldInfo
+	if len(fields) > 0 {
+		info = fields[len(fields)-1]
+	}
+	path = e.path(path)
+	name := e.binder.applied[path]
+	value, _ := e.binder.lookupEnv(name)
+	return e.binder.badEnv(newEnvError(path, name, value, t, info, err), info)
+}
+
+// secret reports whether the field at path, or a struct containing it, is
+// secret
+func (e *Env) secret(path string) bool {
+	fields, _ := fieldsAlong(e.target, path)
+	for _, info := range fields {
+		if info.Secret {
+			return true
+		}
+	}
+	return false
+}
+
+// envNameKey identifies a variable name derived without KeyTranslation
+type envNameKey struct {
+	prefix, delimiter, path string
+	normalizeDash           bool
+}
+
+// envNameCache memoizes the variable names generated code looks up, which
+// are the same on every load
+var envNameCache sync.Map
+
+func (e *Env) varName(path string) string {
+	b := e.binder
+	if b.translate != nil {
+		return b.varName(path)
+	}
+	key := envNameKey{prefix: b.prefix, delimiter: b.delimiter, path: path, normalizeDash: b.normalizeDash}
+	if name, ok := envNameCache.Load(key); ok {
+		return name.(string)
+	}
+	name := b.varName(path)
+	envNameCache.Store(key, name)
+	return name
+}
+
+func (e *Env) path(path string) string {
+	if e.section == "" {
+		return path
+	}
+	if path == "" {
+		return e.section
+	}
+	return e.section + "." + path
+}
+
+// bind applies overrides to target, through its generated BindEnv method
+// when it has one
+func (b *envBinder) bind(target any, section string) error {
+	if bindable, ok := target.(EnvBindable); ok && !b.tagCompat {
+		return bindable.BindEnv(&Env{binder: b, section: section, target: reflect.TypeOf(target)})
+	}
+	return b.apply(reflect.ValueOf(target), section)
+}
@@ -0,0 +1,188 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//go:generate go run ../../cmd/yamlenv-gen -type GenConfig,GenDB
+
+type GenMode string
+
+type GenShared struct {
+	Region string `yaml:"region"`
+}
+
+type GenDB struct {
+	Host    string        `yaml:"host"`
+	Port    uint16        `yaml:"port" desc:"database port"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+type GenConfig struct {
+	GenShared `yaml:",inline"`
+
+	Name     string         `yaml:"name"`
+	Mode     GenMode        `yaml:"mode"`
+	Workers  int            `yaml:"workers" envinvalid:"warn"`
+	Ratio    float32        `yaml:"ratio"`
+	Debug    bool           `yaml:"debug"`
+	DB       GenDB          `yaml:"db"`
+	Replica  *GenDB         `yaml:"replica"`
+	Tags     []string       `yaml:"tags"`
+	Password Secret[string] `yaml:"password"`
+	Limits   struct {
+		Burst int `yaml:"burst"`
+	} `yaml:"limits"`
+	Ignored string `yaml:"-"`
+	hidden  string
+}
+
+// genReflect and genDBReflect have the fields of GenConfig and GenDB but
+// no BindEnv, so they are bound with reflection
+type (
+	genReflect   GenConfig
+	genDBReflect GenDB
+)
+
+const genYAML = `
+region: eu
+name: base
+mode: dev
+workers: 2
+db:
+  host: localhost
+  port: 5432
+  timeout: 1s
+limits:
+  burst: 10
+`
+
+func setGenEnv(t testing.TB) {
+	t.Setenv("GEN_REGION", "us")
+	t.Setenv("GEN_MODE", "prod")
+	t.Setenv("GEN_WORKERS", "8")
+	t.Setenv("GEN_RATIO", "0.5")
+	t.Setenv("GEN_DEBUG", "true")
+	t.Setenv("GEN_DB__PORT", "6432")
+	t.Setenv("GEN_DB__TIMEOUT", "5s")
+	t.Setenv("GEN_REPLICA__HOST", "replica")
+	t.Setenv("GEN_TAGS", `["a","b"]`)
+	t.Setenv("GEN_PASSWORD", "hunter2")
+	t.Setenv("GEN_LIMITS__BURST", "20")
+	t.Setenv("GEN_IGNORED", "x")
+}
+
+// Test that generated binding gives the same config and sources as reflection
+func TestBindEnv_MatchesReflection(t *testing.T) {
+	setGenEnv(t)
+	load := func(target any) *LoadResult {
+		result, err := Load(LoaderOptions{
+			BaseSource: StringSource(genYAML),
+			EnvPrefix:  "GEN_",
+			Delimiter:  "__",
+			EnvJSON:    true,
+			Target:     target,
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	var generated GenConfig
+	var reflected genReflect
+	generatedResult := load(&generated)
+	reflectedResult := load(&reflected)
+
+	assert.Equal(t, GenConfig(reflected), generated)
+	assert.Equal(t, reflectedResult.Sources, generatedResult.Sources)
+
+	assert.Equal(t, "us", generated.Region)
+	assert.Equal(t, GenMode("prod"), generated.Mode)
+	assert.Equal(t, uint16(6432), generated.DB.Port)
+	assert.Equal(t, 5*time.Second, generated.DB.Timeout)
+	require.NotNil(t, generated.Replica)
+	assert.Equal(t, "replica", generated.Replica.Host)
+	assert.Equal(t, "hunter2", generated.Password.Value())
+	assert.Equal(t, []string{"a", "b"}, generated.Tags)
+	assert.Equal(t, 20, generated.Limits.Burst)
+	assert.Empty(t, generated.Ignored)
+	assert.Equal(t, "env:GEN_DB__PORT", generatedResult.Sources["db.port"])
+}
+
+// Test that parse errors from generated code read like reflective ones
+func TestBindEnv_ParseError(t *testing.T) {
+	t.Setenv("GEN_DB__PORT", "high")
+	load := func(target any) error {
+		return LoadConfig(LoaderOptions{BaseSource: StringSource(genYAML), EnvPrefix: "GEN_", Delimiter: "__", Target: target})
+	}
+
+	err := load(&GenConfig{})
+	require.Error(t, err)
+	assert.Equal(t, load(&genReflect{}).Error(), err.Error())
+	assert.Contains(t, err.Error(), `set field db.port (database port) from GEN_DB__PORT="high" (uint16): parse uint "high"`)
+}
+
+// Test that generated paths are prefixed with the section being loaded
+func TestBindEnv_Section(t *testing.T) {
+	t.Setenv("GEN_SVC__DB__HOST", "svc-db")
+	var cfg GenConfig
+	err := LoadSection(LoaderOptions{
+		BaseSource: StringSource("svc:\n" + indentYAML(genYAML)),
+		EnvPrefix:  "GEN_",
+		Delimiter:  "__",
+	}, "svc", &cfg)
+
+	require.NoError(t, err)
+	assert.Equal(t, "svc-db", cfg.DB.Host)
+}
+
+type countingBindable struct {
+	Name  string `yaml:"name"`
+	calls int
+}
+
+func (c *countingBindable) BindEnv(env *Env) error {
+	c.calls++
+	return nil
+}
+
+// Test that EnvTagCompat needs field tags, so it binds with reflection
+func TestBindEnv_TagCompatUsesReflection(t *testing.T) {
+	var cfg countingBindable
+	require.NoError(t, LoadConfig(LoaderOptions{BaseSource: StringSource(genYAML), Target: &cfg}))
+	assert.Equal(t, 1, cfg.calls)
+
+	cfg = countingBindable{}
+	require.NoError(t, LoadConfig(LoaderOptions{BaseSource: StringSource(genYAML), EnvTagCompat: true, Target: &cfg}))
+	assert.Equal(t, 0, cfg.calls)
+}
+
+func indentYAML(s string) string {
+	return strings.ReplaceAll(s, "\n", "\n  ")
+}
+
+// Benchmark applying env overrides alone, the part generated code replaces
+func benchmarkBindEnv(b *testing.B, target any) {
+	b.Setenv("GEN_HOST", "db")
+	b.Setenv("GEN_PORT", "6432")
+	b.Setenv("GEN_TIMEOUT", "5s")
+	opts := LoaderOptions{EnvPrefix: "GEN_", Delimiter: "__"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := newEnvBinder(opts).bind(target, ""); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBindEnv_Generated(b *testing.B) {
+	benchmarkBindEnv(b, &GenDB{})
+}
+
+func BenchmarkBindEnv_Reflection(b *testing.B) {
+	benchmarkBindEnv(b, &genDBReflect{})
+}
@@ -0,0 +1,92 @@
+package yamlenv
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingLogger keeps the lines it is given
+type recordingLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *recordingLogger) Printf(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+// Test that concurrent loads into different targets don't affect each
+// other; run with -race to check for data races
+func TestLoadConfig_Concurrent(t *testing.T) {
+	const loads = 32
+	for i := 0; i < loads; i++ {
+		t.Setenv(fmt.Sprintf("CONC%d_APP__PORT", i), fmt.Sprint(9000+i))
+	}
+	environ := os.Environ()
+
+	var wg sync.WaitGroup
+	configs := make([]TestConfig, loads)
+	loggers := make([]*recordingLogger, loads)
+	errs := make([]error, loads)
+	for i := 0; i < loads; i++ {
+		loggers[i] = &recordingLogger{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = LoadConfig(LoaderOptions{
+				BaseSource:  StringSource(fmt.Sprintf("app:\n  name: app%d\n  port: 80\n", i)),
+				LocalSource: StringSource(fmt.Sprintf("db:\n  host: db%d\n", i)),
+				EnvPrefix:   fmt.Sprintf("CONC%d_", i),
+				Delimiter:   "__",
+				DebugKeys:   true,
+				Logger:      loggers[i],
+				Target:      &configs[i],
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < loads; i++ {
+		require.NoError(t, errs[i])
+		assert.Equal(t, fmt.Sprintf("app%d", i), configs[i].App.Name)
+		assert.Equal(t, 9000+i, configs[i].App.Port)
+		assert.Equal(t, fmt.Sprintf("db%d", i), configs[i].DB.Host)
+
+		// Each load logs only its own overrides
+		require.Len(t, loggers[i].lines, 1)
+		assert.True(t, strings.HasSuffix(loggers[i].lines[0], fmt.Sprintf("(from CONC%d_APP__PORT)", i)), loggers[i].lines[0])
+	}
+	assert.Equal(t, environ, os.Environ(), "loads must not modify the environment")
+}
+
+// Test that one Loader can be reloaded while others read its config
+func TestLoader_ConcurrentReloadAndRead(t *testing.T) {
+	var cfg TestConfig
+	loader, err := NewLoader(LoaderOptions{
+		BaseSource: StringSource("app:\n  name: app\n  port: 80\n"),
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, loader.Reload())
+		}()
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, "app", loader.Current().(*TestConfig).App.Name)
+		}()
+	}
+	wg.Wait()
+}
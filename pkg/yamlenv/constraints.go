@@ -0,0 +1,149 @@
+package yamlenv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// constraintCheck collects violations of the tag-declared constraints while
+// walking the effective config
+type constraintCheck struct {
+	sources    map[string]string
+	path       string // full path of the field being checked
+	desc       string // description of the field being checked
+	violations []error
+}
+
+// Validatable is implemented by config types that check their own values,
+// such as ranges that tags can't express. Validate is called after loading
+// on the target and every struct nested in it, along with the tag
+// constraints; its error is reported under the path of the struct.
+type Validatable interface {
+	Validate() error
+}
+
+// checkConstraints checks the constraints declared in struct tags (enum,
+// requires, conflicts) and by Validatable types against the effective config
+// in opts.Target. All violations are reported together, each naming where
+// the value came from.
+func checkConstraints(opts LoaderOptions, result *LoadResult) error {
+	c := &constraintCheck{sources: result.Sources}
+	c.walk(reflect.ValueOf(opts.Target), opts.Section)
+	if len(c.violations) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(c.violations...))
+	}
+	return nil
+}
+
+func (c *constraintCheck) walk(v reflect.Value, path string) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	checkValidatable(v, path, c)
+	for _, info := range cachedFields(v.Type()) {
+		field := v.Field(info.Index)
+		fieldPath := path
+		if !info.Inline {
+			fieldPath = joinPath(path, info.Name)
+		}
+		c.path, c.desc = fieldPath, info.Desc
+		if len(info.Enum) > 0 {
+			checkEnum(field, info, c)
+		}
+		if len(info.Requires) > 0 || len(info.Conflicts) > 0 {
+			checkRelations(v, path, field, info, c)
+		}
+		if info.Nested || info.NestedPtr {
+			c.walk(field, fieldPath)
+		}
+	}
+}
+
+// checkValidatable calls Validate on a struct that implements Validatable,
+// with a pointer or value receiver
+func checkValidatable(v reflect.Value, path string, c *constraintCheck) {
+	if v.CanAddr() {
+		v = v.Addr()
+	}
+	if !v.CanInterface() {
+		return
+	}
+	validatable, ok := v.Interface().(Validatable)
+	if !ok {
+		return
+	}
+	err := validatable.Validate()
+	if err == nil {
+		return
+	}
+	// Report each of a joined error's errors under the path
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	for _, err := range errs {
+		if path == "" {
+			c.fail("%w", err)
+		} else {
+			c.fail("%s: %w", path, err)
+		}
+	}
+}
+
+// fail records a violation
+func (c *constraintCheck) fail(format string, args ...any) {
+	c.violations = append(c.violations, fmt.Errorf(format, args...))
+}
+
+// label returns the path of the field being checked with its description
+func (c *constraintCheck) label() string {
+	return describe(c.path, c.desc)
+}
+
+// from formats the source of the value at path for messages, e.g. " (from base)"
+func (c *constraintCheck) from(path string) string {
+	if source := c.sources[path]; source != "" {
+		return " (from " + source + ")"
+	}
+	return ""
+}
+
+// checkRelations checks the `requires:"..."` and `conflicts:"..."` tags of a
+// field that is set: every required path must be set as well, and no
+// conflicting one may be. Paths are relative to the struct declaring the
+// tag, so reusable section types work under any parent.
+func checkRelations(parent reflect.Value, parentPath string, field reflect.Value, info fieldInfo, c *constraintCheck) {
+	if !isSetValue(field) {
+		return
+	}
+	path := c.path
+	for _, rel := range info.Requires {
+		if other, ok := lookupField(parent, rel); !ok || !isSetValue(other) {
+			c.fail("%s is set%s, so %s is required", c.label(), c.from(path), joinPath(parentPath, rel))
+		}
+	}
+	for _, rel := range info.Conflicts {
+		if other, ok := lookupField(parent, rel); ok && isSetValue(other) {
+			otherPath := joinPath(parentPath, rel)
+			c.fail("%s%s and %s%s cannot both be set", c.label(), c.from(path), otherPath, c.from(otherPath))
+		}
+	}
+}
+
+// isSetValue reports whether v holds a non-zero value. Maps and slices count
+// as set when they have elements.
+func isSetValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice:
+		return v.Len() > 0
+	default:
+		return v.IsValid() && !v.IsZero()
+	}
+}
@@ -0,0 +1,109 @@
+package yamlenv
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TLSTestConfig is a reusable section with a conditional requirement
+type TLSTestConfig struct {
+	Enabled bool   `yaml:"enabled" requires:"cert, key"`
+	Cert    string `yaml:"cert"`
+	Key     string `yaml:"key"`
+}
+
+// RelationTestConfig declares cross-field rules
+type RelationTestConfig struct {
+	Server struct {
+		Addr       string        `yaml:"addr" conflicts:"socket_path"`
+		SocketPath string        `yaml:"socket_path"`
+		TLS        TLSTestConfig `yaml:"tls"`
+	} `yaml:"server"`
+	Proxy *struct {
+		URL   string   `yaml:"url" requires:"hosts"`
+		Hosts []string `yaml:"hosts"`
+	} `yaml:"proxy"`
+}
+
+// Test that satisfied rules and unset fields pass
+func TestLoad_RelationsSatisfied(t *testing.T) {
+	var cfg RelationTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader(`server:
+  socket_path: /run/app.sock
+  tls:
+    enabled: true
+    cert: /tls/cert.pem
+    key: /tls/key.pem
+proxy:
+  url: http://proxy
+  hosts: [a]
+`)),
+		Target: &cfg,
+	})
+
+	require.NoError(t, err)
+}
+
+// Test that missing requirements and conflicts are reported together with their sources
+func TestLoad_RelationsViolated(t *testing.T) {
+	setEnvVar(t, "REL_SERVER__TLS__ENABLED", "true")
+
+	var cfg RelationTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader("server:\n  addr: :8080\n  tls:\n    cert: /tls/cert.pem\nproxy:\n  url: http://proxy\n")),
+		LocalSource: ReaderSource(strings.NewReader("server:\n  socket_path: /run/app.sock\n")),
+		EnvPrefix:   "REL_",
+		Delimiter:   "__",
+		Target:      &cfg,
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, `invalid config: server.addr (from base) and server.socket_path (from local) cannot both be set
+server.tls.enabled is set (from env:REL_SERVER__TLS__ENABLED), so server.tls.key is required
+proxy.url is set (from base), so proxy.hosts is required`, err.Error())
+}
+
+// Test that rule paths are relative to the declaring struct when binding a section
+func TestLoadSection_Relations(t *testing.T) {
+	var tls TLSTestConfig
+	err := LoadSection(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("server:\n  tls:\n    enabled: true\n")),
+	}, "server.tls", &tls)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "server.tls.enabled is set (from base), so server.tls.cert is required")
+	assert.Contains(t, err.Error(), "so server.tls.key is required")
+}
+
+// rangeTestConfig checks its own values
+type rangeTestConfig struct {
+	Min int `yaml:"min"`
+	Max int `yaml:"max"`
+}
+
+func (r rangeTestConfig) Validate() error {
+	if r.Min > r.Max {
+		return fmt.Errorf("min %d is above max %d", r.Min, r.Max)
+	}
+	return nil
+}
+
+// Test that Validatable sections are checked at load under their path
+func TestLoad_Validatable(t *testing.T) {
+	var cfg struct {
+		Pool    rangeTestConfig  `yaml:"pool"`
+		Workers *rangeTestConfig `yaml:"workers"`
+	}
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("pool:\n  min: 5\n  max: 2\nworkers:\n  min: 1\n  max: 3\n")),
+		Target:     &cfg,
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, "invalid config: pool: min 5 is above max 2", err.Error())
+}
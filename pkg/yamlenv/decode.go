@@ -0,0 +1,336 @@
+package yamlenv
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DecodeHookFunc converts raw configuration data before it is bound into a
+// value of type to, in the style of mapstructure decode hooks. from is the
+// type of the raw data as decoded from YAML (string, int, float64, bool,
+// map[string]any, []any, ...).
+//
+// A hook returns data unchanged to pass, or a converted value. If the result
+// is assignable to to it is stored directly; otherwise it is decoded into the
+// field like any other YAML value.
+type DecodeHookFunc func(from reflect.Type, to reflect.Type, data any) (any, error)
+
+var (
+	yamlNodeType        = reflect.TypeOf(yaml.Node{})
+	yamlUnmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// StringToSliceHook splits strings on sep when the target is a slice, so
+// "a,b,c" can populate a []string field
+func StringToSliceHook(sep string) DecodeHookFunc {
+	return func(from reflect.Type, to reflect.Type, data any) (any, error) {
+		s, ok := data.(string)
+		if !ok || to.Kind() != reflect.Slice {
+			return data, nil
+		}
+		if s == "" {
+			return []any{}, nil
+		}
+		parts := strings.Split(s, sep)
+		out := make([]any, len(parts))
+		for i, part := range parts {
+			out[i] = strings.TrimSpace(part)
+		}
+		return out, nil
+	}
+}
+
+// TextUnmarshalerHook converts strings into types implementing
+// encoding.TextUnmarshaler (net.IP, netip.Addr, slog.Level, ...)
+func TextUnmarshalerHook() DecodeHookFunc {
+	return func(from reflect.Type, to reflect.Type, data any) (any, error) {
+		s, ok := data.(string)
+		if !ok || !reflect.PointerTo(to).Implements(textUnmarshalerType) {
+			return data, nil
+		}
+		v := reflect.New(to)
+		if err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return nil, err
+		}
+		return v.Elem().Interface(), nil
+	}
+}
+
+// decoder binds a YAML node tree into Go values
+type decoder struct {
+	hooks []DecodeHookFunc
+	weak  bool
+}
+
+func newDecoder(opts LoaderOptions) *decoder {
+	return &decoder{hooks: opts.DecodeHooks, weak: opts.WeaklyTyped}
+}
+
+// walks reports whether binding needs the reflective walk; otherwise the
+// tree is handed to yaml.v3 directly
+func (d *decoder) walks() bool {
+	return len(d.hooks) > 0 || d.weak
+}
+
+// decodeInto binds n into the value target points to
+func (d *decoder) decodeInto(n *yaml.Node, target any) error {
+	if !d.walks() && !hasLocation(reflect.TypeOf(target)) {
+		return n.Decode(target)
+	}
+	return d.decode(n, reflect.ValueOf(target).Elem(), "")
+}
+
+// decode binds n into v, walking structs, maps and slices so hooks can see
+// every value on the way down
+func (d *decoder) decode(n *yaml.Node, v reflect.Value, path string) error {
+	n = resolveAlias(n)
+	if n == nil {
+		return nil
+	}
+
+	if len(d.hooks) > 0 && !isUnsetNode(n) {
+		done, replaced, err := d.runHooks(n, v)
+		if err != nil {
+			return fmt.Errorf("decode hook for %s: %w", displayPath(path), err)
+		}
+		if done {
+			return nil
+		}
+		n = replaced
+	}
+
+	if d.weak {
+		n = weakenNode(n, v.Type())
+	}
+
+	if v.Type() == locationPtrType {
+		return decodeLocation(n, v, path)
+	}
+
+	// Types with their own YAML handling are decoded by yaml.v3
+	if v.Type() == yamlNodeType || reflect.PointerTo(v.Type()).Implements(yamlUnmarshalerType) {
+		return n.Decode(v.Addr().Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if isUnsetNode(n) {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decode(n, v.Elem(), path)
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode {
+			return n.Decode(v.Addr().Interface())
+		}
+		return d.decodeStruct(n, v, path)
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			return n.Decode(v.Addr().Interface())
+		}
+		return d.decodeMap(n, v, path)
+	case reflect.Slice:
+		if n.Kind != yaml.SequenceNode {
+			return n.Decode(v.Addr().Interface())
+		}
+		slice := reflect.MakeSlice(v.Type(), len(n.Content), len(n.Content))
+		for i, elem := range n.Content {
+			if err := d.decode(elem, slice.Index(i), joinPath(path, fmt.Sprint(i))); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	default:
+		return n.Decode(v.Addr().Interface())
+	}
+}
+
+// decodeStruct binds a mapping node into a struct by yaml field names
+func (d *decoder) decodeStruct(n *yaml.Node, v reflect.Value, path string) error {
+	fields := cachedFields(v.Type())
+	for _, info := range fields {
+		if info.Inline && v.Field(info.Index).Kind() == reflect.Struct {
+			if err := d.decodeStruct(n, v.Field(info.Index), path); err != nil {
+				return err
+			}
+		}
+	}
+	content := flattenMapping(n)
+	for i := 0; i+1 < len(content); i += 2 {
+		key := content[i].Value
+		for _, info := range fields {
+			if info.Inline || info.Name != key {
+				continue
+			}
+			if err := d.decode(content[i+1], v.Field(info.Index), joinPath(path, key)); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// decodeMap binds a mapping node into a map, decoding each entry freshly
+func (d *decoder) decodeMap(n *yaml.Node, v reflect.Value, path string) error {
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+	keyType, elemType := v.Type().Key(), v.Type().Elem()
+	content := flattenMapping(n)
+	for i := 0; i+1 < len(content); i += 2 {
+		key := reflect.New(keyType).Elem()
+		if err := content[i].Decode(key.Addr().Interface()); err != nil {
+			return err
+		}
+		elem := reflect.New(elemType).Elem()
+		if err := d.decode(content[i+1], elem, joinPath(path, content[i].Value)); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+	}
+	return nil
+}
+
+// runHooks passes the raw value of n through the hooks. It reports done when
+// a hook produced a value that was stored directly, and otherwise returns the
+// node to continue decoding (re-encoded if a hook changed the data).
+func (d *decoder) runHooks(n *yaml.Node, v reflect.Value) (bool, *yaml.Node, error) {
+	var raw any
+	if err := n.Decode(&raw); err != nil {
+		return false, nil, err
+	}
+	data := raw
+	for _, hook := range d.hooks {
+		var err error
+		if data, err = hook(reflect.TypeOf(data), v.Type(), data); err != nil {
+			return false, nil, err
+		}
+	}
+	if reflect.DeepEqual(data, raw) {
+		return false, n, nil
+	}
+	if data != nil && v.Kind() != reflect.Interface && reflect.TypeOf(data).AssignableTo(v.Type()) {
+		v.Set(reflect.ValueOf(data))
+		return true, nil, nil
+	}
+	var replaced yaml.Node
+	if err := replaced.Encode(data); err != nil {
+		return false, nil, err
+	}
+	return false, &replaced, nil
+}
+
+// weakenNode rewrites a scalar so that loosely typed input can bind into t:
+// numeric strings into numbers, 1/0/yes/no/on/off into bools, and a single
+// scalar into a one-element list. Nodes that need no conversion are returned
+// unchanged; converted nodes are copies.
+func weakenNode(n *yaml.Node, t reflect.Type) *yaml.Node {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if n.Kind != yaml.ScalarNode || isUnsetNode(n) {
+		return n
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if t == reflect.TypeOf(time.Duration(0)) {
+			return n
+		}
+		switch n.ShortTag() {
+		case "!!str":
+			value := strings.TrimSpace(n.Value)
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return n
+			}
+			return retagScalar(n, "", value)
+		case "!!bool":
+			var b bool
+			if err := n.Decode(&b); err != nil {
+				return n
+			}
+			if b {
+				return retagScalar(n, "!!int", "1")
+			}
+			return retagScalar(n, "!!int", "0")
+		}
+	case reflect.Bool:
+		if n.ShortTag() == "!!bool" {
+			return n
+		}
+		switch strings.ToLower(strings.TrimSpace(n.Value)) {
+		case "1", "yes", "y", "on", "true", "t":
+			return retagScalar(n, "!!bool", "true")
+		case "0", "no", "n", "off", "false", "f", "":
+			return retagScalar(n, "!!bool", "false")
+		}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return n
+		}
+		return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{n}, Line: n.Line, Column: n.Column}
+	}
+	return n
+}
+
+// retagScalar returns a copy of a scalar node with a new tag and value
+func retagScalar(n *yaml.Node, tag, value string) *yaml.Node {
+	out := *n
+	out.Tag, out.Value, out.Style = tag, value, 0
+	return &out
+}
+
+// joinPath appends key to a dot-separated path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// displayPath formats a path for error messages
+func displayPath(path string) string {
+	if path == "" {
+		return "<root>"
+	}
+	return path
+}
+
+// decodeMerged binds the merged tree (or the given section of it) into target.
+// When decoding fails, the individual layers are decoded into scratch values
+// so the error can be attributed to the layer that introduced the bad value.
+func decodeMerged(d *decoder, merged *yaml.Node, layers []configLayer, section string, target any) error {
+	merged = lookupPath(merged, section)
+	if merged == nil {
+		return nil
+	}
+	err := d.decodeInto(merged, target)
+	if err == nil {
+		return nil
+	}
+	targetType := reflect.TypeOf(target).Elem()
+	for _, layer := range layers {
+		node := lookupPath(layer.node, section)
+		if node == nil {
+			continue
+		}
+		if layerErr := d.decodeInto(node, reflect.New(targetType).Interface()); layerErr != nil {
+			return layer.loadError(layerErr)
+		}
+	}
+	return fmt.Errorf("decode merged config: %w", err)
+}
@@ -0,0 +1,146 @@
+package yamlenv
+
+import (
+	"errors"
+	"log/slog"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Color int
+
+const (
+	Red Color = iota + 1
+	Green
+)
+
+// colorHook converts color names into Color values
+func colorHook(from reflect.Type, to reflect.Type, data any) (any, error) {
+	if to != reflect.TypeOf(Color(0)) {
+		return data, nil
+	}
+	switch data {
+	case "red":
+		return Red, nil
+	case "green":
+		return Green, nil
+	}
+	return nil, errors.New("unknown color")
+}
+
+type HookTestConfig struct {
+	Color   Color            `yaml:"color"`
+	Palette map[string]Color `yaml:"palette"`
+	Stack   []Color          `yaml:"stack"`
+	Addr    net.IP           `yaml:"addr"`
+	Level   slog.Level       `yaml:"level"`
+	Hosts   []string         `yaml:"hosts"`
+	Nested  *struct {
+		Color Color `yaml:"color"`
+	} `yaml:"nested"`
+}
+
+// Test a custom string-to-enum hook across fields, maps, slices and pointers
+func TestLoadConfig_DecodeHooksEnum(t *testing.T) {
+	var cfg HookTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader("color: red\npalette:\n  bg: green\nstack: [green, red]\nnested:\n  color: green\n")),
+		DecodeHooks: []DecodeHookFunc{colorHook},
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, Red, cfg.Color)
+	assert.Equal(t, map[string]Color{"bg": Green}, cfg.Palette)
+	assert.Equal(t, []Color{Green, Red}, cfg.Stack)
+	require.NotNil(t, cfg.Nested)
+	assert.Equal(t, Green, cfg.Nested.Color)
+}
+
+// Test the built-in TextUnmarshaler and string-to-slice hooks
+func TestLoadConfig_BuiltinDecodeHooks(t *testing.T) {
+	var cfg HookTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader("addr: 10.0.0.1\nlevel: WARN\nhosts: a, b ,c\n")),
+		DecodeHooks: []DecodeHookFunc{TextUnmarshalerHook(), StringToSliceHook(",")},
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", cfg.Addr.String())
+	assert.Equal(t, slog.LevelWarn, cfg.Level)
+	assert.Equal(t, []string{"a", "b", "c"}, cfg.Hosts)
+}
+
+// Test that hook errors carry the field path and layer
+func TestLoadConfig_DecodeHookError(t *testing.T) {
+	var cfg HookTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader("color: red\n")),
+		LocalSource: ReaderSource(strings.NewReader("palette:\n  fg: purple\n")),
+		DecodeHooks: []DecodeHookFunc{colorHook},
+		Target:      &cfg,
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "load local config")
+	assert.Contains(t, err.Error(), "decode hook for palette.fg")
+	assert.Contains(t, err.Error(), "unknown color")
+}
+
+// Test that the walk handles inline structs and YAML merge keys
+func TestLoadConfig_DecodeHooksInlineAndMergeKeys(t *testing.T) {
+	type Common struct {
+		Color Color `yaml:"color"`
+	}
+	type Config struct {
+		Common `yaml:",inline"`
+		Items  map[string]struct {
+			Color Color `yaml:"color"`
+			Size  int   `yaml:"size"`
+		} `yaml:"items"`
+	}
+
+	yamlDoc := `
+color: green
+defaults: &defaults
+  color: red
+  size: 1
+items:
+  first:
+    <<: *defaults
+  second:
+    <<: *defaults
+    color: green
+`
+
+	var cfg Config
+	err := LoadConfig(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader(yamlDoc)),
+		DecodeHooks: []DecodeHookFunc{colorHook},
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, Green, cfg.Color)
+	assert.Equal(t, Red, cfg.Items["first"].Color)
+	assert.Equal(t, 1, cfg.Items["first"].Size)
+	assert.Equal(t, Green, cfg.Items["second"].Color)
+}
+
+// Test that without hooks plain YAML decoding is unchanged
+func TestLoadConfig_NoDecodeHooks(t *testing.T) {
+	var cfg HookTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("color: 2\n")),
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, Green, cfg.Color)
+}
@@ -0,0 +1,114 @@
+package yamlenv
+
+import (
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// nonZeroNode converts a struct or map into a YAML node, as for the Defaults
+// layer. Struct fields with zero values are left out, so they don't show up
+// as set in LoadResult.Sources or mask keys removed by other layers.
+func nonZeroNode(value any) (*yaml.Node, error) {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct && v.Kind() != reflect.Map {
+		return nil, fmt.Errorf("expected a struct or map, got %s", v.Type())
+	}
+
+	var doc yaml.Node
+	if err := doc.Encode(nonZeroValue(v)); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// nonZeroValue returns v with zero-valued struct fields dropped; structs
+// become maps keyed by YAML name and everything else is returned as is
+func nonZeroValue(v reflect.Value) any {
+	if v.Kind() == reflect.Ptr && !v.IsNil() && isNestedStruct(v.Elem().Type()) {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Struct && v.Type().Implements(secretHolderType) {
+		return v.Interface().(secretHolder).secretValue()
+	}
+	if v.Kind() != reflect.Struct || !isNestedStruct(v.Type()) || v.Type().Implements(yamlMarshalerType) {
+		return v.Interface()
+	}
+	out := map[string]any{}
+	for _, info := range cachedFields(v.Type()) {
+		field := v.Field(info.Index)
+		if field.IsZero() {
+			continue
+		}
+		value := nonZeroValue(field)
+		if inline, ok := value.(map[string]any); ok && info.Inline {
+			for key, inner := range inline {
+				out[key] = inner
+			}
+			continue
+		}
+		if m, ok := value.(map[string]any); ok && len(m) == 0 {
+			continue
+		}
+		out[info.Name] = value
+	}
+	return out
+}
+
+// Defaulter is implemented by config types that fill in their own defaults.
+// SetDefaults is called before any layer is applied, so files, Defaults and
+// the environment override what it sets.
+type Defaulter interface {
+	SetDefaults()
+}
+
+// applyDefaults calls SetDefaults on v and every struct nested in it, inner
+// structs first so an outer type has the last word. Nil struct pointers are
+// allocated, and defaulted, when n (the merged YAML bound into v) has a
+// section for them; they stay nil otherwise.
+func applyDefaults(v reflect.Value, n *yaml.Node) {
+	applyDefaultsVisit(v, n, map[uintptr]bool{})
+}
+
+func applyDefaultsVisit(v reflect.Value, n *yaml.Node, visiting map[uintptr]bool) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() || visiting[v.Pointer()] {
+			return
+		}
+		visiting[v.Pointer()] = true
+		defer delete(visiting, v.Pointer())
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct || !v.CanAddr() {
+		return
+	}
+	for _, info := range cachedFields(v.Type()) {
+		field := v.Field(info.Index)
+		child := n
+		if !info.Inline {
+			child = lookupPath(n, info.Name)
+		}
+		switch {
+		case info.Nested:
+			applyDefaultsVisit(field, child, visiting)
+		case info.NestedPtr:
+			if field.IsNil() {
+				if child == nil || isUnsetNode(child) || child.Kind != yaml.MappingNode {
+					continue
+				}
+				field.Set(reflect.New(field.Type().Elem()))
+			}
+			applyDefaultsVisit(field, child, visiting)
+		}
+	}
+	if d, ok := v.Addr().Interface().(Defaulter); ok {
+		d.SetDefaults()
+	}
+}
@@ -0,0 +1,164 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that non-zero fields of a Defaults struct form the lowest layer
+func TestLoad_DefaultsStruct(t *testing.T) {
+	setEnvVar(t, "DEF_DB__PORT", "6543")
+
+	var defaults TestConfig
+	defaults.App.Name = "default-app"
+	defaults.App.Port = 8080
+	defaults.DB.Host = "default-host"
+	defaults.DB.Port = 5432
+	defaults.Timeout = 30 * time.Second
+
+	var cfg TestConfig
+	result, err := Load(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("app:\n  name: base-app\n  debug: false\ndb:\n  host: null\n")),
+		EnvPrefix:  "DEF_",
+		Delimiter:  "__",
+		Defaults:   &defaults,
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "base-app", cfg.App.Name)
+	assert.Equal(t, 8080, cfg.App.Port)
+	assert.Empty(t, cfg.DB.Host, "a null in the base file removes the default")
+	assert.Equal(t, 6543, cfg.DB.Port)
+	assert.Equal(t, 30*time.Second, cfg.Timeout)
+
+	assert.Equal(t, "base", result.Sources["app.name"])
+	assert.Equal(t, "defaults", result.Sources["app.port"])
+	assert.Equal(t, "defaults", result.Sources["timeout"])
+	assert.Equal(t, "env:DEF_DB__PORT", result.Sources["db.port"])
+	assert.NotContains(t, result.Sources, "db.name", "zero fields are not part of the defaults layer")
+	assert.NotContains(t, result.Sources, "db.host")
+}
+
+// Test that a map can serve as Defaults and is bound below a Section
+func TestLoad_DefaultsMap(t *testing.T) {
+	var db struct {
+		Host string `yaml:"host"`
+		Port int    `yaml:"port"`
+	}
+	result, err := Load(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("db:\n  host: db.internal\n")),
+		Defaults:   map[string]any{"db": map[string]any{"host": "localhost", "port": 5432}},
+		Section:    "db",
+		Target:     &db,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "db.internal", db.Host)
+	assert.Equal(t, 5432, db.Port)
+	assert.Equal(t, "defaults", result.Sources["db.port"])
+}
+
+// Test that Defaults must be a struct or map and takes part in Precedence
+func TestLoad_DefaultsInvalid(t *testing.T) {
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("")),
+		Defaults:   "port: 1",
+		Target:     &cfg,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "load defaults: expected a struct or map, got string")
+
+	err = LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("")),
+		Defaults:   map[string]any{"version": "1"},
+		Precedence: []LayerKind{LayerBase, LayerEnv},
+		Target:     &cfg,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "precedence: defaults layer is configured but not listed")
+}
+
+// Test that Defaults listed above the base file override it
+func TestLoad_DefaultsPrecedence(t *testing.T) {
+	var cfg TestConfig
+	result, err := Load(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("app:\n  name: base-app\n  port: 80\n")),
+		Defaults:   map[string]any{"app": map[string]any{"name": "forced"}},
+		Precedence: []LayerKind{LayerBase, LayerDefaults, LayerEnv},
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "forced", cfg.App.Name)
+	assert.Equal(t, 80, cfg.App.Port)
+	assert.Equal(t, "defaults", result.Sources["app.name"])
+}
+
+// DefaulterDB fills in its own defaults
+type DefaulterDB struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+}
+
+func (d *DefaulterDB) SetDefaults() {
+	d.Host = "localhost"
+	d.Port = 5432
+}
+
+// DefaulterConfig has defaulted sections by value and behind pointers
+type DefaulterConfig struct {
+	Name    string       `yaml:"name"`
+	Primary DefaulterDB  `yaml:"primary"`
+	Replica *DefaulterDB `yaml:"replica"`
+	Cache   *DefaulterDB `yaml:"cache"`
+	Backup  *DefaulterDB `yaml:"backup"`
+}
+
+func (c *DefaulterConfig) SetDefaults() {
+	c.Name = "app"
+	c.Primary.Port = 6432 // outer defaults run after the nested ones
+}
+
+// Test that SetDefaults runs before layering, also on pointers allocated for present sections
+func TestLoad_SetDefaults(t *testing.T) {
+	setEnvVar(t, "SD_BACKUP__HOST", "backup.internal")
+
+	var cfg DefaulterConfig
+	result, err := Load(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("primary:\n  host: db.internal\nreplica:\n  port: 7000\ncache: null\n")),
+		EnvPrefix:  "SD_",
+		Delimiter:  "__",
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "app", cfg.Name)
+	assert.Equal(t, DefaulterDB{Host: "db.internal", Port: 6432}, cfg.Primary)
+	require.NotNil(t, cfg.Replica)
+	assert.Equal(t, DefaulterDB{Host: "localhost", Port: 7000}, *cfg.Replica)
+	assert.Nil(t, cfg.Cache, "sections absent or null stay nil")
+	require.NotNil(t, cfg.Backup)
+	assert.Equal(t, DefaulterDB{Host: "backup.internal", Port: 5432}, *cfg.Backup, "pointers allocated for env vars are defaulted")
+	assert.NotContains(t, result.Sources, "name", "SetDefaults values have no layer")
+}
+
+// Test that the Defaults layer overrides SetDefaults
+func TestLoad_SetDefaultsBelowDefaultsLayer(t *testing.T) {
+	var cfg DefaulterConfig
+	result, err := Load(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("")),
+		Defaults:   DefaulterConfig{Name: "from-defaults"},
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "from-defaults", cfg.Name)
+	assert.Equal(t, "defaults", result.Sources["name"])
+	assert.Equal(t, 6432, cfg.Primary.Port)
+}
@@ -0,0 +1,83 @@
+package yamlenv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TreeNode struct {
+	Name  string    `yaml:"name"`
+	Child *TreeNode `yaml:"child"`
+}
+
+type TreeConfig struct {
+	Root TreeNode `yaml:"root"`
+}
+
+// Test that env overrides reach pointer-to-struct fields, allocating them on demand
+func TestLoadConfig_EnvOverridesPointerStructs(t *testing.T) {
+	setEnvVar(t, "TREE_ROOT__CHILD__CHILD__NAME", "grandchild")
+
+	var cfg TreeConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("root:\n  name: root\n")),
+		EnvPrefix:  "TREE_",
+		Delimiter:  "__",
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Root.Child)
+	require.NotNil(t, cfg.Root.Child.Child)
+	assert.Equal(t, "grandchild", cfg.Root.Child.Child.Name)
+	assert.Nil(t, cfg.Root.Child.Child.Child)
+}
+
+// Test that nil pointer structs stay nil when no variable targets them
+func TestLoadConfig_PointerStructsStayNil(t *testing.T) {
+	var cfg TreeConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("root:\n  name: root\n")),
+		EnvPrefix:  "TREENIL_",
+		Delimiter:  "__",
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Nil(t, cfg.Root.Child)
+}
+
+// Test that cyclic pointer graphs don't recurse forever
+func TestEnvBinder_PointerCycle(t *testing.T) {
+	setEnvVar(t, "CYCLE_ROOT__NAME", "env")
+
+	var cfg TreeConfig
+	cfg.Root.Child = &TreeNode{}
+	cfg.Root.Child.Child = cfg.Root.Child
+
+	err := newEnvBinder(LoaderOptions{EnvPrefix: "CYCLE_", Delimiter: "__"}).apply(reflect.ValueOf(&cfg), "")
+
+	require.NoError(t, err)
+	assert.Equal(t, "env", cfg.Root.Name)
+}
+
+// Test that nesting beyond MaxDepth is reported instead of overflowing the stack
+func TestLoadConfig_MaxDepth(t *testing.T) {
+	setEnvVar(t, "DEEP_ROOT__CHILD__CHILD__CHILD__NAME", "deep")
+
+	var cfg TreeConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("root:\n  name: root\n")),
+		EnvPrefix:  "DEEP_",
+		Delimiter:  "__",
+		MaxDepth:   3,
+		Target:     &cfg,
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "apply env overrides: root.child.child: struct nesting exceeds MaxDepth (3)")
+}
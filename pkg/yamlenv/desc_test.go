@@ -0,0 +1,61 @@
+package yamlenv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// DescConfig has fields documented with desc tags
+type DescConfig struct {
+	DB struct {
+		PoolSize int    `yaml:"pool_size" desc:"maximum connections in the pool"`
+		Mode     string `yaml:"mode" enum:"primary,replica" desc:"which server to connect to"`
+		Replica  string `yaml:"replica" requires:"mode"`
+	} `yaml:"db"`
+	TLS struct {
+		Cert string `yaml:"cert" requires:"key" desc:"PEM certificate file"`
+		Key  string `yaml:"key"`
+	} `yaml:"tls"`
+}
+
+// Test that env parse errors name the field with its description
+func TestDesc_EnvError(t *testing.T) {
+	t.Setenv("DESC_DB__POOL_SIZE", "ten")
+
+	var cfg DescConfig
+	err := LoadConfig(LoaderOptions{BaseSource: StringSource("{}"), EnvPrefix: "DESC_", Delimiter: "__", Target: &cfg})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `set field db.pool_size (maximum connections in the pool) from DESC_DB__POOL_SIZE="ten" (int): parse int "ten"`)
+}
+
+// Test that constraint violations name the field with its description
+func TestDesc_ConstraintErrors(t *testing.T) {
+	var cfg DescConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: StringSource("db:\n  mode: standby\ntls:\n  cert: /etc/tls.pem\n"),
+		Target:     &cfg,
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, `invalid config: db.mode (which server to connect to): invalid value "standby" (from base); allowed: primary, replica
+tls.cert (PEM certificate file) is set (from base), so tls.key is required`, err.Error())
+}
+
+// Test that descriptions are listed by EnvVars and in .env.example files
+func TestDesc_EnvVarsAndExample(t *testing.T) {
+	opts := LoaderOptions{BaseSource: StringSource("db:\n  pool_size: 10\n"), EnvPrefix: "DESC_", Delimiter: "__", Target: &DescConfig{}}
+
+	vars, err := EnvVars(opts)
+	require.NoError(t, err)
+	assert.Equal(t, "maximum connections in the pool", vars[0].Desc)
+	assert.Empty(t, vars[2].Desc)
+
+	var out bytes.Buffer
+	require.NoError(t, WriteEnvExample(&out, opts))
+	assert.True(t, strings.Contains(out.String(), "\n# maximum connections in the pool\n# db.pool_size (int), default: 10\nDESC_DB__POOL_SIZE=\n"), out.String())
+}
@@ -0,0 +1,134 @@
+package yamlenv
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// redacted replaces secret values in diffs and other output
+const redacted = "***"
+
+// Change describes one configuration value that differs between two configs
+type Change struct {
+	Path string // dot-separated YAML path, e.g. "db.pool_size"
+	Old  any    // previous value; nil if the key was added
+	New  any    // new value; nil if the key was removed
+}
+
+// String formats the change as "path: old -> new"
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %v -> %v", c.Path, c.Old, c.New)
+}
+
+// Diff compares two configs of the same type (values or pointers) and
+// returns the changed leaf values in path order. Values of fields tagged
+// `secret:"true"` are reported as "***".
+func Diff(old, new any) []Change {
+	var changes []Change
+	diffValues(reflect.ValueOf(old), reflect.ValueOf(new), "", false, &changes)
+	return changes
+}
+
+func diffValues(a, b reflect.Value, path string, secret bool, out *[]Change) {
+	a, b = indirectValue(a), indirectValue(b)
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() != b.IsValid() {
+			*out = append(*out, Change{Path: path, Old: changeValue(a, secret), New: changeValue(b, secret)})
+		}
+		return
+	}
+	if a.Type() != b.Type() || isLeafType(a.Type()) {
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*out = append(*out, Change{Path: path, Old: changeValue(a, secret), New: changeValue(b, secret)})
+		}
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Struct:
+		for _, info := range cachedFields(a.Type()) {
+			fieldPath := path
+			if !info.Inline {
+				fieldPath = joinPath(path, info.Name)
+			}
+			diffValues(a.Field(info.Index), b.Field(info.Index), fieldPath, secret || info.Secret, out)
+		}
+	case reflect.Map:
+		keys := map[string]reflect.Value{}
+		for _, k := range a.MapKeys() {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		for _, k := range b.MapKeys() {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		names := make([]string, 0, len(keys))
+		for name := range keys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			diffValues(a.MapIndex(keys[name]), b.MapIndex(keys[name]), joinPath(path, name), secret, out)
+		}
+	case reflect.Slice, reflect.Array:
+		n := a.Len()
+		if b.Len() > n {
+			n = b.Len()
+		}
+		for i := 0; i < n; i++ {
+			var ai, bi reflect.Value
+			if i < a.Len() {
+				ai = a.Index(i)
+			}
+			if i < b.Len() {
+				bi = b.Index(i)
+			}
+			diffValues(ai, bi, joinPath(path, fmt.Sprint(i)), secret, out)
+		}
+	}
+}
+
+// indirectValue dereferences pointers and interfaces; nil ones become invalid
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// changeValue returns the value to report in a Change
+func changeValue(v reflect.Value, secret bool) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if secret {
+		return redacted
+	}
+	return v.Interface()
+}
+
+// isLeafType reports whether values of t are compared as a whole rather
+// than walked field by field
+func isLeafType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct:
+		if t == reflect.TypeOf(time.Time{}) || t == yamlNodeType {
+			return true
+		}
+		ptr := reflect.PointerTo(t)
+		return ptr.Implements(envUnmarshalerType) ||
+			ptr.Implements(yamlUnmarshalerType) ||
+			ptr.Implements(reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem())
+	case reflect.Map, reflect.Array:
+		return false
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8
+	default:
+		return true
+	}
+}
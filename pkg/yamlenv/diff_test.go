@@ -0,0 +1,109 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type DiffTestConfig struct {
+	App struct {
+		Name    string        `yaml:"name"`
+		Timeout time.Duration `yaml:"timeout"`
+	} `yaml:"app"`
+	DB struct {
+		Host     string `yaml:"host"`
+		Password string `yaml:"password" secret:"true"`
+	} `yaml:"db"`
+	Tokens  map[string]string `yaml:"tokens" secret:"true"`
+	Hosts   []string          `yaml:"hosts"`
+	Labels  map[string]string `yaml:"labels"`
+	Retries Option[int]       `yaml:"retries"`
+	Backup  *struct {
+		Dir string `yaml:"dir"`
+	} `yaml:"backup"`
+}
+
+// Test leaf-level changes across structs, maps, slices and pointers
+func TestDiff_Changes(t *testing.T) {
+	var a, b DiffTestConfig
+	a.App.Name, b.App.Name = "one", "two"
+	a.App.Timeout, b.App.Timeout = time.Second, time.Second
+	a.Hosts, b.Hosts = []string{"a", "b"}, []string{"a", "c", "d"}
+	a.Labels, b.Labels = map[string]string{"team": "x", "old": "y"}, map[string]string{"team": "x", "new": "z"}
+	a.Retries, b.Retries = Some(1), Some(2)
+	b.Backup = &struct {
+		Dir string `yaml:"dir"`
+	}{Dir: "/backup"}
+
+	changes := Diff(&a, &b)
+
+	assert.Equal(t, []Change{
+		{Path: "app.name", Old: "one", New: "two"},
+		{Path: "hosts.1", Old: "b", New: "c"},
+		{Path: "hosts.2", Old: nil, New: "d"},
+		{Path: "labels.new", Old: nil, New: "z"},
+		{Path: "labels.old", Old: "y", New: nil},
+		{Path: "retries", Old: Some(1), New: Some(2)},
+		{Path: "backup", Old: nil, New: *b.Backup},
+	}, changes)
+}
+
+// Test that secret fields and everything below them are redacted
+func TestDiff_RedactsSecrets(t *testing.T) {
+	var a, b DiffTestConfig
+	a.DB.Password, b.DB.Password = "old-pass", "new-pass"
+	a.Tokens, b.Tokens = map[string]string{"api": "t1"}, map[string]string{"api": "t2"}
+
+	changes := Diff(a, b)
+
+	require.Len(t, changes, 2)
+	assert.Equal(t, Change{Path: "db.password", Old: "***", New: "***"}, changes[0])
+	assert.Equal(t, Change{Path: "tokens.api", Old: "***", New: "***"}, changes[1])
+	assert.Equal(t, "db.password: *** -> ***", changes[0].String())
+}
+
+func TestDiff_NoChanges(t *testing.T) {
+	var a DiffTestConfig
+	a.Hosts = []string{"x"}
+	assert.Empty(t, Diff(&a, &a))
+}
+
+// Test that OnChange receives the diff after a reload
+func TestLoader_OnChange(t *testing.T) {
+	file := createTempYAML(t, "app:\n  name: one\ndb:\n  password: p1\n")
+
+	var cfg DiffTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(file), Target: &cfg})
+	require.NoError(t, err)
+
+	var got [][]Change
+	unsubscribe := loader.OnChange(func(changes []Change) { got = append(got, changes) })
+
+	// No change, no callback
+	require.NoError(t, loader.Reload())
+	assert.Empty(t, got)
+
+	writeFile(t, file, "app:\n  name: two\ndb:\n  password: p2\n")
+	require.NoError(t, loader.Reload())
+	require.Len(t, got, 1)
+	assert.Equal(t, []Change{
+		{Path: "app.name", Old: "one", New: "two"},
+		{Path: "db.password", Old: "***", New: "***"},
+	}, got[0])
+
+	unsubscribe()
+	writeFile(t, file, "app:\n  name: three\n")
+	require.NoError(t, loader.Reload())
+	assert.Len(t, got, 1)
+}
+
+func TestDiff_TypeMismatchIsLeaf(t *testing.T) {
+	changes := Diff(map[string]any{"a": 1}, map[string]any{"a": "1"})
+	require.Len(t, changes, 1)
+	assert.Equal(t, "a", changes[0].Path)
+	assert.True(t, strings.HasPrefix(changes[0].String(), "a: 1 -> 1"))
+}
@@ -0,0 +1,105 @@
+package yamlenv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DirSource creates a ConfigSource from a directory of one-value files, as
+// used for Docker secrets (/run/secrets) and systemd credentials. Each file
+// becomes a key named after the file; with a non-empty delimiter the name is
+// split into nested keys, so "db__password" sets db.password. Trailing
+// newlines are trimmed and values are typed like plain YAML scalars.
+// Hidden files and subdirectories are skipped.
+func DirSource(dir, delimiter string) ConfigSource {
+	return func() (io.ReadCloser, error) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("read config dir: %w", err)
+		}
+
+		root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("read %s: %w", entry.Name(), err)
+			}
+
+			keys := []string{entry.Name()}
+			if delimiter != "" {
+				keys = strings.Split(entry.Name(), delimiter)
+			}
+			setNodePath(root, keys, dirValueNode(strings.TrimRight(string(data), "\r\n")))
+		}
+
+		data, err := yaml.Marshal(root)
+		if err != nil {
+			return nil, fmt.Errorf("marshal config dir: %w", err)
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+}
+
+// CredentialsSource creates a ConfigSource from the systemd credentials
+// directory ($CREDENTIALS_DIRECTORY, populated by LoadCredential= and
+// SetCredential=), with file names mapped to keys like DirSource. It fails
+// if the variable isn't set when the source is read.
+func CredentialsSource(delimiter string) ConfigSource {
+	return func() (io.ReadCloser, error) {
+		dir := os.Getenv("CREDENTIALS_DIRECTORY")
+		if dir == "" {
+			return nil, fmt.Errorf("CREDENTIALS_DIRECTORY is not set")
+		}
+		return DirSource(dir, delimiter)()
+	}
+}
+
+// dirValueNode returns a plain scalar for a file value; values that YAML
+// would read as null are kept as strings so they don't delete keys
+func dirValueNode(value string) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+	switch value {
+	case "", "~", "null", "Null", "NULL":
+		n.Tag = "!!str"
+	}
+	return n
+}
+
+// setNodePath sets value at the nested keys below a mapping node, creating
+// intermediate mappings as needed
+func setNodePath(n *yaml.Node, keys []string, value *yaml.Node) {
+	for i, key := range keys {
+		idx := mappingIndex(n, key)
+		if i == len(keys)-1 {
+			if idx >= 0 {
+				n.Content[idx+1] = value
+			} else {
+				n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+			}
+			return
+		}
+		if idx < 0 || n.Content[idx+1].Kind != yaml.MappingNode {
+			child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			if idx >= 0 {
+				n.Content[idx+1] = child
+			} else {
+				n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, child)
+			}
+		}
+		n = n.Content[mappingIndex(n, key)+1]
+	}
+}
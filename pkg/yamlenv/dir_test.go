@@ -0,0 +1,84 @@
+package yamlenv
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createSecretsDir(t *testing.T) string {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "db__host"), "db.internal\n")
+	writeFile(t, filepath.Join(dir, "db__port"), "6543\n")
+	writeFile(t, filepath.Join(dir, "app__name"), "null\n")
+	writeFile(t, filepath.Join(dir, ".hidden"), "ignored")
+	return dir
+}
+
+// Test that secret files are layered over local config and under env
+func TestLoadConfig_SecretsDir(t *testing.T) {
+	setEnvVar(t, "SECRETS_DB__PORT", "7000")
+
+	var cfg TestConfig
+	result, err := Load(LoaderOptions{
+		BaseSource:    ReaderSource(strings.NewReader("app:\n  name: base\n  port: 8080\ndb:\n  host: localhost\n  port: 5432\n")),
+		LocalSource:   ReaderSource(strings.NewReader("db:\n  host: local\n")),
+		SecretsSource: DirSource(createSecretsDir(t), "__"),
+		EnvPrefix:     "SECRETS_",
+		Delimiter:     "__",
+		Target:        &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "null", cfg.App.Name)
+	assert.Equal(t, 8080, cfg.App.Port)
+	assert.Equal(t, "db.internal", cfg.DB.Host)
+	assert.Equal(t, 7000, cfg.DB.Port)
+	assert.Equal(t, "secrets", result.Sources["db.host"])
+}
+
+// Test that CredentialsSource reads $CREDENTIALS_DIRECTORY
+func TestLoadConfig_SystemdCredentials(t *testing.T) {
+	setEnvVar(t, "CREDENTIALS_DIRECTORY", createSecretsDir(t))
+
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:    ReaderSource(strings.NewReader("db:\n  port: 5432\n")),
+		SecretsSource: CredentialsSource("__"),
+		Target:        &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "db.internal", cfg.DB.Host)
+	assert.Equal(t, 6543, cfg.DB.Port)
+}
+
+// Test that a missing credentials directory is reported
+func TestCredentialsSource_NotSet(t *testing.T) {
+	setEnvVar(t, "CREDENTIALS_DIRECTORY", "")
+
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:    ReaderSource(strings.NewReader("db:\n  port: 5432\n")),
+		SecretsSource: CredentialsSource("__"),
+		Target:        &cfg,
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "load secrets config")
+	assert.Contains(t, err.Error(), "CREDENTIALS_DIRECTORY is not set")
+}
+
+// Test that file names without a delimiter map to top-level keys
+func TestDirSource_FlatNames(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "version"), "2.0\n")
+
+	node, err := loadNodeFromSource(DirSource(dir, ""), parseLimits{})
+	require.NoError(t, err)
+	require.NotNil(t, lookupPath(node, "version"))
+	assert.Equal(t, "2.0", lookupPath(node, "version").Value)
+}
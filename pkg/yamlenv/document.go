@@ -0,0 +1,232 @@
+package yamlenv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is a YAML config file held as a yaml.v3 node tree, so it can be
+// read, edited and written back with its comments, key order and formatting
+// intact. Its top level is always a mapping.
+//
+//	doc, err := yamlenv.ReadDocument("config.local.yaml")
+//	err = doc.Set("db.host", "dev-db")
+//	err = doc.WriteFile("config.local.yaml")
+type Document struct {
+	node *yaml.Node  // the document node; node.Content[0] is the top-level mapping
+	mode fs.FileMode // permissions of the file read, used by WriteFile
+}
+
+// NewDocument returns an empty document
+func NewDocument() *Document {
+	return &Document{
+		node: &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}},
+		mode: 0o644,
+	}
+}
+
+// ParseDocument parses YAML into a document. Empty input yields an empty
+// document; anything but a mapping at the top level is an error.
+func ParseDocument(data []byte) (*Document, error) {
+	d := NewDocument()
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	if len(node.Content) == 0 {
+		return d, nil
+	}
+	if resolveAlias(node.Content[0]).Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("top level is not a mapping")
+	}
+	d.node = &node
+	return d, nil
+}
+
+// ReadDocument parses the YAML file at path. A missing file yields an empty
+// document, so edits can create it; WriteFile keeps the file's permissions.
+func ReadDocument(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return NewDocument(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	d, err := ParseDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if info, err := os.Stat(path); err == nil {
+		d.mode = info.Mode().Perm()
+	}
+	return d, nil
+}
+
+// Node returns the top-level mapping node. Changes to it are part of the
+// document.
+func (d *Document) Node() *yaml.Node {
+	return resolveAlias(d.node.Content[0])
+}
+
+// Get returns the node at the dot-separated path, following aliases
+func (d *Document) Get(path string) (*yaml.Node, bool) {
+	n := lookupPath(d.Node(), path)
+	return n, n != nil
+}
+
+// Decode decodes the value at path into v, or the whole document when path
+// is empty. It reports false if path doesn't exist.
+func (d *Document) Decode(path string, v any) (bool, error) {
+	n, ok := d.Get(path)
+	if !ok {
+		return false, nil
+	}
+	return true, n.Decode(v)
+}
+
+// Set sets the value at the dot-separated path, creating mappings along the
+// way. value is a *yaml.Node or any value yaml.v3 can encode. A replaced
+// value keeps its comments unless the new node has its own. Setting below a
+// key that holds a scalar or list is an error.
+func (d *Document) Set(path string, value any) error {
+	node, ok := value.(*yaml.Node)
+	if !ok {
+		node = &yaml.Node{}
+		if err := node.Encode(value); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	keys := strings.Split(path, ".")
+	n := d.Node()
+	for i, key := range keys[:len(keys)-1] {
+		idx := mappingIndex(n, key)
+		if idx < 0 {
+			n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+			idx = len(n.Content) - 2
+		}
+		if resolveAlias(n.Content[idx+1]).Kind != yaml.MappingNode {
+			return fmt.Errorf("%s is not a mapping", strings.Join(keys[:i+1], "."))
+		}
+		n = resolveAlias(n.Content[idx+1])
+	}
+	replaceValue(n, keys[len(keys)-1], node)
+	return nil
+}
+
+// Delete removes the key at the dot-separated path, along with its
+// comments. It reports whether the key existed.
+func (d *Document) Delete(path string) bool {
+	parent, key := d.Node(), path
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		parent, key = lookupPath(d.Node(), path[:i]), path[i+1:]
+	}
+	if parent == nil || parent.Kind != yaml.MappingNode {
+		return false
+	}
+	idx := mappingIndex(parent, key)
+	if idx < 0 {
+		return false
+	}
+	parent.Content = append(parent.Content[:idx], parent.Content[idx+2:]...)
+	return true
+}
+
+// Merge merges patch into the document: nested mappings merge key by key,
+// and other values replace existing ones, keeping their comments. patch is
+// another *Document, a mapping *yaml.Node, or a struct or map whose non-zero
+// values are merged.
+func (d *Document) Merge(patch any) error {
+	var src *yaml.Node
+	switch p := patch.(type) {
+	case *Document:
+		src = p.Node()
+	case *yaml.Node:
+		src = p
+	default:
+		n, err := nonZeroNode(patch)
+		if err != nil {
+			return err
+		}
+		src = n
+	}
+	if src == nil {
+		return nil
+	}
+	if src.Kind == yaml.DocumentNode {
+		if len(src.Content) == 0 {
+			return nil
+		}
+		src = src.Content[0]
+	}
+	src = resolveAlias(src)
+	if src.Kind != yaml.MappingNode {
+		return fmt.Errorf("merge: patch is not a mapping")
+	}
+	mergeDocumentNode(d.Node(), src)
+	return nil
+}
+
+// Bytes encodes the document as YAML with two-space indentation
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(d.node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteFile writes the document to path atomically, with the permissions of
+// the file it was read from (0644 for new documents)
+func (d *Document) WriteFile(path string) error {
+	data, err := d.Bytes()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, d.mode)
+}
+
+// mergeDocumentNode merges the mapping src into dst in place
+func mergeDocumentNode(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		if idx := mappingIndex(dst, key.Value); idx >= 0 {
+			existing := resolveAlias(dst.Content[idx+1])
+			if existing.Kind == yaml.MappingNode && resolveAlias(value).Kind == yaml.MappingNode {
+				mergeDocumentNode(existing, resolveAlias(value))
+				continue
+			}
+		}
+		replaceValue(dst, key.Value, value)
+	}
+}
+
+// replaceValue sets key in the mapping n to value. A replaced value passes
+// its comments on to a value without any.
+func replaceValue(n *yaml.Node, key string, value *yaml.Node) {
+	idx := mappingIndex(n, key)
+	if idx < 0 {
+		n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+		return
+	}
+	existing := n.Content[idx+1]
+	if value.HeadComment == "" && value.LineComment == "" && value.FootComment == "" {
+		value.HeadComment, value.LineComment, value.FootComment = existing.HeadComment, existing.LineComment, existing.FootComment
+	}
+	n.Content[idx+1] = value
+}
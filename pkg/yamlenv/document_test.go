@@ -0,0 +1,84 @@
+package yamlenv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// Test that edits keep comments, key order and the file's permissions
+func TestDocument_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`# Service config
+app:
+  # Public name
+  name: svc
+  port: 8080 # behind the proxy
+db:
+  host: localhost
+  name: app
+`), 0o600))
+
+	doc, err := ReadDocument(path)
+	require.NoError(t, err)
+	require.NoError(t, doc.Set("app.port", 9090))
+	require.NoError(t, doc.Set("db.pool.size", 10))
+	assert.True(t, doc.Delete("db.name"))
+	assert.False(t, doc.Delete("db.missing"))
+	require.NoError(t, doc.Merge(map[string]any{"app": map[string]any{"name": "api"}}))
+	require.NoError(t, doc.WriteFile(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `# Service config
+app:
+  # Public name
+  name: api
+  port: 9090 # behind the proxy
+db:
+  host: localhost
+  pool:
+    size: 10
+`, string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+// Test reading values and the errors for non-mapping documents and paths
+func TestDocument_GetAndErrors(t *testing.T) {
+	doc, err := ParseDocument([]byte("base: &base\n  host: h\ndb: *base\nlist: [1]\n"))
+	require.NoError(t, err)
+
+	var host string
+	ok, err := doc.Decode("db.host", &host)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "h", host)
+	_, ok = doc.Get("db.port")
+	assert.False(t, ok)
+
+	assert.EqualError(t, doc.Set("list.x", 1), "list is not a mapping")
+	assert.EqualError(t, doc.Merge(&yaml.Node{Kind: yaml.ScalarNode, Value: "x"}), "merge: patch is not a mapping")
+
+	_, err = ParseDocument([]byte("- a\n"))
+	assert.EqualError(t, err, "top level is not a mapping")
+
+	empty, err := ParseDocument(nil)
+	require.NoError(t, err)
+	other, err := ParseDocument([]byte("a:\n  b: 1\n"))
+	require.NoError(t, err)
+	require.NoError(t, empty.Merge(other))
+	data, err := empty.Bytes()
+	require.NoError(t, err)
+	assert.Equal(t, "a:\n  b: 1\n", string(data))
+
+	missing, err := ReadDocument(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.NoError(t, err)
+	_, ok = missing.Get("a")
+	assert.False(t, ok)
+}
@@ -0,0 +1,173 @@
+package yamlenv
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Drift compares the effective config current with a reference, such as
+// the last-deployed artifact written by WriteEffectiveConfig, and returns
+// the values that differ in path order, with Old from reference and New
+// from current. Either side may be a config struct (value or pointer) or
+// the map[string]any a YAML document decodes into; values are compared by
+// YAML name after a YAML round trip, so 8080 and uint16(8080) match.
+// Secret fields of current are not compared, as artifacts are usually
+// redacted.
+func Drift(reference, current any) []Change {
+	var changes []Change
+	driftValues(normalizePlain(reference), normalizePlain(current), "", &changes)
+	return changes
+}
+
+// normalizePlain converts v into the maps, slices and scalars a YAML
+// document with the same content decodes into
+func normalizePlain(v any) any {
+	plain := plainValue(reflect.ValueOf(v), false)
+	data, err := yaml.Marshal(plain)
+	if err != nil {
+		return plain
+	}
+	var out any
+	if err := yaml.Unmarshal(data, &out); err != nil {
+		return plain
+	}
+	return out
+}
+
+func driftValues(ref, cur any, path string, out *[]Change) {
+	if cur == redacted {
+		return
+	}
+	refMap, refIsMap := ref.(map[string]any)
+	curMap, curIsMap := cur.(map[string]any)
+	if refIsMap && curIsMap {
+		keys := make([]string, 0, len(refMap)+len(curMap))
+		for key := range refMap {
+			keys = append(keys, key)
+		}
+		for key := range curMap {
+			if _, ok := refMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			driftValues(refMap[key], curMap[key], joinPath(path, key), out)
+		}
+		return
+	}
+	refList, refIsList := ref.([]any)
+	curList, curIsList := cur.([]any)
+	if refIsList && curIsList {
+		for i := 0; i < max(len(refList), len(curList)); i++ {
+			var r, c any
+			if i < len(refList) {
+				r = refList[i]
+			}
+			if i < len(curList) {
+				c = curList[i]
+			}
+			driftValues(r, c, joinPath(path, fmt.Sprint(i)), out)
+		}
+		return
+	}
+	if !reflect.DeepEqual(ref, cur) {
+		*out = append(*out, Change{Path: path, Old: ref, New: cur})
+	}
+}
+
+// DriftMetrics is implemented by Metrics that also record config drift.
+// WatchDrift calls DriftChecked after every check; drifted is 0 when the
+// config matches the reference.
+type DriftMetrics interface {
+	DriftChecked(drifted int, err error)
+}
+
+// DriftOptions configures Loader.WatchDrift
+type DriftOptions struct {
+	Reference ConfigSource   // required: the expected effective config, e.g. FileSource of a WriteEffectiveConfig artifact
+	Interval  time.Duration  // time between checks; default 1m
+	OnDrift   func([]Change) // optional: called when the set of drifted values changes, with nil once it is back in line; default logs a warning
+	OnError   func(error)    // optional: called when the reference can't be read
+}
+
+// CheckDrift compares the loader's current config with the reference
+// document, typically the artifact written by WriteEffectiveConfig at
+// deploy time. The reference holds the whole document, so with a Section
+// only its subtree is compared.
+func (l *Loader) CheckDrift(reference ConfigSource) ([]Change, error) {
+	node, err := loadNodeFromSource(reference, sourceLimits(l.opts))
+	if err != nil {
+		return nil, fmt.Errorf("load drift reference: %w", err)
+	}
+	var doc any
+	if n := lookupPath(node, l.opts.Section); n != nil {
+		if err := n.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("load drift reference: %w", err)
+		}
+	}
+	return Drift(doc, l.Current()), nil
+}
+
+// WatchDrift checks the loader's config against opts.Reference every
+// interval, and reports when it drifts, for instance after somebody edited
+// config.local.yaml on one host and the config was reloaded. The reference
+// is read again for every check, so redeploying it is picked up. Results go
+// to the loader's Metrics when it implements DriftMetrics. It blocks until
+// ctx is done and returns ctx.Err().
+func (l *Loader) WatchDrift(ctx context.Context, opts DriftOptions) error {
+	if opts.Reference == nil {
+		return fmt.Errorf("drift reference cannot be nil")
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	onDrift := opts.OnDrift
+	if onDrift == nil {
+		logger := loggerOrDefault(l.opts.Logger)
+		onDrift = func(changes []Change) {
+			if len(changes) == 0 {
+				logger.Printf("[yamlenv] config matches its reference again")
+				return
+			}
+			paths := make([]string, len(changes))
+			for i, c := range changes {
+				paths[i] = c.Path
+			}
+			logger.Printf("[yamlenv] warning: config drifted from its reference: %s", strings.Join(paths, ", "))
+		}
+	}
+	metrics, _ := l.opts.Metrics.(DriftMetrics)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var last []Change
+	for {
+		changes, err := l.CheckDrift(opts.Reference)
+		if metrics != nil {
+			metrics.DriftChecked(len(changes), err)
+		}
+		switch {
+		case err != nil:
+			if opts.OnError != nil {
+				opts.OnError(err)
+			}
+		case !reflect.DeepEqual(changes, last):
+			last = changes
+			onDrift(changes)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
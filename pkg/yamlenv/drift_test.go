@@ -0,0 +1,118 @@
+package yamlenv
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that a struct is compared with a decoded YAML document by YAML name
+func TestDrift_StructAgainstDocument(t *testing.T) {
+	var cfg EffectiveTestConfig
+	cfg.App.Name, cfg.App.Port = "svc", 8081
+	cfg.DB.Password = "changed"
+	reference := map[string]any{
+		"app": map[string]any{"name": "svc", "port": 8080},
+		"db":  map[string]any{"password": "***", "token": "***"},
+	}
+
+	changes := Drift(reference, &cfg)
+
+	assert.Equal(t, []Change{{Path: "app.port", Old: 8080, New: 8081}}, changes)
+	assert.Empty(t, Drift(map[string]any{
+		"app": map[string]any{"name": "svc", "port": uint16(8081)},
+		"db":  map[string]any{"password": "***", "token": "***"},
+	}, cfg))
+}
+
+// Test that CheckDrift compares against an artifact written by WriteEffectiveConfig
+func TestLoader_CheckDrift(t *testing.T) {
+	base := createTempYAML(t, "app:\n  name: svc\n  port: 8080\ndb:\n  password: hunter2\n")
+	local := base + ".local"
+	var cfg EffectiveTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(base), LocalSource: FileSource(local), Target: &cfg})
+	require.NoError(t, err)
+	artifact := filepath.Join(t.TempDir(), "effective.yaml")
+	require.NoError(t, loader.WriteEffectiveConfig(artifact, true, 0))
+
+	changes, err := loader.CheckDrift(FileSource(artifact))
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	require.NoError(t, os.WriteFile(local, []byte("app:\n  port: 9090\n"), 0o644))
+	require.NoError(t, loader.Reload())
+	changes, err = loader.CheckDrift(FileSource(artifact))
+	require.NoError(t, err)
+	assert.Equal(t, []Change{{Path: "app.port", Old: 8080, New: 9090}}, changes)
+
+	_, err = loader.CheckDrift(FileSource(filepath.Join(t.TempDir(), "missing.yaml")))
+	assert.ErrorContains(t, err, "load drift reference")
+}
+
+// driftRecorder also records DriftChecked calls
+type driftRecorder struct {
+	recordingMetrics
+	checks []int
+}
+
+func (r *driftRecorder) DriftChecked(drifted int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, drifted)
+}
+
+// Test that WatchDrift reports drift once, and again when it is resolved
+func TestLoader_WatchDrift(t *testing.T) {
+	base := createTempYAML(t, "app:\n  name: svc\n  port: 8080\n")
+	metrics := &driftRecorder{}
+	var cfg EffectiveTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(base), Target: &cfg, Metrics: metrics})
+	require.NoError(t, err)
+	reference := createTempYAML(t, "app:\n  name: svc\n  port: 9090\ndb:\n  password: '***'\n  token: '***'\n")
+
+	reports := make(chan []Change, 4)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- loader.WatchDrift(ctx, DriftOptions{
+			Reference: FileSource(reference),
+			Interval:  10 * time.Millisecond,
+			OnDrift:   func(changes []Change) { reports <- changes },
+		})
+	}()
+
+	select {
+	case changes := <-reports:
+		assert.Equal(t, []Change{{Path: "app.port", Old: 9090, New: 8080}}, changes)
+	case <-time.After(time.Second):
+		t.Fatal("drift not reported")
+	}
+
+	require.NoError(t, os.WriteFile(base, []byte("app:\n  name: svc\n  port: 9090\n"), 0o644))
+	require.NoError(t, loader.Reload())
+	select {
+	case changes := <-reports:
+		assert.Empty(t, changes)
+	case <-time.After(time.Second):
+		t.Fatal("resolved drift not reported")
+	}
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Empty(t, reports, "unchanged drift is reported once")
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	assert.Equal(t, 1, metrics.checks[0])
+}
+
+func TestLoader_WatchDriftRequiresReference(t *testing.T) {
+	var cfg EffectiveTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(createTempYAML(t, "app:\n  name: svc\n")), Target: &cfg})
+	require.NoError(t, err)
+	assert.Error(t, loader.WatchDrift(context.Background(), DriftOptions{}))
+}
@@ -0,0 +1,106 @@
+package yamlenv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WriteEffectiveConfig writes the current configuration as YAML to path, for
+// audit and debugging: every value after all layers, env vars and overrides
+// are applied, nested under Section when one is bound. With redact, secret
+// values are written as "***"; without it they are written in full, so keep
+// such files private.
+//
+// The file is replaced atomically, so readers never see a partial write.
+// mode sets its permissions; 0 means 0644 when redacted and 0600 otherwise.
+//
+//	err := loader.WriteEffectiveConfig("/var/lib/app/effective-config.yaml", true, 0)
+func (l *Loader) WriteEffectiveConfig(path string, redact bool, mode fs.FileMode) error {
+	if mode == 0 {
+		mode = 0o644
+		if !redact {
+			mode = 0o600
+		}
+	}
+
+	current := l.snapshot()
+	var doc any = convertPlain(current, false, !redact)
+	if l.opts.Section != "" {
+		segments := strings.Split(l.opts.Section, ".")
+		for i := len(segments) - 1; i >= 0; i-- {
+			doc = map[string]any{segments[i]: doc}
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Effective configuration written by yamlenv at %s\n", time.Now().UTC().Format(time.RFC3339))
+	fmt.Fprintf(&buf, "# checksum: %s\n", Checksum(current.Interface()))
+	if redact {
+		buf.WriteString("# Secret values are redacted.\n")
+	}
+	if err := encodeYAML(&buf, doc); err != nil {
+		return fmt.Errorf("write effective config: %w", err)
+	}
+	if err := writeFileAtomic(path, buf.Bytes(), mode); err != nil {
+		return fmt.Errorf("write effective config: %w", err)
+	}
+	return nil
+}
+
+// RenderYAML renders a config as YAML keyed by YAML names, with map keys
+// sorted, durations as strings and secret values as "***", so the same
+// values always give the same bytes. It is meant for golden-file tests of
+// a service's effective config; see the yamlenvtest package.
+func RenderYAML(cfg any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeYAML(&buf, plainValue(reflect.ValueOf(cfg), false)); err != nil {
+		return nil, fmt.Errorf("render config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeYAML writes doc with two-space indentation
+func encodeYAML(w io.Writer, doc any) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it over path, so path always holds either the old or the new content
+func writeFileAtomic(path string, data []byte, mode fs.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
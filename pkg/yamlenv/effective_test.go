@@ -0,0 +1,100 @@
+package yamlenv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// EffectiveTestConfig has plain and secret values
+type EffectiveTestConfig struct {
+	App struct {
+		Name string `yaml:"name"`
+		Port int    `yaml:"port"`
+	} `yaml:"app"`
+	DB struct {
+		Password string         `yaml:"password" secret:"true"`
+		Token    Secret[string] `yaml:"token"`
+	} `yaml:"db"`
+}
+
+// Test that the effective config is written redacted, with a private mode when unredacted
+func TestLoader_WriteEffectiveConfig(t *testing.T) {
+	setEnvVar(t, "EFF_APP__PORT", "9090")
+	var cfg EffectiveTestConfig
+	loader, err := NewLoader(LoaderOptions{
+		BaseSource: FileSource(createTempYAML(t, "app:\n  name: svc\n  port: 8080\ndb:\n  password: hunter2\n  token: t0k3n\n")),
+		EnvPrefix:  "EFF_",
+		Delimiter:  "__",
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "effective.yaml")
+	require.NoError(t, loader.WriteEffectiveConfig(path, true, 0))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.SplitN(string(data), "\n", 4)
+	assert.True(t, strings.HasPrefix(lines[0], "# Effective configuration written by yamlenv at "))
+	assert.Equal(t, "# checksum: "+loader.Checksum(), lines[1])
+	assert.Equal(t, "# Secret values are redacted.", lines[2])
+	assert.Equal(t, "app:\n  name: svc\n  port: 9090\ndb:\n  password: '***'\n  token: '***'\n", lines[3])
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+
+	require.NoError(t, loader.WriteEffectiveConfig(path, false, 0))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "db:\n  password: hunter2\n  token: t0k3n\n")
+	assert.NotContains(t, string(data), "redacted")
+	info, err = os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+}
+
+// Test that a bound section is nested under its path and the mode is honored
+func TestLoader_WriteEffectiveConfigSection(t *testing.T) {
+	var db struct {
+		Host string `yaml:"host"`
+	}
+	loader, err := NewLoader(LoaderOptions{
+		BaseSource: FileSource(createTempYAML(t, "services:\n  db:\n    host: db.internal\n")),
+		Section:    "services.db",
+		Target:     &db,
+	})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "effective.yaml")
+	require.NoError(t, loader.WriteEffectiveConfig(path, true, 0o640))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(string(data), "services:\n  db:\n    host: db.internal\n"))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+
+	err = loader.WriteEffectiveConfig(filepath.Join(t.TempDir(), "missing", "effective.yaml"), true, 0)
+	assert.ErrorContains(t, err, "write effective config:")
+}
+
+// Test that RenderYAML sorts keys and redacts secrets
+func TestRenderYAML(t *testing.T) {
+	var cfg EffectiveTestConfig
+	cfg.App.Name, cfg.App.Port = "svc", 8080
+	cfg.DB.Password = "hunter2"
+
+	data, err := RenderYAML(&cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "app:\n  name: svc\n  port: 8080\ndb:\n  password: '***'\n  token: '***'\n", string(data))
+}
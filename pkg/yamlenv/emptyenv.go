@@ -0,0 +1,39 @@
+package yamlenv
+
+import "fmt"
+
+// EmptyEnv says what an environment variable set to the empty string means
+type EmptyEnv int
+
+const (
+	// EmptyEnvEmpty treats MYAPP_DB__HOST="" as an explicit empty value that
+	// blanks the field (default)
+	EmptyEnvEmpty EmptyEnv = iota
+	// EmptyEnvUnset treats empty variables as if they weren't set, so
+	// platforms that export empty placeholders don't wipe file values and
+	// defaults
+	EmptyEnvUnset
+)
+
+// String returns the name of the mode
+func (e EmptyEnv) String() string {
+	switch e {
+	case EmptyEnvEmpty:
+		return "empty"
+	case EmptyEnvUnset:
+		return "unset"
+	default:
+		return fmt.Sprintf("EmptyEnv(%d)", int(e))
+	}
+}
+
+// skipEmpty returns a lookup that reports variables set to "" as unset
+func skipEmpty(lookup lookupEnvFunc) lookupEnvFunc {
+	return func(name string) (string, bool) {
+		value, exists := lookup(name)
+		if value == "" {
+			return "", false
+		}
+		return value, exists
+	}
+}
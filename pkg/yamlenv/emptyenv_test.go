@@ -0,0 +1,72 @@
+package yamlenv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const emptyEnvYAML = "app:\n  name: base-app\n  port: 8080\ndb:\n  host: db.local\n"
+
+// Test that by default an empty variable blanks the field
+func TestEmptyEnv_DefaultBlanksField(t *testing.T) {
+	t.Setenv("EMPTY_DB__HOST", "")
+
+	var cfg TestConfig
+	result, err := Load(LoaderOptions{BaseSource: StringSource(emptyEnvYAML), EnvPrefix: "EMPTY_", Delimiter: "__", Target: &cfg})
+
+	require.NoError(t, err)
+	assert.Equal(t, "", cfg.DB.Host)
+	assert.Equal(t, "env:EMPTY_DB__HOST", result.Sources["db.host"])
+}
+
+// Test that EmptyEnvUnset ignores empty variables but applies set ones
+func TestEmptyEnv_Unset(t *testing.T) {
+	t.Setenv("EMPTY_DB__HOST", "")
+	t.Setenv("EMPTY_APP__PORT", "")
+	t.Setenv("EMPTY_APP__NAME", "env-app")
+
+	var cfg TestConfig
+	result, err := Load(LoaderOptions{
+		BaseSource:    StringSource(emptyEnvYAML),
+		EnvPrefix:     "EMPTY_",
+		Delimiter:     "__",
+		EmptyEnvMeans: EmptyEnvUnset,
+		Target:        &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "db.local", cfg.DB.Host)
+	assert.Equal(t, 8080, cfg.App.Port, "an empty number is skipped instead of failing to parse")
+	assert.Equal(t, "env-app", cfg.App.Name)
+	assert.Equal(t, "base", result.Sources["db.host"])
+}
+
+// Test that empty variables don't allocate optional sections
+func TestEmptyEnv_UnsetKeepsNilPointers(t *testing.T) {
+	t.Setenv("EMPTY_REPLICA__HOST", "")
+	type config struct {
+		Replica *struct {
+			Host string `yaml:"host"`
+		} `yaml:"replica"`
+	}
+
+	var cfg config
+	err := LoadConfig(LoaderOptions{
+		BaseSource:    StringSource("{}"),
+		EnvPrefix:     "EMPTY_",
+		Delimiter:     "__",
+		EmptyEnvMeans: EmptyEnvUnset,
+		Target:        &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Nil(t, cfg.Replica)
+}
+
+func TestEmptyEnv_String(t *testing.T) {
+	assert.Equal(t, "empty", EmptyEnvEmpty.String())
+	assert.Equal(t, "unset", EmptyEnvUnset.String())
+	assert.Equal(t, "EmptyEnv(7)", EmptyEnv(7).String())
+}
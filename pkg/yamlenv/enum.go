@@ -0,0 +1,23 @@
+package yamlenv
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// checkEnum reports a string field tagged `enum:"..."` whose value isn't one
+// of the allowed ones. Empty values are left to other checks, so optional
+// enum fields can stay unset.
+func checkEnum(field reflect.Value, info fieldInfo, c *constraintCheck) {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return
+		}
+		field = field.Elem()
+	}
+	if field.Kind() != reflect.String || field.String() == "" || slices.Contains(info.Enum, field.String()) {
+		return
+	}
+	c.fail("%s: invalid value %q%s; allowed: %s", c.label(), field.String(), c.from(c.path), strings.Join(info.Enum, ", "))
+}
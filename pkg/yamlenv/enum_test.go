@@ -0,0 +1,80 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// EnumTestConfig has enum-tagged fields of several shapes
+type EnumTestConfig struct {
+	Log struct {
+		Level  string  `yaml:"level" enum:"debug,info,warn,error"`
+		Format *string `yaml:"format" enum:"json, text"`
+	} `yaml:"log"`
+	Mode EnumMode `yaml:"mode" enum:"fast,safe"`
+}
+
+// EnumMode is a named string type
+type EnumMode string
+
+// Test that values from the allowed set, and unset values, pass
+func TestLoad_EnumValid(t *testing.T) {
+	var cfg EnumTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("log:\n  level: warn\n  format: text\n")),
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "warn", cfg.Log.Level)
+	assert.Equal(t, EnumMode(""), cfg.Mode)
+}
+
+// Test that every invalid value is reported with its source and the allowed set
+func TestLoad_EnumInvalid(t *testing.T) {
+	setEnvVar(t, "ENUM_MODE", "quick")
+
+	var cfg EnumTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("log:\n  level: inof\n  format: xml\n")),
+		EnvPrefix:  "ENUM_",
+		Delimiter:  "__",
+		Target:     &cfg,
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, `invalid config: log.level: invalid value "inof" (from base); allowed: debug, info, warn, error
+log.format: invalid value "xml" (from base); allowed: json, text
+mode: invalid value "quick" (from env:ENUM_MODE); allowed: fast, safe`, err.Error())
+}
+
+// Test that enum fields are checked with full paths below a section
+func TestLoadSection_Enum(t *testing.T) {
+	var log struct {
+		Level string `yaml:"level" enum:"debug,info"`
+	}
+	err := LoadSection(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("log:\n  level: trace\n")),
+	}, "log", &log)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `log.level: invalid value "trace" (from base); allowed: debug, info`)
+}
+
+// Test that a runtime override with an invalid value is rejected
+func TestLoader_EnumOverride(t *testing.T) {
+	var cfg EnumTestConfig
+	loader, err := NewLoader(LoaderOptions{
+		BaseSource: FileSource(createTempYAML(t, "log:\n  level: info\n")),
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+
+	_, err = loader.ApplyOverride("log.level", "verbose", 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `log.level: invalid value "verbose" (from override)`)
+	assert.Equal(t, "info", cfg.Log.Level)
+}
@@ -0,0 +1,77 @@
+package yamlenv
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that EnvIgnoreCase matches variables regardless of case
+func TestLoadConfig_EnvIgnoreCase(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("environment names are always case-insensitive on Windows")
+	}
+	setEnvVar(t, "ciApp_app__Name", "mixed")
+	setEnvVar(t, "ciapp_db__port", "6543")
+
+	load := func(ignoreCase bool) TestConfig {
+		var cfg TestConfig
+		require.NoError(t, LoadConfig(LoaderOptions{
+			BaseSource:    ReaderSource(strings.NewReader("app:\n  name: base\ndb:\n  port: 5432\n")),
+			EnvPrefix:     "CIAPP_",
+			Delimiter:     "__",
+			EnvIgnoreCase: ignoreCase,
+			Target:        &cfg,
+		}))
+		return cfg
+	}
+
+	cfg := load(true)
+	assert.Equal(t, "mixed", cfg.App.Name)
+	assert.Equal(t, 6543, cfg.DB.Port)
+
+	cfg = load(false)
+	assert.Equal(t, "base", cfg.App.Name)
+	assert.Equal(t, 5432, cfg.DB.Port)
+}
+
+// Test that an exact-case match wins over case-folded ones
+func TestNewLookupEnv_PrefersExactMatch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("environment names are always case-insensitive on Windows")
+	}
+	setEnvVar(t, "CIEXACT_NAME", "exact")
+	setEnvVar(t, "ciexact_name", "folded")
+	setEnvVar(t, "ciexact_other", "other")
+
+	lookup := newLookupEnv(true)
+
+	value, ok := lookup("CIEXACT_NAME")
+	assert.True(t, ok)
+	assert.Equal(t, "exact", value)
+
+	value, ok = lookup("CIEXACT_OTHER")
+	assert.True(t, ok)
+	assert.Equal(t, "other", value)
+
+	_, ok = lookup("CIEXACT_MISSING")
+	assert.False(t, ok)
+}
+
+// Test that Values honor EnvIgnoreCase
+func TestLoadValues_EnvIgnoreCase(t *testing.T) {
+	setEnvVar(t, "civalues_app__name", "lower")
+
+	values, err := LoadValues(LoaderOptions{
+		BaseSource:    ReaderSource(strings.NewReader("app:\n  name: base\n")),
+		EnvPrefix:     "CIVALUES_",
+		Delimiter:     "__",
+		EnvIgnoreCase: true,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "lower", values.String("app.name"))
+}
@@ -0,0 +1,61 @@
+package yamlenv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// EnvError reports an environment variable whose value can't be parsed
+// into the field it overrides. Loads return it wrapped; use errors.As to
+// inspect it.
+type EnvError struct {
+	Path  string // dot-separated path of the field
+	Desc  string // the field's `desc:"..."` tag, if any
+	Var   string // name of the variable
+	Value string // the variable's value; "***" for secret fields
+	Type  string // Go type of the field, e.g. "int" or "time.Duration"
+	Err   error  // why the value was rejected
+
+	secret string // value redacted from Err's message
+}
+
+// newEnvError describes a failure to set the field at path, of type t, from
+// the variable name
+func newEnvError(path, name, value string, t reflect.Type, info fieldInfo, err error) *EnvError {
+	e := &EnvError{Path: path, Desc: info.Desc, Var: name, Value: value, Err: err}
+	if t != nil {
+		e.Type = t.String()
+	}
+	if info.Secret && value != "" {
+		e.Value, e.secret = "***", value
+	}
+	return e
+}
+
+// Error formats the error as
+// `set field db.port from APP_DB__PORT="eighty" (int): parse int "eighty": ...`
+func (e *EnvError) Error() string {
+	cause := e.Err.Error()
+	if e.secret != "" {
+		cause = strings.ReplaceAll(cause, e.secret, "***")
+	}
+	return fmt.Sprintf("set field %s from %s=%q (%s): %s", describe(e.Path, e.Desc), e.Var, e.Value, e.Type, cause)
+}
+
+// Unwrap returns the underlying parse error
+func (e *EnvError) Unwrap() error {
+	return e.Err
+}
+
+// badEnv returns the error for a value that couldn't be set, or logs it and
+// returns nil for fields tagged `envinvalid:"warn"`, which keep the value
+// they had before the override
+func (b *envBinder) badEnv(err *EnvError, info fieldInfo) error {
+	if !info.WarnBadEnv {
+		return err
+	}
+	delete(b.applied, err.Path)
+	b.logger.Printf("[yamlenv] warning: ignoring invalid override: %v", err)
+	return nil
+}
@@ -0,0 +1,100 @@
+package yamlenv
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type EnvErrorConfig struct {
+	Port     int           `yaml:"port"`
+	Timeout  time.Duration `yaml:"timeout" envinvalid:"warn"`
+	Hosts    []string      `yaml:"hosts" envinvalid:"warn"`
+	Password Secret[int]   `yaml:"password"`
+}
+
+// Test that a bad override is reported with its variable, value, type and path
+func TestLoad_EnvError(t *testing.T) {
+	t.Setenv("EE_PORT", "eighty")
+
+	var cfg EnvErrorConfig
+	err := LoadConfig(LoaderOptions{BaseSource: StringSource("port: 80\n"), EnvPrefix: "EE_", Delimiter: "__", Target: &cfg})
+
+	var envErr *EnvError
+	require.ErrorAs(t, err, &envErr)
+	assert.Equal(t, "port", envErr.Path)
+	assert.Equal(t, "EE_PORT", envErr.Var)
+	assert.Equal(t, "eighty", envErr.Value)
+	assert.Equal(t, "int", envErr.Type)
+	assert.ErrorIs(t, err, strconv.ErrSyntax)
+	assert.Equal(t, `apply env overrides: set field port from EE_PORT="eighty" (int): parse int "eighty": strconv.ParseInt: parsing "eighty": invalid syntax`, err.Error())
+}
+
+// Test that values of secret fields are redacted
+func TestLoad_EnvErrorSecret(t *testing.T) {
+	t.Setenv("EE_PASSWORD", "hunter2")
+
+	var cfg EnvErrorConfig
+	err := LoadConfig(LoaderOptions{BaseSource: StringSource("{}"), EnvPrefix: "EE_", Delimiter: "__", Target: &cfg})
+
+	var envErr *EnvError
+	require.ErrorAs(t, err, &envErr)
+	assert.Equal(t, "***", envErr.Value)
+	assert.NotContains(t, err.Error(), "hunter2")
+	assert.Contains(t, err.Error(), `set field password from EE_PASSWORD="***" (yamlenv.Secret[int])`)
+}
+
+// Test that fields tagged envinvalid:"warn" log a bad override and keep
+// the value from the files
+func TestLoad_EnvErrorWarn(t *testing.T) {
+	t.Setenv("EE_TIMEOUT", "5 minutes")
+	t.Setenv("EE_HOSTS", `["a",`)
+
+	logger := &recordingLogger{}
+	var cfg EnvErrorConfig
+	result, err := Load(LoaderOptions{
+		BaseSource: StringSource("timeout: 1s\nhosts: [x]\n"),
+		EnvPrefix:  "EE_",
+		Delimiter:  "__",
+		EnvJSON:    true,
+		Logger:     logger,
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, cfg.Timeout)
+	assert.Equal(t, []string{"x"}, cfg.Hosts)
+	assert.Equal(t, "base", result.Sources["timeout"])
+	require.Len(t, logger.lines, 2)
+	assert.Contains(t, logger.lines[0], `[yamlenv] warning: ignoring invalid override: set field timeout from EE_TIMEOUT="5 minutes" (time.Duration)`)
+	assert.Contains(t, logger.lines[1], `set field hosts from EE_HOSTS="[\"a\"," ([]string)`)
+}
+
+// Test that generated binding warns and reports errors like reflection
+func TestBindEnv_EnvErrorWarn(t *testing.T) {
+	t.Setenv("GEN_WORKERS", "many")
+	load := func(target any) ([]string, error) {
+		logger := &recordingLogger{}
+		err := LoadConfig(LoaderOptions{BaseSource: StringSource(genYAML), EnvPrefix: "GEN_", Delimiter: "__", Logger: logger, Target: target})
+		return logger.lines, err
+	}
+
+	var generated GenConfig
+	lines, err := load(&generated)
+	require.NoError(t, err)
+	assert.Equal(t, 2, generated.Workers)
+	reflected, _ := load(&genReflect{})
+	assert.Equal(t, reflected, lines)
+	assert.Contains(t, lines[0], `set field workers from GEN_WORKERS="many" (int)`)
+
+	t.Setenv("GEN_WORKERS", "4")
+	t.Setenv("GEN_DB__PORT", "high")
+	_, err = load(&GenConfig{})
+	var envErr *EnvError
+	require.True(t, errors.As(err, &envErr))
+	assert.Equal(t, EnvError{Path: "db.port", Desc: "database port", Var: "GEN_DB__PORT", Value: "high", Type: "uint16", Err: envErr.Err}, *envErr)
+}
@@ -0,0 +1,93 @@
+package yamlenv
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// WriteEnvExample writes a .env.example listing every environment variable
+// the loader honors for Target, one commented entry per field with its YAML
+// path, type and default. Defaults come from Target's current values and
+// SetDefaults, with the YAML layers applied on top when BaseSource is set;
+// environment variables are not read, and secret fields never show a default.
+//
+//	f, _ := os.Create(".env.example")
+//	err := yamlenv.WriteEnvExample(f, opts)
+func WriteEnvExample(w io.Writer, opts LoaderOptions) error {
+	if err := validateExampleOptions(opts); err != nil {
+		return err
+	}
+	defaults := deepCopy(reflect.ValueOf(opts.Target))
+	if opts.BaseSource != nil {
+		layers, err := loadLayers(opts)
+		if err != nil {
+			return err
+		}
+		opts.Target = defaults.Interface()
+		if _, _, err := decodeLayers(opts, layers); err != nil {
+			return err
+		}
+	} else {
+		applyDefaults(defaults, nil)
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# Environment variables read by the config loader. Each one overrides the")
+	fmt.Fprintln(bw, "# YAML key in its comment. Generated by yamlenv; do not edit.")
+	for _, v := range newEnvBinder(opts).vars(defaults, opts.Section) {
+		fmt.Fprintln(bw)
+		if v.Desc != "" {
+			fmt.Fprintf(bw, "# %s\n", v.Desc)
+		}
+		fmt.Fprintf(bw, "# %s (%s)", v.Path, envTypeName(v.Field.Type))
+		if def, ok := exampleDefault(v); ok {
+			fmt.Fprintf(bw, ", default: %s", def)
+		}
+		fmt.Fprintln(bw)
+		if len(v.Aliases) > 0 {
+			fmt.Fprintf(bw, "# also read from: %s\n", strings.Join(v.Aliases, ", "))
+		}
+		fmt.Fprintf(bw, "%s=\n", v.Name)
+	}
+	return bw.Flush()
+}
+
+// validateExampleOptions checks the options a generator needs: a struct
+// Target and, with a prefix, a delimiter. BaseSource is optional.
+func validateExampleOptions(opts LoaderOptions) error {
+	if opts.BaseSource == nil {
+		opts.BaseSource = ReaderSource(strings.NewReader(""))
+	}
+	return validateOptions(opts)
+}
+
+// envTypeName names a field type for humans: "duration" instead of
+// "time.Duration" and the wrapped type for generic wrappers like Option
+func envTypeName(t reflect.Type) string {
+	switch {
+	case t == durationType:
+		return "duration"
+	case t.Kind() == reflect.Interface:
+		return "any"
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map:
+		return t.String() + " as JSON"
+	}
+	name := t.String()
+	if i := strings.Index(name, "["); i >= 0 && t.Kind() == reflect.Struct {
+		// yamlenv.Option[int] -> int
+		return strings.TrimSuffix(name[i+1:], "]")
+	}
+	return name
+}
+
+// exampleDefault formats the default value of v, if it has a non-zero one
+// that isn't secret
+func exampleDefault(v envVar) (string, bool) {
+	if v.Secret {
+		return "", false
+	}
+	return envValueString(v.Value)
+}
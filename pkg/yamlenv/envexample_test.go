@@ -0,0 +1,102 @@
+package yamlenv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// EnvExampleConfig covers the field kinds listed in a .env.example
+type EnvExampleConfig struct {
+	App struct {
+		Name    string        `yaml:"name"`
+		Port    int           `yaml:"port"`
+		Timeout time.Duration `yaml:"timeout"`
+	} `yaml:"app"`
+	DB struct {
+		Password string `yaml:"password" secret:"true"`
+	} `yaml:"db"`
+	Retries Option[int] `yaml:"retries"`
+	Tags    []string    `yaml:"tags"`
+	Cache   *struct {
+		TTL time.Duration `yaml:"ttl"`
+	} `yaml:"cache"`
+}
+
+// Test that every honored variable is listed with type and YAML defaults
+func TestWriteEnvExample(t *testing.T) {
+	setEnvVar(t, "EX_APP__NAME", "must-not-leak")
+
+	cfg := EnvExampleConfig{}
+	cfg.App.Timeout = 5 * time.Second
+	var out bytes.Buffer
+	err := WriteEnvExample(&out, LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("app:\n  name: svc\n  port: 8080\ndb:\n  password: hunter2\nretries: 3\n")),
+		EnvPrefix:  "EX_",
+		Delimiter:  "__",
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `# Environment variables read by the config loader. Each one overrides the
+# YAML key in its comment. Generated by yamlenv; do not edit.
+
+# app.name (string), default: svc
+EX_APP__NAME=
+
+# app.port (int), default: 8080
+EX_APP__PORT=
+
+# app.timeout (duration), default: 5s
+EX_APP__TIMEOUT=
+
+# db.password (string)
+EX_DB__PASSWORD=
+
+# retries (int), default: 3
+EX_RETRIES=
+
+# cache.ttl (duration)
+EX_CACHE__TTL=
+`, out.String())
+	assert.Empty(t, cfg.App.Name, "Target must not be modified")
+}
+
+// Test that JSON-settable fields, sections and legacy tag names are included
+func TestWriteEnvExample_Options(t *testing.T) {
+	type Config struct {
+		Hosts []string `yaml:"hosts" env:"HOSTS"`
+		Level string   `yaml:"level"`
+	}
+
+	var out bytes.Buffer
+	err := WriteEnvExample(&out, LoaderOptions{
+		BaseSource:   ReaderSource(strings.NewReader("svc:\n  hosts: [a, b]\n")),
+		EnvPrefix:    "EX_",
+		Delimiter:    "__",
+		Section:      "svc",
+		EnvJSON:      true,
+		EnvTagCompat: true,
+		Target:       &Config{},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, out.String(), "# svc.hosts ([]string as JSON), default: [\"a\",\"b\"]\n# also read from: HOSTS\nEX_SVC__HOSTS=\n")
+	assert.Contains(t, out.String(), "# svc.level (string)\nEX_SVC__LEVEL=\n")
+}
+
+// Test that a Target alone is enough and invalid options are rejected
+func TestWriteEnvExample_NoBaseSource(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, WriteEnvExample(&out, LoaderOptions{EnvPrefix: "EX_", Delimiter: "__", Target: &EnvExampleConfig{}}))
+	assert.Contains(t, out.String(), "EX_APP__NAME=\n")
+	assert.NotContains(t, out.String(), "EX_TAGS=")
+
+	err := WriteEnvExample(&out, LoaderOptions{EnvPrefix: "EX_", Target: &EnvExampleConfig{}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "delimiter cannot be empty")
+}
@@ -0,0 +1,80 @@
+package yamlenv
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ToEnviron converts a config into "NAME=value" assignments, named by the
+// same rules loading uses, so loading them back with the same prefix and
+// delimiter gives the same config: app.port becomes PREFIX_APP__PORT=8080
+// with delim "__". List elements and map entries get one variable each
+// (PREFIX_HOSTS__0), nil values and empty maps and lists none. The result
+// is sorted and ready for exec.Cmd.Env.
+//
+// Dashes in keys become underscores, since they aren't valid in variable
+// names: app-name becomes PREFIX_APP_NAME, which a loader with NormalizeDash
+// (or DashToUnderscore key translation) reads back.
+//
+// Keys of map fields are written as loading reads them: a delimiter or "."
+// inside a key is doubled, so routes["a__b"].url becomes
+// PREFIX_ROUTES__A____B__URL. Loading adds new keys in lower case and reads
+// a doubled delimiter as the delimiter, so keys with "." or upper case
+// letters come back as written only if the loaded YAML already has them.
+//
+// Secret values are included, since the process reading them needs them.
+//
+//	cmd := exec.Command("./legacy-worker")
+//	cmd.Env = append(os.Environ(), yamlenv.ToEnviron(&cfg, "APP_", "__")...)
+func ToEnviron(cfg any, prefix, delim string) []string {
+	var environ []string
+	t := reflect.TypeOf(cfg)
+	walkLeaves(convertPlain(reflect.ValueOf(cfg), false, true), nil, func(path []string, value string, empty bool) {
+		if empty || len(path) == 0 {
+			return
+		}
+		environ = append(environ, environName(t, prefix, delim, path)+"="+value)
+	})
+	sort.Strings(environ)
+	return environ
+}
+
+// environName names the variable for path below a value of type t, segment
+// by segment: field names and list indexes as envVarName does, and keys of
+// map fields with the binder's key encoding. A map at the top stands in for
+// a config struct, and values below an interface have no known type, so
+// their keys are named like fields.
+func environName(t reflect.Type, prefix, delim string, path []string) string {
+	sep := delim
+	if sep == "" {
+		sep = "."
+	}
+	keys := &envBinder{normalizeDash: true}
+	segments := make([]string, len(path))
+	for i, key := range path {
+		segments[i] = strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch {
+		case t == nil:
+		case t.Kind() == reflect.Struct:
+			if info, ok := structField(t, key); ok {
+				t = t.Field(info.Index).Type
+			} else {
+				t = nil
+			}
+		case t.Kind() == reflect.Map:
+			if i > 0 {
+				segments[i] = keys.encodeKey(key, sep)
+			}
+			t = t.Elem()
+		case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+			t = t.Elem()
+		default:
+			t = nil
+		}
+	}
+	return prefix + strings.Join(segments, sep)
+}
@@ -0,0 +1,119 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that ToEnviron names variables the way loading reads them
+func TestToEnviron(t *testing.T) {
+	var cfg DiffTestConfig
+	cfg.App.Name, cfg.App.Timeout = "svc", 5*time.Second
+	cfg.DB.Password = "hunter2"
+	cfg.Hosts = []string{"a", "b"}
+	cfg.Labels = map[string]string{"team": "core"}
+
+	environ := ToEnviron(&cfg, "EXPORT_", "__")
+
+	// retries is an unset Option and backup a nil pointer: no variables
+	assert.Equal(t, []string{
+		"EXPORT_APP__NAME=svc",
+		"EXPORT_APP__TIMEOUT=5s",
+		"EXPORT_DB__HOST=",
+		"EXPORT_DB__PASSWORD=hunter2",
+		"EXPORT_HOSTS__0=a",
+		"EXPORT_HOSTS__1=b",
+		"EXPORT_LABELS__TEAM=core",
+	}, environ)
+}
+
+// Test that loading the assignments back gives the same config
+func TestToEnviron_RoundTrip(t *testing.T) {
+	var cfg DiffTestConfig
+	cfg.App.Name, cfg.App.Timeout = "svc", 5*time.Second
+	cfg.DB.Host, cfg.DB.Password = "db.internal", "hunter2"
+	cfg.Hosts = []string{"a", "b"}
+	cfg.Retries = Some(3)
+
+	for _, assignment := range ToEnviron(&cfg, "EXPORT_", "__") {
+		name, value, _ := strings.Cut(assignment, "=")
+		setEnvVar(t, name, value)
+	}
+	var loaded DiffTestConfig
+	require.NoError(t, LoadConfig(LoaderOptions{
+		BaseSource: StringSource("hosts: [x, y]\n"),
+		EnvPrefix:  "EXPORT_",
+		Delimiter:  "__",
+		Target:     &loaded,
+	}))
+	assert.Equal(t, cfg, loaded)
+}
+
+// Test that kebab-case keys give valid names that NormalizeDash reads back
+func TestToEnviron_KebabCase(t *testing.T) {
+	type kebabConfig struct {
+		App struct {
+			AppName string `yaml:"app-name"`
+		} `yaml:"app"`
+	}
+	var cfg kebabConfig
+	cfg.App.AppName = "svc"
+
+	environ := ToEnviron(&cfg, "KEBAB_", "__")
+	assert.Equal(t, []string{"KEBAB_APP__APP_NAME=svc"}, environ)
+
+	name, value, _ := strings.Cut(environ[0], "=")
+	setEnvVar(t, name, value)
+	var loaded kebabConfig
+	require.NoError(t, LoadConfig(LoaderOptions{
+		BaseSource:    StringSource("app:\n  app-name: other\n"),
+		EnvPrefix:     "KEBAB_",
+		Delimiter:     "__",
+		NormalizeDash: true,
+		Target:        &loaded,
+	}))
+	assert.Equal(t, cfg, loaded)
+}
+
+// Test that map keys holding the delimiter or "." are escaped, so loading
+// the assignments back gives the same maps
+func TestToEnviron_MapKeysRoundTrip(t *testing.T) {
+	type route struct {
+		URL string `yaml:"url"`
+	}
+	type routesConfig struct {
+		Routes map[string]route          `yaml:"routes"`
+		Tiers  map[string]map[string]int `yaml:"tiers"`
+	}
+	cfg := routesConfig{
+		Routes: map[string]route{"eu.west": {URL: "https://eu"}, "a__b": {URL: "https://ab"}, "primary": {URL: "https://p"}},
+		Tiers:  map[string]map[string]int{"gold": {"max__conns": 10}},
+	}
+
+	environ := ToEnviron(&cfg, "MAPKEYS_", "__")
+	assert.Equal(t, []string{
+		"MAPKEYS_ROUTES__A____B__URL=https://ab",
+		"MAPKEYS_ROUTES__EU____WEST__URL=https://eu",
+		"MAPKEYS_ROUTES__PRIMARY__URL=https://p",
+		"MAPKEYS_TIERS__GOLD__MAX____CONNS=10",
+	}, environ)
+
+	for _, assignment := range environ {
+		name, value, _ := strings.Cut(assignment, "=")
+		setEnvVar(t, name, value)
+	}
+	// New keys are added as decoded; "eu.west" needs the YAML to name it,
+	// since a doubled delimiter also stands for "."
+	var loaded routesConfig
+	require.NoError(t, LoadConfig(LoaderOptions{
+		BaseSource: StringSource("routes:\n  eu.west: {}\n"),
+		EnvPrefix:  "MAPKEYS_",
+		Delimiter:  "__",
+		Target:     &loaded,
+	}))
+	assert.Equal(t, cfg, loaded)
+}
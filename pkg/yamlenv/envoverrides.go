@@ -0,0 +1,91 @@
+package yamlenv
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EnvOverride is an environment variable that a load applied to the config
+type EnvOverride struct {
+	Path  string // field path, e.g. "db.host"
+	Var   string // variable name, e.g. "APP_DB__HOST"
+	Value string // the variable's value; "***" for secret fields
+}
+
+// String formats the override for an audit log, e.g.
+// "db.host = db.internal (from APP_DB__HOST)"
+func (o EnvOverride) String() string {
+	return fmt.Sprintf("%s = %s (from %s)", o.Path, o.Value, o.Var)
+}
+
+// envOverrides lists the overrides the binder applied to target that no
+// higher layer replaced, sorted by path, with the values of secret fields
+// and of fields below them redacted
+func envOverrides(b *envBinder, sources map[string]string, target any, section string) []EnvOverride {
+	if len(b.applied) == 0 {
+		return nil
+	}
+	plain := plainValue(reflect.ValueOf(target), false)
+	overrides := make([]EnvOverride, 0, len(b.applied))
+	for path, name := range b.applied {
+		if sources[path] != "env:"+name {
+			continue
+		}
+		value, _ := b.lookupEnv(name)
+		relative := path
+		if section != "" {
+			relative = strings.TrimPrefix(strings.TrimPrefix(path, section), ".")
+		}
+		if isRedacted(plain, relative) {
+			value = redacted
+		}
+		overrides = append(overrides, EnvOverride{Path: path, Var: name, Value: value})
+	}
+	sort.Slice(overrides, func(i, j int) bool { return overrides[i].Path < overrides[j].Path })
+	return overrides
+}
+
+// isRedacted reports whether plainValue redacted the value at path, a value
+// above it or, as for a DSN with a password, one below it
+func isRedacted(plain any, path string) bool {
+	for _, key := range strings.Split(path, ".") {
+		if plain == redacted {
+			return true
+		}
+		switch v := plain.(type) {
+		case map[string]any:
+			plain = v[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return false
+			}
+			plain = v[i]
+		default:
+			return false
+		}
+	}
+	return containsRedacted(plain)
+}
+
+// containsRedacted reports whether a plainValue result has a redacted value
+func containsRedacted(plain any) bool {
+	switch v := plain.(type) {
+	case map[string]any:
+		for _, inner := range v {
+			if containsRedacted(inner) {
+				return true
+			}
+		}
+	case []any:
+		for _, inner := range v {
+			if containsRedacted(inner) {
+				return true
+			}
+		}
+	}
+	return plain == redacted
+}
@@ -0,0 +1,71 @@
+package yamlenv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that LoadResult lists the applied env overrides with secrets redacted
+func TestLoad_EnvOverrides(t *testing.T) {
+	setEnvVar(t, "AUDIT_APP__NAME", "from-env")
+	setEnvVar(t, "AUDIT_DB__PASSWORD", "hunter2")
+	setEnvVar(t, "AUDIT_TOKENS__API", "t0k3n")
+	setEnvVar(t, "AUDIT_HOSTS__1", "b2")
+
+	var cfg DiffTestConfig
+	result, err := Load(LoaderOptions{
+		BaseSource: StringSource("app:\n  name: svc\nhosts: [a, b]\ntokens:\n  api: x\n"),
+		EnvPrefix:  "AUDIT_",
+		Delimiter:  "__",
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []EnvOverride{
+		{Path: "app.name", Var: "AUDIT_APP__NAME", Value: "from-env"},
+		{Path: "db.password", Var: "AUDIT_DB__PASSWORD", Value: "***"},
+		{Path: "hosts.1", Var: "AUDIT_HOSTS__1", Value: "b2"},
+		{Path: "tokens.api", Var: "AUDIT_TOKENS__API", Value: "***"},
+	}, result.EnvOverrides)
+	assert.Equal(t, "app.name = from-env (from AUDIT_APP__NAME)", result.EnvOverrides[0].String())
+}
+
+// Test that overrides replaced by a layer above the environment are not listed
+func TestLoad_EnvOverridesBelowLocal(t *testing.T) {
+	setEnvVar(t, "AUDIT_APP__NAME", "from-env")
+
+	var cfg DiffTestConfig
+	result, err := Load(LoaderOptions{
+		BaseSource:  StringSource("app:\n  name: svc\n"),
+		LocalSource: StringSource("app:\n  name: local\n"),
+		EnvPrefix:   "AUDIT_",
+		Delimiter:   "__",
+		Precedence:  []LayerKind{LayerBase, LayerEnv, LayerLocal},
+		Target:      &cfg,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "local", cfg.App.Name)
+	assert.Empty(t, result.EnvOverrides)
+}
+
+// Test that paths keep the section while secrets are found within it
+func TestLoad_EnvOverridesSection(t *testing.T) {
+	setEnvVar(t, "AUDIT_SVC__DB__PASSWORD", "hunter2")
+	setEnvVar(t, "AUDIT_SVC__DB__HOST", "db.internal")
+
+	var cfg DiffTestConfig
+	result, err := Load(LoaderOptions{
+		BaseSource: StringSource("svc:\n  app:\n    name: svc\n"),
+		EnvPrefix:  "AUDIT_",
+		Delimiter:  "__",
+		Section:    "svc",
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []EnvOverride{
+		{Path: "svc.db.host", Var: "AUDIT_SVC__DB__HOST", Value: "db.internal"},
+		{Path: "svc.db.password", Var: "AUDIT_SVC__DB__PASSWORD", Value: "***"},
+	}, result.EnvOverrides)
+}
@@ -0,0 +1,86 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type LegacyTagConfig struct {
+	DB struct {
+		Host     string `yaml:"host" env:"LEGACY_DATABASE_HOST"`
+		Port     int    `yaml:"port" envconfig:"DATABASE_PORT"`
+		Password string `yaml:"password" env:"LEGACY_DB_PASSWORD,required"`
+		Name     string `yaml:"name"`
+	} `yaml:"db"`
+}
+
+const legacyBaseYAML = `
+db:
+  host: localhost
+  port: 5432
+  password: base
+  name: app
+`
+
+// Test that env and envconfig tags are honored in compatibility mode
+func TestLoadConfig_EnvTagCompat(t *testing.T) {
+	setEnvVar(t, "LEGACY_DATABASE_HOST", "legacy-host")
+	setEnvVar(t, "COMPAT_DATABASE_PORT", "6543")
+	setEnvVar(t, "LEGACY_DB_PASSWORD", "s3cret")
+	setEnvVar(t, "COMPAT_DB__NAME", "derived")
+
+	var cfg LegacyTagConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:   ReaderSource(strings.NewReader(legacyBaseYAML)),
+		EnvPrefix:    "COMPAT_",
+		Delimiter:    "__",
+		EnvTagCompat: true,
+		Target:       &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "legacy-host", cfg.DB.Host)
+	assert.Equal(t, 6543, cfg.DB.Port)
+	assert.Equal(t, "s3cret", cfg.DB.Password)
+	// Fields without legacy tags keep the derived name
+	assert.Equal(t, "derived", cfg.DB.Name)
+}
+
+// Test that tagged variables win over the derived name, which stays as a fallback
+func TestLoadConfig_EnvTagCompatPrecedence(t *testing.T) {
+	setEnvVar(t, "LEGACY_DATABASE_HOST", "legacy-host")
+	setEnvVar(t, "COMPATP_DB__HOST", "derived-host")
+	setEnvVar(t, "COMPATP_DB__PORT", "7000")
+
+	var cfg LegacyTagConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:   ReaderSource(strings.NewReader(legacyBaseYAML)),
+		EnvPrefix:    "COMPATP_",
+		Delimiter:    "__",
+		EnvTagCompat: true,
+		Target:       &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "legacy-host", cfg.DB.Host)
+	assert.Equal(t, 7000, cfg.DB.Port)
+}
+
+// Test that legacy tags are ignored unless compatibility mode is enabled
+func TestLoadConfig_EnvTagsIgnoredByDefault(t *testing.T) {
+	setEnvVar(t, "LEGACY_DATABASE_HOST", "legacy-host")
+
+	var cfg LegacyTagConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader(legacyBaseYAML)),
+		EnvPrefix:  "NOCOMPAT_",
+		Delimiter:  "__",
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", cfg.DB.Host)
+}
@@ -0,0 +1,153 @@
+package yamlenv
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// EnvVar describes an environment variable the loader honors
+type EnvVar struct {
+	Name    string   // variable name, with prefix and delimiter applied
+	Aliases []string // legacy env/envconfig tag names also honored (EnvTagCompat)
+	Path    string   // dot-separated YAML path of the field it overrides
+	Type    string   // field type, e.g. "string", "duration", "[]string as JSON"
+	Secret  bool     // true if the field or one of its parents is tagged secret
+	Value   string   // Target's current value, formatted as the variable would be set; "" if zero
+	Desc    string   // description from the field's desc tag
+}
+
+// EnvVars lists the environment variables the loader honors for Target, in
+// field order, with Target's current values. Sources and the environment
+// are not read.
+func EnvVars(opts LoaderOptions) ([]EnvVar, error) {
+	if err := validateExampleOptions(opts); err != nil {
+		return nil, err
+	}
+	vars := newEnvBinder(opts).vars(reflect.ValueOf(opts.Target), opts.Section)
+	out := make([]EnvVar, len(vars))
+	for i, v := range vars {
+		value, _ := envValueString(v.Value)
+		out[i] = EnvVar{Name: v.Name, Aliases: v.Aliases, Path: v.Path, Type: envTypeName(v.Field.Type), Secret: v.Secret, Value: value, Desc: v.Desc}
+	}
+	return out, nil
+}
+
+// envVar describes one environment variable the loader honors
+type envVar struct {
+	Name    string              // variable name, with prefix and delimiter applied
+	Aliases []string            // legacy env/envconfig tag names also honored (EnvTagCompat)
+	Path    string              // dot-separated field path
+	Field   reflect.StructField // the struct field it sets
+	Value   reflect.Value       // the field's current value; invalid below nil pointers
+	Secret  bool                // true if the field or one of its parents is tagged secret
+	Desc    string              // description from the field's desc tag
+}
+
+// vars lists the variables that can override fields of the struct val
+// points to, in field order. Fields only settable as JSON are listed when
+// EnvJSON is on; nil struct pointers are walked through their type, and a
+// type already being walked is not entered again.
+func (b *envBinder) vars(val reflect.Value, path string) []envVar {
+	var out []envVar
+	b.collectVars(val.Type(), val, path, false, 0, map[reflect.Type]bool{}, &out)
+	return out
+}
+
+func (b *envBinder) collectVars(t reflect.Type, val reflect.Value, path string, secret bool, depth int, visiting map[reflect.Type]bool, out *[]envVar) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		if val.IsValid() {
+			if val.IsNil() {
+				val = reflect.Value{}
+			} else {
+				val = val.Elem()
+			}
+		}
+	}
+	if t.Kind() != reflect.Struct || visiting[t] || depth >= b.maxDepth {
+		return
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	for _, info := range cachedFields(t) {
+		field := t.Field(info.Index)
+		var fieldVal reflect.Value
+		if val.IsValid() {
+			fieldVal = val.Field(info.Index)
+		}
+
+		fieldPath := info.Name
+		if info.Inline {
+			fieldPath = path
+		} else if path != "" {
+			fieldPath = path + "." + fieldPath
+		}
+
+		switch {
+		case info.Nested || info.NestedPtr:
+			b.collectVars(field.Type, fieldVal, fieldPath, secret || info.Secret, depth+1, visiting, out)
+		case !b.settable(field.Type):
+			continue
+		default:
+			v := envVar{Name: b.varName(fieldPath), Path: fieldPath, Field: field, Value: fieldVal, Secret: secret || info.Secret, Desc: info.Desc}
+			if b.tagCompat {
+				if info.EnvTag != "" {
+					v.Aliases = append(v.Aliases, info.EnvTag)
+				}
+				if info.EnvconfigTag != "" {
+					v.Aliases = append(v.Aliases, b.prefix+info.EnvconfigTag)
+				}
+			}
+			*out = append(*out, v)
+		}
+	}
+}
+
+// settable reports whether an env value can be parsed into a field of type t
+func (b *envBinder) settable(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(envUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Interface:
+		return t.NumMethod() == 0
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return b.json
+	}
+	return false
+}
+
+// envValueString formats a field value as an environment variable would
+// set it: scalars as text and composite values as JSON. It reports false for
+// zero values.
+func envValueString(v reflect.Value) (string, bool) {
+	if !v.IsValid() || v.IsZero() {
+		return "", false
+	}
+	if h, ok := v.Interface().(secretHolder); ok {
+		// EnvVar values are real values; EnvVar.Secret marks them
+		if b, ok := h.secretValue().([]byte); ok {
+			return string(b), true
+		}
+		return envValueString(reflect.ValueOf(h.secretValue()))
+	}
+	plain := plainValue(v, false)
+	if plain == nil {
+		return "", false
+	}
+	switch p := plain.(type) {
+	case string:
+		return p, true
+	case map[string]any, []any:
+		data, err := json.Marshal(p)
+		return string(data), err == nil
+	}
+	return fmt.Sprint(plain), true
+}
@@ -0,0 +1,27 @@
+package yamlenv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that EnvVars lists names, types and current values, including secrets
+func TestEnvVars(t *testing.T) {
+	cfg := EnvExampleConfig{}
+	cfg.App.Port = 8080
+	cfg.App.Timeout = time.Minute
+	cfg.DB.Password = "hunter2"
+
+	vars, err := EnvVars(LoaderOptions{EnvPrefix: "EV_", Delimiter: "__", EnvJSON: true, Target: &cfg})
+	require.NoError(t, err)
+
+	require.Len(t, vars, 7)
+	assert.Equal(t, EnvVar{Name: "EV_APP__NAME", Path: "app.name", Type: "string"}, vars[0])
+	assert.Equal(t, EnvVar{Name: "EV_APP__PORT", Path: "app.port", Type: "int", Value: "8080"}, vars[1])
+	assert.Equal(t, EnvVar{Name: "EV_APP__TIMEOUT", Path: "app.timeout", Type: "duration", Value: "1m0s"}, vars[2])
+	assert.Equal(t, EnvVar{Name: "EV_DB__PASSWORD", Path: "db.password", Type: "string", Secret: true, Value: "hunter2"}, vars[3])
+	assert.Equal(t, EnvVar{Name: "EV_TAGS", Path: "tags", Type: "[]string as JSON"}, vars[5])
+}
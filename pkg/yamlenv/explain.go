@@ -0,0 +1,186 @@
+package yamlenv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// LayerValue is the value one layer gave a key
+type LayerValue struct {
+	Layer   string // layer name: "defaults", "base", "local", "secrets", "runtime"
+	Label   string // label of the layer's Named source, if any
+	Value   any    // decoded value; redacted for secrets
+	Removed bool   // true if the layer deleted the key with null or !unset
+}
+
+// Explanation describes how a single key was resolved
+type Explanation struct {
+	Path     string       // the key explained
+	Layers   []LayerValue // every layer that mentions the key, in merge order
+	EnvVar   string       // environment variable consulted for the key; "" if none can set it
+	EnvSet   bool         // true if EnvVar is set
+	EnvValue string       // value of EnvVar; redacted for secrets
+	Source   string       // winner, as in LoadResult.Sources; "" if nothing set the key
+	Value    any          // effective value; redacted for secrets
+	Found    bool         // true if the key exists in the effective config
+	Secret   bool         // true if the key is secret
+}
+
+// Explain loads the configuration like Plan, without touching Target, and
+// explains the key at path: the value each layer provided, the environment
+// variable consulted and which one won. Without a Target, keys are resolved
+// like LoadValues, and keys named like credentials are treated as secrets.
+func Explain(opts LoaderOptions, path string) (*Explanation, error) {
+	if opts.Target == nil {
+		return explainValues(opts, path)
+	}
+	if err := validateOptions(opts); err != nil {
+		return nil, err
+	}
+	layers, err := loadLayers(opts)
+	if err != nil {
+		return nil, err
+	}
+	cfg := deepCopy(reflect.ValueOf(opts.Target))
+	opts.Target = cfg.Interface()
+	result, err := bindLayers(opts, layers)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Explanation{Path: path, Source: result.Sources[path]}
+	binder := newEnvBinder(opts)
+	names := []string{}
+	for _, v := range binder.vars(cfg, opts.Section) {
+		if v.Path == path {
+			// Legacy tag names take precedence, as in envBinder.lookup
+			names = append(append(names, v.Aliases...), v.Name)
+			e.Secret = v.Secret
+		}
+	}
+	e.explainEnv(binder, names)
+
+	rel := strings.TrimPrefix(strings.TrimPrefix(path, opts.Section), ".")
+	if opts.Section == "" || path == opts.Section || strings.HasPrefix(path, opts.Section+".") {
+		if v, ok := lookupField(cfg, rel); ok {
+			e.Value, e.Found = plainValue(v, e.Secret), true
+		}
+	}
+	e.explainLayers(layers)
+	return e, nil
+}
+
+// explainValues explains a key without a Target, resolving env overrides
+// like LoadValues
+func explainValues(opts LoaderOptions, path string) (*Explanation, error) {
+	if opts.EnvPrefix != "" && opts.Delimiter == "" {
+		return nil, fmt.Errorf("delimiter cannot be empty when EnvPrefix is provided - use a non-empty delimiter like '__' for proper environment variable mapping")
+	}
+	if opts.BaseSource == nil {
+		return nil, fmt.Errorf("BaseSource cannot be nil")
+	}
+	layers, err := loadLayers(opts)
+	if err != nil {
+		return nil, err
+	}
+	root := cloneNode(newMerger(opts).mergeLayers(layers))
+	binder := newEnvBinder(opts)
+	binder.applyToNode(root, "")
+
+	segments := strings.Split(path, ".")
+	e := &Explanation{Path: path, Source: layerSources(layers, "")[path], Secret: isSensitiveKey(segments[len(segments)-1])}
+	e.explainEnv(binder, []string{binder.varName(path)})
+	if envName, ok := binder.applied[path]; ok {
+		e.Source = "env:" + envName
+	}
+	if n := lookupPath(root, path); n != nil {
+		var value any
+		if err := n.Decode(&value); err == nil {
+			e.Value, e.Found = value, true
+		}
+		if e.Secret {
+			e.Value = redacted
+		}
+	}
+	e.explainLayers(layers)
+	return e, nil
+}
+
+// explainEnv records the first of names that is set, or the last one (the
+// derived name) if none is
+func (e *Explanation) explainEnv(b *envBinder, names []string) {
+	for _, name := range names {
+		e.EnvVar = name
+		if value, exists := b.lookupEnv(name); exists {
+			e.EnvSet, e.EnvValue = true, value
+			if e.Secret {
+				e.EnvValue = redacted
+			}
+			return
+		}
+	}
+}
+
+// explainLayers records the value each layer gives the key
+func (e *Explanation) explainLayers(layers []configLayer) {
+	for _, layer := range layers {
+		n := resolveAlias(lookupPath(layer.node, e.Path))
+		if n == nil {
+			continue
+		}
+		lv := LayerValue{Layer: layer.name, Label: layer.label}
+		switch {
+		case isUnsetNode(n):
+			lv.Removed = true
+		case e.Secret:
+			lv.Value = redacted
+		default:
+			_ = n.Decode(&lv.Value)
+		}
+		e.Layers = append(e.Layers, lv)
+	}
+}
+
+// String formats the explanation for humans:
+//
+//	db.host = db.internal (from env:APP_DB__HOST)
+//	  base:  localhost
+//	  local: db.local
+//	  env:   APP_DB__HOST=db.internal
+func (e *Explanation) String() string {
+	var b strings.Builder
+	switch {
+	case e.Found:
+		fmt.Fprintf(&b, "%s = %v", e.Path, e.Value)
+	case e.Source != "":
+		fmt.Fprintf(&b, "%s has no config field", e.Path)
+	default:
+		fmt.Fprintf(&b, "%s is not set", e.Path)
+	}
+	if e.Source != "" {
+		fmt.Fprintf(&b, " (from %s)", e.Source)
+	}
+	b.WriteString("\n")
+
+	width := len("env")
+	for _, lv := range e.Layers {
+		width = max(width, len(layerTitle(lv.Layer, lv.Label)))
+	}
+	for _, lv := range e.Layers {
+		value := fmt.Sprint(lv.Value)
+		if lv.Removed {
+			value = "(removed)"
+		}
+		fmt.Fprintf(&b, "  %-*s %s\n", width+1, layerTitle(lv.Layer, lv.Label)+":", value)
+	}
+	switch {
+	case e.EnvVar == "":
+		fmt.Fprintf(&b, "  %-*s (no variable maps to this key)\n", width+1, "env:")
+	case e.EnvSet:
+		fmt.Fprintf(&b, "  %-*s %s=%s\n", width+1, "env:", e.EnvVar, e.EnvValue)
+	default:
+		fmt.Fprintf(&b, "  %-*s %s (not set)\n", width+1, "env:", e.EnvVar)
+	}
+	return b.String()
+}
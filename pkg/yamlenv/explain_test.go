@@ -0,0 +1,97 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that every layer's value, the env var and the winner are reported
+func TestExplain(t *testing.T) {
+	setEnvVar(t, "EXP_DB__HOST", "db.internal")
+
+	var cfg TestConfig
+	e, err := Explain(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader("db:\n  host: localhost\n  port: 5432\n")),
+		LocalSource: ReaderSource(strings.NewReader("db:\n  host: db.local\n")),
+		EnvPrefix:   "EXP_",
+		Delimiter:   "__",
+		Target:      &cfg,
+	}, "db.host")
+	require.NoError(t, err)
+
+	assert.Equal(t, &Explanation{
+		Path:     "db.host",
+		Layers:   []LayerValue{{Layer: "base", Value: "localhost"}, {Layer: "local", Value: "db.local"}},
+		EnvVar:   "EXP_DB__HOST",
+		EnvSet:   true,
+		EnvValue: "db.internal",
+		Source:   "env:EXP_DB__HOST",
+		Value:    "db.internal",
+		Found:    true,
+	}, e)
+	assert.Equal(t, "db.host = db.internal (from env:EXP_DB__HOST)\n  base:  localhost\n  local: db.local\n  env:   EXP_DB__HOST=db.internal\n", e.String())
+	assert.Empty(t, cfg.DB.Host)
+}
+
+// Test that unset variables, removed keys and secrets are explained
+func TestExplain_RemovedAndSecret(t *testing.T) {
+	var cfg PlanTestConfig
+	opts := func() LoaderOptions {
+		return LoaderOptions{
+			BaseSource:  ReaderSource(strings.NewReader("app:\n  name: svc\ndb:\n  password: hunter2\n")),
+			LocalSource: ReaderSource(strings.NewReader("app:\n  name: null\n")),
+			EnvPrefix:   "EXP_",
+			Delimiter:   "__",
+			Target:      &cfg,
+		}
+	}
+
+	e, err := Explain(opts(), "app.name")
+	require.NoError(t, err)
+	assert.Equal(t, []LayerValue{{Layer: "base", Value: "svc"}, {Layer: "local", Removed: true}}, e.Layers)
+	assert.Equal(t, "", e.Source)
+	assert.Equal(t, "app.name = \n  base:  svc\n  local: (removed)\n  env:   EXP_APP__NAME (not set)\n", e.String())
+
+	e, err = Explain(opts(), "db.password")
+	require.NoError(t, err)
+	assert.True(t, e.Secret)
+	assert.Equal(t, "***", e.Value)
+	assert.Equal(t, []LayerValue{{Layer: "base", Value: "***"}}, e.Layers)
+}
+
+// Test that keys are explained without a Target, like LoadValues
+func TestExplain_NoTarget(t *testing.T) {
+	setEnvVar(t, "EXP_PLUGINS__S3__REGION", "eu-west-1")
+
+	e, err := Explain(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("plugins:\n  s3:\n    region: us-east-1\n    token: abc\n")),
+		EnvPrefix:  "EXP_",
+		Delimiter:  "__",
+	}, "plugins.s3.region")
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", e.Value)
+	assert.Equal(t, "env:EXP_PLUGINS__S3__REGION", e.Source)
+
+	e, err = Explain(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("plugins:\n  s3:\n    token: abc\n")),
+	}, "plugins.s3.token")
+	require.NoError(t, err)
+	assert.Equal(t, "***", e.Value)
+	assert.Equal(t, "base", e.Source)
+}
+
+// Test that keys without a field report that no variable maps to them
+func TestExplain_UnknownKey(t *testing.T) {
+	var cfg TestConfig
+	e, err := Explain(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("extra: 1\n")),
+		Target:     &cfg,
+	}, "extra")
+	require.NoError(t, err)
+
+	assert.False(t, e.Found)
+	assert.Equal(t, "extra has no config field (from base)\n  base: 1\n  env:  (no variable maps to this key)\n", e.String())
+}
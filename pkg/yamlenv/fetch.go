@@ -0,0 +1,81 @@
+package yamlenv
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fetchJob is one source to fetch and its outcome
+type fetchJob struct {
+	layer   string
+	source  ConfigSource
+	label   string // label of a Named source, once fetched
+	node    *yaml.Node
+	err     error
+	warning error // set when a Cached source served a stale copy
+}
+
+// fetchAll fetches the sources of jobs, at most opts.FetchConcurrency at a
+// time, and records each outcome in its job, so load time is that of the
+// slowest source rather than the sum. It returns an error only if opts.ctx
+// is done first; the jobs must not be used then, as fetches still running
+// write to them.
+func fetchAll(opts LoaderOptions, jobs []fetchJob) error {
+	ctx := opts.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	limit := opts.FetchConcurrency
+	if limit <= 0 || limit > len(jobs) {
+		limit = len(jobs)
+	}
+	if limit <= 1 {
+		for i := range jobs {
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("load config: %w", err)
+			}
+			fetchNode(opts, &jobs[i])
+		}
+		return nil
+	}
+
+	results := make([]fetchJob, len(jobs))
+	copy(results, jobs)
+	slots := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(job *fetchJob) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				job.err = ctx.Err()
+				return
+			}
+			defer func() { <-slots }()
+			fetchNode(opts, job)
+		}(&results[i])
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		copy(jobs, results)
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("load config: %w", ctx.Err())
+	}
+}
+
+// configLayer returns the layer of a fetched job holding node
+func (job *fetchJob) configLayer(node *yaml.Node) configLayer {
+	return configLayer{name: job.layer, label: job.label, node: node}
+}
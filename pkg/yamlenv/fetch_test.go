@@ -0,0 +1,90 @@
+package yamlenv
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowSource returns a source that takes d to open and tracks how many
+// sources are open at once
+func slowSource(yamlText string, d time.Duration, active, peak *int32) ConfigSource {
+	return func() (io.ReadCloser, error) {
+		n := atomic.AddInt32(active, 1)
+		for {
+			p := atomic.LoadInt32(peak)
+			if n <= p || atomic.CompareAndSwapInt32(peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(d)
+		atomic.AddInt32(active, -1)
+		return io.NopCloser(strings.NewReader(yamlText)), nil
+	}
+}
+
+// Test that sources are fetched concurrently, up to FetchConcurrency, and
+// merged in declared order
+func TestFetchConcurrency(t *testing.T) {
+	for _, tt := range []struct {
+		concurrency int
+		peak        int32
+	}{{0, 4}, {2, 2}, {1, 1}} {
+		var active, peak int32
+		var cfg TestConfig
+		result, err := Load(LoaderOptions{
+			BaseSource:       slowSource("app:\n  name: base\n  port: 1\n", 20*time.Millisecond, &active, &peak),
+			LocalSource:      slowSource("app:\n  port: 2\n", 20*time.Millisecond, &active, &peak),
+			SecretsSource:    slowSource("db:\n  host: secret\n", 20*time.Millisecond, &active, &peak),
+			Patches:          []ConfigSource{slowSource(`{"app": {"port": 3}}`, 20*time.Millisecond, &active, &peak)},
+			FetchConcurrency: tt.concurrency,
+			Target:           &cfg,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, tt.peak, peak, "concurrency %d", tt.concurrency)
+		assert.Equal(t, "base", cfg.App.Name)
+		assert.Equal(t, 3, cfg.App.Port)
+		assert.Equal(t, "secret", cfg.DB.Host)
+		assert.Equal(t, "patch:0", result.Sources["app.port"])
+	}
+}
+
+// Test that the first failing source in declared order is reported
+func TestFetchConcurrency_Errors(t *testing.T) {
+	failing := func(msg string) ConfigSource {
+		return func() (io.ReadCloser, error) { return nil, errors.New(msg) }
+	}
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:    StringSource("app:\n  name: x\n"),
+		LocalSource:   failing("local down"),
+		SecretsSource: failing("secrets down"),
+		Target:        &cfg,
+	})
+	assert.EqualError(t, err, "load local config: open config source: local down")
+}
+
+// Test that LoadContext stops waiting for slow sources when the context ends
+func TestLoadContext(t *testing.T) {
+	var active, peak int32
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	var cfg TestConfig
+	start := time.Now()
+	_, err := LoadContext(ctx, LoaderOptions{
+		BaseSource:  slowSource("app:\n  name: x\n", time.Second, &active, &peak),
+		LocalSource: StringSource("app:\n  port: 1\n"),
+		Target:      &cfg,
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.EqualError(t, err, "load config: context deadline exceeded")
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
@@ -0,0 +1,293 @@
+package yamlenv
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// fieldInfo holds the reflected metadata for a single struct field
+type fieldInfo struct {
+	Index     int    // position of the field in the struct
+	Name      string // path segment used for the field (yaml tag or lowercased name)
+	Nested    bool   // true if the field is a struct that should be walked recursively
+	NestedPtr bool   // true if the field is a pointer to such a struct
+	Inline    bool   // true if the field is tagged ",inline" and shares its parent's path
+	Secret    bool   // true if the field is tagged `secret:"true"` or is a Secret, and must be redacted in output
+
+	Deprecated string   // message from a `deprecated:"..."` tag, reported by Lint when the key is set
+	Enum       []string // allowed values from an `enum:"a,b,c"` tag, checked after load
+	Requires   []string // paths from a `requires:"..."` tag that must be set when this field is
+	Conflicts  []string // paths from a `conflicts:"..."` tag that must not be set when this field is
+
+	Aliases []string // other YAML keys from an `alias:"a,b"` tag that bind to the field
+	Desc    string   // human description from a `desc:"..."` tag, shown in errors and generated docs
+
+	EnvTag       string // variable name from an `env:"..."` tag (caarlos0/env)
+	EnvconfigTag string // variable name from an `envconfig:"..."` tag (kelseyhightower/envconfig)
+	WarnBadEnv   bool   // true if the field is tagged `envinvalid:"warn"`: an unparsable override is logged and ignored
+}
+
+// envUnmarshaler is implemented by types that parse their own environment
+// override values instead of being walked as nested structs
+type envUnmarshaler interface {
+	unmarshalEnv(value string) error
+}
+
+var envUnmarshalerType = reflect.TypeOf((*envUnmarshaler)(nil)).Elem()
+
+// fieldCache maps reflect.Type to []fieldInfo so repeated loads of the
+// same target type don't pay for the full reflection walk every time
+var fieldCache sync.Map
+
+// cachedFields returns the field metadata for a struct type, computing it on first use
+func cachedFields(t reflect.Type) []fieldInfo {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.([]fieldInfo)
+	}
+	fields := buildFields(t)
+	actual, _ := fieldCache.LoadOrStore(t, fields)
+	return actual.([]fieldInfo)
+}
+
+// buildFields reflects over a struct type and collects metadata for exported fields
+func buildFields(t reflect.Type) []fieldInfo {
+	fields := make([]fieldInfo, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// Skip unexported fields
+		if !field.IsExported() {
+			continue
+		}
+
+		// Get yaml tag or use field name
+		yamlTag := field.Tag.Get("yaml")
+		if yamlTag == "-" {
+			continue
+		}
+		// Remove options like ",omitempty"
+		inline := false
+		if idx := strings.Index(yamlTag, ","); idx >= 0 {
+			inline = strings.Contains(yamlTag[idx:], ",inline")
+			yamlTag = yamlTag[:idx]
+		}
+
+		fields = append(fields, fieldInfo{
+			Index:        i,
+			Name:         getStructPath(field, yamlTag),
+			Nested:       isNestedStruct(field.Type),
+			NestedPtr:    field.Type.Kind() == reflect.Ptr && isNestedStruct(field.Type.Elem()),
+			Inline:       inline,
+			Secret:       field.Tag.Get("secret") == "true" || field.Type.Implements(secretHolderType),
+			Deprecated:   field.Tag.Get("deprecated"),
+			Enum:         tagList(field.Tag.Get("enum")),
+			Requires:     tagList(field.Tag.Get("requires")),
+			Conflicts:    tagList(field.Tag.Get("conflicts")),
+			Aliases:      tagList(field.Tag.Get("alias")),
+			Desc:         field.Tag.Get("desc"),
+			EnvTag:       tagName(field.Tag.Get("env")),
+			EnvconfigTag: tagName(field.Tag.Get("envconfig")),
+			WarnBadEnv:   field.Tag.Get("envinvalid") == "warn",
+		})
+	}
+	return fields
+}
+
+// isNestedStruct reports whether env overrides walk into values of type t
+// instead of parsing them as a single value
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t == yamlNodeType || t == locationType {
+		return false
+	}
+	ptr := reflect.PointerTo(t)
+	return !ptr.Implements(envUnmarshalerType) || ptr.Implements(envCompositeType)
+}
+
+// describe labels a path with the field's description for messages:
+// "db.pool_size (maximum connections in the pool)"
+func describe(path, desc string) string {
+	if desc == "" {
+		return path
+	}
+	return path + " (" + desc + ")"
+}
+
+// tagName returns the name part of a struct tag value, dropping options like ",required"
+func tagName(tag string) string {
+	if idx := strings.Index(tag, ","); idx >= 0 {
+		tag = tag[:idx]
+	}
+	if tag == "-" {
+		return ""
+	}
+	return tag
+}
+
+// lookupField walks a dot-separated YAML path through structs, maps, slices
+// and pointers below v
+func lookupField(v reflect.Value, path string) (reflect.Value, bool) {
+	if path == "" {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		return v, v.IsValid()
+	}
+	for _, key := range strings.Split(path, ".") {
+		next, ok := childField(v, key)
+		if !ok {
+			return reflect.Value{}, false
+		}
+		v = next
+	}
+	return lookupField(v, "")
+}
+
+// childField returns the child of v addressed by a single path segment
+func childField(v reflect.Value, key string) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, info := range cachedFields(v.Type()) {
+			field := v.Field(info.Index)
+			if info.Inline {
+				if inner, ok := childField(field, key); ok {
+					return inner, true
+				}
+				continue
+			}
+			if info.Name == key {
+				return field, true
+			}
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, false
+		}
+		elem := v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+		return elem, elem.IsValid()
+	case reflect.Slice, reflect.Array:
+		idx, err := strconv.Atoi(key)
+		if err != nil || idx < 0 || idx >= v.Len() {
+			return reflect.Value{}, false
+		}
+		return v.Index(idx), true
+	}
+	return reflect.Value{}, false
+}
+
+// deepCopy returns a pointer to a deep copy of the value v points to.
+// Maps, slices and pointers are duplicated so the copy shares no mutable
+// state with the original; unexported fields are copied shallowly. Pointers
+// and maps reached more than once, including through cycles, are copied
+// once, so the copy has the same shape.
+func deepCopy(v reflect.Value) reflect.Value {
+	return copier{}.pointer(v)
+}
+
+// copyValue returns a deep copy of v, as deepCopy does
+func copyValue(v reflect.Value) reflect.Value {
+	return copier{}.value(v)
+}
+
+// copyKey identifies a pointer or map already copied. The type is part of
+// the key, as a struct and its first field share an address.
+type copyKey struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+// copier maps the pointers and maps of one deep copy to their copies
+type copier map[copyKey]reflect.Value
+
+// pointer copies the non-nil pointer v
+func (c copier) pointer(v reflect.Value) reflect.Value {
+	key := copyKey{v.Pointer(), v.Type()}
+	if out, ok := c[key]; ok {
+		return out
+	}
+	out := reflect.New(v.Type().Elem())
+	// Recorded before copying what v points to, so cycles end here
+	c[key] = out
+	out.Elem().Set(c.value(v.Elem()))
+	return out
+}
+
+func (c copier) value(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		return c.pointer(v)
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if out.Field(i).CanSet() {
+				out.Field(i).Set(c.value(v.Field(i)))
+			}
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		key := copyKey{v.Pointer(), v.Type()}
+		if out, ok := c[key]; ok {
+			return out
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		c[key] = out
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), c.value(iter.Value()))
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(c.value(v.Index(i)))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(c.value(v.Index(i)))
+		}
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(c.value(v.Elem()))
+		return out
+	default:
+		return v
+	}
+}
+
+// tagList splits a comma-separated tag value like `enum:"a, b"` into its items
+func tagList(tag string) []string {
+	if tag == "" {
+		return nil
+	}
+	items := strings.Split(tag, ",")
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+	}
+	return items
+}
@@ -0,0 +1,79 @@
+package yamlenv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that field metadata honors yaml tags, skips and unexported fields
+func TestCachedFields_Metadata(t *testing.T) {
+	type Inner struct {
+		Value string `yaml:"value"`
+	}
+	type Sample struct {
+		Name     string `yaml:"name,omitempty"`
+		Ignored  string `yaml:"-"`
+		hidden   string
+		NoTag    int
+		Inner    Inner `yaml:"inner"`
+		Duration int   `yaml:"duration"`
+	}
+	_ = Sample{}.hidden
+
+	fields := cachedFields(reflect.TypeOf(Sample{}))
+	require.Len(t, fields, 4)
+
+	assert.Equal(t, fieldInfo{Index: 0, Name: "name"}, fields[0])
+	assert.Equal(t, fieldInfo{Index: 3, Name: "notag"}, fields[1])
+	assert.Equal(t, fieldInfo{Index: 4, Name: "inner", Nested: true}, fields[2])
+	assert.Equal(t, fieldInfo{Index: 5, Name: "duration"}, fields[3])
+}
+
+// Test that repeated lookups for the same type are served from the cache
+func TestCachedFields_ReusesCache(t *testing.T) {
+	typ := reflect.TypeOf(TestConfig{})
+
+	first := cachedFields(typ)
+	second := cachedFields(typ)
+
+	require.NotEmpty(t, first)
+	assert.Equal(t, reflect.ValueOf(first).Pointer(), reflect.ValueOf(second).Pointer())
+}
+
+// Test that repeated loads into the same type keep applying env overrides
+func TestLoadConfig_RepeatedLoadsWithCache(t *testing.T) {
+	setEnvVar(t, "CACHE_APP__NAME", "cached")
+
+	for i := 0; i < 3; i++ {
+		var cfg TestConfig
+		err := LoadConfig(LoaderOptions{
+			BaseSource: ReaderSource(strings.NewReader("app:\n  name: base\n  port: 8080\n")),
+			EnvPrefix:  "CACHE_",
+			Delimiter:  "__",
+			Target:     &cfg,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "cached", cfg.App.Name)
+		assert.Equal(t, 8080, cfg.App.Port)
+	}
+}
+
+// Benchmark env override application with warm field metadata
+func BenchmarkApplyEnvOverrides(b *testing.B) {
+	var cfg TestConfig
+	val := reflect.ValueOf(&cfg)
+	binder := newEnvBinder(LoaderOptions{EnvPrefix: "BENCH_", Delimiter: "__"})
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := binder.apply(val, ""); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
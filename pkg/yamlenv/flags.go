@@ -0,0 +1,91 @@
+package yamlenv
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FlagSet reads boolean feature flags from one section of a Loader's
+// config. Every call sees the latest reload, so flags can be flipped by
+// editing config.local.yaml, setting an environment variable or applying a
+// runtime override.
+//
+//	flags := yamlenv.Flags(loader, "features")
+//	if flags.Enabled("new_checkout") { ... }
+//
+// The section may be a struct of bool fields or a map such as
+// map[string]bool. Flags come from the loaded config only: an environment
+// variable such as MYAPP_FEATURES__NEW_CHECKOUT takes effect on the next
+// reload, like any other value.
+type FlagSet struct {
+	loader *Loader
+	path   string
+}
+
+// Flags returns a FlagSet for the section at path (dot-separated YAML keys)
+func Flags(loader *Loader, path string) *FlagSet {
+	return &FlagSet{loader: loader, path: path}
+}
+
+// Enabled reports whether the flag is set to true. Missing flags and values
+// that aren't booleans are disabled.
+func (f *FlagSet) Enabled(name string) bool {
+	v, ok := lookupField(f.loader.snapshot(), joinPath(f.path, name))
+	return ok && flagValue(v)
+}
+
+// All returns the state of every flag in the section
+func (f *FlagSet) All() map[string]bool {
+	out := map[string]bool{}
+	for _, name := range f.names() {
+		out[name] = f.Enabled(name)
+	}
+	return out
+}
+
+// names returns the sorted flag names in the section
+func (f *FlagSet) names() []string {
+	section, ok := lookupField(f.loader.snapshot(), f.path)
+	if !ok {
+		return nil
+	}
+	var names []string
+	switch section.Kind() {
+	case reflect.Struct:
+		for _, info := range cachedFields(section.Type()) {
+			if !info.Inline {
+				names = append(names, info.Name)
+			}
+		}
+	case reflect.Map:
+		for _, key := range section.MapKeys() {
+			names = append(names, key.String())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// flagValue interprets a config value as a flag
+func flagValue(v reflect.Value) bool {
+	v = indirectValue(v)
+	if !v.IsValid() {
+		return false
+	}
+	if v.Kind() == reflect.Struct && v.Type().Implements(yamlMarshalerType) {
+		// Option[bool] and similar wrappers
+		out, err := v.Interface().(yaml.Marshaler).MarshalYAML()
+		return err == nil && flagValue(reflect.ValueOf(out))
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.String:
+		enabled, _ := strconv.ParseBool(v.String())
+		return enabled
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package yamlenv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that flags in a struct section follow env, reloads and overrides
+func TestFlags_StructSection(t *testing.T) {
+	type FlagsConfig struct {
+		Features struct {
+			NewCheckout bool         `yaml:"new_checkout"`
+			DarkMode    bool         `yaml:"dark_mode"`
+			Beta        Option[bool] `yaml:"beta"`
+		} `yaml:"features"`
+	}
+	setEnvVar(t, "FLAGS_FEATURES__DARK_MODE", "true")
+	file := createTempYAML(t, "features:\n  new_checkout: false\n  beta: true\n")
+
+	var cfg FlagsConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(file), EnvPrefix: "FLAGS_", Delimiter: "__", Target: &cfg})
+	require.NoError(t, err)
+
+	flags := Flags(loader, "features")
+	assert.False(t, flags.Enabled("new_checkout"))
+	assert.True(t, flags.Enabled("dark_mode"))
+	assert.True(t, flags.Enabled("beta"))
+	assert.False(t, flags.Enabled("missing"))
+
+	writeFile(t, file, "features:\n  new_checkout: true\n")
+	require.NoError(t, loader.Reload())
+	assert.True(t, flags.Enabled("new_checkout"))
+	assert.False(t, flags.Enabled("beta"))
+
+	_, err = loader.ApplyOverride("features.dark_mode", false, 0)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"beta": false, "dark_mode": false, "new_checkout": true}, flags.All())
+}
+
+// Test that flags in a map section honor env overrides, including entries
+// only the environment sets, and read them as of the latest load
+func TestFlags_MapSection(t *testing.T) {
+	type FlagsConfig struct {
+		Features map[string]bool `yaml:"features"`
+	}
+	setEnvVar(t, "MAPFLAGS_FEATURES__SEARCH", "false")
+	setEnvVar(t, "MAPFLAGS_FEATURES__IMPORT", "true")
+
+	var cfg FlagsConfig
+	loader, err := NewLoader(LoaderOptions{
+		BaseSource: FileSource(createTempYAML(t, "features:\n  search: true\n  export: true\n")),
+		EnvPrefix:  "MAPFLAGS_",
+		Delimiter:  "__",
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+
+	flags := Flags(loader, "features")
+	assert.False(t, flags.Enabled("search"))
+	assert.True(t, flags.Enabled("export"))
+	assert.True(t, flags.Enabled("import"))
+	assert.False(t, flags.Enabled("other"))
+	assert.Equal(t, map[string]bool{"export": true, "import": true, "search": false}, flags.All())
+
+	setEnvVar(t, "MAPFLAGS_FEATURES__SEARCH", "true")
+	assert.False(t, flags.Enabled("search"), "env changes apply on reload")
+	require.NoError(t, loader.Reload())
+	assert.True(t, flags.Enabled("search"))
+}
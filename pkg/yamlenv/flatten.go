@@ -0,0 +1,68 @@
+package yamlenv
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Flatten turns a config into one entry per leaf value, keyed by the YAML
+// names of its path joined with delim ("." when empty), e.g.
+// "app.port" -> "8080". List elements are keyed by index, durations are
+// formatted like "5s", and nil values and empty maps and lists map to "".
+// Secret fields and everything below them are "***"; see FlattenRevealed.
+//
+//	for key, value := range yamlenv.Flatten(&cfg, ".") {
+//		fmt.Printf("%s=%s\n", key, value)
+//	}
+func Flatten(cfg any, delim string) map[string]string {
+	return flatten(convertPlain(reflect.ValueOf(cfg), false, false), delim)
+}
+
+// FlattenRevealed is Flatten with secret values included, including those
+// wrapped in Secret. Keep its output out of logs and status pages.
+func FlattenRevealed(cfg any, delim string) map[string]string {
+	return flatten(convertPlain(reflect.ValueOf(cfg), false, true), delim)
+}
+
+func flatten(plain any, delim string) map[string]string {
+	if delim == "" {
+		delim = "."
+	}
+	out := map[string]string{}
+	walkLeaves(plain, nil, func(path []string, value string, empty bool) {
+		if len(path) > 0 {
+			out[strings.Join(path, delim)] = value
+		}
+	})
+	return out
+}
+
+// walkLeaves calls fn with the path and formatted value of every leaf of a
+// plainValue result. nil values and empty maps and lists are leaves too,
+// reported as empty.
+func walkLeaves(v any, path []string, fn func(path []string, value string, empty bool)) {
+	switch v := v.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			fn(path, "", true)
+		}
+		for k, inner := range v {
+			walkLeaves(inner, append(path[:len(path):len(path)], k), fn)
+		}
+	case []any:
+		if len(v) == 0 {
+			fn(path, "", true)
+		}
+		for i, inner := range v {
+			walkLeaves(inner, append(path[:len(path):len(path)], strconv.Itoa(i)), fn)
+		}
+	case nil:
+		fn(path, "", true)
+	case []byte:
+		fn(path, string(v), false)
+	default:
+		fn(path, fmt.Sprint(v), false)
+	}
+}
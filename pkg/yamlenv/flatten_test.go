@@ -0,0 +1,35 @@
+package yamlenv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that leaves are keyed by their YAML path, with secrets redacted
+func TestFlatten(t *testing.T) {
+	var cfg DiffTestConfig
+	cfg.App.Name, cfg.App.Timeout = "svc", 5*time.Second
+	cfg.DB.Host, cfg.DB.Password = "db.internal", "hunter2"
+	cfg.Tokens = map[string]string{"api": "t0k3n"}
+	cfg.Hosts = []string{"a", "b"}
+	cfg.Retries = Some(3)
+
+	assert.Equal(t, map[string]string{
+		"app.name":    "svc",
+		"app.timeout": "5s",
+		"db.host":     "db.internal",
+		"db.password": "***",
+		"tokens":      "***",
+		"hosts.0":     "a",
+		"hosts.1":     "b",
+		"labels":      "",
+		"retries":     "3",
+		"backup":      "",
+	}, Flatten(&cfg, "."))
+
+	revealed := FlattenRevealed(cfg, "__")
+	assert.Equal(t, "hunter2", revealed["db__password"])
+	assert.Equal(t, "t0k3n", revealed["tokens__api"])
+}
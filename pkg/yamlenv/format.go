@@ -0,0 +1,173 @@
+package yamlenv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UnmarshalFunc decodes a document in some configuration format into v,
+// which is a *map[string]any. json.Unmarshal and most format libraries'
+// Unmarshal functions have this signature.
+type UnmarshalFunc func(data []byte, v any) error
+
+// FormatSource adapts a source in another format (HCL, TOML, ...) to a
+// ConfigSource: the data is decoded with unmarshal into a map and re-encoded
+// as YAML, so it layers, merges and binds exactly like a YAML source and
+// environment overrides work the same way. MaxSourceSize limits the data
+// read from source.
+//
+//	base := yamlenv.FormatSource(yamlenv.FileSource("config.hcl"), hcl.Unmarshal)
+//
+// hcl.Unmarshal is HCL1 (github.com/hashicorp/hcl). For HCL2, use the
+// separate github.com/tendant/yamlenv/pkg/yamlenvhcl module.
+func FormatSource(source ConfigSource, unmarshal UnmarshalFunc) ConfigSource {
+	return decodingSource(source, func(data []byte) ([]byte, error) {
+		values := map[string]any{}
+		if len(bytes.TrimSpace(data)) > 0 {
+			if err := unmarshal(data, &values); err != nil {
+				return nil, fmt.Errorf("decode config source: %w", err)
+			}
+		}
+		out, err := yaml.Marshal(values)
+		if err != nil {
+			return nil, fmt.Errorf("marshal config source: %w", err)
+		}
+		return out, nil
+	})
+}
+
+// decodingSource returns source converted to YAML by convert. The input is
+// read and converted when the loader prepares the reader, so MaxSourceSize
+// limits the input rather than only the YAML it turns into.
+func decodingSource(source ConfigSource, convert func([]byte) ([]byte, error)) ConfigSource {
+	return func() (io.ReadCloser, error) {
+		reader, err := source()
+		if err != nil {
+			return nil, err
+		}
+		return &decodingReader{source: reader, convert: convert}, nil
+	}
+}
+
+// decodingReader serves the YAML its source converts to
+type decodingReader struct {
+	source  io.ReadCloser
+	convert func([]byte) ([]byte, error)
+	out     *bytes.Reader
+}
+
+// decode reads and converts the source once, failing if it holds more than
+// maxSize bytes (0 for no limit)
+func (r *decodingReader) decode(maxSize int64) error {
+	if r.out != nil {
+		return nil
+	}
+	var in io.Reader = r.source
+	if maxSize > 0 {
+		in = &sizeLimitReader{r: r.source, limit: maxSize, remaining: maxSize}
+	}
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return fmt.Errorf("read config source: %w", err)
+	}
+	out, err := r.convert(data)
+	if err != nil {
+		return err
+	}
+	r.out = bytes.NewReader(out)
+	return nil
+}
+
+func (r *decodingReader) Read(p []byte) (int, error) {
+	if err := r.decode(0); err != nil {
+		return 0, err
+	}
+	return r.out.Read(p)
+}
+
+func (r *decodingReader) Close() error {
+	return r.source.Close()
+}
+
+// prepareSource decodes a reader from FormatSource and similar adapters,
+// also behind a Named label, with the MaxSourceSize limit on its input
+func prepareSource(reader io.ReadCloser, maxSize int64) error {
+	switch r := reader.(type) {
+	case *decodingReader:
+		return r.decode(maxSize)
+	case labeledReader:
+		return prepareSource(r.ReadCloser, maxSize)
+	}
+	return nil
+}
+
+// Format identifies the encoding of config data held in memory
+type Format int
+
+const (
+	FormatYAML       Format = iota // YAML
+	FormatJSON                     // JSON, parsed as the YAML subset it is
+	FormatProperties               // Java .properties, as PropertiesSource
+	FormatINI                      // INI, as INISource
+)
+
+// String returns the format's name
+func (f Format) String() string {
+	switch f {
+	case FormatYAML:
+		return "yaml"
+	case FormatJSON:
+		return "json"
+	case FormatProperties:
+		return "properties"
+	case FormatINI:
+		return "ini"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+// FormatFromExtension returns the format implied by a file name's
+// extension: .yaml/.yml, .json, .properties, or .ini/.cfg
+func FormatFromExtension(filename string) (Format, bool) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		return FormatYAML, true
+	case ".json":
+		return FormatJSON, true
+	case ".properties":
+		return FormatProperties, true
+	case ".ini", ".cfg":
+		return FormatINI, true
+	}
+	return 0, false
+}
+
+// BytesSource creates a ConfigSource from config data already in memory,
+// e.g. downloaded, decrypted or embedded, in the given format. data is
+// copied, and the source can be read any number of times.
+//
+//	base := yamlenv.BytesSource(decrypted, yamlenv.FormatJSON)
+func BytesSource(data []byte, format Format) ConfigSource {
+	data = bytes.Clone(data)
+	raw := func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	switch format {
+	case FormatYAML, FormatJSON:
+		return raw
+	case FormatProperties:
+		return PropertiesSource(raw)
+	case FormatINI:
+		return INISource(raw)
+	default:
+		return func() (io.ReadCloser, error) {
+			return nil, fmt.Errorf("unknown config format %v", format)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package yamlenv
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that a source in another format layers like YAML and honors env overrides
+func TestLoadConfig_FormatSource(t *testing.T) {
+	setEnvVar(t, "FMT_DB__PORT", "6543")
+
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:  FormatSource(ReaderSource(strings.NewReader(`{"app": {"name": "json", "port": 8080}, "db": {"port": 5432}}`)), json.Unmarshal),
+		LocalSource: ReaderSource(strings.NewReader("app:\n  port: 9090\n")),
+		EnvPrefix:   "FMT_",
+		Delimiter:   "__",
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "json", cfg.App.Name)
+	assert.Equal(t, 9090, cfg.App.Port)
+	assert.Equal(t, 6543, cfg.DB.Port)
+}
+
+// Test that decoder errors are attributed to the layer
+func TestLoadConfig_FormatSourceErrors(t *testing.T) {
+	failing := func([]byte, any) error { return errors.New("bad syntax") }
+
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader("app:\n  name: base\n")),
+		LocalSource: FormatSource(ReaderSource(strings.NewReader("x = 1")), failing),
+		Target:      &cfg,
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "load local config")
+	assert.Contains(t, err.Error(), "decode config source: bad syntax")
+}
+
+// Test that empty documents are treated like empty YAML
+func TestFormatSource_Empty(t *testing.T) {
+	node, err := loadNodeFromSource(FormatSource(ReaderSource(strings.NewReader("  \n")), json.Unmarshal), parseLimits{})
+
+	require.NoError(t, err)
+	require.NotNil(t, node)
+	assert.Empty(t, node.Content)
+}
+
+// Test that in-memory data is parsed according to its format
+func TestBytesSource(t *testing.T) {
+	tests := []struct {
+		format Format
+		data   string
+	}{
+		{FormatYAML, "app:\n  name: bytes\n  port: 8080\n"},
+		{FormatJSON, `{"app": {"name": "bytes", "port": 8080}}`},
+		{FormatProperties, "app.name=bytes\napp.port=8080\n"},
+		{FormatINI, "[app]\nname = bytes\nport = 8080\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			data := []byte(tt.data)
+			source := BytesSource(data, tt.format)
+			copy(data, "xxxx") // the source keeps its own copy
+
+			var cfg TestConfig
+			require.NoError(t, LoadConfig(LoaderOptions{BaseSource: source, Target: &cfg}))
+			assert.Equal(t, "bytes", cfg.App.Name)
+			assert.Equal(t, 8080, cfg.App.Port)
+		})
+	}
+
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{BaseSource: BytesSource(nil, Format(42)), Target: &cfg})
+	assert.EqualError(t, err, "load base config: open config source: unknown config format Format(42)")
+
+	format, ok := FormatFromExtension("conf/app.JSON")
+	assert.True(t, ok)
+	assert.Equal(t, FormatJSON, format)
+	_, ok = FormatFromExtension("app.toml")
+	assert.False(t, ok)
+}
+
+// Test that MaxSourceSize limits the input of a FormatSource, not only the
+// YAML it converts to, also behind Named and templates
+func TestFormatSource_MaxSourceSize(t *testing.T) {
+	padded := `{"app": {"name": "json"}` + strings.Repeat(" ", 1024) + "}"
+	for name, opts := range map[string]LoaderOptions{
+		"plain":    {BaseSource: FormatSource(StringSource(padded), json.Unmarshal)},
+		"named":    {BaseSource: Named("app.json", FormatSource(StringSource(padded), json.Unmarshal))},
+		"template": {BaseSource: FormatSource(StringSource(padded), json.Unmarshal), Template: true},
+	} {
+		var cfg TestConfig
+		opts.MaxSourceSize, opts.Target = 128, &cfg
+		err := LoadConfig(opts)
+		assert.ErrorContains(t, err, "read config source: config source exceeds maximum size of 128 bytes", name)
+	}
+
+	var cfg TestConfig
+	require.NoError(t, LoadConfig(LoaderOptions{
+		BaseSource:    FormatSource(StringSource(padded), json.Unmarshal),
+		MaxSourceSize: int64(len(padded)),
+		Target:        &cfg,
+	}))
+	assert.Equal(t, "json", cfg.App.Name)
+}
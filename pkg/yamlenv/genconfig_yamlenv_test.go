@@ -0,0 +1,115 @@
+// Code generated by yamlenv-gen; DO NOT EDIT.
+
+package yamlenv
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// BindEnv applies environment overrides to c without reflection
+func (c *GenConfig) BindEnv(env *Env) error {
+	if v, ok := env.Lookup("region"); ok {
+		c.GenShared.Region = v
+	}
+	if v, ok := env.Lookup("name"); ok {
+		c.Name = v
+	}
+	if v, ok := env.Lookup("mode"); ok {
+		c.Mode = GenMode(v)
+	}
+	if v, ok := env.Lookup("workers"); ok {
+		if parsed, err := env.ParseInt(v); err != nil {
+			if err := env.Error("workers", fmt.Errorf("parse int %q: %w", v, err)); err != nil {
+				return err
+			}
+		} else {
+			c.Workers = int(parsed)
+		}
+	}
+	if v, ok := env.Lookup("ratio"); ok {
+		if parsed, err := strconv.ParseFloat(v, 64); err != nil {
+			if err := env.Error("ratio", fmt.Errorf("parse float %q: %w", v, err)); err != nil {
+				return err
+			}
+		} else {
+			c.Ratio = float32(parsed)
+		}
+	}
+	if v, ok := env.Lookup("debug"); ok {
+		if parsed, err := strconv.ParseBool(v); err != nil {
+			if err := env.Error("debug", fmt.Errorf("parse bool %q: %w", v, err)); err != nil {
+				return err
+			}
+		} else {
+			c.Debug = parsed
+		}
+	}
+	if v, ok := env.Lookup("db.host"); ok {
+		c.DB.Host = v
+	}
+	if v, ok := env.Lookup("db.port"); ok {
+		if parsed, err := env.ParseUint(v); err != nil {
+			if err := env.Error("db.port", fmt.Errorf("parse uint %q: %w", v, err)); err != nil {
+				return err
+			}
+		} else {
+			c.DB.Port = uint16(parsed)
+		}
+	}
+	if v, ok := env.Lookup("db.timeout"); ok {
+		if parsed, err := time.ParseDuration(v); err != nil {
+			if err := env.Error("db.timeout", fmt.Errorf("parse duration %q: %w", v, err)); err != nil {
+				return err
+			}
+		} else {
+			c.DB.Timeout = parsed
+		}
+	}
+	if err := env.Field("replica", &c.Replica); err != nil {
+		return err
+	}
+	if err := env.Field("tags", &c.Tags); err != nil {
+		return err
+	}
+	if err := env.Field("password", &c.Password); err != nil {
+		return err
+	}
+	if v, ok := env.Lookup("limits.burst"); ok {
+		if parsed, err := env.ParseInt(v); err != nil {
+			if err := env.Error("limits.burst", fmt.Errorf("parse int %q: %w", v, err)); err != nil {
+				return err
+			}
+		} else {
+			c.Limits.Burst = int(parsed)
+		}
+	}
+	return nil
+}
+
+// BindEnv applies environment overrides to c without reflection
+func (c *GenDB) BindEnv(env *Env) error {
+	if v, ok := env.Lookup("host"); ok {
+		c.Host = v
+	}
+	if v, ok := env.Lookup("port"); ok {
+		if parsed, err := env.ParseUint(v); err != nil {
+			if err := env.Error("port", fmt.Errorf("parse uint %q: %w", v, err)); err != nil {
+				return err
+			}
+		} else {
+			c.Port = uint16(parsed)
+		}
+	}
+	if v, ok := env.Lookup("timeout"); ok {
+		if parsed, err := time.ParseDuration(v); err != nil {
+			if err := env.Error("timeout", fmt.Errorf("parse duration %q: %w", v, err)); err != nil {
+				return err
+			}
+		} else {
+			c.Timeout = parsed
+		}
+	}
+	return nil
+}
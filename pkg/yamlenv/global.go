@@ -0,0 +1,72 @@
+package yamlenv
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// global holds the process-wide config registered with SetGlobal
+var global struct {
+	mu     sync.RWMutex
+	value  reflect.Value // pointer to a private deep copy
+	frozen bool
+}
+
+// SetGlobal registers cfg (a struct or a pointer to one) as the process-wide
+// config returned by Global. A deep copy is stored, so later changes to cfg
+// are not visible through Global. It panics after FreezeGlobal.
+//
+// This is meant for legacy code that reads config through globals; new code
+// should pass the config or a Loader explicitly.
+func SetGlobal(cfg any) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Ptr {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		v = ptr
+	}
+	if v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("yamlenv: SetGlobal requires a struct or a non-nil pointer to one, got %T", cfg))
+	}
+	copied := deepCopy(v)
+
+	global.mu.Lock()
+	defer global.mu.Unlock()
+	if global.frozen {
+		panic("yamlenv: SetGlobal called after FreezeGlobal")
+	}
+	global.value = copied
+}
+
+// FreezeGlobal makes the current global config permanent; any later
+// SetGlobal call panics
+func FreezeGlobal() {
+	global.mu.Lock()
+	defer global.mu.Unlock()
+	global.frozen = true
+}
+
+// Global returns a copy of the config registered with SetGlobal, as T (the
+// struct type or a pointer to it). Each call returns an independent copy, so
+// callers may modify it without racing other readers. It returns the zero
+// value of T if nothing was registered and panics if T doesn't match the
+// registered type.
+func Global[T any]() T {
+	global.mu.RLock()
+	value := global.value
+	global.mu.RUnlock()
+
+	var zero T
+	if !value.IsValid() {
+		return zero
+	}
+	copied := deepCopy(value)
+	switch reflect.TypeOf((*T)(nil)).Elem() {
+	case value.Type():
+		return copied.Interface().(T)
+	case value.Type().Elem():
+		return copied.Elem().Interface().(T)
+	}
+	panic(fmt.Sprintf("yamlenv: Global[%T] does not match registered config type %s", zero, value.Type().Elem()))
+}
@@ -0,0 +1,74 @@
+package yamlenv
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// resetGlobal clears the global config for the duration of a test
+func resetGlobal(t *testing.T) {
+	t.Cleanup(func() {
+		global.mu.Lock()
+		defer global.mu.Unlock()
+		global.value, global.frozen = reflect.Value{}, false
+	})
+}
+
+// Test that Global returns independent copies of the registered config
+func TestGlobal_CopyOnRead(t *testing.T) {
+	resetGlobal(t)
+	assert.Nil(t, Global[*LoaderTestConfig]())
+
+	var cfg LoaderTestConfig
+	cfg.App.Name = "svc"
+	cfg.DB.Options = map[string]string{"ssl": "on"}
+	SetGlobal(&cfg)
+
+	cfg.App.Name = "changed"
+	got := Global[*LoaderTestConfig]()
+	assert.Equal(t, "svc", got.App.Name)
+
+	got.DB.Options["ssl"] = "off"
+	assert.Equal(t, "on", Global[LoaderTestConfig]().DB.Options["ssl"])
+
+	assert.Panics(t, func() { Global[TestConfig]() })
+}
+
+// Test that SetGlobal panics once the global config is frozen
+func TestGlobal_Freeze(t *testing.T) {
+	resetGlobal(t)
+
+	SetGlobal(LoaderTestConfig{})
+	FreezeGlobal()
+
+	assert.Panics(t, func() { SetGlobal(LoaderTestConfig{}) })
+	assert.Panics(t, func() { SetGlobal(42) })
+}
+
+// Test concurrent reads and writes of the global config
+func TestGlobal_Concurrent(t *testing.T) {
+	resetGlobal(t)
+	SetGlobal(&LoaderTestConfig{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if i%2 == 0 {
+					cfg := Global[*LoaderTestConfig]()
+					cfg.App.Port = j
+				} else {
+					var cfg LoaderTestConfig
+					cfg.App.Port = j
+					SetGlobal(&cfg)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,148 @@
+package yamlenv
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	yamlMarshalerType = reflect.TypeOf((*yaml.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	durationType      = reflect.TypeOf(time.Duration(0))
+)
+
+// debugResponse is the JSON document served by Handler
+type debugResponse struct {
+	Config     any               `json:"config"`
+	Checksum   string            `json:"checksum"`
+	Sources    map[string]string `json:"sources"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	UnusedKeys []string          `json:"unused_keys,omitempty"`
+}
+
+// Handler returns an http.Handler that serves the loader's current effective
+// configuration as JSON, keyed by YAML names, with fields tagged
+// `secret:"true"` replaced by "***". The response also has the config's
+// Checksum, where each value came from ("base", "local" or "env:VAR"), the
+// labels of Named sources and any unused YAML keys.
+//
+// It is meant for an internal debug endpoint:
+//
+//	mux.Handle("/debug/config", yamlenv.Handler(loader))
+func Handler(loader *Loader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		loader.mu.RLock()
+		resp := debugResponse{
+			Config:     plainValue(loader.current, false),
+			Checksum:   Checksum(loader.current.Interface()),
+			Sources:    loader.result.Sources,
+			Labels:     loader.result.Labels,
+			UnusedKeys: loader.result.UnusedKeys,
+		}
+		loader.mu.RUnlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(resp); err != nil {
+			http.Error(w, fmt.Sprintf("encode config: %v", err), http.StatusInternalServerError)
+		}
+	})
+}
+
+// plainValue converts a config value into maps, slices and scalars keyed by
+// YAML names, replacing secret values with "***"
+func plainValue(v reflect.Value, secret bool) any {
+	return convertPlain(v, secret, false)
+}
+
+// convertPlain is plainValue; with reveal, secret values are kept, including
+// those wrapped in Secret
+func convertPlain(v reflect.Value, secret, reveal bool) any {
+	v = indirectValue(v)
+	if !v.IsValid() {
+		return nil
+	}
+	if secret && !reveal {
+		return redacted
+	}
+
+	t := v.Type()
+	switch {
+	case t == durationType:
+		return v.Interface().(time.Duration).String()
+	case t == locationType:
+		if v.CanAddr() {
+			return v.Addr().Interface().(*time.Location).String()
+		}
+		loc := v.Interface().(time.Location)
+		return loc.String()
+	case t == yamlNodeType:
+		var out any
+		node := v.Interface().(yaml.Node)
+		if err := node.Decode(&out); err != nil {
+			return nil
+		}
+		return out
+	case reveal && t.Implements(secretHolderType):
+		return convertPlain(reflect.ValueOf(v.Interface().(secretHolder).secretValue()), false, true)
+	case t.Implements(yamlMarshalerType):
+		out, err := v.Interface().(yaml.Marshaler).MarshalYAML()
+		if err != nil {
+			return nil
+		}
+		return convertPlain(reflect.ValueOf(out), false, reveal)
+	case t.Implements(textMarshalerType):
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil
+		}
+		return string(text)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		out := map[string]any{}
+		for _, info := range cachedFields(t) {
+			value := convertPlain(v.Field(info.Index), info.Secret, reveal)
+			if inline, ok := value.(map[string]any); ok && info.Inline {
+				for key, inner := range inline {
+					out[key] = inner
+				}
+				continue
+			}
+			out[info.Name] = value
+		}
+		return out
+	case reflect.Map:
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = convertPlain(iter.Value(), false, reveal)
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return v.Interface()
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = convertPlain(v.Index(i), false, reveal)
+		}
+		return out
+	default:
+		return v.Interface()
+	}
+}
@@ -0,0 +1,72 @@
+package yamlenv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the debug handler serves the effective config with secrets redacted
+func TestHandler_ServesRedactedConfig(t *testing.T) {
+	setEnvVar(t, "DEBUG_APP__NAME", "from-env")
+
+	var cfg DiffTestConfig
+	loader, err := NewLoader(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader("app:\n  name: svc\n  timeout: 5s\ndb:\n  host: db.internal\n  password: hunter2\ntokens:\n  ci: abc\nhosts: [a, b]\nretries: 3\nextra: 1\n")),
+		LocalSource: ReaderSource(strings.NewReader("db:\n  host: localhost\n")),
+		EnvPrefix:   "DEBUG_",
+		Delimiter:   "__",
+		Target:      &cfg,
+	})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	Handler(loader).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/config", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.NotContains(t, rec.Body.String(), "hunter2")
+	assert.NotContains(t, rec.Body.String(), "abc")
+
+	var resp struct {
+		Config     map[string]any    `json:"config"`
+		Checksum   string            `json:"checksum"`
+		Sources    map[string]string `json:"sources"`
+		UnusedKeys []string          `json:"unused_keys"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+
+	app := resp.Config["app"].(map[string]any)
+	assert.Equal(t, "from-env", app["name"])
+	assert.Equal(t, "5s", app["timeout"])
+	db := resp.Config["db"].(map[string]any)
+	assert.Equal(t, "localhost", db["host"])
+	assert.Equal(t, "***", db["password"])
+	assert.Equal(t, "***", resp.Config["tokens"])
+	assert.Equal(t, []any{"a", "b"}, resp.Config["hosts"])
+	assert.Equal(t, float64(3), resp.Config["retries"])
+	assert.Nil(t, resp.Config["backup"])
+	assert.Equal(t, loader.Checksum(), resp.Checksum)
+
+	assert.Equal(t, "env:DEBUG_APP__NAME", resp.Sources["app.name"])
+	assert.Equal(t, "local", resp.Sources["db.host"])
+	assert.Equal(t, "base", resp.Sources["db.password"])
+	assert.Equal(t, []string{"extra"}, resp.UnusedKeys)
+}
+
+// Test that the debug handler rejects writes
+func TestHandler_MethodNotAllowed(t *testing.T) {
+	var cfg TestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: ReaderSource(strings.NewReader("app:\n  name: svc\n")), Target: &cfg})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	Handler(loader).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/config", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,118 @@
+package yamlenv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// HTTPSource creates a ConfigSource that fetches url with http.DefaultClient
+func HTTPSource(url string) ConfigSource {
+	return HTTPSourceWithClient(http.DefaultClient, url)
+}
+
+// HTTPSourceWithClient creates a ConfigSource that fetches url with client.
+// The source remembers the ETag and Last-Modified headers of the last
+// successful response and sends conditional requests, so periodic refreshes
+// of unchanged config cost a 304 instead of a full download.
+func HTTPSourceWithClient(client *http.Client, url string) ConfigSource {
+	return HTTPSourceWithOptions(url, HTTPOptions{Client: client})
+}
+
+// HTTPOptions configures HTTPSourceWithOptions
+type HTTPOptions struct {
+	Client       *http.Client // optional: HTTP client; nil = http.DefaultClient
+	MaxBytes     int64        // maximum response body size, checked against Content-Length before reading; 0 = unlimited
+	ContentTypes []string     // media types accepted, e.g. "application/yaml"; empty = anything but HTML
+}
+
+// HTTPSourceWithOptions is HTTPSourceWithClient with guards against
+// payloads that can't be config: bodies larger than MaxBytes and responses
+// whose Content-Type isn't listed in ContentTypes are rejected before they
+// are read. HTML, usually a login or error page, is always rejected unless
+// listed.
+func HTTPSourceWithOptions(url string, opts HTTPOptions) ConfigSource {
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	var (
+		mu           sync.Mutex
+		body         []byte
+		etag         string
+		lastModified string
+	)
+	return func() (io.ReadCloser, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("create request for %s: %w", url, err)
+		}
+		if body != nil {
+			if etag != "" {
+				req.Header.Set("If-None-Match", etag)
+			}
+			if lastModified != "" {
+				req.Header.Set("If-Modified-Since", lastModified)
+			}
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("fetch %s: %w", url, err)
+		}
+		defer resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusNotModified && body != nil:
+			return io.NopCloser(bytes.NewReader(body)), nil
+		case resp.StatusCode < 200 || resp.StatusCode > 299:
+			return nil, fmt.Errorf("fetch %s: unexpected status %s", url, resp.Status)
+		}
+
+		if err := checkContentType(resp.Header.Get("Content-Type"), opts.ContentTypes); err != nil {
+			return nil, fmt.Errorf("fetch %s: %w", url, err)
+		}
+		if opts.MaxBytes > 0 && resp.ContentLength > opts.MaxBytes {
+			return nil, fmt.Errorf("fetch %s: response of %d bytes exceeds maximum size of %d bytes", url, resp.ContentLength, opts.MaxBytes)
+		}
+
+		var r io.Reader = resp.Body
+		if opts.MaxBytes > 0 {
+			r = &sizeLimitReader{r: resp.Body, limit: opts.MaxBytes, remaining: opts.MaxBytes}
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", url, err)
+		}
+		body, etag, lastModified = data, resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+}
+
+// checkContentType checks a response's Content-Type against the accepted
+// media types; with none given, everything but HTML is accepted
+func checkContentType(header string, accepted []string) error {
+	if header == "" && len(accepted) == 0 {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		mediaType = header
+	}
+	switch {
+	case slices.Contains(accepted, mediaType):
+		return nil
+	case len(accepted) > 0:
+		return fmt.Errorf("unexpected content type %q, want one of %v", header, accepted)
+	case mediaType == "text/html":
+		return fmt.Errorf("unexpected content type %q (an error or login page?)", header)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package yamlenv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// configServer serves YAML with an ETag and counts full and conditional responses
+type configServer struct {
+	mu          sync.Mutex
+	body        string
+	etag        string
+	full        int
+	notModified int
+}
+
+func (s *configServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Header.Get("If-None-Match") == s.etag {
+		s.notModified++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	s.full++
+	w.Header().Set("ETag", s.etag)
+	w.Write([]byte(s.body))
+}
+
+func (s *configServer) set(body, etag string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body, s.etag = body, etag
+}
+
+// Test that repeated fetches use conditional requests and serve the cached body
+func TestHTTPSource_ConditionalRequests(t *testing.T) {
+	srv := &configServer{body: "app:\n  name: remote\n", etag: `"v1"`}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	source := HTTPSource(ts.URL)
+	for i := 0; i < 3; i++ {
+		var cfg TestConfig
+		require.NoError(t, LoadConfig(LoaderOptions{BaseSource: source, Target: &cfg}))
+		assert.Equal(t, "remote", cfg.App.Name)
+	}
+
+	assert.Equal(t, 1, srv.full)
+	assert.Equal(t, 2, srv.notModified)
+
+	srv.set("app:\n  name: updated\n", `"v2"`)
+	var cfg TestConfig
+	require.NoError(t, LoadConfig(LoaderOptions{BaseSource: source, Target: &cfg}))
+	assert.Equal(t, "updated", cfg.App.Name)
+	assert.Equal(t, 2, srv.full)
+}
+
+// Test that error statuses fail the load
+func TestHTTPSource_ErrorStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{BaseSource: HTTPSource(ts.URL), Target: &cfg})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "load base config")
+	assert.Contains(t, err.Error(), "unexpected status 404")
+}
+
+// Test that oversized and wrongly typed responses are rejected with clear errors
+func TestHTTPSource_Guards(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html>sign in</html>"))
+		case "/big":
+			w.Header().Set("Content-Type", "application/yaml")
+			w.Write([]byte("app:\n  name: " + strings.Repeat("x", 100) + "\n"))
+		case "/chunked":
+			w.Header().Set("Content-Type", "application/yaml")
+			w.(http.Flusher).Flush() // no Content-Length
+			w.Write([]byte("app:\n  name: " + strings.Repeat("x", 100) + "\n"))
+		default:
+			w.Header().Set("Content-Type", "application/yaml")
+			w.Write([]byte("app:\n  name: ok\n"))
+		}
+	}))
+	defer ts.Close()
+
+	_, err := HTTPSource(ts.URL + "/login")()
+	assert.EqualError(t, err, "fetch "+ts.URL+`/login: unexpected content type "text/html; charset=utf-8" (an error or login page?)`)
+
+	opts := HTTPOptions{MaxBytes: 64, ContentTypes: []string{"application/yaml"}}
+	_, err = HTTPSourceWithOptions(ts.URL+"/big", opts)()
+	assert.EqualError(t, err, "fetch "+ts.URL+"/big: response of 114 bytes exceeds maximum size of 64 bytes")
+	_, err = HTTPSourceWithOptions(ts.URL+"/chunked", opts)()
+	assert.EqualError(t, err, "read "+ts.URL+"/chunked: config source exceeds maximum size of 64 bytes")
+
+	var cfg TestConfig
+	require.NoError(t, LoadConfig(LoaderOptions{BaseSource: HTTPSourceWithOptions(ts.URL+"/ok", opts), Target: &cfg}))
+	assert.Equal(t, "ok", cfg.App.Name)
+
+	opts.ContentTypes = []string{"application/json"}
+	_, err = HTTPSourceWithOptions(ts.URL+"/ok", opts)()
+	assert.EqualError(t, err, "fetch "+ts.URL+`/ok: unexpected content type "application/yaml", want one of [application/json]`)
+}
@@ -0,0 +1,224 @@
+package yamlenv
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// HTTPServerConfig is a ready-made config section for an HTTP server, with
+// the same timeout defaults in every service:
+//
+//	type Config struct {
+//	    Server yamlenv.HTTPServerConfig `yaml:"server"`
+//	}
+//
+//	// server:
+//	//   addr: :8080
+//	//   write_timeout: 1m
+//	srv, err := cfg.Server.Build(mux)
+//
+// Unset fields get the defaults below; a timeout of 0 disables it.
+type HTTPServerConfig struct {
+	Addr              string        `yaml:"addr" desc:"listen address, host:port; default :8080"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" desc:"maximum time to read a request, body included; default 30s"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" desc:"maximum time to read request headers; default 10s"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" desc:"maximum time to write a response; default 30s"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" desc:"how long keep-alive connections wait for the next request; default 2m"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes" desc:"maximum size of request headers; default 1MiB"`
+}
+
+// SetDefaults implements Defaulter
+func (c *HTTPServerConfig) SetDefaults() {
+	c.Addr = ":8080"
+	c.ReadTimeout = 30 * time.Second
+	c.ReadHeaderTimeout = 10 * time.Second
+	c.WriteTimeout = 30 * time.Second
+	c.IdleTimeout = 2 * time.Minute
+	c.MaxHeaderBytes = 1 << 20
+}
+
+// Validate implements Validatable, so mistakes fail the load
+func (c HTTPServerConfig) Validate() error {
+	var errs []error
+	if _, _, err := net.SplitHostPort(c.Addr); c.Addr != "" && err != nil {
+		errs = append(errs, fmt.Errorf("addr %q: want host:port, e.g. :8080", c.Addr))
+	}
+	errs = append(errs,
+		nonNegative("read_timeout", c.ReadTimeout),
+		nonNegative("read_header_timeout", c.ReadHeaderTimeout),
+		nonNegative("write_timeout", c.WriteTimeout),
+		nonNegative("idle_timeout", c.IdleTimeout),
+		nonNegative("max_header_bytes", c.MaxHeaderBytes),
+	)
+	return errors.Join(errs...)
+}
+
+// Build returns an *http.Server for the section that serves handler
+func (c HTTPServerConfig) Build(handler http.Handler) (*http.Server, error) {
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("http server: %w", err)
+	}
+	return &http.Server{
+		Addr:              c.Addr,
+		Handler:           handler,
+		ReadTimeout:       c.ReadTimeout,
+		ReadHeaderTimeout: c.ReadHeaderTimeout,
+		WriteTimeout:      c.WriteTimeout,
+		IdleTimeout:       c.IdleTimeout,
+		MaxHeaderBytes:    c.MaxHeaderBytes,
+	}, nil
+}
+
+// HTTPClientConfig is a ready-made config section for an HTTP client:
+//
+//	type Config struct {
+//	    Billing struct {
+//	        URL    string                   `yaml:"url"`
+//	        Client yamlenv.HTTPClientConfig `yaml:"client"`
+//	    } `yaml:"billing"`
+//	}
+//
+//	// billing:
+//	//   client:
+//	//     timeout: 5s
+//	//     retries: 2
+//	client, err := cfg.Billing.Client.Build()
+//
+// Retries apply to requests that are safe to repeat (GET, HEAD, OPTIONS,
+// PUT, DELETE) with a replayable body, after network errors and 502, 503 or
+// 504 responses.
+type HTTPClientConfig struct {
+	Timeout      time.Duration `yaml:"timeout" desc:"limit for a whole request, retries included; default 30s, 0 disables it"`
+	Proxy        string        `yaml:"proxy" desc:"proxy URL; default from HTTP_PROXY, HTTPS_PROXY and NO_PROXY, \"none\" disables proxying"`
+	Retries      int           `yaml:"retries" desc:"times a failed request is retried"`
+	RetryBackoff time.Duration `yaml:"retry_backoff" desc:"wait before the first retry, doubled for each next one; default 100ms"`
+}
+
+// SetDefaults implements Defaulter
+func (c *HTTPClientConfig) SetDefaults() {
+	c.Timeout = 30 * time.Second
+	c.RetryBackoff = 100 * time.Millisecond
+}
+
+// Validate implements Validatable, so mistakes fail the load
+func (c HTTPClientConfig) Validate() error {
+	_, err := c.proxy()
+	return errors.Join(
+		err,
+		nonNegative("timeout", c.Timeout),
+		nonNegative("retries", c.Retries),
+		nonNegative("retry_backoff", c.RetryBackoff),
+	)
+}
+
+// Build returns an *http.Client for the section, with its own transport
+func (c HTTPClientConfig) Build() (*http.Client, error) {
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("http client: %w", err)
+	}
+	proxy, _ := c.proxy()
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+
+	client := &http.Client{Transport: transport, Timeout: c.Timeout}
+	if c.Retries > 0 {
+		client.Transport = &retryTransport{next: transport, retries: c.Retries, backoff: c.RetryBackoff}
+	}
+	return client, nil
+}
+
+// proxy returns the transport's proxy function for the Proxy field
+func (c HTTPClientConfig) proxy() (func(*http.Request) (*url.URL, error), error) {
+	switch c.Proxy {
+	case "":
+		return http.ProxyFromEnvironment, nil
+	case "none":
+		return nil, nil
+	}
+	u, err := url.Parse(c.Proxy)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("proxy: want a URL like http://proxy:3128 or \"none\"")
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("proxy: unsupported scheme %q; allowed: http, https, socks5, socks5h", u.Scheme)
+	}
+	return http.ProxyURL(u), nil
+}
+
+// nonNegative reports a negative duration or count
+func nonNegative[T time.Duration | int](name string, value T) error {
+	if value < 0 {
+		return fmt.Errorf("%s must not be negative, got %v", name, value)
+	}
+	return nil
+}
+
+// retryTransport retries idempotent requests after network errors and
+// gateway errors, backing off exponentially
+type retryTransport struct {
+	next    http.RoundTripper
+	retries int
+	backoff time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !retryable(req) {
+		return t.next.RoundTrip(req)
+	}
+	backoff := t.backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if attempt == t.retries || !shouldRetry(resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// retryable reports whether req may be sent again
+func retryable(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// shouldRetry reports whether an attempt failed in a way worth retrying
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package yamlenv
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type HTTPSectionTestConfig struct {
+	Server HTTPServerConfig `yaml:"server"`
+	Client HTTPClientConfig `yaml:"client"`
+}
+
+// Test that unset fields get the defaults and set ones override them
+func TestHTTPConfig_Defaults(t *testing.T) {
+	setEnvVar(t, "HTTPCFG_SERVER__IDLE_TIMEOUT", "0s")
+
+	var cfg HTTPSectionTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: StringSource("server:\n  addr: 127.0.0.1:9000\n  write_timeout: 1m\nclient:\n  retries: 2\n"),
+		EnvPrefix:  "HTTPCFG_",
+		Delimiter:  "__",
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, HTTPServerConfig{
+		Addr:              "127.0.0.1:9000",
+		ReadTimeout:       30 * time.Second,
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      time.Minute,
+		MaxHeaderBytes:    1 << 20,
+	}, cfg.Server)
+	assert.Equal(t, HTTPClientConfig{Timeout: 30 * time.Second, Retries: 2, RetryBackoff: 100 * time.Millisecond}, cfg.Client)
+
+	srv, err := cfg.Server.Build(http.NotFoundHandler())
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:9000", srv.Addr)
+	assert.Equal(t, time.Minute, srv.WriteTimeout)
+	assert.Zero(t, srv.IdleTimeout)
+	assert.Equal(t, 1<<20, srv.MaxHeaderBytes)
+}
+
+// Test that invalid values fail the load, each under its section
+func TestHTTPConfig_InvalidAtLoad(t *testing.T) {
+	var cfg HTTPSectionTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: StringSource("server:\n  addr: localhost\n  read_timeout: -1s\nclient:\n  proxy: ftp://proxy:21\n"),
+		Target:     &cfg,
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, `invalid config: server: addr "localhost": want host:port, e.g. :8080
+server: read_timeout must not be negative, got -1s
+client: proxy: unsupported scheme "ftp"; allowed: http, https, socks5, socks5h`, err.Error())
+}
+
+func TestHTTPClientConfig_Build(t *testing.T) {
+	client, err := HTTPClientConfig{Timeout: 5 * time.Second, Proxy: "http://proxy.internal:3128"}.Build()
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, client.Timeout)
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	proxy, err := client.Transport.(*http.Transport).Proxy(req)
+	require.NoError(t, err)
+	assert.Equal(t, "http://proxy.internal:3128", proxy.String())
+
+	client, err = HTTPClientConfig{Proxy: "none"}.Build()
+	require.NoError(t, err)
+	assert.Nil(t, client.Transport.(*http.Transport).Proxy)
+
+	_, err = HTTPClientConfig{Proxy: "proxy.internal"}.Build()
+	assert.ErrorContains(t, err, "http client: proxy: want a URL")
+}
+
+// Test that idempotent requests are retried after gateway errors, and
+// others are sent once
+func TestHTTPClientConfig_Retries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	client, err := HTTPClientConfig{Retries: 2, RetryBackoff: time.Millisecond}.Build()
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPut, srv.URL, strings.NewReader("payload"))
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "payload", string(body))
+	assert.Equal(t, int32(3), calls.Load())
+
+	calls.Store(0)
+	resp, err = client.Post(srv.URL, "text/plain", strings.NewReader("payload"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Retries run out
+	calls.Store(-10)
+	resp, err = client.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int32(-7), calls.Load())
+}
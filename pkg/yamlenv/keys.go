@@ -0,0 +1,213 @@
+package yamlenv
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// keyMatcher reports whether a YAML key refers to a struct field name
+type keyMatcher func(key, field string) bool
+
+// newKeyMatcher returns the matcher for CaseInsensitiveKeys and
+// KeyTranslation, or nil if keys must match exactly
+func newKeyMatcher(opts LoaderOptions) keyMatcher {
+	translate := opts.KeyTranslation
+	switch {
+	case translate == nil && !opts.CaseInsensitiveKeys:
+		return nil
+	case translate == nil:
+		return strings.EqualFold
+	case opts.CaseInsensitiveKeys:
+		return func(key, field string) bool { return strings.EqualFold(translate(key), translate(field)) }
+	default:
+		return func(key, field string) bool { return translate(key) == translate(field) }
+	}
+}
+
+// exactMatch is the matcher used when only alias tags rename keys
+func exactMatch(key, field string) bool {
+	return key == field
+}
+
+// canonicalizer renames the keys of one layer
+type canonicalizer struct {
+	match    keyMatcher
+	layer    string
+	warnings []string // one per alias key renamed or ignored
+}
+
+// canonicalLayers returns copies of the layers whose mapping keys are
+// renamed to the struct field names they match, so with case-insensitive
+// matching "Host", "host" and "HOST" all bind to the same field, and keys
+// listed in a field's alias tag bind to the field. Within one mapping the
+// field's own name wins over aliases and earlier aliases over later ones;
+// otherwise the last of several matching keys wins. It also returns a
+// warning for every alias key found.
+func canonicalLayers(layers []configLayer, section string, t reflect.Type, match keyMatcher) ([]configLayer, []string) {
+	out := make([]configLayer, len(layers))
+	var warnings []string
+	for i, layer := range layers {
+		c := &canonicalizer{match: match, layer: layer.title()}
+		out[i] = configLayer{name: layer.name, label: layer.label, node: c.canonicalSection(layer.node, section, t, section)}
+		warnings = append(warnings, c.warnings...)
+	}
+	return out, warnings
+}
+
+// canonicalSection canonicalizes the path segments of section and the
+// subtree below it against t
+func (c *canonicalizer) canonicalSection(n *yaml.Node, section string, t reflect.Type, path string) *yaml.Node {
+	if section == "" {
+		return c.canonicalKeys(n, t, path)
+	}
+	n = resolveAlias(n)
+	if n == nil || n.Kind != yaml.MappingNode {
+		return n
+	}
+	key, rest, _ := strings.Cut(section, ".")
+	return c.renameKeys(n, path, func(name string) (matchedField, func(*yaml.Node) *yaml.Node) {
+		if !c.match(name, key) {
+			return matchedField{Name: name}, nil
+		}
+		return matchedField{Name: key}, func(v *yaml.Node) *yaml.Node { return c.canonicalSection(v, rest, t, path) }
+	})
+}
+
+// canonicalKeys canonicalizes the keys of n, at path, against the type it
+// binds to
+func (c *canonicalizer) canonicalKeys(n *yaml.Node, t reflect.Type, path string) *yaml.Node {
+	n = resolveAlias(n)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if n == nil || isLeafType(t) {
+		return n
+	}
+
+	switch {
+	case n.Kind == yaml.MappingNode && t.Kind() == reflect.Struct:
+		return c.renameKeys(n, path, func(name string) (matchedField, func(*yaml.Node) *yaml.Node) {
+			field, ok := c.match.field(t, name)
+			if !ok {
+				return matchedField{Name: name}, nil
+			}
+			return field, func(v *yaml.Node) *yaml.Node { return c.canonicalKeys(v, field.Type, joinPath(path, field.Name)) }
+		})
+	case n.Kind == yaml.MappingNode && t.Kind() == reflect.Map:
+		return c.renameKeys(n, path, func(name string) (matchedField, func(*yaml.Node) *yaml.Node) {
+			return matchedField{Name: name}, func(v *yaml.Node) *yaml.Node { return c.canonicalKeys(v, t.Elem(), joinPath(path, name)) }
+		})
+	case n.Kind == yaml.SequenceNode && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array):
+		out := copyNode(n)
+		for i, elem := range n.Content {
+			out.Content[i] = c.canonicalKeys(elem, t.Elem(), joinPath(path, strconv.Itoa(i)))
+		}
+		return out
+	}
+	return n
+}
+
+// renameKeys copies the mapping n at path, expanding merge keys and passing
+// every key through rename, which returns the field the key binds to and an
+// optional function to transform the value
+func (c *canonicalizer) renameKeys(n *yaml.Node, path string, rename func(string) (matchedField, func(*yaml.Node) *yaml.Node)) *yaml.Node {
+	out := copyNode(n)
+	out.Content = nil
+	pairs := flattenMapping(n)
+	fields := make([]matchedField, len(pairs)/2)
+	transforms := make([]func(*yaml.Node) *yaml.Node, len(pairs)/2)
+	best := map[string]int{} // lowest rank of the keys binding to each name
+	for i := range fields {
+		fields[i], transforms[i] = rename(pairs[2*i].Value)
+		if rank, ok := best[fields[i].Name]; !ok || fields[i].Rank < rank {
+			best[fields[i].Name] = fields[i].Rank
+		}
+	}
+	for i, field := range fields {
+		name := field.Name
+		if field.Alias != "" {
+			warning := fmt.Sprintf("%s: key %q is an old name of %q", c.layer, joinPath(path, field.Alias), joinPath(path, name))
+			if field.Rank > best[name] {
+				warning += " and is ignored because a preferred name is also set"
+			}
+			c.warnings = append(c.warnings, warning)
+		}
+		if field.Rank > best[name] {
+			continue
+		}
+		key, value := pairs[2*i], pairs[2*i+1]
+		if name != key.Value {
+			renamed := *key
+			renamed.Value = name
+			key = &renamed
+		}
+		if transforms[i] != nil {
+			value = transforms[i](value)
+		}
+		if idx := mappingIndex(out, name); idx >= 0 {
+			out.Content[idx+1] = value
+			continue
+		}
+		out.Content = append(out.Content, key, value)
+	}
+	return out
+}
+
+// matchedField is a struct field matched by a YAML key
+type matchedField struct {
+	Name  string
+	Type  reflect.Type
+	Alias string // the alias the key matched, if it matched one
+	Rank  int    // 0 for the field's name, 1 + the index of the alias otherwise
+}
+
+// field finds the field of struct type t whose YAML name matches key,
+// looking through inline structs
+func (match keyMatcher) field(t reflect.Type, key string) (matchedField, bool) {
+	for _, info := range cachedFields(t) {
+		field := t.Field(info.Index)
+		if info.Inline {
+			inner := field.Type
+			for inner.Kind() == reflect.Ptr {
+				inner = inner.Elem()
+			}
+			if inner.Kind() == reflect.Struct {
+				if found, ok := match.field(inner, key); ok {
+					return found, true
+				}
+			}
+			continue
+		}
+		if match(key, info.Name) {
+			return matchedField{Name: info.Name, Type: field.Type}, true
+		}
+		for i, alias := range info.Aliases {
+			if match(key, alias) {
+				return matchedField{Name: info.Name, Type: field.Type, Alias: key, Rank: i + 1}, true
+			}
+		}
+	}
+	return matchedField{}, false
+}
+
+// hasAliases reports whether t or a type below it has a field with an
+// alias tag
+func hasAliases(t reflect.Type, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || isLeafType(t) || seen[t] {
+		return false
+	}
+	seen[t] = true
+	for _, info := range cachedFields(t) {
+		if len(info.Aliases) > 0 || hasAliases(t.Field(info.Index).Type, seen) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that differently-cased keys bind to the same fields across layers
+func TestLoadConfig_CaseInsensitiveKeys(t *testing.T) {
+	type Server struct {
+		Name    string `yaml:"name"`
+		MaxConn int    `yaml:"maxConn"`
+	}
+	type CaseConfig struct {
+		App struct {
+			Name string `yaml:"name"`
+			Port int    `yaml:"port"`
+		} `yaml:"app"`
+		Servers []Server          `yaml:"servers"`
+		Labels  map[string]string `yaml:"labels"`
+	}
+
+	var cfg CaseConfig
+	result, err := Load(LoaderOptions{
+		BaseSource:          ReaderSource(strings.NewReader("App:\n  Name: base\n  PORT: 8080\nservers:\n  - NAME: api\n    MAXCONN: 10\nLabels:\n  Team: core\n")),
+		LocalSource:         ReaderSource(strings.NewReader("app:\n  name: local\n")),
+		CaseInsensitiveKeys: true,
+		Target:              &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "local", cfg.App.Name)
+	assert.Equal(t, 8080, cfg.App.Port)
+	assert.Equal(t, []Server{{Name: "api", MaxConn: 10}}, cfg.Servers)
+	assert.Equal(t, map[string]string{"Team": "core"}, cfg.Labels, "map keys keep their case")
+	assert.Empty(t, result.UnusedKeys)
+	assert.Equal(t, "local", result.Sources["app.name"])
+}
+
+// Test that keys only match case-sensitively by default
+func TestLoadConfig_CaseSensitiveKeysByDefault(t *testing.T) {
+	var cfg TestConfig
+	result, err := Load(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("App:\n  Name: base\n")),
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "", cfg.App.Name)
+	assert.Equal(t, []string{"App"}, result.UnusedKeys)
+}
+
+// Test that the last of several differently-cased keys in one document wins
+func TestLoadConfig_CaseInsensitiveDuplicateKeys(t *testing.T) {
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:          ReaderSource(strings.NewReader("app:\n  name: first\n  Name: second\n")),
+		CaseInsensitiveKeys: true,
+		Target:              &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "second", cfg.App.Name)
+}
+
+// Test that section paths and inline fields match case-insensitively
+func TestLoadConfig_CaseInsensitiveSectionAndInline(t *testing.T) {
+	type Common struct {
+		Host string `yaml:"host"`
+	}
+	type DB struct {
+		Common `yaml:",inline"`
+		Port   int `yaml:"port"`
+	}
+
+	var cfg DB
+	err := LoadConfig(LoaderOptions{
+		BaseSource:          ReaderSource(strings.NewReader("DB:\n  HOST: db.internal\n  Port: 5432\n")),
+		Section:             "db",
+		CaseInsensitiveKeys: true,
+		Target:              &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "db.internal", cfg.Host)
+	assert.Equal(t, 5432, cfg.Port)
+}
+
+type AliasConfig struct {
+	Timeout int `yaml:"timeout" alias:"timeout_seconds,timeoutSec"`
+	DB      struct {
+		Host string `yaml:"host" alias:"hostname"`
+	} `yaml:"db"`
+}
+
+// Test that old key names in an alias tag bind to the field, with warnings
+func TestLoadConfig_AliasKeys(t *testing.T) {
+	logger := &recordingLogger{}
+	var cfg AliasConfig
+	result, err := Load(LoaderOptions{
+		BaseSource:  StringSource("timeoutSec: 10\ndb:\n  hostname: old-db\n"),
+		LocalSource: StringSource("timeout_seconds: 20\n"),
+		Logger:      logger,
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 20, cfg.Timeout)
+	assert.Equal(t, "old-db", cfg.DB.Host)
+	assert.Empty(t, result.UnusedKeys)
+	assert.Equal(t, "local", result.Sources["timeout"])
+	assert.Equal(t, []string{
+		`[yamlenv] warning: base: key "timeoutSec" is an old name of "timeout"`,
+		`[yamlenv] warning: base: key "db.hostname" is an old name of "db.host"`,
+		`[yamlenv] warning: local: key "timeout_seconds" is an old name of "timeout"`,
+	}, logger.lines)
+}
+
+// Test that the field's own name wins over aliases, and earlier aliases
+// over later ones, wherever they appear in the mapping
+func TestLoadConfig_AliasPriority(t *testing.T) {
+	logger := &recordingLogger{}
+	var cfg AliasConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: StringSource("timeoutSec: 1\ntimeout: 2\ntimeout_seconds: 3\n"),
+		Logger:     logger,
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, cfg.Timeout)
+	assert.Contains(t, logger.lines, `[yamlenv] warning: base: key "timeout_seconds" is an old name of "timeout" and is ignored because a preferred name is also set`)
+
+	cfg = AliasConfig{}
+	err = LoadConfig(LoaderOptions{
+		BaseSource: StringSource("timeoutSec: 1\ntimeout_seconds: 3\n"),
+		Logger:     &recordingLogger{},
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, cfg.Timeout)
+}
+
+// Test that aliases combine with case-insensitive matching
+func TestLoadConfig_AliasKeysCaseInsensitive(t *testing.T) {
+	var cfg AliasConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:          StringSource("TIMEOUTSEC: 10\n"),
+		CaseInsensitiveKeys: true,
+		Logger:              &recordingLogger{},
+		Target:              &cfg,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 10, cfg.Timeout)
+}
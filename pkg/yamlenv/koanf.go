@@ -0,0 +1,55 @@
+package yamlenv
+
+import (
+	"errors"
+)
+
+// KoanfRaw is the part of *koanf.Koanf used by the adapters below. It is
+// declared here so yamlenv doesn't depend on koanf; any *koanf.Koanf
+// satisfies it.
+type KoanfRaw interface {
+	Raw() map[string]any
+}
+
+// KoanfProvider exposes yamlenv's base+local+env layering as a koanf.Provider,
+// so teams already on koanf can keep their plumbing:
+//
+//	k := koanf.New(".")
+//	err := k.Load(yamlenv.NewKoanfProvider(opts), nil)
+type KoanfProvider struct {
+	opts LoaderOptions
+}
+
+// NewKoanfProvider returns a koanf.Provider that loads opts through LoadValues
+func NewKoanfProvider(opts LoaderOptions) *KoanfProvider {
+	return &KoanfProvider{opts: opts}
+}
+
+// ReadBytes is not supported; the provider returns parsed data via Read
+func (p *KoanfProvider) ReadBytes() ([]byte, error) {
+	return nil, errors.New("yamlenv provider does not support this method")
+}
+
+// Read returns the merged configuration, with env overrides applied, as a nested map
+func (p *KoanfProvider) Read() (map[string]any, error) {
+	values, err := LoadValues(p.opts)
+	if err != nil {
+		return nil, err
+	}
+	return values.All(), nil
+}
+
+// KoanfSource creates a ConfigSource from the current contents of a koanf
+// instance, so it can be layered like any other source
+func KoanfSource(k KoanfRaw) ConfigSource {
+	return mapFuncSource(k.Raw)
+}
+
+// FromKoanf binds the contents of a koanf instance into target, applying
+// yamlenv's env overrides on top. opts supplies the env settings; its
+// BaseSource and Target are replaced.
+func FromKoanf(k KoanfRaw, opts LoaderOptions, target any) error {
+	opts.BaseSource = KoanfSource(k)
+	opts.Target = target
+	return LoadConfig(opts)
+}
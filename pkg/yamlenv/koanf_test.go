@@ -0,0 +1,70 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKoanf mimics *koanf.Koanf's Raw method
+type fakeKoanf map[string]any
+
+func (f fakeKoanf) Raw() map[string]any { return f }
+
+// Test that the provider returns the merged and env-overridden tree
+func TestKoanfProvider_Read(t *testing.T) {
+	setEnvVar(t, "KOANF_APP__PORT", "9000")
+
+	p := NewKoanfProvider(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader("app:\n  name: base\n  port: 8080\n")),
+		LocalSource: ReaderSource(strings.NewReader("app:\n  name: local\n")),
+		EnvPrefix:   "KOANF_",
+		Delimiter:   "__",
+	})
+
+	data, err := p.Read()
+	require.NoError(t, err)
+	app, ok := data["app"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "local", app["name"])
+	assert.Equal(t, 9000, app["port"])
+
+	_, err = p.ReadBytes()
+	assert.Error(t, err)
+}
+
+// Test binding a koanf instance's data with env overrides on top
+func TestFromKoanf(t *testing.T) {
+	setEnvVar(t, "FROMKOANF_DB__HOST", "env-db")
+
+	k := fakeKoanf{
+		"app": map[string]any{"name": "koanf-app", "port": 8080},
+		"db":  map[string]any{"host": "localhost"},
+	}
+
+	var cfg TestConfig
+	err := FromKoanf(k, LoaderOptions{EnvPrefix: "FROMKOANF_", Delimiter: "__"}, &cfg)
+
+	require.NoError(t, err)
+	assert.Equal(t, "koanf-app", cfg.App.Name)
+	assert.Equal(t, 8080, cfg.App.Port)
+	assert.Equal(t, "env-db", cfg.DB.Host)
+}
+
+// Test using a koanf instance as the base layer under a local YAML file
+func TestKoanfSource_AsLayer(t *testing.T) {
+	k := fakeKoanf{"app": map[string]any{"name": "koanf-app", "port": 8080}}
+
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:  KoanfSource(k),
+		LocalSource: ReaderSource(strings.NewReader("app:\n  port: 3000\n")),
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "koanf-app", cfg.App.Name)
+	assert.Equal(t, 3000, cfg.App.Port)
+}
@@ -0,0 +1,112 @@
+package yamlenv
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// siSuffixes are the multipliers LenientNumbers accepts after an integer,
+// longest first so "Ki" is tried before "K"
+var siSuffixes = []struct {
+	suffix string
+	factor uint64
+}{
+	{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40}, {"Pi", 1 << 50}, {"Ei", 1 << 60},
+	{"k", 1e3}, {"K", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12}, {"P", 1e15}, {"E", 1e18},
+}
+
+// parseLenientUint parses an unsigned integer written the way YAML allows,
+// with "_" separators and 0x, 0o or 0b prefixes, optionally followed by an
+// SI suffix: 1_000_000, 0x1F, 10k, 2M, 512Ki. Hex numbers take no suffix,
+// since "E" is a hex digit. Leading zeros don't make a number octal, as in
+// YAML 1.2.
+func parseLenientUint(s string) (uint64, error) {
+	num, factor := s, uint64(1)
+	hex := strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X")
+	for _, si := range siSuffixes {
+		if !hex && strings.HasSuffix(s, si.suffix) {
+			num, factor = strings.TrimSuffix(s, si.suffix), si.factor
+			break
+		}
+	}
+	num = trimLeadingZeros(num)
+	n, err := strconv.ParseUint(num, 0, 64)
+	if err != nil {
+		return 0, &strconv.NumError{Func: "ParseUint", Num: s, Err: err.(*strconv.NumError).Err}
+	}
+	hi, n := bits.Mul64(n, factor)
+	if hi != 0 {
+		return 0, &strconv.NumError{Func: "ParseUint", Num: s, Err: strconv.ErrRange}
+	}
+	return n, nil
+}
+
+// parseLenientInt is parseLenientUint for signed integers
+func parseLenientInt(s string) (int64, error) {
+	abs, neg := s, false
+	if strings.HasPrefix(abs, "-") {
+		abs, neg = abs[1:], true
+	} else {
+		abs = strings.TrimPrefix(abs, "+")
+	}
+	n, err := parseLenientUint(abs)
+	if err != nil {
+		return 0, &strconv.NumError{Func: "ParseInt", Num: s, Err: err.(*strconv.NumError).Err}
+	}
+	switch {
+	case neg && n <= -math.MinInt64:
+		return -int64(n), nil
+	case !neg && n <= math.MaxInt64:
+		return int64(n), nil
+	}
+	return 0, &strconv.NumError{Func: "ParseInt", Num: s, Err: strconv.ErrRange}
+}
+
+// trimLeadingZeros drops the zeros before a decimal number, which
+// strconv's base 0 would read as an octal prefix
+func trimLeadingZeros(s string) string {
+	if len(s) < 2 || s[0] != '0' || s[1] < '0' || s[1] > '9' && s[1] != '_' {
+		return s
+	}
+	trimmed := strings.TrimLeft(s, "0_")
+	if trimmed == "" {
+		return "0"
+	}
+	return trimmed
+}
+
+// setLenientValue is setFieldValue for integer fields with LenientNumbers;
+// other fields, including durations, are set by setFieldValue
+func setLenientValue(field reflect.Value, value string) error {
+	if !field.CanSet() || field.Type() == reflect.TypeOf(time.Duration(0)) || reflect.PointerTo(field.Type()).Implements(envUnmarshalerType) {
+		return setFieldValue(field, value)
+	}
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := parseLenientInt(value)
+		if err == nil && field.OverflowInt(n) {
+			err = &strconv.NumError{Func: "ParseInt", Num: value, Err: strconv.ErrRange}
+		}
+		if err != nil {
+			return fmt.Errorf("parse int %q: %w", value, err)
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := parseLenientUint(value)
+		if err == nil && field.OverflowUint(n) {
+			err = &strconv.NumError{Func: "ParseUint", Num: value, Err: strconv.ErrRange}
+		}
+		if err != nil {
+			return fmt.Errorf("parse uint %q: %w", value, err)
+		}
+		field.SetUint(n)
+	default:
+		return setFieldValue(field, value)
+	}
+	return nil
+}
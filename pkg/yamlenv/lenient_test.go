@@ -0,0 +1,88 @@
+package yamlenv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLenientInt(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"42", 42},
+		{"1_000_000", 1000000},
+		{"0x1F", 31},
+		{"0x1E", 30},
+		{"0o17", 15},
+		{"0b101", 5},
+		{"010", 10},
+		{"10k", 10000},
+		{"2M", 2000000},
+		{"3G", 3000000000},
+		{"512Ki", 512 << 10},
+		{"1Gi", 1 << 30},
+		{"-2k", -2000},
+		{"+7", 7},
+		{"-9223372036854775808", -9223372036854775808},
+	}
+	for _, tt := range tests {
+		got, err := parseLenientInt(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	for _, in := range []string{"", "k", "1.5k", "10x", "abc", "10E", "9223372036854775808"} {
+		_, err := parseLenientInt(in)
+		assert.Error(t, err, in)
+	}
+}
+
+type LenientConfig struct {
+	MaxBytes int64         `yaml:"max_bytes"`
+	Workers  uint8         `yaml:"workers"`
+	Mask     uint32        `yaml:"mask"`
+	Timeout  time.Duration `yaml:"timeout"`
+}
+
+// Test that LenientNumbers accepts YAML-style and SI forms from env
+func TestLoad_LenientNumbers(t *testing.T) {
+	t.Setenv("LEN_MAX_BYTES", "10Mi")
+	t.Setenv("LEN_WORKERS", "1_6")
+	t.Setenv("LEN_MASK", "0xFF")
+	t.Setenv("LEN_TIMEOUT", "5s")
+
+	var cfg LenientConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:     StringSource("{}"),
+		EnvPrefix:      "LEN_",
+		Delimiter:      "__",
+		LenientNumbers: true,
+		Target:         &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(10<<20), cfg.MaxBytes)
+	assert.Equal(t, uint8(16), cfg.Workers)
+	assert.Equal(t, uint32(255), cfg.Mask)
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+}
+
+// Test that lenient forms are rejected unless enabled, and that values
+// too large for the field fail
+func TestLoad_LenientNumbersErrors(t *testing.T) {
+	t.Setenv("LEN_MAX_BYTES", "10k")
+	var cfg LenientConfig
+	err := LoadConfig(LoaderOptions{BaseSource: StringSource("{}"), EnvPrefix: "LEN_", Delimiter: "__", Target: &cfg})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `set field max_bytes from LEN_MAX_BYTES="10k" (int64): parse int "10k"`)
+
+	t.Setenv("LEN_MAX_BYTES", "1")
+	t.Setenv("LEN_WORKERS", "1k")
+	err = LoadConfig(LoaderOptions{BaseSource: StringSource("{}"), EnvPrefix: "LEN_", Delimiter: "__", LenientNumbers: true, Target: &cfg})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `set field workers from LEN_WORKERS="1k" (uint8): parse uint "1k": strconv.ParseUint: parsing "1k": value out of range`)
+}
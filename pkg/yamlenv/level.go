@@ -0,0 +1,105 @@
+package yamlenv
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+)
+
+// Level is a log level bound to a config path that follows reloads and
+// runtime overrides. It implements slog.Leveler, so it can be passed straight
+// to slog.HandlerOptions; other loggers can follow it with OnChange.
+//
+//	level, err := yamlenv.NewLevel(loader, "log.level")
+//	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+//
+//	// zap: slog levels are 4 apart, zapcore levels 1 apart
+//	level.OnChange(func(l slog.Level) { atom.SetLevel(zapcore.Level(l / 4)) })
+//
+// The config value may be a level name such as "debug" or "WARN+2" (see
+// slog.Level.UnmarshalText), or an integer slog level. Values that fail to
+// parse on reload are ignored and the previous level is kept.
+type Level struct {
+	v           slog.LevelVar
+	unsubscribe func()
+
+	mu        sync.Mutex
+	listeners []func(slog.Level)
+}
+
+// NewLevel binds a Level to the value at path in the loader's config.
+// It returns an error if the current value is missing or not a level.
+func NewLevel(loader *Loader, path string) (*Level, error) {
+	lvl, err := parseLevel(valueAt(loader.snapshot(), path))
+	if err != nil {
+		return nil, fmt.Errorf("level %s: %w", path, err)
+	}
+
+	l := &Level{}
+	l.v.Set(lvl)
+	l.unsubscribe = loader.Subscribe(path, func(_, new any) {
+		if lvl, err := parseLevel(new); err == nil {
+			l.set(lvl)
+		}
+	})
+	return l, nil
+}
+
+// Level returns the current level; it implements slog.Leveler
+func (l *Level) Level() slog.Level {
+	return l.v.Level()
+}
+
+// String formats the current level like slog.Level
+func (l *Level) String() string {
+	return l.v.Level().String()
+}
+
+// OnChange registers fn to be called with the new level whenever it changes
+func (l *Level) OnChange(fn func(slog.Level)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.listeners = append(l.listeners, fn)
+}
+
+// Close stops following the config; the level keeps its last value
+func (l *Level) Close() {
+	l.unsubscribe()
+}
+
+func (l *Level) set(lvl slog.Level) {
+	if l.v.Level() == lvl {
+		return
+	}
+	l.v.Set(lvl)
+
+	l.mu.Lock()
+	listeners := append([]func(slog.Level){}, l.listeners...)
+	l.mu.Unlock()
+	for _, fn := range listeners {
+		fn(lvl)
+	}
+}
+
+// parseLevel converts a config value into a slog.Level
+func parseLevel(value any) (slog.Level, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, fmt.Errorf("no value")
+	case slog.Level:
+		return v, nil
+	case string:
+		var lvl slog.Level
+		if err := lvl.UnmarshalText([]byte(v)); err != nil {
+			return 0, err
+		}
+		return lvl, nil
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return slog.Level(rv.Int()), nil
+	}
+	return 0, fmt.Errorf("cannot use %T as a log level", value)
+}
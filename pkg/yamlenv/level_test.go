@@ -0,0 +1,89 @@
+package yamlenv
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type LevelTestConfig struct {
+	Log struct {
+		Level string `yaml:"level"`
+	} `yaml:"log"`
+}
+
+// Test that a Level follows reloads and runtime overrides
+func TestLevel_FollowsConfig(t *testing.T) {
+	file := createTempYAML(t, "log:\n  level: info\n")
+
+	var cfg LevelTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(file), Target: &cfg})
+	require.NoError(t, err)
+
+	level, err := NewLevel(loader, "log.level")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelInfo, level.Level())
+
+	var seen []slog.Level
+	level.OnChange(func(l slog.Level) { seen = append(seen, l) })
+
+	writeFile(t, file, "log:\n  level: DEBUG\n")
+	require.NoError(t, loader.Reload())
+	assert.Equal(t, slog.LevelDebug, level.Level())
+
+	// Unparseable values keep the previous level
+	writeFile(t, file, "log:\n  level: loud\n")
+	require.NoError(t, loader.Reload())
+	assert.Equal(t, slog.LevelDebug, level.Level())
+
+	rollback, err := loader.ApplyOverride("log.level", "warn+2", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "WARN+2", level.String())
+	writeFile(t, file, "log:\n  level: info\n")
+	require.NoError(t, rollback())
+	assert.Equal(t, slog.LevelInfo, level.Level())
+
+	assert.Equal(t, []slog.Level{slog.LevelDebug, slog.LevelWarn + 2, slog.LevelInfo}, seen)
+
+	level.Close()
+	writeFile(t, file, "log:\n  level: error\n")
+	require.NoError(t, loader.Reload())
+	assert.Equal(t, slog.LevelInfo, level.Level())
+}
+
+// Test that a Level can drive a slog handler
+func TestLevel_SlogLeveler(t *testing.T) {
+	var cfg LevelTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(createTempYAML(t, "log:\n  level: error\n")), Target: &cfg})
+	require.NoError(t, err)
+	level, err := NewLevel(loader, "log.level")
+	require.NoError(t, err)
+
+	handler := slog.NewTextHandler(nil, &slog.HandlerOptions{Level: level})
+	assert.False(t, handler.Enabled(context.Background(), slog.LevelWarn))
+	assert.True(t, handler.Enabled(context.Background(), slog.LevelError))
+}
+
+// Test that missing or invalid values are rejected when binding
+func TestNewLevel_Invalid(t *testing.T) {
+	var cfg LevelTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(createTempYAML(t, "log:\n  level: loud\n")), Target: &cfg})
+	require.NoError(t, err)
+
+	_, err = NewLevel(loader, "log.level")
+	assert.Error(t, err)
+	_, err = NewLevel(loader, "log.missing")
+	assert.Error(t, err)
+}
+
+func TestParseLevel(t *testing.T) {
+	lvl, err := parseLevel(8)
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelError, lvl)
+
+	_, err = parseLevel(1.5)
+	assert.Error(t, err)
+}
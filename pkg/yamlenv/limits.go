@@ -0,0 +1,90 @@
+package yamlenv
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Defaults for the document limits, generous for any hand-written config
+const (
+	defaultMaxAliasNodes = 1_000_000
+	defaultMaxYAMLDepth  = 100
+)
+
+// parseLimits bounds what a single source may parse into; zero fields are
+// unlimited
+type parseLimits struct {
+	maxSize       int64 // bytes read
+	maxAliasNodes int   // nodes after expanding aliases
+	maxDepth      int   // nesting of mappings and lists, after expanding aliases
+}
+
+// sourceLimits returns the parse limits of opts with defaults applied
+func sourceLimits(opts LoaderOptions) parseLimits {
+	return parseLimits{
+		maxSize:       opts.MaxSourceSize,
+		maxAliasNodes: limitOrDefault(opts.MaxAliasNodes, defaultMaxAliasNodes),
+		maxDepth:      limitOrDefault(opts.MaxYAMLDepth, defaultMaxYAMLDepth),
+	}
+}
+
+// limitOrDefault maps 0 to def and negative values to 0, meaning unlimited
+func limitOrDefault(limit, def int) int {
+	switch {
+	case limit == 0:
+		return def
+	case limit < 0:
+		return 0
+	default:
+		return limit
+	}
+}
+
+// check fails if root, with every alias expanded, has more nodes or deeper
+// nesting than allowed. Sizes are memoized per node, so a "billion laughs"
+// document is measured without being expanded. root must be free of alias
+// cycles.
+func (l parseLimits) check(root *yaml.Node) error {
+	if l.maxAliasNodes <= 0 && l.maxDepth <= 0 {
+		return nil
+	}
+	nodes, depth := measureNode(root, map[*yaml.Node][2]int{}, l.maxAliasNodes)
+	if l.maxAliasNodes > 0 && nodes > l.maxAliasNodes {
+		return fmt.Errorf("config source expands to more than %d nodes through aliases (see MaxAliasNodes)", l.maxAliasNodes)
+	}
+	if l.maxDepth > 0 && depth > l.maxDepth {
+		return fmt.Errorf("config source is nested %d levels deep, more than the maximum of %d (see MaxYAMLDepth)", depth, l.maxDepth)
+	}
+	return nil
+}
+
+// measureNode returns the number of nodes and the nesting depth of n with
+// aliases expanded. Counts stop growing past ceiling, when it is set, so
+// they can't overflow.
+func measureNode(n *yaml.Node, memo map[*yaml.Node][2]int, ceiling int) (nodes, depth int) {
+	if n == nil {
+		return 0, 0
+	}
+	if m, ok := memo[n]; ok {
+		return m[0], m[1]
+	}
+	if n.Kind == yaml.AliasNode {
+		nodes, depth = measureNode(n.Alias, memo, ceiling)
+	} else {
+		nodes = 1
+		for _, child := range n.Content {
+			childNodes, childDepth := measureNode(child, memo, ceiling)
+			nodes += childNodes
+			if ceiling > 0 && nodes > ceiling {
+				nodes = ceiling + 1
+			}
+			depth = max(depth, childDepth)
+		}
+		if n.Kind == yaml.MappingNode || n.Kind == yaml.SequenceNode {
+			depth++
+		}
+	}
+	memo[n] = [2]int{nodes, depth}
+	return nodes, depth
+}
@@ -0,0 +1,63 @@
+package yamlenv
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// billionLaughs returns a document whose aliases expand to 10^levels nodes
+func billionLaughs(levels int) string {
+	var b strings.Builder
+	b.WriteString("a0: &a0 [x, x, x, x, x, x, x, x, x, x]\n")
+	for i := 1; i <= levels; i++ {
+		b.WriteString("a" + strconv.Itoa(i) + ": &a" + strconv.Itoa(i) + " [")
+		for j := 0; j < 10; j++ {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString("*a" + strconv.Itoa(i-1))
+		}
+		b.WriteString("]\n")
+	}
+	return b.String()
+}
+
+// limitsTestConfig accepts any value under the keys the tests use
+type limitsTestConfig struct {
+	A any `yaml:"a"`
+	B any `yaml:"b"`
+}
+
+// Test that a document expanding to too many nodes is rejected before it is expanded
+func TestLoadConfig_MaxAliasNodes(t *testing.T) {
+	var cfg limitsTestConfig
+	err := LoadConfig(LoaderOptions{BaseSource: StringSource(billionLaughs(9)), Target: &cfg})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "load base config")
+	assert.Contains(t, err.Error(), "more than 1000000 nodes through aliases")
+
+	err = LoadConfig(LoaderOptions{BaseSource: StringSource(billionLaughs(2)), MaxAliasNodes: 100, Target: &cfg})
+	assert.ErrorContains(t, err, "more than 100 nodes")
+
+	err = LoadConfig(LoaderOptions{BaseSource: StringSource(billionLaughs(2)), Target: &cfg})
+	assert.NoError(t, err, "small expansions are fine")
+}
+
+// Test that deep nesting is rejected, counting nesting reached through aliases
+func TestLoadConfig_MaxYAMLDepth(t *testing.T) {
+	deep := strings.Repeat("[", 101) + strings.Repeat("]", 101)
+	var cfg limitsTestConfig
+	err := LoadConfig(LoaderOptions{BaseSource: StringSource("a: " + deep + "\n"), Target: &cfg})
+	assert.ErrorContains(t, err, "nested 102 levels deep, more than the maximum of 100")
+
+	viaAlias := "a: &a [[[x]]]\nb: [[*a]]\n"
+	err = LoadConfig(LoaderOptions{BaseSource: StringSource(viaAlias), MaxYAMLDepth: 5, Target: &cfg})
+	assert.ErrorContains(t, err, "nested 6 levels deep")
+
+	err = LoadConfig(LoaderOptions{BaseSource: StringSource("a: " + deep + "\n"), MaxYAMLDepth: -1, Target: &cfg})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,319 @@
+package yamlenv
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Severity ranks lint issues
+type Severity int
+
+const (
+	SeverityWarning Severity = iota // suspicious, but the config works
+	SeverityError                   // should block a deploy
+)
+
+// String returns "warning" or "error"
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Issue is a problem reported by a lint rule
+type Issue struct {
+	Rule     string   // name of the rule that reported it
+	Severity Severity // how serious it is
+	Path     string   // dot-separated key path; "" for the whole config
+	Source   string   // where the value came from, as in LoadResult.Sources
+	Message  string   // human-readable description
+}
+
+// String formats the issue as "error: db.password (base): message [rule]"
+func (i Issue) String() string {
+	var b strings.Builder
+	b.WriteString(i.Severity.String())
+	b.WriteString(": ")
+	if i.Path != "" {
+		b.WriteString(i.Path)
+		if i.Source != "" {
+			b.WriteString(" (" + i.Source + ")")
+		}
+		b.WriteString(": ")
+	}
+	b.WriteString(i.Message)
+	b.WriteString(" [" + i.Rule + "]")
+	return b.String()
+}
+
+// LintContext is the loaded configuration a rule inspects
+type LintContext struct {
+	Profile string      // deployment profile from LintOptions, e.g. "prod"
+	Config  any         // effective config, a pointer of Target's type
+	Result  *LoadResult // unused keys and value sources of the load
+
+	section string // LoaderOptions.Section; paths include it, Config doesn't
+}
+
+// relPath returns path relative to the bound section
+func (c *LintContext) relPath(path string) string {
+	if c.section == "" {
+		return path
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(path, c.section), ".")
+}
+
+// Value returns the effective value at path
+func (c *LintContext) Value(path string) (any, bool) {
+	v, ok := lookupField(reflect.ValueOf(c.Config), c.relPath(path))
+	if !ok || !v.CanInterface() {
+		return nil, false
+	}
+	return v.Interface(), true
+}
+
+// Paths returns the leaf paths that have a value, sorted
+func (c *LintContext) Paths() []string {
+	paths := make([]string, 0, len(c.Result.Sources))
+	for path := range c.Result.Sources {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// fields returns the struct field metadata along path (relative to the
+// section), skipping map and slice steps; it stops where the config type no
+// longer has structure
+func (c *LintContext) fields(path string) []fieldInfo {
+	out, _ := fieldsAlong(reflect.TypeOf(c.Config), path)
+	return out
+}
+
+// fieldsAlong returns the struct field metadata along path below t,
+// skipping map and slice steps, and the type path leads to. It stops where
+// t no longer has structure, returning a nil type.
+func fieldsAlong(t reflect.Type, path string) ([]fieldInfo, reflect.Type) {
+	var out []fieldInfo
+	for _, key := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			info, ok := structField(t, key)
+			if !ok {
+				return out, nil
+			}
+			out = append(out, info)
+			t = t.Field(info.Index).Type
+		case reflect.Map, reflect.Slice, reflect.Array:
+			t = t.Elem()
+		default:
+			return out, nil
+		}
+	}
+	return out, t
+}
+
+// structField finds the field bound to key, searching inline structs
+func structField(t reflect.Type, key string) (fieldInfo, bool) {
+	for _, info := range cachedFields(t) {
+		if info.Inline {
+			inner := t.Field(info.Index).Type
+			if inner.Kind() == reflect.Struct {
+				if found, ok := structField(inner, key); ok {
+					return found, true
+				}
+			}
+			continue
+		}
+		if info.Name == key {
+			return info, true
+		}
+	}
+	return fieldInfo{}, false
+}
+
+// LintRule checks a loaded configuration
+type LintRule interface {
+	Name() string
+	Check(c *LintContext) []Issue
+}
+
+type lintRule struct {
+	name  string
+	check func(c *LintContext) []Issue
+}
+
+func (r lintRule) Name() string                 { return r.name }
+func (r lintRule) Check(c *LintContext) []Issue { return r.check(c) }
+
+// NewLintRule returns a LintRule named name that runs check. Issues without a
+// Rule are attributed to it.
+func NewLintRule(name string, check func(c *LintContext) []Issue) LintRule {
+	return lintRule{name: name, check: check}
+}
+
+// Built-in lint rules
+var (
+	// UnknownKeysRule reports keys that don't map to any field of Target
+	UnknownKeysRule = NewLintRule("unknown-key", func(c *LintContext) []Issue {
+		var issues []Issue
+		for _, path := range c.Result.UnusedKeys {
+			issues = append(issues, Issue{Path: path, Source: c.Result.Sources[path], Message: "key does not map to any config field"})
+		}
+		return issues
+	})
+
+	// DeprecatedKeysRule reports set keys whose field has a `deprecated:"..."` tag
+	DeprecatedKeysRule = NewLintRule("deprecated-key", func(c *LintContext) []Issue {
+		var issues []Issue
+		seen := map[string]bool{}
+		for _, path := range c.Paths() {
+			rel := c.relPath(path)
+			segments := strings.Split(rel, ".")
+			for i, info := range c.fields(rel) {
+				prefix := joinPath(c.section, strings.Join(segments[:i+1], "."))
+				if info.Deprecated == "" || seen[prefix] {
+					continue
+				}
+				seen[prefix] = true
+				issues = append(issues, Issue{Path: prefix, Source: c.Result.Sources[path], Message: "deprecated: " + info.Deprecated})
+			}
+		}
+		return issues
+	})
+
+	// SecretsInYAMLRule reports secret values read from the base, local or
+	// tenant YAML files instead of a secrets layer or the environment.
+	// Fields tagged `secret:"true"` are secret, as are keys below maps and
+	// untyped fields whose name looks like a credential.
+	SecretsInYAMLRule = NewLintRule("secret-in-yaml", func(c *LintContext) []Issue {
+		var issues []Issue
+		for _, path := range c.Paths() {
+			source := c.Result.Sources[path]
+			if !isPlainYAMLLayer(source) {
+				continue
+			}
+			if !c.isSecret(path) {
+				continue
+			}
+			if v, ok := c.Value(path); ok && reflect.ValueOf(v).IsZero() {
+				continue
+			}
+			issues = append(issues, Issue{Severity: SeverityError, Path: path, Source: source, Message: "secret value in plain YAML; move it to SecretsSource or the environment"})
+		}
+		return issues
+	})
+
+	// LocalhostInProdRule reports values pointing at localhost when the
+	// profile is "prod" or "production"
+	LocalhostInProdRule = NewLintRule("localhost-in-prod", func(c *LintContext) []Issue {
+		if profile := strings.ToLower(c.Profile); profile != "prod" && profile != "production" {
+			return nil
+		}
+		var issues []Issue
+		for _, path := range c.Paths() {
+			v, _ := c.Value(path)
+			s, ok := v.(string)
+			if !ok || !isLocalAddress(s) {
+				continue
+			}
+			issues = append(issues, Issue{Path: path, Source: c.Result.Sources[path], Message: fmt.Sprintf("%q points at localhost in %s", s, c.Profile)})
+		}
+		return issues
+	})
+)
+
+// DefaultLintRules returns the built-in rules Lint runs when none are given
+func DefaultLintRules() []LintRule {
+	return []LintRule{UnknownKeysRule, DeprecatedKeysRule, SecretsInYAMLRule, LocalhostInProdRule}
+}
+
+// isSecret reports whether the value at path is tagged secret, or, where
+// the config has no struct field for it, whether its key looks sensitive
+func (c *LintContext) isSecret(path string) bool {
+	path = c.relPath(path)
+	fields := c.fields(path)
+	for _, info := range fields {
+		if info.Secret {
+			return true
+		}
+	}
+	if len(fields) == strings.Count(path, ".")+1 {
+		return false
+	}
+	return isSensitiveKey(lastSegment(path))
+}
+
+// isSensitiveKey reports whether a key name looks like it holds a credential
+func isSensitiveKey(key string) bool {
+	key = strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	for _, word := range []string{"password", "passwd", "secret", "token", "apikey", "accesskey", "privatekey", "signingkey", "encryptionkey", "credential"} {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLocalAddress reports whether s names the loopback host
+func isLocalAddress(s string) bool {
+	s = strings.ToLower(s)
+	return strings.Contains(s, "localhost") || strings.Contains(s, "127.0.0.1") || strings.Contains(s, "[::1]") || s == "::1"
+}
+
+// untypedConfig accepts any document when Lint has no Target
+type untypedConfig struct {
+	Values map[string]any `yaml:",inline"`
+}
+
+// LintOptions configures Lint
+type LintOptions struct {
+	LoaderOptions
+
+	Profile string     // deployment profile, e.g. "prod"; enables profile-specific rules
+	Rules   []LintRule // rules to run; nil = DefaultLintRules()
+}
+
+// Lint loads the configuration like Load and runs the lint rules against
+// it, returning the issues sorted by path. Target only supplies the config
+// type and is not modified; without a Target the config is loaded as a
+// map, so only rules that don't need field metadata report anything. A
+// failed load is reported as a single "load" issue.
+func Lint(opts LintOptions) []Issue {
+	loadOpts := opts.LoaderOptions
+	if loadOpts.Target == nil {
+		loadOpts.Target = &untypedConfig{}
+	}
+	if target := reflect.ValueOf(loadOpts.Target); target.Kind() == reflect.Ptr && !target.IsNil() {
+		loadOpts.Target = deepCopy(target).Interface()
+	}
+
+	result, err := Load(loadOpts)
+	if err != nil {
+		return []Issue{{Rule: "load", Severity: SeverityError, Message: err.Error()}}
+	}
+
+	rules := opts.Rules
+	if rules == nil {
+		rules = DefaultLintRules()
+	}
+	c := &LintContext{Profile: opts.Profile, Config: loadOpts.Target, Result: result, section: loadOpts.Section}
+
+	var issues []Issue
+	for _, rule := range rules {
+		for _, issue := range rule.Check(c) {
+			if issue.Rule == "" {
+				issue.Rule = rule.Name()
+			}
+			issues = append(issues, issue)
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Path < issues[j].Path })
+	return issues
+}
@@ -0,0 +1,132 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// LintTestConfig exercises the built-in lint rules
+type LintTestConfig struct {
+	DB struct {
+		Host     string `yaml:"host"`
+		Password string `yaml:"password" secret:"true"`
+		PoolSize int    `yaml:"pool" deprecated:"use db.max_conns"`
+	} `yaml:"db"`
+	Plugins map[string]map[string]string `yaml:"plugins"`
+}
+
+// issueKeys returns "rule path" for each issue, for compact assertions
+func issueKeys(issues []Issue) []string {
+	out := make([]string, len(issues))
+	for i, issue := range issues {
+		out[i] = issue.Rule + " " + issue.Path
+	}
+	return out
+}
+
+// Test that the built-in rules report unknown, deprecated, secret and localhost values
+func TestLint_DefaultRules(t *testing.T) {
+	base := `db:
+  host: localhost:5432
+  password: hunter2
+  pool: 10
+  timeout: 5s
+plugins:
+  s3:
+    api_key: abc
+    region: us-east-1
+`
+	var cfg LintTestConfig
+	issues := Lint(LintOptions{
+		LoaderOptions: LoaderOptions{
+			BaseSource: ReaderSource(strings.NewReader(base)),
+			Target:     &cfg,
+		},
+		Profile: "prod",
+	})
+
+	assert.Equal(t, []string{
+		"localhost-in-prod db.host",
+		"secret-in-yaml db.password",
+		"deprecated-key db.pool",
+		"unknown-key db.timeout",
+		"secret-in-yaml plugins.s3.api_key",
+	}, issueKeys(issues))
+	assert.Equal(t, SeverityError, issues[1].Severity)
+	assert.Equal(t, "error: db.password (base): secret value in plain YAML; move it to SecretsSource or the environment [secret-in-yaml]", issues[1].String())
+	assert.Equal(t, "warning: db.pool (base): deprecated: use db.max_conns [deprecated-key]", issues[2].String())
+	assert.Empty(t, cfg.DB.Host, "Lint must not modify Target")
+}
+
+// Test that secrets from the environment or a secrets layer pass, and localhost is fine outside prod
+func TestLint_CleanConfig(t *testing.T) {
+	setEnvVar(t, "LINT_DB__PASSWORD", "hunter2")
+
+	var cfg LintTestConfig
+	issues := Lint(LintOptions{
+		LoaderOptions: LoaderOptions{
+			BaseSource:    ReaderSource(strings.NewReader("db:\n  host: localhost\n  password: \"\"\n")),
+			SecretsSource: ReaderSource(strings.NewReader("plugins:\n  s3:\n    api_key: abc\n")),
+			EnvPrefix:     "LINT_",
+			Delimiter:     "__",
+			Target:        &cfg,
+		},
+		Profile: "dev",
+	})
+
+	assert.Empty(t, issues)
+}
+
+// Test that custom rules run alongside the built-ins and load failures become issues
+func TestLint_CustomRules(t *testing.T) {
+	requireHost := NewLintRule("require-host", func(c *LintContext) []Issue {
+		if v, _ := c.Value("db.host"); v == "" {
+			return []Issue{{Severity: SeverityError, Path: "db.host", Message: "db.host must be set"}}
+		}
+		return nil
+	})
+
+	var cfg LintTestConfig
+	issues := Lint(LintOptions{
+		LoaderOptions: LoaderOptions{
+			BaseSource: ReaderSource(strings.NewReader("db:\n  pool_size: 1\n")),
+			Target:     &cfg,
+		},
+		Rules: append(DefaultLintRules(), requireHost),
+	})
+	assert.Equal(t, []string{"require-host db.host", "unknown-key db.pool_size"}, issueKeys(issues))
+
+	issues = Lint(LintOptions{LoaderOptions: LoaderOptions{BaseSource: ReaderSource(strings.NewReader("db: [")), Target: &cfg}})
+	require.Len(t, issues, 1)
+	assert.Equal(t, "load", issues[0].Rule)
+	assert.Equal(t, SeverityError, issues[0].Severity)
+}
+
+// Test that paths are reported in full when a section is bound
+func TestLint_Section(t *testing.T) {
+	var db struct {
+		Password string `yaml:"password" secret:"true"`
+	}
+	issues := Lint(LintOptions{
+		LoaderOptions: LoaderOptions{
+			BaseSource: ReaderSource(strings.NewReader("db:\n  password: hunter2\n")),
+			Section:    "db",
+			Target:     &db,
+		},
+	})
+
+	assert.Equal(t, []string{"secret-in-yaml db.password"}, issueKeys(issues))
+}
+
+// Test that without a Target only schema-less rules report
+func TestLint_NoTarget(t *testing.T) {
+	issues := Lint(LintOptions{
+		LoaderOptions: LoaderOptions{BaseSource: ReaderSource(strings.NewReader("db:\n  url: http://127.0.0.1\n  token: t\n"))},
+		Profile:       "production",
+	})
+
+	assert.Equal(t, []string{"secret-in-yaml db.token", "localhost-in-prod db.url"}, issueKeys(issues))
+}
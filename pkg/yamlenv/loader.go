@@ -0,0 +1,251 @@
+package yamlenv
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Loader keeps a loaded configuration and reloads it on demand, notifying
+// subscribers about the keys that changed. Each reload binds into a fresh
+// copy of the target, so a failed reload leaves the current config in place.
+//
+//	loader, err := yamlenv.NewLoader(opts)
+//	loader.Subscribe("db.pool_size", func(old, new any) { pool.Resize(new.(int)) })
+//	err = loader.Reload()
+type Loader struct {
+	opts     LoaderOptions
+	template reflect.Value // deep copy of the target before the first load; seeds every reload
+
+	mu      sync.RWMutex
+	current reflect.Value // pointer to the latest successfully loaded config
+	result  *LoadResult
+	layers  []configLayer // parsed layers of the latest load, reused by ForTenant
+
+	// notifyMu is held from publishing a config through notifying about
+	// it, so concurrent reloads deliver their changes one after another
+	notifyMu sync.Mutex
+
+	subsMu   sync.Mutex
+	subs     map[int]subscription
+	onChange map[int]func([]Change)
+	nextID   int
+
+	overridesMu  sync.Mutex
+	overrides    map[string]runtimeOverride
+	nextOverride int
+
+	rotated map[string]any       // values set by Rotate, guarded by mu
+	leases  map[string]time.Time // expiry of each rotated value with a TTL, guarded by mu
+
+	statsMu sync.Mutex
+	stats   LoaderStats
+}
+
+// subscription is a callback registered for one config path
+type subscription struct {
+	path string
+	fn   func(old, new any)
+}
+
+// NewLoader performs the initial load into opts.Target and returns a Loader
+// for subsequent reloads. Values already present in Target act as defaults
+// for every reload.
+func NewLoader(opts LoaderOptions) (*Loader, error) {
+	if opts.Target == nil {
+		return nil, fmt.Errorf("target cannot be nil")
+	}
+	targetValue := reflect.ValueOf(opts.Target)
+	if targetValue.Kind() != reflect.Ptr || targetValue.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("target must be a pointer to struct")
+	}
+
+	l := &Loader{
+		opts:      opts,
+		template:  deepCopy(targetValue),
+		subs:      map[int]subscription{},
+		onChange:  map[int]func([]Change){},
+		overrides: map[string]runtimeOverride{},
+		rotated:   map[string]any{},
+		leases:    map[string]time.Time{},
+	}
+	if _, _, err := l.load(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// load binds the configuration into a fresh copy of the template, publishes
+// it to Target and returns the previous and the new config
+func (l *Loader) load() (previous, current reflect.Value, err error) {
+	defer func(start time.Time) { l.recordLoad(start, err) }(time.Now())
+	fresh := deepCopy(l.template)
+	opts := l.opts
+	opts.Target = fresh.Interface()
+	ctx, end := startSpan(opts, "yamlenv.load")
+	defer func() { end(err) }()
+	opts.ctx = ctx
+	if err := validateOptions(opts); err != nil {
+		return reflect.Value{}, reflect.Value{}, err
+	}
+	layers, warnings, err := loadLayersPartial(opts)
+	if err != nil {
+		return reflect.Value{}, reflect.Value{}, err
+	}
+	result, err := bindLayers(opts, layers)
+	if err != nil {
+		return reflect.Value{}, reflect.Value{}, err
+	}
+	result.Warnings = warnings
+	if err := l.applyOverrides(fresh, result); err != nil {
+		return reflect.Value{}, reflect.Value{}, err
+	}
+	if err := checkConfig(opts, result); err != nil {
+		return reflect.Value{}, reflect.Value{}, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.applyRotated(fresh, result); err != nil {
+		return reflect.Value{}, reflect.Value{}, err
+	}
+	previous = l.current
+	l.current, l.result, l.layers = fresh, result, layers
+	reflect.ValueOf(l.opts.Target).Elem().Set(deepCopy(fresh).Elem())
+	return previous, fresh, nil
+}
+
+// Reload loads all layers again. On success Target is replaced with the new
+// configuration and subscribers of changed keys are notified; on failure the
+// current configuration is kept and the error returned.
+//
+// Target is updated in place; goroutines reading the config concurrently
+// with reloads should use Current instead. Concurrent reloads notify one
+// after another, so callbacks must not reload or apply overrides themselves.
+func (l *Loader) Reload() error {
+	l.notifyMu.Lock()
+	defer l.notifyMu.Unlock()
+	previous, current, err := l.load()
+	if err != nil {
+		return fmt.Errorf("reload config: %w", err)
+	}
+	l.notify(previous, current)
+	return nil
+}
+
+// Current returns a pointer to a copy of the latest configuration, with the
+// same type as Target. Callers may keep it; later reloads don't modify it.
+func (l *Loader) Current() any {
+	return deepCopy(l.snapshot()).Interface()
+}
+
+// Get returns a copy of the value at path (dot-separated YAML keys) in the
+// latest configuration, or nil if the path doesn't resolve. Section helpers
+// use it to read their section by path.
+func (l *Loader) Get(path string) any {
+	field, ok := lookupField(l.snapshot(), path)
+	if !ok {
+		return nil
+	}
+	return copyValue(field).Interface()
+}
+
+// Result returns the LoadResult of the latest successful load
+func (l *Loader) Result() *LoadResult {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.result
+}
+
+// snapshot returns the latest loaded config pointer
+func (l *Loader) snapshot() reflect.Value {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.current
+}
+
+// Subscribe registers fn to be called after a reload changes the value at
+// path (dot-separated YAML keys, e.g. "db.pool_size"; "" for the whole
+// config). old or new is nil when the path doesn't resolve. The returned
+// function removes the subscription.
+func (l *Loader) Subscribe(path string, fn func(old, new any)) (unsubscribe func()) {
+	l.subsMu.Lock()
+	defer l.subsMu.Unlock()
+	id := l.nextID
+	l.nextID++
+	l.subs[id] = subscription{path: path, fn: fn}
+	return func() {
+		l.subsMu.Lock()
+		defer l.subsMu.Unlock()
+		delete(l.subs, id)
+	}
+}
+
+// OnChange registers fn to be called after a reload that changed the
+// configuration, with the list of changed values (secrets redacted) for
+// audit logging. The returned function removes the callback.
+func (l *Loader) OnChange(fn func(changes []Change)) (unsubscribe func()) {
+	l.subsMu.Lock()
+	defer l.subsMu.Unlock()
+	id := l.nextID
+	l.nextID++
+	l.onChange[id] = fn
+	return func() {
+		l.subsMu.Lock()
+		defer l.subsMu.Unlock()
+		delete(l.onChange, id)
+	}
+}
+
+// notify calls the change callbacks and the subscribers whose path changed
+// between two configs, each in the order they were registered; l.notifyMu
+// must be held
+func (l *Loader) notify(previous, current reflect.Value) {
+	l.subsMu.Lock()
+	subs := make([]subscription, 0, len(l.subs))
+	for _, id := range sortedIDs(l.subs) {
+		subs = append(subs, l.subs[id])
+	}
+	onChange := make([]func([]Change), 0, len(l.onChange))
+	for _, id := range sortedIDs(l.onChange) {
+		onChange = append(onChange, l.onChange[id])
+	}
+	l.subsMu.Unlock()
+
+	if len(onChange) > 0 {
+		if changes := Diff(previous.Interface(), current.Interface()); len(changes) > 0 {
+			for _, fn := range onChange {
+				fn(changes)
+			}
+		}
+	}
+
+	for _, sub := range subs {
+		oldValue := valueAt(previous, sub.path)
+		newValue := valueAt(current, sub.path)
+		if !reflect.DeepEqual(oldValue, newValue) {
+			sub.fn(oldValue, newValue)
+		}
+	}
+}
+
+// sortedIDs returns the keys of a callback map in registration order
+func sortedIDs[V any](m map[int]V) []int {
+	ids := make([]int, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// valueAt returns the value at a dot-separated path below v, or nil
+func valueAt(v reflect.Value, path string) any {
+	field, ok := lookupField(v, path)
+	if !ok {
+		return nil
+	}
+	return field.Interface()
+}
@@ -0,0 +1,235 @@
+package yamlenv
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type LoaderTestConfig struct {
+	App struct {
+		Name string `yaml:"name"`
+		Port int    `yaml:"port"`
+	} `yaml:"app"`
+	DB struct {
+		PoolSize int               `yaml:"pool_size"`
+		Options  map[string]string `yaml:"options"`
+	} `yaml:"db"`
+}
+
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+// Test that reloads notify only the subscribers whose key changed
+func TestLoader_SubscribeNotifiesChangedKeys(t *testing.T) {
+	file := createTempYAML(t, "app:\n  name: svc\n  port: 8080\ndb:\n  pool_size: 5\n")
+
+	var cfg LoaderTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(file), Target: &cfg})
+	require.NoError(t, err)
+	assert.Equal(t, 5, cfg.DB.PoolSize)
+
+	var poolChanges [][2]any
+	loader.Subscribe("db.pool_size", func(old, new any) {
+		poolChanges = append(poolChanges, [2]any{old, new})
+	})
+	nameCalls := 0
+	loader.Subscribe("app.name", func(old, new any) { nameCalls++ })
+
+	writeFile(t, file, "app:\n  name: svc\n  port: 8080\ndb:\n  pool_size: 10\n")
+	require.NoError(t, loader.Reload())
+
+	assert.Equal(t, [][2]any{{5, 10}}, poolChanges)
+	assert.Equal(t, 0, nameCalls)
+	assert.Equal(t, 10, cfg.DB.PoolSize)
+}
+
+// Test subscriptions to keys that appear or disappear, and unsubscribe
+func TestLoader_SubscribeMapKeysAndUnsubscribe(t *testing.T) {
+	file := createTempYAML(t, "db:\n  options:\n    sslmode: disable\n")
+
+	var cfg LoaderTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(file), Target: &cfg})
+	require.NoError(t, err)
+
+	var got []any
+	unsubscribe := loader.Subscribe("db.options.timeout", func(old, new any) {
+		got = append(got, old, new)
+	})
+
+	writeFile(t, file, "db:\n  options:\n    sslmode: disable\n    timeout: 5s\n")
+	require.NoError(t, loader.Reload())
+	assert.Equal(t, []any{nil, "5s"}, got)
+
+	unsubscribe()
+	writeFile(t, file, "db:\n  options:\n    timeout: 10s\n")
+	require.NoError(t, loader.Reload())
+	assert.Len(t, got, 2)
+}
+
+// Test that subscribers are notified in the order they subscribed
+func TestLoader_SubscribersNotifiedInOrder(t *testing.T) {
+	file := createTempYAML(t, "db:\n  pool_size: 5\n")
+
+	var cfg LoaderTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(file), Target: &cfg})
+	require.NoError(t, err)
+
+	var order, want []int
+	for i := 0; i < 20; i++ {
+		loader.Subscribe("db.pool_size", func(old, new any) { order = append(order, i) })
+		loader.OnChange(func([]Change) { order = append(order, 100+i) })
+	}
+	for i := 0; i < 20; i++ {
+		want = append(want, 100+i)
+	}
+	for i := 0; i < 20; i++ {
+		want = append(want, i)
+	}
+
+	writeFile(t, file, "db:\n  pool_size: 10\n")
+	require.NoError(t, loader.Reload())
+	assert.Equal(t, want, order)
+}
+
+// Test that concurrent changes notify one after another, each starting from
+// the config the previous notification ended with
+func TestLoader_ConcurrentNotificationsSerialized(t *testing.T) {
+	var cfg LoaderTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: StringSource("db:\n  pool_size: 0\n"), Target: &cfg})
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var changes [][2]any
+	loader.Subscribe("db.pool_size", func(old, new any) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, [2]any{old, new})
+	})
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := loader.ApplyOverride("db.pool_size", i, 0)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, changes, 20)
+	assert.Equal(t, 0, changes[0][0])
+	for i := 1; i < len(changes); i++ {
+		assert.Equal(t, changes[i-1][1], changes[i][0])
+	}
+	assert.Equal(t, changes[len(changes)-1][1], loader.Current().(*LoaderTestConfig).DB.PoolSize)
+}
+
+// Test that a failed reload keeps the previous configuration
+func TestLoader_FailedReloadKeepsConfig(t *testing.T) {
+	file := createTempYAML(t, "app:\n  port: 8080\n")
+
+	var cfg LoaderTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(file), Target: &cfg})
+	require.NoError(t, err)
+
+	called := false
+	loader.Subscribe("app.port", func(old, new any) { called = true })
+
+	writeFile(t, file, "app:\n  port: [broken\n")
+	err = loader.Reload()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reload config")
+	assert.False(t, called)
+	assert.Equal(t, 8080, cfg.App.Port)
+	assert.Equal(t, 8080, loader.Current().(*LoaderTestConfig).App.Port)
+}
+
+// Test that values present in Target before the first load act as defaults
+// and that keys removed from the file fall back to them
+func TestLoader_TargetDefaultsSurviveReload(t *testing.T) {
+	file := createTempYAML(t, "app:\n  name: svc\n  port: 9000\ndb:\n  options:\n    a: \"1\"\n")
+
+	cfg := LoaderTestConfig{}
+	cfg.App.Port = 8080
+	cfg.DB.Options = map[string]string{"default": "yes"}
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(file), Target: &cfg})
+	require.NoError(t, err)
+	assert.Equal(t, 9000, cfg.App.Port)
+
+	writeFile(t, file, "app:\n  name: svc\n")
+	require.NoError(t, loader.Reload())
+	assert.Equal(t, 8080, cfg.App.Port)
+	assert.Equal(t, map[string]string{"default": "yes"}, cfg.DB.Options)
+}
+
+// Test that Current returns an independent snapshot
+func TestLoader_CurrentIsSnapshot(t *testing.T) {
+	file := createTempYAML(t, "app:\n  name: one\n")
+
+	var cfg LoaderTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(file), Target: &cfg})
+	require.NoError(t, err)
+
+	snap := loader.Current().(*LoaderTestConfig)
+	writeFile(t, file, "app:\n  name: two\n")
+	require.NoError(t, loader.Reload())
+
+	assert.Equal(t, "one", snap.App.Name)
+	assert.Equal(t, "two", loader.Current().(*LoaderTestConfig).App.Name)
+	assert.NotNil(t, loader.Result())
+}
+
+// Test that Get returns copies of values by path
+func TestLoader_Get(t *testing.T) {
+	file := createTempYAML(t, "app:\n  name: svc\ndb:\n  options:\n    sslmode: disable\n")
+
+	var cfg LoaderTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(file), Target: &cfg})
+	require.NoError(t, err)
+
+	assert.Equal(t, "svc", loader.Get("app.name"))
+	assert.Nil(t, loader.Get("app.missing"))
+
+	options := loader.Get("db.options").(map[string]string)
+	options["sslmode"] = "require"
+	assert.Equal(t, "disable", loader.Get("db.options.sslmode"))
+}
+
+func TestNewLoader_Validation(t *testing.T) {
+	_, err := NewLoader(LoaderOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "target cannot be nil")
+
+	var cfg LoaderTestConfig
+	_, err = NewLoader(LoaderOptions{Target: &cfg})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "BaseSource cannot be nil")
+}
+
+// Test that loads, Current and Get copy configs whose pointers form a cycle
+func TestLoader_PointerCycles(t *testing.T) {
+	type CyclicConfig struct {
+		Name string      `yaml:"name"`
+		Ring *cyclicNode `yaml:"ring"`
+	}
+	cfg := CyclicConfig{Ring: &cyclicNode{Name: "a"}}
+	cfg.Ring.Prev = cfg.Ring
+
+	loader, err := NewLoader(LoaderOptions{BaseSource: StringSource("name: svc\n"), Target: &cfg})
+	require.NoError(t, err)
+	require.NoError(t, loader.Reload())
+
+	current := loader.Current().(*CyclicConfig)
+	assert.Equal(t, "svc", current.Name)
+	assert.Same(t, current.Ring, current.Ring.Prev)
+	ring := loader.Get("ring").(cyclicNode)
+	assert.Equal(t, "a", ring.Name)
+	assert.Same(t, ring.Prev, ring.Prev.Prev)
+	assert.NotSame(t, current.Ring, ring.Prev)
+}
@@ -0,0 +1,31 @@
+package yamlenv
+
+import (
+	"fmt"
+)
+
+// WriteLocalOverride merges the non-zero fields of patch (a struct, usually
+// of the config type, or a map) into the local override file at path,
+// creating it if it doesn't exist. Keys already in the file that patch
+// doesn't set are kept, as are comments; patch values replace existing ones.
+// The file is replaced atomically and keeps its permissions (0644 when new).
+//
+// It lets setup wizards and "config set" commands persist choices without
+// touching the base file:
+//
+//	var patch Config
+//	patch.DB.Host = "dev-db"
+//	err := yamlenv.WriteLocalOverride("config.local.yaml", patch)
+func WriteLocalOverride(path string, patch any) error {
+	doc, err := ReadDocument(path)
+	if err != nil {
+		return fmt.Errorf("write local override: %w", err)
+	}
+	if err := doc.Merge(patch); err != nil {
+		return fmt.Errorf("write local override: %w", err)
+	}
+	if err := doc.WriteFile(path); err != nil {
+		return fmt.Errorf("write local override: %w", err)
+	}
+	return nil
+}
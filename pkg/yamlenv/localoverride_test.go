@@ -0,0 +1,72 @@
+package yamlenv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that only non-zero fields are merged into an existing file, keeping comments
+func TestWriteLocalOverride_Merge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.local.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`# developer overrides
+app:
+  name: mine # keep this
+  debug: true
+db:
+  host: old-db # pinned by setup
+`), 0o640))
+
+	var patch TestConfig
+	patch.DB.Host = "dev-db"
+	patch.DB.Port = 6543
+	patch.Version = "2"
+	require.NoError(t, WriteLocalOverride(path, &patch))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `# developer overrides
+app:
+  name: mine # keep this
+  debug: true
+db:
+  host: dev-db # pinned by setup
+  port: 6543
+version: "2"
+`, string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+
+	// The result loads as a local override
+	var cfg TestConfig
+	require.NoError(t, LoadConfig(LoaderOptions{
+		BaseSource:  FileSource(createTempYAML(t, "db:\n  host: base\n  name: app\n")),
+		LocalSource: FileSource(path),
+		Target:      &cfg,
+	}))
+	assert.Equal(t, "dev-db", cfg.DB.Host)
+	assert.Equal(t, "app", cfg.DB.Name)
+	assert.True(t, cfg.App.Debug)
+}
+
+// Test that a missing file is created from a map patch and invalid input is rejected
+func TestWriteLocalOverride_New(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.local.yaml")
+	require.NoError(t, WriteLocalOverride(path, map[string]any{"app": map[string]any{"port": 3000}}))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "app:\n  port: 3000\n", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+
+	assert.EqualError(t, WriteLocalOverride(path, "port: 1"), "write local override: expected a struct or map, got string")
+
+	require.NoError(t, os.WriteFile(path, []byte("- a\n"), 0o644))
+	err = WriteLocalOverride(path, map[string]any{"a": 1})
+	assert.ErrorContains(t, err, "top level is not a mapping")
+}
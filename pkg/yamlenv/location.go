@@ -0,0 +1,85 @@
+package yamlenv
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	locationType    = reflect.TypeOf(time.Location{})
+	locationPtrType = reflect.TypeOf((*time.Location)(nil))
+)
+
+// loadLocation parses a time zone name like "America/New_York", "UTC" or
+// "Local"; "" means no location. Minimal images without a zoneinfo
+// database need an import of time/tzdata.
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		// time's error only repeats the name
+		return nil, fmt.Errorf("invalid time zone %q, want an IANA name like \"America/New_York\"", name)
+	}
+	return loc, nil
+}
+
+// decodeLocation binds a scalar node into a *time.Location field
+func decodeLocation(n *yaml.Node, v reflect.Value, path string) error {
+	if isUnsetNode(n) {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if n.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: %s: time zone must be a string", n.Line, displayPath(path))
+	}
+	loc, err := loadLocation(n.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %s: %w", n.Line, displayPath(path), err)
+	}
+	v.Set(reflect.ValueOf(loc))
+	return nil
+}
+
+// locationTypes caches hasLocation by type
+var locationTypes sync.Map
+
+// hasLocation reports whether values of type t can hold a *time.Location,
+// which yaml.v3 can't decode, so the decoder has to walk them
+func hasLocation(t reflect.Type) bool {
+	if cached, ok := locationTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	found := findLocation(t, map[reflect.Type]bool{})
+	locationTypes.Store(t, found)
+	return found
+}
+
+func findLocation(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if t == locationPtrType {
+		return true
+	}
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	if t != yamlNodeType && reflect.PointerTo(t).Implements(yamlUnmarshalerType) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return findLocation(t.Elem(), seen)
+	case reflect.Struct:
+		for _, info := range cachedFields(t) {
+			if findLocation(t.Field(info.Index).Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package yamlenv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type LocationTestConfig struct {
+	Schedule struct {
+		Zone  *time.Location            `yaml:"zone"`
+		Zones map[string]*time.Location `yaml:"zones"`
+	} `yaml:"schedule"`
+}
+
+// Test that time zones are read from YAML and env
+func TestLoadConfig_Location(t *testing.T) {
+	setEnvVar(t, "LOC_SCHEDULE__ZONES__EU", "Europe/Berlin")
+
+	var cfg LocationTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: StringSource("schedule:\n  zone: America/New_York\n  zones:\n    us: America/Chicago\n    eu: UTC\n"),
+		EnvPrefix:  "LOC_",
+		Delimiter:  "__",
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, cfg.Schedule.Zone)
+	assert.Equal(t, "America/New_York", cfg.Schedule.Zone.String())
+	assert.Equal(t, "America/Chicago", cfg.Schedule.Zones["us"].String())
+	assert.Equal(t, "Europe/Berlin", cfg.Schedule.Zones["eu"].String())
+	assert.Equal(t, "America/New_York", Flatten(&cfg, ".")["schedule.zone"])
+}
+
+func TestLoadConfig_LocationFromEnv(t *testing.T) {
+	setEnvVar(t, "LOC_SCHEDULE__ZONE", "Asia/Tokyo")
+
+	var cfg LocationTestConfig
+	err := LoadConfig(LoaderOptions{BaseSource: StringSource("schedule: {}\n"), EnvPrefix: "LOC_", Delimiter: "__", Target: &cfg})
+	require.NoError(t, err)
+	assert.Equal(t, "Asia/Tokyo", cfg.Schedule.Zone.String())
+}
+
+// Test that an unknown zone is reported with its name and path
+func TestLoadConfig_InvalidLocation(t *testing.T) {
+	var cfg LocationTestConfig
+	err := LoadConfig(LoaderOptions{BaseSource: StringSource("schedule:\n  zone: America/New_Yrok\n"), Target: &cfg})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "schedule.zone")
+	assert.Contains(t, err.Error(), `invalid time zone "America/New_Yrok"`)
+
+	setEnvVar(t, "LOC_SCHEDULE__ZONE", "Mars/Olympus")
+	err = LoadConfig(LoaderOptions{BaseSource: StringSource("schedule: {}\n"), EnvPrefix: "LOC_", Delimiter: "__", Target: &cfg})
+	var envErr *EnvError
+	require.ErrorAs(t, err, &envErr)
+	assert.Equal(t, "LOC_SCHEDULE__ZONE", envErr.Var)
+	assert.Contains(t, err.Error(), `invalid time zone "Mars/Olympus"`)
+}
@@ -0,0 +1,26 @@
+package yamlenv
+
+import (
+	"log"
+	"os"
+)
+
+// Logger receives the loader's diagnostic output, such as DebugKeys lines.
+// *log.Logger implements it; adapt slog or zap with a small wrapper. Loads
+// running concurrently call it from several goroutines, so it must be safe
+// for concurrent use.
+type Logger interface {
+	Printf(format string, args ...any)
+}
+
+// stdoutLogger is used when LoaderOptions.Logger is nil. A log.Logger
+// writes each line with a single call, so lines of concurrent loads don't
+// interleave.
+var stdoutLogger Logger = log.New(os.Stdout, "", 0)
+
+func loggerOrDefault(logger Logger) Logger {
+	if logger == nil {
+		return stdoutLogger
+	}
+	return logger
+}
@@ -0,0 +1,168 @@
+package yamlenv
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// entries applies the variables that name entries of the map at path, like
+// MYAPP_ENDPOINTS__BILLING__URL for map[string]Endpoint. Variable names hold
+// keys in upper case: an existing key matches regardless of case, and a new
+// key is added in lower case. A delimiter inside a key is written twice, so
+// MYAPP_ROUTES__EU____WEST__URL sets routes["eu__west"].url. New struct
+// entries get their SetDefaults defaults and are added only if a variable
+// sets something in them. Entries inherit the map field's secrecy,
+// envinvalid handling and description from parent.
+func (b *envBinder) entries(field reflect.Value, parent fieldInfo, path string, depth int, visiting map[uintptr]bool) error {
+	if !field.CanSet() || field.Type().Key().Kind() != reflect.String {
+		return nil
+	}
+	keys := b.keysBelow(path, field)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	elemType := field.Type().Elem()
+	info := fieldInfo{
+		Nested:     isNestedStruct(elemType),
+		NestedPtr:  elemType.Kind() == reflect.Ptr && isNestedStruct(elemType.Elem()),
+		Secret:     parent.Secret,
+		WarnBadEnv: parent.WarnBadEnv,
+		Desc:       parent.Desc,
+	}
+	for _, key := range keys {
+		mapKey := reflect.ValueOf(key.name).Convert(field.Type().Key())
+		// Map elements aren't addressable, so work on a copy
+		elem := reflect.New(elemType).Elem()
+		existing := field.MapIndex(mapKey)
+		if existing.IsValid() {
+			elem.Set(existing)
+		} else if elemType.Kind() == reflect.Struct {
+			applyDefaults(elem.Addr(), nil)
+		}
+
+		entryPath := path + "." + key.name
+		if key.escaped {
+			b.escapes[entryPath] = key.varName
+		}
+		applied := len(b.applied)
+		if err := b.field(elem, info, entryPath, depth, visiting); err != nil {
+			return err
+		}
+		if len(b.applied) == applied && !existing.IsValid() {
+			continue
+		}
+		if field.IsNil() {
+			field.Set(reflect.MakeMap(field.Type()))
+		}
+		field.SetMapIndex(mapKey, elem)
+	}
+	return nil
+}
+
+// mapKey is a map key named by an environment variable
+type mapKey struct {
+	name    string // key in the map
+	varName string // variable name prefix up to and including the key
+	escaped bool   // true if the key contains the delimiter or "."
+}
+
+// keysBelow returns the map keys that variables name right below path,
+// sorted by name
+func (b *envBinder) keysBelow(path string, field reflect.Value) []mapKey {
+	sep := b.delimiter
+	if sep == "" {
+		sep = "."
+	}
+	parent := b.varName(path)
+
+	existing := map[string]string{} // encoded key -> key
+	for _, key := range field.MapKeys() {
+		name := key.String()
+		existing[strings.ToUpper(b.encodeKey(name, sep))] = name
+	}
+
+	seen := map[string]bool{}
+	var keys []mapKey
+	for _, name := range b.environNames() {
+		rest, ok := b.cutVarPrefix(name, parent+sep)
+		if !ok {
+			continue
+		}
+		encoded, decoded := cutKey(rest, sep)
+		if encoded == "" {
+			continue
+		}
+		key, ok := existing[strings.ToUpper(encoded)]
+		if !ok {
+			key = strings.ToLower(decoded)
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, mapKey{
+			name:    key,
+			varName: parent + sep + encoded,
+			escaped: strings.Contains(key, sep) || strings.Contains(key, "."),
+		})
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].name < keys[j].name })
+	return keys
+}
+
+// encodeKey returns how a map key appears in variable names
+func (b *envBinder) encodeKey(key, sep string) string {
+	key = strings.ReplaceAll(key, sep, sep+sep)
+	key = strings.ReplaceAll(key, ".", sep+sep)
+	if b.normalizeDash {
+		key = strings.ReplaceAll(key, "-", "_")
+	}
+	return strings.ToUpper(key)
+}
+
+// cutKey returns the leading key of rest as it appears in the variable name
+// and with doubled delimiters unescaped
+func cutKey(rest, sep string) (encoded, decoded string) {
+	var key strings.Builder
+	i := 0
+	for i < len(rest) {
+		if strings.HasPrefix(rest[i:], sep+sep) {
+			key.WriteString(sep)
+			i += 2 * len(sep)
+			continue
+		}
+		if strings.HasPrefix(rest[i:], sep) {
+			break
+		}
+		key.WriteByte(rest[i])
+		i++
+	}
+	return rest[:i], key.String()
+}
+
+// escapedName returns the variable name for a path at or below a map entry
+// whose key contains the delimiter, which varName can't derive from the
+// dotted path
+func (b *envBinder) escapedName(path string) (string, bool) {
+	// The innermost entry's name already includes the ones above it
+	var entry string
+	for e := range b.escapes {
+		if (path == e || strings.HasPrefix(path, e+".")) && len(e) > len(entry) {
+			entry = e
+		}
+	}
+	if entry == "" {
+		return "", false
+	}
+	name := b.escapes[entry]
+	if path == entry {
+		return name, true
+	}
+	sep := b.delimiter
+	if sep == "" {
+		sep = "."
+	}
+	return name + sep + strings.TrimPrefix(b.varName(path[len(entry)+1:]), b.prefix), true
+}
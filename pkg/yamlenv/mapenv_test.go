@@ -0,0 +1,123 @@
+package yamlenv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Endpoint struct {
+	URL     string        `yaml:"url"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+func (e *Endpoint) SetDefaults() {
+	e.Timeout = time.Second
+}
+
+type MapEnvConfig struct {
+	Endpoints map[string]Endpoint  `yaml:"endpoints"`
+	Backends  map[string]*Endpoint `yaml:"backends"`
+	Labels    map[string]string    `yaml:"labels"`
+}
+
+const mapEnvYAML = `
+endpoints:
+  billing:
+    url: http://billing
+    timeout: 5s
+  Search:
+    url: http://search
+labels:
+  team: core
+`
+
+// Test that variables with a key override entries of a map
+func TestEnvMapKey_OverridesEntries(t *testing.T) {
+	t.Setenv("MAP_ENDPOINTS__BILLING__URL", "http://billing.pod")
+	t.Setenv("MAP_ENDPOINTS__SEARCH__TIMEOUT", "2s")
+	t.Setenv("MAP_LABELS__TEAM", "edge")
+
+	var cfg MapEnvConfig
+	result, err := Load(LoaderOptions{BaseSource: StringSource(mapEnvYAML), EnvPrefix: "MAP_", Delimiter: "__", Target: &cfg})
+
+	require.NoError(t, err)
+	assert.Equal(t, Endpoint{URL: "http://billing.pod", Timeout: 5 * time.Second}, cfg.Endpoints["billing"])
+	assert.Equal(t, Endpoint{URL: "http://search", Timeout: 2 * time.Second}, cfg.Endpoints["Search"], "existing keys match regardless of case")
+	assert.Equal(t, map[string]string{"team": "edge"}, cfg.Labels)
+	assert.Equal(t, "env:MAP_ENDPOINTS__BILLING__URL", result.Sources["endpoints.billing.url"])
+}
+
+// Test that variables add new entries, and only when they set something
+func TestEnvMapKey_AddsEntries(t *testing.T) {
+	t.Setenv("MAP_ENDPOINTS__AUDIT__URL", "http://audit")
+	t.Setenv("MAP_ENDPOINTS__TYPO__URLL", "http://typo")
+	t.Setenv("MAP_BACKENDS__PRIMARY__URL", "http://primary")
+	t.Setenv("MAP_LABELS__ZONE", "a")
+
+	var cfg MapEnvConfig
+	err := LoadConfig(LoaderOptions{BaseSource: StringSource("{}"), EnvPrefix: "MAP_", Delimiter: "__", Target: &cfg})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Endpoint{"audit": {URL: "http://audit", Timeout: time.Second}}, cfg.Endpoints)
+	assert.Equal(t, map[string]*Endpoint{"primary": {URL: "http://primary", Timeout: time.Second}}, cfg.Backends)
+	assert.Equal(t, map[string]string{"zone": "a"}, cfg.Labels)
+}
+
+// Test that a doubled delimiter stands for a delimiter or dot in a key
+func TestEnvMapKey_Escaping(t *testing.T) {
+	t.Setenv("MAP_ENDPOINTS__EU____WEST__URL", "http://eu-west")
+	t.Setenv("MAP_ENDPOINTS__API____V1__URL", "http://api-v1")
+
+	var cfg MapEnvConfig
+	result, err := Load(LoaderOptions{
+		BaseSource: StringSource("endpoints:\n  api.v1:\n    url: http://old\n"),
+		EnvPrefix:  "MAP_",
+		Delimiter:  "__",
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "http://eu-west", cfg.Endpoints["eu__west"].URL)
+	assert.Equal(t, "http://api-v1", cfg.Endpoints["api.v1"].URL)
+	assert.Equal(t, "env:MAP_ENDPOINTS__EU____WEST__URL", result.Sources["endpoints.eu__west.url"])
+}
+
+// Test that entries inherit the map field's secret, envinvalid and desc tags
+func TestEnvMapKey_InheritsTags(t *testing.T) {
+	type tagged struct {
+		Keys   map[string]int `yaml:"keys" secret:"true" desc:"signing keys"`
+		Limits map[string]int `yaml:"limits" envinvalid:"warn"`
+	}
+	t.Setenv("MAPTAG_KEYS__PRIMARY", "s3cr3t")
+	var cfg tagged
+	err := LoadConfig(LoaderOptions{BaseSource: StringSource("keys:\n  primary: 1\n"), EnvPrefix: "MAPTAG_", Delimiter: "__", Target: &cfg})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `set field keys.primary (signing keys) from MAPTAG_KEYS__PRIMARY="***" (int)`)
+	assert.NotContains(t, err.Error(), "s3cr3t")
+
+	t.Setenv("MAPTAG_KEYS__PRIMARY", "7")
+	t.Setenv("MAPTAG_LIMITS__CPU", "lots")
+	logger := &recordingLogger{}
+	require.NoError(t, LoadConfig(LoaderOptions{BaseSource: StringSource("limits:\n  cpu: 2\n"), EnvPrefix: "MAPTAG_", Delimiter: "__", Logger: logger, Target: &cfg}))
+	assert.Equal(t, map[string]int{"cpu": 2}, cfg.Limits)
+	require.Len(t, logger.lines, 1)
+	assert.Contains(t, logger.lines[0], `ignoring invalid override: set field limits.cpu from MAPTAG_LIMITS__CPU="lots"`)
+}
+
+func TestCutKey(t *testing.T) {
+	tests := []struct{ rest, encoded, decoded string }{
+		{"BILLING__URL", "BILLING", "BILLING"},
+		{"BILLING", "BILLING", "BILLING"},
+		{"EU____WEST__URL", "EU____WEST", "EU__WEST"},
+		{"A______B", "A____", "A__"},
+		{"__URL", "", ""},
+	}
+	for _, tt := range tests {
+		encoded, decoded := cutKey(tt.rest, "__")
+		assert.Equal(t, tt.encoded, encoded, tt.rest)
+		assert.Equal(t, tt.decoded, decoded, tt.rest)
+	}
+}
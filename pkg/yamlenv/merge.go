@@ -0,0 +1,269 @@
+package yamlenv
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MergeStrategy controls how lists are combined when several layers define the same key
+type MergeStrategy int
+
+const (
+	// MergeReplace lets the later layer's list replace the earlier one (default)
+	MergeReplace MergeStrategy = iota
+	// MergeAppend appends the later layer's elements to the earlier list
+	MergeAppend
+	// MergeByKey merges list elements that share the same key field (see ArrayMergeKeys)
+	// and appends the rest, similar to Kubernetes named-list merging
+	MergeByKey
+)
+
+// defaultArrayMergeKeys are the element fields MergeByKey matches on when none are configured
+var defaultArrayMergeKeys = []string{"name", "id"}
+
+// String returns the name of the merge strategy
+func (s MergeStrategy) String() string {
+	switch s {
+	case MergeReplace:
+		return "replace"
+	case MergeAppend:
+		return "append"
+	case MergeByKey:
+		return "merge-by-key"
+	default:
+		return fmt.Sprintf("MergeStrategy(%d)", int(s))
+	}
+}
+
+// configLayer is a parsed configuration source waiting to be merged
+type configLayer struct {
+	name  string     // layer name used in provenance, e.g. "base" or "local"
+	label string     // label of a Named source; "" if unnamed
+	node  *yaml.Node // root content node; nil for an empty document
+}
+
+// merger merges parsed YAML layers according to the loader options
+type merger struct {
+	arrays    MergeStrategy
+	arrayKeys []string
+	reresolve bool // resolve aliases after merging instead of within each layer
+}
+
+func newMerger(opts LoaderOptions) *merger {
+	keys := opts.ArrayMergeKeys
+	if len(keys) == 0 {
+		keys = defaultArrayMergeKeys
+	}
+	return &merger{arrays: opts.ArrayMerge, arrayKeys: keys, reresolve: opts.ResolveAliasesAfterMerge}
+}
+
+// mergeLayers folds all layers, in order, into a single tree. Aliases and
+// merge keys are resolved within each layer first, or after merging when
+// reresolve is set.
+func (m *merger) mergeLayers(layers []configLayer) *yaml.Node {
+	var merged *yaml.Node
+	if !m.reresolve {
+		for _, layer := range layers {
+			merged = m.merge(merged, resolveDocument(layer.node))
+		}
+		return merged
+	}
+
+	aliases := newDeferredAliases()
+	for _, layer := range layers {
+		merged = m.merge(merged, aliases.deferLayer(layer.node, ""))
+	}
+	return aliases.resolve(merged, merged, map[string]bool{})
+}
+
+// merge combines src on top of dst without modifying either input
+func (m *merger) merge(dst, src *yaml.Node) *yaml.Node {
+	dst, src = resolveAlias(dst), resolveAlias(src)
+	if src == nil || isUnsetNode(src) {
+		// An empty or null document has no opinion on the value
+		return dst
+	}
+	if src.Tag == replaceTag {
+		// A list computed by a patch layer is already the final list
+		out := copyNode(src)
+		out.Tag = "!!seq"
+		return out
+	}
+	if dst == nil {
+		if src.Kind != yaml.MappingNode {
+			return src
+		}
+		// Merge into an empty mapping so nested null/!unset markers are dropped
+		dst = copyNode(src)
+		dst.Content = nil
+	}
+
+	switch {
+	case dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode:
+		return m.mergeMappings(dst, src)
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode:
+		return m.mergeSequences(dst, src)
+	case dst.Tag == aliasTag && src.Kind == yaml.MappingNode:
+		// The alias isn't resolved yet; keep it as a merge key so its values
+		// still apply below the keys src sets
+		out := m.merge(nil, src)
+		mergeKey := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!merge", Value: "<<"}
+		out.Content = append([]*yaml.Node{mergeKey, dst}, out.Content...)
+		return out
+	default:
+		return src
+	}
+}
+
+// mergeMappings merges src keys into a copy of dst, recursing into shared keys
+func (m *merger) mergeMappings(dst, src *yaml.Node) *yaml.Node {
+	out := copyNode(dst)
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		idx := mappingIndex(out, key.Value)
+		if isUnsetNode(resolveAlias(value)) {
+			// "key: null" or "key: !unset" removes the key set by earlier layers
+			if idx >= 0 {
+				out.Content = append(out.Content[:idx], out.Content[idx+2:]...)
+			}
+			continue
+		}
+		if idx >= 0 {
+			out.Content[idx+1] = m.merge(out.Content[idx+1], value)
+			continue
+		}
+		out.Content = append(out.Content, key, m.merge(nil, value))
+	}
+	return out
+}
+
+// mergeSequences combines two lists according to the configured strategy
+func (m *merger) mergeSequences(dst, src *yaml.Node) *yaml.Node {
+	switch m.arrays {
+	case MergeAppend:
+		out := copyNode(dst)
+		out.Content = append(out.Content, src.Content...)
+		return out
+	case MergeByKey:
+		out := copyNode(dst)
+		for _, elem := range src.Content {
+			key, value, ok := m.elementKey(elem)
+			if !ok {
+				out.Content = append(out.Content, elem)
+				continue
+			}
+			matched := false
+			for i, existing := range out.Content {
+				if k, v, ok := m.elementKey(existing); ok && k == key && v == value {
+					out.Content[i] = m.merge(existing, elem)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				out.Content = append(out.Content, elem)
+			}
+		}
+		return out
+	default:
+		return src
+	}
+}
+
+// elementKey returns the first configured key field present on a list element
+func (m *merger) elementKey(elem *yaml.Node) (string, string, bool) {
+	elem = resolveAlias(elem)
+	if elem == nil || elem.Kind != yaml.MappingNode {
+		return "", "", false
+	}
+	for _, key := range m.arrayKeys {
+		if idx := mappingIndex(elem, key); idx >= 0 {
+			value := resolveAlias(elem.Content[idx+1])
+			if value.Kind == yaml.ScalarNode {
+				return key, value.Value, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// mappingIndex returns the index of key within a mapping node's content, or -1
+func mappingIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// flattenMapping returns the key/value content of a mapping node with "<<"
+// merge keys expanded. Explicit keys win over merged ones, and earlier merge
+// sources win over later ones, as in the YAML merge key spec.
+func flattenMapping(n *yaml.Node) []*yaml.Node {
+	var explicit, merges []*yaml.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == "<<" && n.Content[i].ShortTag() == "!!merge" {
+			merges = append(merges, n.Content[i+1])
+			continue
+		}
+		explicit = append(explicit, n.Content[i], n.Content[i+1])
+	}
+	if len(merges) == 0 {
+		return n.Content
+	}
+
+	out := &yaml.Node{Kind: yaml.MappingNode, Content: explicit}
+	add := func(src *yaml.Node) {
+		src = resolveAlias(src)
+		if src == nil || src.Kind != yaml.MappingNode {
+			return
+		}
+		pairs := flattenMapping(src)
+		for i := 0; i+1 < len(pairs); i += 2 {
+			if mappingIndex(out, pairs[i].Value) < 0 {
+				out.Content = append(out.Content, pairs[i], pairs[i+1])
+			}
+		}
+	}
+	for _, m := range merges {
+		if m = resolveAlias(m); m != nil && m.Kind == yaml.SequenceNode {
+			for _, elem := range m.Content {
+				add(elem)
+			}
+			continue
+		}
+		add(m)
+	}
+	return out.Content
+}
+
+// copyNode returns a shallow copy of n with its own Content slice
+func copyNode(n *yaml.Node) *yaml.Node {
+	out := *n
+	out.Content = append([]*yaml.Node(nil), n.Content...)
+	return &out
+}
+
+// resolveAlias follows alias nodes to the node they reference
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// unsetTag is the YAML tag that explicitly removes a key set by an earlier layer
+const unsetTag = "!unset"
+
+// isUnsetNode reports whether n is an explicit YAML null or tagged !unset
+func isUnsetNode(n *yaml.Node) bool {
+	if n == nil {
+		return false
+	}
+	if n.Tag == unsetTag {
+		return true
+	}
+	return n.Kind == yaml.ScalarNode && n.ShortTag() == "!!null"
+}
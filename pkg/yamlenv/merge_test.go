@@ -0,0 +1,205 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type MergeTestConfig struct {
+	Servers []struct {
+		Name string `yaml:"name"`
+		Host string `yaml:"host"`
+		Port int    `yaml:"port"`
+	} `yaml:"servers"`
+	Tags []string `yaml:"tags"`
+}
+
+const mergeBaseYAML = `
+servers:
+  - name: api
+    host: api.internal
+    port: 8080
+  - name: web
+    host: web.internal
+    port: 80
+tags: [a, b]
+`
+
+const mergeLocalYAML = `
+servers:
+  - name: web
+    port: 8081
+  - name: admin
+    host: admin.internal
+    port: 9000
+tags: [c]
+`
+
+func loadMergeTest(t *testing.T, opts LoaderOptions) MergeTestConfig {
+	var cfg MergeTestConfig
+	opts.BaseSource = ReaderSource(strings.NewReader(mergeBaseYAML))
+	opts.LocalSource = ReaderSource(strings.NewReader(mergeLocalYAML))
+	opts.Target = &cfg
+	require.NoError(t, LoadConfig(opts))
+	return cfg
+}
+
+// Test that lists are replaced by the later layer by default
+func TestLoadConfig_ArrayMergeReplace(t *testing.T) {
+	cfg := loadMergeTest(t, LoaderOptions{})
+
+	require.Len(t, cfg.Servers, 2)
+	assert.Equal(t, "web", cfg.Servers[0].Name)
+	assert.Equal(t, "", cfg.Servers[0].Host)
+	assert.Equal(t, 8081, cfg.Servers[0].Port)
+	assert.Equal(t, "admin", cfg.Servers[1].Name)
+	assert.Equal(t, []string{"c"}, cfg.Tags)
+}
+
+// Test that MergeAppend concatenates lists across layers
+func TestLoadConfig_ArrayMergeAppend(t *testing.T) {
+	cfg := loadMergeTest(t, LoaderOptions{ArrayMerge: MergeAppend})
+
+	require.Len(t, cfg.Servers, 4)
+	assert.Equal(t, "api", cfg.Servers[0].Name)
+	assert.Equal(t, "web", cfg.Servers[1].Name)
+	assert.Equal(t, "web", cfg.Servers[2].Name)
+	assert.Equal(t, "admin", cfg.Servers[3].Name)
+	assert.Equal(t, []string{"a", "b", "c"}, cfg.Tags)
+}
+
+// Test that MergeByKey merges named elements and appends new ones
+func TestLoadConfig_ArrayMergeByKey(t *testing.T) {
+	cfg := loadMergeTest(t, LoaderOptions{ArrayMerge: MergeByKey})
+
+	require.Len(t, cfg.Servers, 3)
+	assert.Equal(t, "api", cfg.Servers[0].Name)
+	assert.Equal(t, 8080, cfg.Servers[0].Port)
+
+	// web keeps its base host and picks up the local port
+	assert.Equal(t, "web", cfg.Servers[1].Name)
+	assert.Equal(t, "web.internal", cfg.Servers[1].Host)
+	assert.Equal(t, 8081, cfg.Servers[1].Port)
+
+	assert.Equal(t, "admin", cfg.Servers[2].Name)
+	assert.Equal(t, "admin.internal", cfg.Servers[2].Host)
+
+	// Scalar lists have no key field, so elements are appended
+	assert.Equal(t, []string{"a", "b", "c"}, cfg.Tags)
+}
+
+// Test that MergeByKey can match on a custom key field
+func TestLoadConfig_ArrayMergeByCustomKey(t *testing.T) {
+	type RulesConfig struct {
+		Rules []struct {
+			Match  string `yaml:"match"`
+			Action string `yaml:"action"`
+		} `yaml:"rules"`
+	}
+
+	var cfg RulesConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:     ReaderSource(strings.NewReader("rules:\n  - match: /api\n    action: allow\n  - match: /admin\n    action: allow\n")),
+		LocalSource:    ReaderSource(strings.NewReader("rules:\n  - match: /admin\n    action: deny\n")),
+		ArrayMerge:     MergeByKey,
+		ArrayMergeKeys: []string{"match"},
+		Target:         &cfg,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, cfg.Rules, 2)
+	assert.Equal(t, "allow", cfg.Rules[0].Action)
+	assert.Equal(t, "/admin", cfg.Rules[1].Match)
+	assert.Equal(t, "deny", cfg.Rules[1].Action)
+}
+
+// Test that merging does not mutate the parsed base layer
+func TestMerger_DoesNotMutateInputs(t *testing.T) {
+	base, err := loadNodeFromSource(ReaderSource(strings.NewReader("app:\n  name: base\n")), parseLimits{})
+	require.NoError(t, err)
+	local, err := loadNodeFromSource(ReaderSource(strings.NewReader("app:\n  port: 1\n")), parseLimits{})
+	require.NoError(t, err)
+
+	m := newMerger(LoaderOptions{})
+	merged := m.mergeLayers([]configLayer{{name: "base", node: base}, {name: "local", node: local}})
+
+	require.NotNil(t, merged)
+	assert.Len(t, base.Content[1].Content, 2)
+	assert.Len(t, merged.Content[1].Content, 4)
+}
+
+func TestMergeStrategy_String(t *testing.T) {
+	assert.Equal(t, "replace", MergeReplace.String())
+	assert.Equal(t, "append", MergeAppend.String())
+	assert.Equal(t, "merge-by-key", MergeByKey.String())
+	assert.Equal(t, "MergeStrategy(9)", MergeStrategy(9).String())
+}
+
+// Test that "key: null" in the local layer removes a value set by base
+func TestLoadConfig_NullDeletesKey(t *testing.T) {
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader("app:\n  name: base\n  port: 8080\nversion: \"1.0\"\n")),
+		LocalSource: ReaderSource(strings.NewReader("app:\n  name: null\nversion: ~\n")),
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "", cfg.App.Name)
+	assert.Equal(t, 8080, cfg.App.Port)
+	assert.Equal(t, "", cfg.Version)
+}
+
+// Test that the !unset tag removes whole sections set by base
+func TestLoadConfig_UnsetTagDeletesSection(t *testing.T) {
+	type SectionConfig struct {
+		Cache map[string]string `yaml:"cache"`
+		Name  string            `yaml:"name"`
+	}
+
+	var cfg SectionConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader("cache:\n  driver: redis\n  addr: localhost:6379\nname: base\n")),
+		LocalSource: ReaderSource(strings.NewReader("cache: !unset\nname: !unset\n")),
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Nil(t, cfg.Cache)
+	assert.Equal(t, "", cfg.Name)
+}
+
+// Test that unsetting a key that no earlier layer set is a no-op
+func TestLoadConfig_UnsetMissingKey(t *testing.T) {
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader("app:\n  name: base\n")),
+		LocalSource: ReaderSource(strings.NewReader("db:\n  host: !unset\n  port: 5432\n")),
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "base", cfg.App.Name)
+	assert.Equal(t, "", cfg.DB.Host)
+	assert.Equal(t, 5432, cfg.DB.Port)
+}
+
+// Test that env overrides still apply to keys removed by the local layer
+func TestLoadConfig_UnsetThenEnvOverride(t *testing.T) {
+	setEnvVar(t, "UNSET_APP__NAME", "fromenv")
+
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader("app:\n  name: base\n")),
+		LocalSource: ReaderSource(strings.NewReader("app:\n  name: !unset\n")),
+		EnvPrefix:   "UNSET_",
+		Delimiter:   "__",
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "fromenv", cfg.App.Name)
+}
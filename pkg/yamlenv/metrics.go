@@ -0,0 +1,84 @@
+package yamlenv
+
+import (
+	"errors"
+	"io/fs"
+	"time"
+)
+
+// Metrics receives the timing and outcome of loads, to be exported to
+// Prometheus or a similar system. Methods are called synchronously while
+// loading, SourceFetched from several goroutines at once when sources are
+// fetched concurrently, and must be safe for concurrent use.
+type Metrics interface {
+	// SourceFetched reports reading and parsing one source, by layer name
+	// ("base", "local", "secrets", "runtime" or "patch:<index>"). A missing
+	// optional local source is reported as a success.
+	SourceFetched(layer string, d time.Duration, err error)
+
+	// Loaded reports a complete load: Load and LoadConfig, a Loader's
+	// initial load and every reload, including failed ones
+	Loaded(d time.Duration, err error)
+}
+
+// LoaderStats summarizes a Loader's loads
+type LoaderStats struct {
+	Loads       int       // loads attempted, including the initial one
+	Failures    int       // loads that failed and left the previous config in place
+	LastSuccess time.Time // time of the latest successful load
+	LastFailure time.Time // time of the latest failed load; zero if none failed
+	LastError   error     // error of the latest failed load; nil if none failed
+}
+
+// Stats returns counters and timestamps of the loader's loads, so callers
+// can alert when reloads start failing while the last good config is served
+func (l *Loader) Stats() LoaderStats {
+	l.statsMu.Lock()
+	defer l.statsMu.Unlock()
+	return l.stats
+}
+
+// recordLoad updates the loader's stats and reports the load to Metrics
+func (l *Loader) recordLoad(start time.Time, err error) {
+	now := time.Now()
+	l.statsMu.Lock()
+	l.stats.Loads++
+	if err != nil {
+		l.stats.Failures++
+		l.stats.LastFailure, l.stats.LastError = now, err
+	} else {
+		l.stats.LastSuccess = now
+	}
+	l.statsMu.Unlock()
+
+	if l.opts.Metrics != nil {
+		l.opts.Metrics.Loaded(now.Sub(start), err)
+	}
+}
+
+// fetchNode loads the source of job like loadConditionalNode, records the
+// outcome in job and reports the fetch to opts.Metrics
+func fetchNode(opts LoaderOptions, job *fetchJob) {
+	start := time.Now()
+	layer := job.layer
+	source := captureStale(captureLabel(job.source, &job.label), &job.warning)
+	var endParse func(error)
+	if opts.Tracer != nil {
+		source = traceSource(opts, layer, source, &endParse)
+	}
+	if opts.Template {
+		source = templateSource(opts, layer, source)
+	}
+	node, err := loadConditionalNode(opts, source)
+	if endParse != nil {
+		endParse(err)
+	}
+	if opts.Metrics != nil {
+		reported := err
+		if layer == "local" && errors.Is(err, fs.ErrNotExist) && !opts.LocalRequired {
+			reported = nil
+		}
+		opts.Metrics.SourceFetched(layer, time.Since(start), reported)
+	}
+	job.node, job.err = node, err
+}
@@ -0,0 +1,92 @@
+package yamlenv
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingMetrics records the events it receives
+type recordingMetrics struct {
+	mu      sync.Mutex
+	fetches []string
+	loads   []error
+}
+
+func (m *recordingMetrics) SourceFetched(layer string, d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	event := layer + " ok"
+	if err != nil {
+		event = layer + " failed"
+	}
+	m.fetches = append(m.fetches, event)
+}
+
+func (m *recordingMetrics) Loaded(d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.loads = append(m.loads, err)
+}
+
+// Test that Load reports each source fetch and the load itself
+func TestLoad_Metrics(t *testing.T) {
+	metrics := &recordingMetrics{}
+	var cfg TestConfig
+	_, err := Load(LoaderOptions{
+		BaseSource:    ReaderSource(strings.NewReader("app:\n  name: x\n")),
+		LocalSource:   FileSource(filepath.Join(t.TempDir(), "missing.yaml")),
+		SecretsSource: ReaderSource(strings.NewReader("")),
+		Target:        &cfg,
+		Metrics:       metrics,
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"base ok", "local ok", "secrets ok"}, metrics.fetches)
+	assert.Equal(t, []error{nil}, metrics.loads)
+
+	metrics = &recordingMetrics{}
+	_, err = Load(LoaderOptions{
+		BaseSource: func() (io.ReadCloser, error) { return nil, errors.New("remote down") },
+		Target:     &cfg,
+		Metrics:    metrics,
+	})
+	require.Error(t, err)
+	assert.Equal(t, []string{"base failed"}, metrics.fetches)
+	require.Len(t, metrics.loads, 1)
+	assert.ErrorContains(t, metrics.loads[0], "remote down")
+}
+
+// Test that a Loader counts reloads and records the latest success and failure
+func TestLoader_Stats(t *testing.T) {
+	path := createTempYAML(t, "app:\n  port: 1\n")
+	metrics := &recordingMetrics{}
+	var cfg LoaderTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(path), Target: &cfg, Metrics: metrics})
+	require.NoError(t, err)
+
+	stats := loader.Stats()
+	assert.Equal(t, 1, stats.Loads)
+	assert.Zero(t, stats.Failures)
+	assert.False(t, stats.LastSuccess.IsZero())
+	assert.True(t, stats.LastFailure.IsZero())
+
+	require.NoError(t, os.WriteFile(path, []byte("app: ["), 0o644))
+	require.Error(t, loader.Reload())
+
+	stats = loader.Stats()
+	assert.Equal(t, 2, stats.Loads)
+	assert.Equal(t, 1, stats.Failures)
+	assert.False(t, stats.LastFailure.Before(stats.LastSuccess))
+	assert.ErrorContains(t, stats.LastError, "load base config")
+	require.Len(t, metrics.loads, 2)
+	assert.NoError(t, metrics.loads[0])
+	assert.Error(t, metrics.loads[1])
+}
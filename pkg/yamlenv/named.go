@@ -0,0 +1,88 @@
+package yamlenv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Named labels source for people reading load errors, warnings,
+// LoadResult.Labels and Explain output, which matters once sources are
+// readers, embeds and remote URLs rather than file paths. The layer the
+// source is used for, and its name in LoadResult.Sources, don't change.
+//
+//	BaseSource: yamlenv.Named("s3://configs/app.yaml", remote),
+func Named(label string, source ConfigSource) ConfigSource {
+	return func() (io.ReadCloser, error) {
+		rc, err := source()
+		if err != nil {
+			return nil, &labeledError{label: label, err: err}
+		}
+		return labeledReader{ReadCloser: rc, label: label}, nil
+	}
+}
+
+// labeledReader carries the label of a Named source to the loader
+type labeledReader struct {
+	io.ReadCloser
+	label string
+}
+
+// labeledError carries the label of a Named source that failed to open
+type labeledError struct {
+	label string
+	err   error
+}
+
+func (e *labeledError) Error() string { return e.err.Error() }
+func (e *labeledError) Unwrap() error { return e.err }
+
+// captureLabel returns source, recording the label of a Named source in
+// label when it is opened
+func captureLabel(source ConfigSource, label *string) ConfigSource {
+	return func() (io.ReadCloser, error) {
+		rc, err := source()
+		var labeled *labeledError
+		if r, ok := rc.(labeledReader); ok {
+			*label = r.label
+		} else if errors.As(err, &labeled) {
+			*label = labeled.label
+		}
+		return rc, err
+	}
+}
+
+// layerTitle names a layer in messages: "base", or "base (label)" for a
+// Named source
+func layerTitle(name, label string) string {
+	if label == name {
+		return name
+	}
+	return describe(name, label)
+}
+
+// layerLabels maps the names of layers read from Named sources to their
+// labels
+func layerLabels(layers []configLayer) map[string]string {
+	labels := map[string]string{}
+	for _, layer := range layers {
+		if layer.label != "" {
+			labels[layer.name] = layer.label
+		}
+	}
+	return labels
+}
+
+// title names the layer in messages
+func (l configLayer) title() string {
+	return layerTitle(l.name, l.label)
+}
+
+// loadError reports a layer that couldn't be read or decoded
+func (l configLayer) loadError(err error) error {
+	what := l.name + " config"
+	if l.label != "" && l.label != l.name {
+		what += " (" + l.label + ")"
+	}
+	return fmt.Errorf("load %s: %w", what, err)
+}
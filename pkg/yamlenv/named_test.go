@@ -0,0 +1,70 @@
+package yamlenv
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that labels of Named sources appear in errors for sources that
+// fail to open or parse
+func TestNamed_Errors(t *testing.T) {
+	failing := Named("s3://configs/app.yaml", func() (io.ReadCloser, error) {
+		return nil, errors.New("access denied")
+	})
+	err := LoadConfig(LoaderOptions{BaseSource: failing, Target: &TestConfig{}})
+	require.Error(t, err)
+	assert.Equal(t, "load base config (s3://configs/app.yaml): open config source: access denied", err.Error())
+
+	err = LoadConfig(LoaderOptions{
+		BaseSource:  StringSource("app:\n  name: x\n"),
+		LocalSource: Named("team overrides", StringSource("app: [oops\n")),
+		Target:      &TestConfig{},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "load local config (team overrides): ")
+
+	err = LoadConfig(LoaderOptions{
+		BaseSource:  StringSource("app:\n  port: 80\n"),
+		LocalSource: Named("team overrides", StringSource("app:\n  port: eighty\n")),
+		Target:      &TestConfig{},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "load local config (team overrides): ")
+}
+
+// Test that a missing Named local source is still skipped
+func TestNamed_MissingLocal(t *testing.T) {
+	missing := Named("local file", func() (io.ReadCloser, error) { return nil, fs.ErrNotExist })
+	err := LoadConfig(LoaderOptions{BaseSource: StringSource("{}"), LocalSource: missing, Target: &TestConfig{}})
+	require.NoError(t, err)
+}
+
+// Test that labels are reported next to the unchanged layer names in
+// provenance, Explain and warnings
+func TestNamed_Provenance(t *testing.T) {
+	base := Named("embed:config.yaml", StringSource("app:\n  name: base\n  port: 80\n"))
+	local := StringSource("app:\n  port: 81\n")
+	patch := Named("hotfix", StringSource("app:\n  debug: true\n"))
+
+	result, err := Load(LoaderOptions{BaseSource: base, LocalSource: local, Patches: []ConfigSource{patch}, Target: &TestConfig{}})
+	require.NoError(t, err)
+	assert.Equal(t, "base", result.Sources["app.name"])
+	assert.Equal(t, "patch:0", result.Sources["app.debug"])
+	assert.Equal(t, map[string]string{"base": "embed:config.yaml", "patch:0": "hotfix"}, result.Labels)
+
+	e, err := Explain(LoaderOptions{BaseSource: base, LocalSource: local, Target: &TestConfig{}}, "app.port")
+	require.NoError(t, err)
+	assert.Equal(t, []LayerValue{{Layer: "base", Label: "embed:config.yaml", Value: 80}, {Layer: "local", Value: 81}}, e.Layers)
+	assert.Contains(t, e.String(), "  base (embed:config.yaml): 80\n  local:                    81\n")
+
+	logger := &recordingLogger{}
+	err = LoadConfig(LoaderOptions{BaseSource: Named("embed:config.yaml", StringSource("timeoutSec: 5\n")), Logger: logger, Target: &AliasConfig{}})
+	require.NoError(t, err)
+	require.Len(t, logger.lines, 1)
+	assert.Contains(t, logger.lines[0], `[yamlenv] warning: base (embed:config.yaml): key "timeoutSec"`)
+}
@@ -0,0 +1,290 @@
+package yamlenv
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// OCIOptions configures OCISource
+type OCIOptions struct {
+	Client    *http.Client // optional: HTTP client; nil = http.DefaultClient
+	Username  string       // optional: registry credentials, for basic auth or token requests
+	Password  string       // optional: password or access token for Username
+	File      string       // optional: layer to read, by its org.opencontainers.image.title annotation; "" = the only layer
+	PlainHTTP bool         // if true, talk to the registry over http, e.g. a local test registry
+}
+
+// ociManifest is the subset of an OCI image or artifact manifest OCISource reads
+type ociManifest struct {
+	Layers []ociDescriptor `json:"layers"`
+	Blobs  []ociDescriptor `json:"blobs"` // artifact manifests
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// ociTitleAnnotation names a layer's file, as set by "oras push"
+const ociTitleAnnotation = "org.opencontainers.image.title"
+
+// ociMaxManifestSize bounds the manifest read from a registry
+const ociMaxManifestSize = 4 << 20
+
+// OCISource creates a ConfigSource that pulls a config artifact, as pushed
+// with "oras push", from an OCI registry. ref must pin a digest, e.g.
+// "ghcr.io/acme/config@sha256:3b1f...". The manifest and the layer are
+// checked against their digests before use, and the verified layer is kept,
+// so reloads don't download it again.
+//
+//	base := yamlenv.OCISource("ghcr.io/acme/config@sha256:3b1f...", yamlenv.OCIOptions{File: "config.yaml"})
+func OCISource(ref string, opts OCIOptions) ConfigSource {
+	var (
+		mu   sync.Mutex
+		body []byte
+	)
+	return func() (io.ReadCloser, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if body == nil {
+			data, err := pullOCI(ref, opts)
+			if err != nil {
+				return nil, fmt.Errorf("oci %s: %w", ref, err)
+			}
+			body = data
+		}
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+}
+
+// pullOCI fetches and verifies the manifest pinned by ref and the layer it
+// selects
+func pullOCI(ref string, opts OCIOptions) ([]byte, error) {
+	registry, repo, digest, err := parseOCIReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	c := &ociClient{opts: opts, client: opts.Client}
+	if c.client == nil {
+		c.client = http.DefaultClient
+	}
+	scheme := "https"
+	if opts.PlainHTTP {
+		scheme = "http"
+	}
+	base := scheme + "://" + registry + "/v2/" + repo
+
+	data, err := c.get(base+"/manifests/"+digest, ociMaxManifestSize,
+		"application/vnd.oci.image.manifest.v1+json, application/vnd.oci.artifact.manifest.v1+json")
+	if err != nil {
+		return nil, fmt.Errorf("fetch manifest: %w", err)
+	}
+	if err := verifyDigest(data, digest); err != nil {
+		return nil, fmt.Errorf("manifest: %w", err)
+	}
+	var manifest ociManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	layer, err := manifest.layer(opts.File)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = c.get(base+"/blobs/"+layer.Digest, layer.Size, "")
+	if err != nil {
+		return nil, fmt.Errorf("fetch layer: %w", err)
+	}
+	if err := verifyDigest(data, layer.Digest); err != nil {
+		return nil, fmt.Errorf("layer: %w", err)
+	}
+	return data, nil
+}
+
+// layer selects the layer titled file, or the only layer
+func (m ociManifest) layer(file string) (ociDescriptor, error) {
+	layers := append(m.Layers, m.Blobs...)
+	if file == "" {
+		if len(layers) != 1 {
+			return ociDescriptor{}, fmt.Errorf("manifest has %d layers; set OCIOptions.File to pick one", len(layers))
+		}
+		return layers[0], nil
+	}
+	for _, layer := range layers {
+		if layer.Annotations[ociTitleAnnotation] == file {
+			return layer, nil
+		}
+	}
+	return ociDescriptor{}, fmt.Errorf("manifest has no layer titled %q", file)
+}
+
+// parseOCIReference splits "registry/repo[:tag]@sha256:hex". Names without
+// a registry host refer to Docker Hub.
+func parseOCIReference(ref string) (registry, repo, digest string, err error) {
+	name, digest, ok := strings.Cut(ref, "@")
+	if !ok || digest == "" {
+		return "", "", "", fmt.Errorf("reference must pin a digest, as in name@sha256:<hex>")
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name = name[:i] // the digest decides; a tag is only informative
+	}
+	registry, repo, ok = strings.Cut(name, "/")
+	if !ok || !strings.ContainsAny(registry, ".:") && registry != "localhost" {
+		registry, repo = "registry-1.docker.io", name
+		if !strings.Contains(repo, "/") {
+			repo = "library/" + repo
+		}
+	}
+	if repo == "" {
+		return "", "", "", fmt.Errorf("reference has no repository")
+	}
+	return registry, repo, digest, nil
+}
+
+// verifyDigest checks data against an OCI digest such as "sha256:<hex>"
+func verifyDigest(data []byte, digest string) error {
+	algorithm, want, _ := strings.Cut(digest, ":")
+	if algorithm != "sha256" {
+		return fmt.Errorf("unsupported digest algorithm %q", algorithm)
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != strings.ToLower(want) {
+		return fmt.Errorf("digest mismatch: expected %s, got sha256:%s", digest, got)
+	}
+	return nil
+}
+
+// ociClient performs registry requests, answering auth challenges with
+// basic credentials or a bearer token
+type ociClient struct {
+	opts   OCIOptions
+	client *http.Client
+	auth   string // Authorization header for subsequent requests
+}
+
+// get fetches u, reading at most limit bytes of the body
+func (c *ociClient) get(u string, limit int64, accept string) ([]byte, error) {
+	resp, err := c.do(u, accept)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && c.auth == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if c.auth, err = c.authorize(challenge); err != nil {
+			return nil, err
+		}
+		if resp, err = c.do(u, accept); err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("response exceeds %d bytes", limit)
+	}
+	return data, nil
+}
+
+func (c *ociClient) do(u, accept string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if c.auth != "" {
+		req.Header.Set("Authorization", c.auth)
+	}
+	return c.client.Do(req)
+}
+
+// authorize answers a WWW-Authenticate challenge and returns the
+// Authorization header to send
+func (c *ociClient) authorize(challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if c.opts.Username == "" {
+			return "", fmt.Errorf("registry requires credentials")
+		}
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth(c.opts.Username, c.opts.Password)
+		return req.Header.Get("Authorization"), nil
+	case "bearer":
+	default:
+		return "", fmt.Errorf("unsupported auth challenge %q", challenge)
+	}
+
+	values := parseChallenge(params)
+	realm, err := url.Parse(values["realm"])
+	if err != nil || values["realm"] == "" {
+		return "", fmt.Errorf("invalid auth challenge %q", challenge)
+	}
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if values[key] != "" {
+			query.Set(key, values[key])
+		}
+	}
+	realm.RawQuery = query.Encode()
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if c.opts.Username != "" {
+		req.SetBasicAuth(c.opts.Username, c.opts.Password)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetch token: unexpected status %s", resp.Status)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, ociMaxManifestSize)).Decode(&token); err != nil {
+		return "", fmt.Errorf("fetch token: %w", err)
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	return "Bearer " + token.Token, nil
+}
+
+// parseChallenge parses the key="value" pairs of a WWW-Authenticate header
+func parseChallenge(params string) map[string]string {
+	values := map[string]string{}
+	for params != "" {
+		var key, value string
+		key, params, _ = strings.Cut(strings.TrimLeft(params, ", "), "=")
+		if strings.HasPrefix(params, `"`) {
+			value, params, _ = strings.Cut(params[1:], `"`)
+		} else {
+			value, params, _ = strings.Cut(params, ",")
+		}
+		values[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+	return values
+}
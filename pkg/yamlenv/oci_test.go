@@ -0,0 +1,133 @@
+package yamlenv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ociTestDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// newTestRegistry serves one config artifact behind bearer token auth and
+// returns the registry host and the manifest digest
+func newTestRegistry(t *testing.T, layers map[string][]byte, tamper bool) (string, string, *int) {
+	blobs := map[string][]byte{}
+	manifest := ociManifest{}
+	for title, data := range layers {
+		digest := ociTestDigest(data)
+		blobs[digest] = data
+		manifest.Layers = append(manifest.Layers, ociDescriptor{
+			MediaType:   "application/yaml",
+			Digest:      digest,
+			Size:        int64(len(data)),
+			Annotations: map[string]string{ociTitleAnnotation: title},
+		})
+	}
+	manifestData, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	manifestDigest := ociTestDigest(manifestData)
+
+	blobFetches := 0
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			user, pass, _ := r.BasicAuth()
+			if user != "ci" || pass != "secret" || r.URL.Query().Get("scope") != "repository:acme/config:pull" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(`{"token": "t0ken"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer t0ken" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+srv.URL+`/token",service="test",scope="repository:acme/config:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/v2/acme/config/manifests/"+manifestDigest:
+			_, _ = w.Write(manifestData)
+		case strings.HasPrefix(r.URL.Path, "/v2/acme/config/blobs/"):
+			data, ok := blobs[strings.TrimPrefix(r.URL.Path, "/v2/acme/config/blobs/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			blobFetches++
+			if tamper {
+				data = []byte(strings.ToUpper(string(data)))
+			}
+			_, _ = w.Write(data)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://"), manifestDigest, &blobFetches
+}
+
+// Test that a pinned artifact is pulled with token auth, verified and cached
+func TestOCISource(t *testing.T) {
+	host, digest, fetches := newTestRegistry(t, map[string][]byte{
+		"config.yaml":       []byte("app:\n  name: from-oci\n  port: 8080\n"),
+		"config.local.yaml": []byte("app:\n  port: 9090\n"),
+	}, false)
+	opts := OCIOptions{Username: "ci", Password: "secret", File: "config.yaml", PlainHTTP: true}
+
+	loader, err := NewLoader(LoaderOptions{
+		BaseSource: OCISource(host+"/acme/config:v1@"+digest, opts),
+		Target:     &TestConfig{},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "from-oci", loader.Current().(*TestConfig).App.Name)
+	require.NoError(t, loader.Reload())
+	assert.Equal(t, 1, *fetches)
+
+	opts.File = ""
+	_, err = OCISource(host+"/acme/config@"+digest, opts)()
+	assert.EqualError(t, err, "oci "+host+"/acme/config@"+digest+": manifest has 2 layers; set OCIOptions.File to pick one")
+
+	_, err = OCISource(host+"/acme/config:v1", opts)()
+	assert.ErrorContains(t, err, "reference must pin a digest")
+
+	opts.File, opts.Password = "config.yaml", "wrong"
+	_, err = OCISource(host+"/acme/config@"+digest, opts)()
+	assert.ErrorContains(t, err, "fetch token: unexpected status 403 Forbidden")
+}
+
+// Test that content not matching its digest is rejected
+func TestOCISource_DigestMismatch(t *testing.T) {
+	host, digest, _ := newTestRegistry(t, map[string][]byte{"config.yaml": []byte("app:\n  name: x\n")}, true)
+	opts := OCIOptions{Username: "ci", Password: "secret", PlainHTTP: true}
+
+	_, err := OCISource(host+"/acme/config@"+digest, opts)()
+	assert.ErrorContains(t, err, "layer: digest mismatch: expected sha256:")
+
+	wrong := "sha256:" + strings.Repeat("0", 64)
+	_, err = OCISource(host+"/acme/config@"+wrong, opts)()
+	assert.ErrorContains(t, err, "fetch manifest: unexpected status 404 Not Found")
+}
+
+// Test reference parsing, including Docker Hub defaults
+func TestParseOCIReference(t *testing.T) {
+	for ref, want := range map[string][3]string{
+		"ghcr.io/acme/config@sha256:ab":      {"ghcr.io", "acme/config", "sha256:ab"},
+		"localhost:5000/config:v2@sha256:ab": {"localhost:5000", "config", "sha256:ab"},
+		"acme/config@sha256:ab":              {"registry-1.docker.io", "acme/config", "sha256:ab"},
+		"config@sha256:ab":                   {"registry-1.docker.io", "library/config", "sha256:ab"},
+	} {
+		registry, repo, digest, err := parseOCIReference(ref)
+		require.NoError(t, err, ref)
+		assert.Equal(t, want, [3]string{registry, repo, digest}, ref)
+	}
+}
@@ -0,0 +1,77 @@
+package yamlenv
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Lazy is a config of type T loaded on first use and shared afterwards; see
+// Once
+type Lazy[T any] struct {
+	opts LoaderOptions
+	once sync.Once
+
+	mu  sync.RWMutex
+	cfg *T
+	err error
+}
+
+// Once returns a config of type T that is loaded with opts (whose Target is
+// ignored) the first time Get is called and memoized from then on, so
+// packages that each need the config don't parse it again. Like sync.Once,
+// concurrent first callers wait for the one load and a failed load is
+// remembered; only Reload loads again.
+//
+//	var config = yamlenv.Once[Config](yamlenv.LoaderOptions{
+//	    BaseSource: yamlenv.FileSource("config.yaml"),
+//	    EnvPrefix:  "APP_",
+//	    Delimiter:  "__",
+//	})
+//
+//	func dsn() string { return config.MustGet().DB.DSN }
+func Once[T any](opts LoaderOptions) *Lazy[T] {
+	return &Lazy[T]{opts: opts}
+}
+
+// Get returns the config, loading it on the first call. The returned
+// pointer is shared by all callers and must not be modified.
+func (o *Lazy[T]) Get() (*T, error) {
+	o.once.Do(func() { _ = o.load() })
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.cfg, o.err
+}
+
+// MustGet is Get that panics if the config can't be loaded
+func (o *Lazy[T]) MustGet() *T {
+	cfg, err := o.Get()
+	if err != nil {
+		panic(fmt.Sprintf("yamlenv: load config: %v", err))
+	}
+	return cfg
+}
+
+// Reload loads the config again. On success later Get calls return the new
+// config; on failure they keep returning the previous one, if any. Pointers
+// already handed out keep pointing at the config they were returned with.
+func (o *Lazy[T]) Reload() error {
+	o.once.Do(func() {}) // a Get after Reload must not load again
+	return o.load()
+}
+
+func (o *Lazy[T]) load() error {
+	cfg := new(T)
+	opts := o.opts
+	opts.Target = cfg
+	_, err := Load(opts)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	switch {
+	case err == nil:
+		o.cfg, o.err = cfg, nil
+	case o.cfg == nil:
+		o.err = err
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+package yamlenv
+
+import (
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the config is loaded once for concurrent callers and again only on Reload
+func TestOnce(t *testing.T) {
+	path := createTempYAML(t, "app:\n  name: v1\n")
+	var fetches int32
+	source := func() (io.ReadCloser, error) {
+		atomic.AddInt32(&fetches, 1)
+		return os.Open(path)
+	}
+	config := Once[TestConfig](LoaderOptions{BaseSource: source})
+
+	var wg sync.WaitGroup
+	results := make([]*TestConfig, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = config.MustGet()
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), fetches)
+	for _, cfg := range results {
+		assert.Same(t, results[0], cfg)
+	}
+	assert.Equal(t, "v1", results[0].App.Name)
+
+	require.NoError(t, os.WriteFile(path, []byte("app:\n  name: v2\n"), 0o644))
+	assert.Equal(t, "v1", config.MustGet().App.Name, "memoized until Reload")
+	require.NoError(t, config.Reload())
+	assert.Equal(t, "v2", config.MustGet().App.Name)
+	assert.Equal(t, "v1", results[0].App.Name, "earlier pointers are not modified")
+
+	// A failed reload keeps the previous config
+	require.NoError(t, os.WriteFile(path, []byte("app: ["), 0o644))
+	assert.Error(t, config.Reload())
+	cfg, err := config.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "v2", cfg.App.Name)
+}
+
+// Test that a failed first load is remembered and MustGet panics
+func TestOnce_Error(t *testing.T) {
+	config := Once[TestConfig](LoaderOptions{BaseSource: FileSource("/nonexistent/config.yaml")})
+	_, err := config.Get()
+	assert.ErrorContains(t, err, "load base config")
+	assert.Panics(t, func() { config.MustGet() })
+
+	reloaded := Once[TestConfig](LoaderOptions{BaseSource: StringSource("app:\n  port: 1\n")})
+	require.NoError(t, reloaded.Reload())
+	assert.Equal(t, 1, reloaded.MustGet().App.Port)
+}
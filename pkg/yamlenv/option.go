@@ -0,0 +1,81 @@
+package yamlenv
+
+import (
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Option wraps a configuration value and records whether any layer
+// (base YAML, local YAML or environment) explicitly provided it. It removes
+// the "is 0/false a real value or just unset" ambiguity without pointers.
+//
+//	type Config struct {
+//	    Retries yamlenv.Option[int] `yaml:"retries"`
+//	}
+//
+//	if cfg.Retries.IsSet() { ... }
+type Option[T any] struct {
+	value T
+	set   bool
+}
+
+// Some returns an Option holding v that reports IsSet
+func Some[T any](v T) Option[T] {
+	return Option[T]{value: v, set: true}
+}
+
+// IsSet reports whether a value was explicitly provided
+func (o Option[T]) IsSet() bool {
+	return o.set
+}
+
+// Value returns the wrapped value, or the zero value of T if unset
+func (o Option[T]) Value() T {
+	return o.value
+}
+
+// ValueOr returns the wrapped value if set, otherwise def
+func (o Option[T]) ValueOr(def T) T {
+	if o.set {
+		return o.value
+	}
+	return def
+}
+
+// String formats the wrapped value, or "<unset>"
+func (o Option[T]) String() string {
+	if !o.set {
+		return "<unset>"
+	}
+	return fmt.Sprint(o.value)
+}
+
+// UnmarshalYAML decodes the wrapped value and marks the option as set
+func (o *Option[T]) UnmarshalYAML(node *yaml.Node) error {
+	var v T
+	if err := node.Decode(&v); err != nil {
+		return err
+	}
+	o.value, o.set = v, true
+	return nil
+}
+
+// MarshalYAML encodes the wrapped value, or null if unset
+func (o Option[T]) MarshalYAML() (any, error) {
+	if !o.set {
+		return nil, nil
+	}
+	return o.value, nil
+}
+
+// unmarshalEnv parses an environment override into the wrapped value
+func (o *Option[T]) unmarshalEnv(value string) error {
+	v := reflect.New(reflect.TypeOf((*T)(nil)).Elem()).Elem()
+	if err := setFieldValue(v, value); err != nil {
+		return err
+	}
+	o.value, o.set = v.Interface().(T), true
+	return nil
+}
@@ -0,0 +1,100 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type OptionTestConfig struct {
+	Retries Option[int]           `yaml:"retries"`
+	Debug   Option[bool]          `yaml:"debug"`
+	Timeout Option[time.Duration] `yaml:"timeout"`
+	Name    Option[string]        `yaml:"name"`
+}
+
+// Test that explicit zero values from YAML are reported as set
+func TestOption_SetFromYAML(t *testing.T) {
+	var cfg OptionTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("retries: 0\ndebug: false\ntimeout: 5s\n")),
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, cfg.Retries.IsSet())
+	assert.Equal(t, 0, cfg.Retries.Value())
+	assert.True(t, cfg.Debug.IsSet())
+	assert.False(t, cfg.Debug.Value())
+	assert.Equal(t, 5*time.Second, cfg.Timeout.Value())
+
+	assert.False(t, cfg.Name.IsSet())
+	assert.Equal(t, "fallback", cfg.Name.ValueOr("fallback"))
+}
+
+// Test that env overrides mark options as set
+func TestOption_SetFromEnv(t *testing.T) {
+	setEnvVar(t, "OPTION_NAME", "envname")
+	setEnvVar(t, "OPTION_TIMEOUT", "1m")
+
+	var cfg OptionTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("retries: 3\n")),
+		EnvPrefix:  "OPTION_",
+		Delimiter:  "__",
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, cfg.Name.IsSet())
+	assert.Equal(t, "envname", cfg.Name.Value())
+	assert.True(t, cfg.Timeout.IsSet())
+	assert.Equal(t, time.Minute, cfg.Timeout.Value())
+	assert.Equal(t, 3, cfg.Retries.Value())
+	assert.False(t, cfg.Debug.IsSet())
+}
+
+// Test that invalid env values for options are reported
+func TestOption_InvalidEnv(t *testing.T) {
+	setEnvVar(t, "OPTBAD_RETRIES", "many")
+
+	var cfg OptionTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("retries: 3\n")),
+		EnvPrefix:  "OPTBAD_",
+		Delimiter:  "__",
+		Target:     &cfg,
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "set field retries")
+}
+
+// Test that null in an override leaves the option unset
+func TestOption_NullRemainsUnset(t *testing.T) {
+	var cfg OptionTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader("retries: 3\n")),
+		LocalSource: ReaderSource(strings.NewReader("retries: null\n")),
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.False(t, cfg.Retries.IsSet())
+}
+
+func TestOption_MarshalAndString(t *testing.T) {
+	cfg := OptionTestConfig{Retries: Some(2)}
+
+	out, err := yaml.Marshal(cfg)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "retries: 2")
+	assert.Contains(t, string(out), "name: null")
+
+	assert.Equal(t, "2", cfg.Retries.String())
+	assert.Equal(t, "<unset>", cfg.Name.String())
+}
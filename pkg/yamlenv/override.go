@@ -0,0 +1,202 @@
+package yamlenv
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+// runtimeOverride is a value set with Loader.ApplyOverride
+type runtimeOverride struct {
+	id    int
+	value any
+	timer *time.Timer // fires when the TTL elapses; nil without TTL
+}
+
+// ApplyOverride sets the value at path (dot-separated YAML keys, e.g.
+// "log.level") above every other layer, including the environment, and
+// reloads. The override survives later reloads until ttl elapses, the
+// returned rollback function is called, or RemoveOverride removes it;
+// ttl <= 0 means no expiry.
+//
+// Strings are parsed like environment values; other values must be
+// assignable or numerically convertible to the field's type. If the path
+// doesn't exist or the value doesn't fit, the override is discarded and the
+// error returned.
+func (l *Loader) ApplyOverride(path string, value any, ttl time.Duration) (rollback func() error, err error) {
+	l.overridesMu.Lock()
+	l.nextOverride++
+	id := l.nextOverride
+	prev, hadPrev := l.overrides[path]
+	l.overrides[path] = runtimeOverride{id: id, value: value}
+	l.overridesMu.Unlock()
+
+	l.notifyMu.Lock()
+	defer l.notifyMu.Unlock()
+	previous, current, err := l.load()
+	if err != nil {
+		l.overridesMu.Lock()
+		if current, ok := l.overrides[path]; ok && current.id == id {
+			if hadPrev {
+				l.overrides[path] = prev
+			} else {
+				delete(l.overrides, path)
+			}
+		}
+		l.overridesMu.Unlock()
+		return nil, err
+	}
+
+	l.overridesMu.Lock()
+	if prev.timer != nil {
+		prev.timer.Stop()
+	}
+	if current, ok := l.overrides[path]; ok && current.id == id && ttl > 0 {
+		current.timer = time.AfterFunc(ttl, func() { _ = l.removeOverride(path, id) })
+		l.overrides[path] = current
+	}
+	l.overridesMu.Unlock()
+
+	l.notify(previous, current)
+	return func() error { return l.removeOverride(path, id) }, nil
+}
+
+// RemoveOverride removes the runtime override at path, if any, and reloads
+func (l *Loader) RemoveOverride(path string) error {
+	return l.removeOverride(path, 0)
+}
+
+// Overrides returns the active runtime overrides by path
+func (l *Loader) Overrides() map[string]any {
+	l.overridesMu.Lock()
+	defer l.overridesMu.Unlock()
+	out := make(map[string]any, len(l.overrides))
+	for path, o := range l.overrides {
+		out[path] = o.value
+	}
+	return out
+}
+
+// removeOverride removes the override at path if its id matches (0 matches
+// any) and reloads without it
+func (l *Loader) removeOverride(path string, id int) error {
+	l.overridesMu.Lock()
+	o, ok := l.overrides[path]
+	if !ok || (id != 0 && o.id != id) {
+		l.overridesMu.Unlock()
+		return nil
+	}
+	if o.timer != nil {
+		o.timer.Stop()
+	}
+	delete(l.overrides, path)
+	l.overridesMu.Unlock()
+
+	return l.Reload()
+}
+
+// applyOverrides sets the runtime overrides on a freshly loaded config and
+// records them in the result's sources
+func (l *Loader) applyOverrides(cfg reflect.Value, result *LoadResult) error {
+	l.overridesMu.Lock()
+	defer l.overridesMu.Unlock()
+	for path, o := range l.overrides {
+		field, ok := lookupField(cfg, path)
+		if !ok || !field.CanSet() {
+			return fmt.Errorf("override %s: no settable config value at this path", path)
+		}
+		if err := setOverrideValue(field, o.value); err != nil {
+			return fmt.Errorf("override %s: %w", path, err)
+		}
+		result.Sources[path] = "override"
+	}
+	return nil
+}
+
+// setOverrideValue assigns an override value to field
+func setOverrideValue(field reflect.Value, value any) error {
+	if s, ok := value.(string); ok && field.Type() != reflect.TypeOf(value) {
+		return setFieldValue(field, s)
+	}
+	v := reflect.ValueOf(value)
+	switch {
+	case !v.IsValid():
+		field.Set(reflect.Zero(field.Type()))
+	case v.Type().AssignableTo(field.Type()):
+		field.Set(v)
+	case isNumberKind(v.Kind()) && isNumberKind(field.Kind()):
+		converted := v.Convert(field.Type())
+		if !reflect.DeepEqual(converted.Convert(v.Type()).Interface(), value) {
+			return fmt.Errorf("%v does not fit in %s", value, field.Type())
+		}
+		field.Set(converted)
+	default:
+		return fmt.Errorf("cannot assign %T to %s", value, field.Type())
+	}
+	return nil
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// overrideRequest is the JSON body accepted by OverrideHandler
+type overrideRequest struct {
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+	TTL   string `json:"ttl"`
+}
+
+// OverrideHandler returns an http.Handler for runtime overrides:
+//
+//	POST   {"path": "log.level", "value": "debug", "ttl": "15m"}  applies an override
+//	DELETE ?path=log.level                                        removes it
+//	GET                                                           lists active overrides
+//
+// The handler performs no authentication; mount it behind whatever guards
+// the rest of your admin endpoints.
+func OverrideHandler(loader *Loader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(loader.Overrides())
+		case http.MethodPost:
+			var req overrideRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, fmt.Sprintf("decode request: %v", err), http.StatusBadRequest)
+				return
+			}
+			var ttl time.Duration
+			if req.TTL != "" {
+				var err error
+				if ttl, err = time.ParseDuration(req.TTL); err != nil {
+					http.Error(w, fmt.Sprintf("parse ttl: %v", err), http.StatusBadRequest)
+					return
+				}
+			}
+			if _, err := loader.ApplyOverride(req.Path, req.Value, ttl); err != nil {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodDelete:
+			if err := loader.RemoveOverride(r.URL.Query().Get("path")); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package yamlenv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that overrides win over env, survive reloads and roll back
+func TestLoader_ApplyOverride(t *testing.T) {
+	setEnvVar(t, "OVR_APP__NAME", "from-env")
+	file := createTempYAML(t, "app:\n  name: svc\n  port: 8080\n")
+
+	var cfg LoaderTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(file), EnvPrefix: "OVR_", Delimiter: "__", Target: &cfg})
+	require.NoError(t, err)
+
+	var changes []Change
+	loader.OnChange(func(c []Change) { changes = append(changes, c...) })
+
+	rollback, err := loader.ApplyOverride("app.name", "override", 0)
+	require.NoError(t, err)
+	_, err = loader.ApplyOverride("app.port", 9090.0, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, "override", cfg.App.Name)
+	assert.Equal(t, 9090, cfg.App.Port)
+	assert.Equal(t, "override", loader.Result().Sources["app.name"])
+	assert.Len(t, changes, 2)
+
+	writeFile(t, file, "app:\n  name: svc\n  port: 1\ndb:\n  pool_size: 3\n")
+	require.NoError(t, loader.Reload())
+	assert.Equal(t, "override", cfg.App.Name)
+	assert.Equal(t, 9090, cfg.App.Port)
+	assert.Equal(t, 3, cfg.DB.PoolSize)
+
+	require.NoError(t, rollback())
+	assert.Equal(t, "from-env", cfg.App.Name)
+	require.NoError(t, loader.RemoveOverride("app.port"))
+	assert.Equal(t, 1, cfg.App.Port)
+	assert.Empty(t, loader.Overrides())
+}
+
+// Test that invalid overrides are rejected and leave the config untouched
+func TestLoader_ApplyOverrideInvalid(t *testing.T) {
+	var cfg LoaderTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: ReaderSource(strings.NewReader("app:\n  port: 8080\n")), Target: &cfg})
+	require.NoError(t, err)
+
+	_, err = loader.ApplyOverride("app.missing", 1, 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "override app.missing")
+
+	_, err = loader.ApplyOverride("app.port", "not-a-number", 0)
+	require.Error(t, err)
+
+	_, err = loader.ApplyOverride("app.port", 1.5, 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not fit")
+
+	assert.Equal(t, 8080, cfg.App.Port)
+	assert.Empty(t, loader.Overrides())
+}
+
+// Test that overrides expire after their TTL
+func TestLoader_ApplyOverrideTTL(t *testing.T) {
+	var cfg LoaderTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(createTempYAML(t, "app:\n  port: 8080\n")), Target: &cfg})
+	require.NoError(t, err)
+
+	_, err = loader.ApplyOverride("app.port", 1, 20*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, 1, loader.Current().(*LoaderTestConfig).App.Port)
+
+	require.Eventually(t, func() bool {
+		return loader.Current().(*LoaderTestConfig).App.Port == 8080
+	}, 2*time.Second, 5*time.Millisecond)
+}
+
+// Test the override HTTP handler
+func TestOverrideHandler(t *testing.T) {
+	var cfg LoaderTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(createTempYAML(t, "app:\n  port: 8080\n")), Target: &cfg})
+	require.NoError(t, err)
+	handler := OverrideHandler(loader)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"path": "app.port", "value": 9000, "ttl": "1h"}`)))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, 9000, loader.Current().(*LoaderTestConfig).App.Port)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.JSONEq(t, `{"app.port": 9000}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"path": "app.nope", "value": 1}`)))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/?path=app.port", nil))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, 8080, loader.Current().(*LoaderTestConfig).App.Port)
+}
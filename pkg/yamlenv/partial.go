@@ -0,0 +1,27 @@
+package yamlenv
+
+// partialLoad collects the failures of optional layers when
+// LoaderOptions.AllowPartial is set, so a load can go on without them: a
+// batch job shouldn't die because a nice-to-have overlay had a hiccup.
+type partialLoad struct {
+	opts     LoaderOptions
+	warnings []error
+}
+
+// skip records err and logs it as a warning if AllowPartial is set, and
+// reports whether the layer that failed may be skipped
+func (p *partialLoad) skip(err error) bool {
+	if !p.opts.AllowPartial {
+		return false
+	}
+	p.warnings = append(p.warnings, err)
+	loggerOrDefault(p.opts.Logger).Printf("[yamlenv] warning: %v; continuing without it", err)
+	return true
+}
+
+// warn records and logs a problem the load got around, such as a stale
+// cached copy, whether or not AllowPartial is set
+func (p *partialLoad) warn(err error) {
+	p.warnings = append(p.warnings, err)
+	loggerOrDefault(p.opts.Logger).Printf("[yamlenv] warning: %v", err)
+}
@@ -0,0 +1,106 @@
+package yamlenv
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type PartialTestConfig struct {
+	App struct {
+		Name  string `yaml:"name"`
+		Port  int    `yaml:"port"`
+		Level string `yaml:"level" enum:"debug,info"`
+	} `yaml:"app"`
+}
+
+// downSource fails like an overlay whose server is unreachable
+func downSource() (io.ReadCloser, error) {
+	return nil, errors.New("connection refused")
+}
+
+// Test that failing optional layers are skipped and reported as warnings
+func TestLoad_AllowPartial(t *testing.T) {
+	logger := &recordingLogger{}
+	var cfg PartialTestConfig
+	result, err := Load(LoaderOptions{
+		BaseSource:    StringSource("app:\n  name: batch\n  port: 8080\n"),
+		LocalSource:   downSource,
+		SecretsSource: StringSource("app: [unclosed\n"),
+		Patches:       []ConfigSource{StringSource("app:\n  port: 9090\n"), StringSource("42\n")},
+		AllowPartial:  true,
+		Logger:        logger,
+		Target:        &cfg,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "batch", cfg.App.Name)
+	assert.Equal(t, 9090, cfg.App.Port)
+	require.Len(t, result.Warnings, 3)
+	assert.ErrorContains(t, result.Warnings[0], "load local config: open config source: connection refused")
+	assert.ErrorContains(t, result.Warnings[1], "load secrets config")
+	assert.ErrorContains(t, result.Warnings[2], "apply patch:1")
+	require.Len(t, logger.lines, 3)
+	assert.Equal(t, "[yamlenv] warning: load local config: open config source: connection refused; continuing without it", logger.lines[0])
+}
+
+// Test the failures AllowPartial doesn't cover
+func TestLoad_AllowPartialFatal(t *testing.T) {
+	tests := []struct {
+		name string
+		opts LoaderOptions
+		want string
+	}{
+		{"without AllowPartial", LoaderOptions{
+			BaseSource: StringSource("app:\n  name: batch\n"), LocalSource: downSource,
+		}, "load local config: open config source: connection refused"},
+		{"base", LoaderOptions{
+			BaseSource: downSource, AllowPartial: true,
+		}, "load base config: open config source: connection refused"},
+		{"required local", LoaderOptions{
+			BaseSource: StringSource("app:\n  name: batch\n"), LocalSource: downSource, LocalRequired: true, AllowPartial: true,
+		}, "load local config: open config source: connection refused"},
+		{"validation", LoaderOptions{
+			BaseSource: StringSource("app:\n  level: loud\n"), LocalSource: downSource, AllowPartial: true, Logger: &recordingLogger{},
+		}, `invalid value "loud"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg PartialTestConfig
+			tt.opts.Target = &cfg
+			_, err := Load(tt.opts)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+// Test that a Loader reports the warnings of its latest load
+func TestLoader_AllowPartialReload(t *testing.T) {
+	down := true
+	overlay := func() (io.ReadCloser, error) {
+		if down {
+			return downSource()
+		}
+		return StringSource("app:\n  port: 9090\n")()
+	}
+
+	var cfg PartialTestConfig
+	loader, err := NewLoader(LoaderOptions{
+		BaseSource:   StringSource("app:\n  port: 8080\n"),
+		LocalSource:  overlay,
+		AllowPartial: true,
+		Logger:       &recordingLogger{},
+		Target:       &cfg,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 8080, cfg.App.Port)
+	assert.Len(t, loader.Result().Warnings, 1)
+
+	down = false
+	require.NoError(t, loader.Reload())
+	assert.Equal(t, 9090, cfg.App.Port)
+	assert.Empty(t, loader.Result().Warnings)
+}
@@ -0,0 +1,386 @@
+package yamlenv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// replaceTag marks a list in a patch layer that replaces the earlier list
+// whatever ArrayMerge says, since the patch already computed the result
+const replaceTag = "!yamlenv.replace"
+
+// loadPatchLayers applies the fetched opts.Patches, in order, on top of the
+// merged layers. Each patch is turned into an ordinary override layer
+// holding the keys it changed, so provenance and Explain work as for files.
+// A mapping is an RFC 7386 JSON Merge Patch; a list is an RFC 6902 JSON
+// Patch.
+func loadPatchLayers(opts LoaderOptions, layers []configLayer, patches []fetchJob, partial *partialLoad) ([]configLayer, error) {
+	m := newMerger(opts)
+	for _, patch := range patches {
+		layer := patch.configLayer(nil)
+		if patch.err != nil {
+			if err := fmt.Errorf("load %s: %w", layer.title(), patch.err); !partial.skip(err) {
+				return nil, err
+			}
+			continue
+		}
+		merged := m.mergeLayers(layers)
+		patched, err := applyPatch(merged, patch.node)
+		if err != nil {
+			if err := fmt.Errorf("apply %s: %w", layer.title(), err); !partial.skip(err) {
+				return nil, err
+			}
+			continue
+		}
+		layer.node = diffNode(merged, patched)
+		layers = append(layers, layer)
+	}
+	return layers, nil
+}
+
+// applyPatch returns a patched copy of the mapping root
+func applyPatch(root, patch *yaml.Node) (*yaml.Node, error) {
+	out := cloneNode(root)
+	if out == nil {
+		out = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	patch = resolveAlias(patch)
+	switch {
+	case patch == nil:
+		return out, nil
+	case patch.Kind == yaml.MappingNode:
+		return mergePatch(out, patch), nil
+	case patch.Kind == yaml.SequenceNode:
+		out, err := applyJSONPatch(out, patch)
+		if err != nil {
+			return nil, err
+		}
+		if resolveAlias(out).Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("patched document is not a mapping")
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("expected a mapping (merge patch) or a list of operations (JSON patch)")
+	}
+}
+
+// mergePatch applies an RFC 7386 merge patch to target: mappings merge key
+// by key, null removes a key and anything else, lists included, replaces
+func mergePatch(target, patch *yaml.Node) *yaml.Node {
+	patch = resolveAlias(patch)
+	if patch.Kind != yaml.MappingNode {
+		return cloneNode(patch)
+	}
+	target = resolveAlias(target)
+	if target == nil || target.Kind != yaml.MappingNode {
+		target = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	for i := 0; i+1 < len(patch.Content); i += 2 {
+		key, value := patch.Content[i], patch.Content[i+1]
+		idx := mappingIndex(target, key.Value)
+		switch {
+		case isUnsetNode(resolveAlias(value)):
+			if idx >= 0 {
+				target.Content = append(target.Content[:idx], target.Content[idx+2:]...)
+			}
+		case idx >= 0:
+			target.Content[idx+1] = mergePatch(target.Content[idx+1], value)
+		default:
+			target.Content = append(target.Content, cloneNode(key), mergePatch(nil, value))
+		}
+	}
+	return target
+}
+
+// patchOp is one RFC 6902 operation
+type patchOp struct {
+	Op    string    `yaml:"op"`
+	Path  string    `yaml:"path"`
+	From  string    `yaml:"from"`
+	Value yaml.Node `yaml:"value"`
+}
+
+// applyJSONPatch applies the RFC 6902 operations in ops to root, which it
+// modifies, and returns the new root
+func applyJSONPatch(root, ops *yaml.Node) (*yaml.Node, error) {
+	for i, n := range ops.Content {
+		var op patchOp
+		if err := n.Decode(&op); err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+		var err error
+		root, err = op.apply(root)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return root, nil
+}
+
+// apply performs the operation on root and returns the new root
+func (op patchOp) apply(root *yaml.Node) (*yaml.Node, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value.Kind == 0 {
+			return nil, fmt.Errorf("missing value")
+		}
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		value, err := pointerGet(root, from)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "copy" {
+			return pointerAdd(root, path, cloneNode(value))
+		}
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("cannot move %s into itself", op.From)
+		}
+		if root, err = pointerRemove(root, from); err != nil {
+			return nil, err
+		}
+		return pointerAdd(root, path, value)
+	}
+
+	switch op.Op {
+	case "add":
+		return pointerAdd(root, path, cloneNode(&op.Value))
+	case "remove":
+		return pointerRemove(root, path)
+	case "replace":
+		if len(path) == 0 {
+			return cloneNode(&op.Value), nil
+		}
+		if root, err = pointerRemove(root, path); err != nil {
+			return nil, err
+		}
+		return pointerAdd(root, path, cloneNode(&op.Value))
+	case "test":
+		value, err := pointerGet(root, path)
+		if err != nil {
+			return nil, err
+		}
+		if !nodesEqual(value, &op.Value) {
+			return nil, fmt.Errorf("test failed")
+		}
+		return root, nil
+	default:
+		return nil, fmt.Errorf("unknown op %q", op.Op)
+	}
+}
+
+// parsePointer splits an RFC 6901 JSON pointer into unescaped tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid pointer %q: must start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// pointerGet returns the node at path
+func pointerGet(root *yaml.Node, path []string) (*yaml.Node, error) {
+	n := resolveAlias(root)
+	for i, token := range path {
+		switch n.Kind {
+		case yaml.MappingNode:
+			idx := mappingIndex(n, token)
+			if idx < 0 {
+				return nil, fmt.Errorf("%s does not exist", formatPointer(path[:i+1]))
+			}
+			n = resolveAlias(n.Content[idx+1])
+		case yaml.SequenceNode:
+			idx, err := sequenceIndex(n, token, false)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", formatPointer(path[:i+1]), err)
+			}
+			n = resolveAlias(n.Content[idx])
+		default:
+			return nil, fmt.Errorf("%s does not exist", formatPointer(path[:i+1]))
+		}
+	}
+	return n, nil
+}
+
+// pointerAdd adds value at path: a mapping key is set, and a list element
+// is inserted before the index or appended for "-"
+func pointerAdd(root *yaml.Node, path []string, value *yaml.Node) (*yaml.Node, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := pointerGet(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch parent.Kind {
+	case yaml.MappingNode:
+		if idx := mappingIndex(parent, token); idx >= 0 {
+			parent.Content[idx+1] = value
+		} else {
+			parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: token}, value)
+		}
+	case yaml.SequenceNode:
+		idx, err := sequenceIndex(parent, token, true)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", formatPointer(path), err)
+		}
+		parent.Content = append(parent.Content[:idx], append([]*yaml.Node{value}, parent.Content[idx:]...)...)
+	default:
+		return nil, fmt.Errorf("%s is not a mapping or list", formatPointer(path[:len(path)-1]))
+	}
+	return root, nil
+}
+
+// pointerRemove removes the value at path, which must exist
+func pointerRemove(root *yaml.Node, path []string) (*yaml.Node, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("cannot remove the whole document")
+	}
+	if _, err := pointerGet(root, path); err != nil {
+		return nil, err
+	}
+	parent, _ := pointerGet(root, path[:len(path)-1])
+	token := path[len(path)-1]
+	if parent.Kind == yaml.MappingNode {
+		idx := mappingIndex(parent, token)
+		parent.Content = append(parent.Content[:idx], parent.Content[idx+2:]...)
+	} else {
+		idx, _ := sequenceIndex(parent, token, false)
+		parent.Content = append(parent.Content[:idx], parent.Content[idx+1:]...)
+	}
+	return root, nil
+}
+
+// sequenceIndex parses a list index token. With insert set, "-" and the
+// list length are accepted and mean the end of the list.
+func sequenceIndex(seq *yaml.Node, token string, insert bool) (int, error) {
+	if token == "-" && insert {
+		return len(seq.Content), nil
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 || strconv.Itoa(idx) != token {
+		return 0, fmt.Errorf("invalid list index %q", token)
+	}
+	if idx > len(seq.Content) || (idx == len(seq.Content) && !insert) {
+		return 0, fmt.Errorf("index %d out of range", idx)
+	}
+	return idx, nil
+}
+
+// formatPointer joins tokens back into a JSON pointer
+func formatPointer(path []string) string {
+	var b strings.Builder
+	for _, token := range path {
+		b.WriteString("/" + strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
+
+// diffNode returns an override layer that turns before into after when
+// merged on top of it: changed and new keys, and !unset for removed ones.
+// It returns nil when nothing changed.
+func diffNode(before, after *yaml.Node) *yaml.Node {
+	before, after = resolveAlias(before), resolveAlias(after)
+	if nodesEqual(before, after) {
+		return nil
+	}
+	if before == nil || before.Kind != yaml.MappingNode || after.Kind != yaml.MappingNode {
+		if before != nil && before.Kind == yaml.SequenceNode && after.Kind == yaml.SequenceNode {
+			out := *after
+			out.Tag = replaceTag
+			return &out
+		}
+		return after
+	}
+
+	out := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	beforeContent, afterContent := flattenMapping(before), flattenMapping(after)
+	for i := 0; i+1 < len(afterContent); i += 2 {
+		key := afterContent[i]
+		if child := diffNode(pairValue(beforeContent, key.Value), afterContent[i+1]); child != nil {
+			out.Content = append(out.Content, key, child)
+		}
+	}
+	for i := 0; i+1 < len(beforeContent); i += 2 {
+		key := beforeContent[i]
+		if pairValue(afterContent, key.Value) == nil {
+			out.Content = append(out.Content, key, &yaml.Node{Kind: yaml.ScalarNode, Tag: unsetTag})
+		}
+	}
+	return out
+}
+
+// nodesEqual reports whether two trees hold the same data, ignoring style,
+// comments and key order
+func nodesEqual(a, b *yaml.Node) bool {
+	a, b = resolveAlias(a), resolveAlias(b)
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Kind == yaml.DocumentNode && len(a.Content) > 0 {
+		a = a.Content[0]
+	}
+	if b.Kind == yaml.DocumentNode && len(b.Content) > 0 {
+		b = b.Content[0]
+	}
+	if a.Kind != b.Kind {
+		return false
+	}
+	switch a.Kind {
+	case yaml.ScalarNode:
+		var av, bv any
+		if a.Decode(&av) != nil || b.Decode(&bv) != nil {
+			return a.Value == b.Value
+		}
+		return fmt.Sprintf("%T %v", av, av) == fmt.Sprintf("%T %v", bv, bv)
+	case yaml.SequenceNode:
+		if len(a.Content) != len(b.Content) {
+			return false
+		}
+		for i := range a.Content {
+			if !nodesEqual(a.Content[i], b.Content[i]) {
+				return false
+			}
+		}
+		return true
+	case yaml.MappingNode:
+		ac, bc := flattenMapping(a), flattenMapping(b)
+		if len(ac) != len(bc) {
+			return false
+		}
+		for i := 0; i+1 < len(ac); i += 2 {
+			if other := pairValue(bc, ac[i].Value); other == nil || !nodesEqual(ac[i+1], other) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// pairValue returns the value for key in flattened mapping content, or nil
+func pairValue(content []*yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(content); i += 2 {
+		if content[i].Value == key {
+			return content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type PatchTestConfig struct {
+	App struct {
+		Name  string   `yaml:"name"`
+		Port  int      `yaml:"port"`
+		Hosts []string `yaml:"hosts"`
+	} `yaml:"app"`
+	DB struct {
+		Host string `yaml:"host"`
+		Name string `yaml:"name"`
+	} `yaml:"db"`
+}
+
+const patchTestBase = `
+app:
+  name: svc
+  port: 8080
+  hosts: [a, b]
+db:
+  host: localhost
+  name: app
+`
+
+// Test that a merge patch replaces lists and removes keys set to null, and
+// that its keys are reported as coming from the patch
+func TestPatches_MergePatch(t *testing.T) {
+	var cfg PatchTestConfig
+	result, err := Load(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader(patchTestBase)),
+		ArrayMerge: MergeAppend,
+		Patches:    []ConfigSource{ReaderSource(strings.NewReader(`{"app": {"port": 9090, "hosts": ["c"]}, "db": {"name": null}}`))},
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 9090, cfg.App.Port)
+	assert.Equal(t, []string{"c"}, cfg.App.Hosts)
+	assert.Equal(t, "localhost", cfg.DB.Host)
+	assert.Equal(t, "", cfg.DB.Name)
+	assert.Equal(t, "patch:0", result.Sources["app.port"])
+	assert.Equal(t, "base", result.Sources["app.name"])
+	assert.NotContains(t, result.Sources, "db.name")
+}
+
+// Test that JSON patches apply in order after the files and below the environment
+func TestPatches_JSONPatch(t *testing.T) {
+	setEnvVar(t, "PATCH_DB__HOST", "env-db")
+	var cfg PatchTestConfig
+	result, err := Load(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader(patchTestBase)),
+		LocalSource: ReaderSource(strings.NewReader("app:\n  name: local\n")),
+		EnvPrefix:   "PATCH_",
+		Delimiter:   "__",
+		Patches: []ConfigSource{
+			ReaderSource(strings.NewReader(`[
+  {"op": "test", "path": "/app/name", "value": "local"},
+  {"op": "add", "path": "/app/hosts/-", "value": "c"},
+  {"op": "remove", "path": "/app/hosts/0"},
+  {"op": "copy", "from": "/app/name", "path": "/db/name"},
+  {"op": "replace", "path": "/db/host", "value": "patched"}
+]`)),
+			ReaderSource(strings.NewReader(`[{"op": "move", "from": "/app/hosts/0", "path": "/app/name"}]`)),
+		},
+		Target: &cfg,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, cfg.App.Hosts)
+	assert.Equal(t, "b", cfg.App.Name)
+	assert.Equal(t, "local", cfg.DB.Name)
+	assert.Equal(t, "env-db", cfg.DB.Host)
+	assert.Equal(t, "patch:1", result.Sources["app.name"])
+	assert.Equal(t, "patch:1", result.Sources["app.hosts"])
+	assert.Equal(t, "patch:0", result.Sources["db.name"])
+}
+
+// Test that failing operations and invalid patches name the patch and operation
+func TestPatches_Errors(t *testing.T) {
+	tests := []struct {
+		patch string
+		err   string
+	}{
+		{`[{"op": "test", "path": "/app/port", "value": 1}]`, "apply patch:0: operation 0 (test /app/port): test failed"},
+		{`[{"op": "replace", "path": "/app/missing", "value": 1}]`, "apply patch:0: operation 0 (replace /app/missing): /app/missing does not exist"},
+		{`[{"op": "add", "path": "/app/hosts/5", "value": "x"}]`, "apply patch:0: operation 0 (add /app/hosts/5): /app/hosts/5: index 5 out of range"},
+		{`[{"op": "move", "from": "/app", "path": "/app/x"}]`, "apply patch:0: operation 0 (move /app/x): cannot move /app into itself"},
+		{`[{"op": "frobnicate", "path": "/app"}]`, `apply patch:0: operation 0 (frobnicate /app): unknown op "frobnicate"`},
+		{`[{"op": "add", "path": "", "value": [1]}]`, "apply patch:0: patched document is not a mapping"},
+		{`"scalar"`, "apply patch:0: expected a mapping (merge patch) or a list of operations (JSON patch)"},
+	}
+	for _, tt := range tests {
+		var cfg PatchTestConfig
+		err := LoadConfig(LoaderOptions{
+			BaseSource: ReaderSource(strings.NewReader(patchTestBase)),
+			Patches:    []ConfigSource{ReaderSource(strings.NewReader(tt.patch))},
+			Target:     &cfg,
+		})
+		assert.EqualError(t, err, tt.err, tt.patch)
+	}
+}
+
+// Test that pointer tokens are unescaped per RFC 6901
+func TestPatches_PointerEscapes(t *testing.T) {
+	var cfg struct {
+		Paths map[string]string `yaml:"paths"`
+	}
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("paths:\n  /api: old\n")),
+		Patches:    []ConfigSource{ReaderSource(strings.NewReader(`[{"op": "replace", "path": "/paths/~1api", "value": "new"}, {"op": "add", "path": "/paths/a~0b", "value": "x"}]`))},
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"/api": "new", "a~b": "x"}, cfg.Paths)
+}
@@ -0,0 +1,47 @@
+package yamlenv
+
+import "reflect"
+
+// Report describes what a load would set, as returned by Plan
+type Report struct {
+	LoadResult
+
+	Config any            // the config that would be bound, a pointer of Target's type
+	Values map[string]any // leaf path -> effective value, with secret values redacted
+}
+
+// Plan performs a complete load (parsing, merging, env resolution, schema
+// and policy checks) into a copy of Target and reports what would be set,
+// without touching Target. Use it for preflight checks in CI and admin
+// tooling; it fails exactly when Load would.
+func Plan(opts LoaderOptions) (*Report, error) {
+	if err := validateOptions(opts); err != nil {
+		return nil, err
+	}
+	cfg := deepCopy(reflect.ValueOf(opts.Target))
+	opts.Target = cfg.Interface()
+
+	result, err := Load(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]any{}
+	flattenPlain(opts.Section, plainValue(cfg, false), values)
+	return &Report{LoadResult: *result, Config: opts.Target, Values: values}, nil
+}
+
+// flattenPlain records the leaves of a plainValue tree by dot-separated
+// path; lists and empty maps are leaves
+func flattenPlain(path string, v any, out map[string]any) {
+	m, ok := v.(map[string]any)
+	if !ok || len(m) == 0 {
+		if path != "" {
+			out[path] = v
+		}
+		return
+	}
+	for key, inner := range m {
+		flattenPlain(joinPath(path, key), inner, out)
+	}
+}
@@ -0,0 +1,68 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// PlanTestConfig has a secret field to check redaction in reports
+type PlanTestConfig struct {
+	App struct {
+		Name string `yaml:"name"`
+		Port int    `yaml:"port"`
+	} `yaml:"app"`
+	DB struct {
+		Password string `yaml:"password" secret:"true"`
+	} `yaml:"db"`
+	Tags []string `yaml:"tags"`
+}
+
+// Test that Plan reports values and sources without touching Target
+func TestPlan(t *testing.T) {
+	setEnvVar(t, "PLAN_APP__PORT", "9090")
+
+	var cfg PlanTestConfig
+	report, err := Plan(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("app:\n  name: svc\n  port: 8080\n  extra: 1\ndb:\n  password: hunter2\ntags: [a]\n")),
+		EnvPrefix:  "PLAN_",
+		Delimiter:  "__",
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"app.name":    "svc",
+		"app.port":    9090,
+		"db.password": "***",
+		"tags":        []any{"a"},
+	}, report.Values)
+	assert.Equal(t, "env:PLAN_APP__PORT", report.Sources["app.port"])
+	assert.Equal(t, []string{"app.extra"}, report.UnusedKeys)
+	assert.Equal(t, "hunter2", report.Config.(*PlanTestConfig).DB.Password)
+	assert.Zero(t, cfg)
+}
+
+// Test that Plan fails like Load and reports section paths in full
+func TestPlan_ErrorsAndSection(t *testing.T) {
+	var cfg PlanTestConfig
+	_, err := Plan(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("app:\n  port: nope\n")),
+		Target:     &cfg,
+	})
+	require.Error(t, err)
+
+	var app struct {
+		Name string `yaml:"name"`
+	}
+	report, err := Plan(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("app:\n  name: svc\n")),
+		Section:    "app",
+		Target:     &app,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"app.name": "svc"}, report.Values)
+	assert.Empty(t, app.Name)
+}
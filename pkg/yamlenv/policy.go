@@ -0,0 +1,32 @@
+package yamlenv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// checkPolicies evaluates opts.Policies against the effective config in
+// opts.Target, after env and runtime overrides. Every policy runs, and all
+// violations are reported together. Secret fields are redacted in the
+// document the policies see.
+func checkPolicies(opts LoaderOptions) error {
+	if len(opts.Policies) == 0 {
+		return nil
+	}
+	doc, _ := plainValue(reflect.ValueOf(opts.Target), false).(map[string]any)
+	if doc == nil {
+		doc = map[string]any{}
+	}
+
+	var violations []error
+	for _, policy := range opts.Policies {
+		if err := policy.Validate(doc); err != nil {
+			violations = append(violations, err)
+		}
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("policy violation: %w", errors.Join(violations...))
+	}
+	return nil
+}
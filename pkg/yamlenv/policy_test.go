@@ -0,0 +1,94 @@
+package yamlenv
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// PolicyTestConfig is an effective config checked by policy tests
+type PolicyTestConfig struct {
+	Env   string `yaml:"env"`
+	Debug bool   `yaml:"debug"`
+	TLS   struct {
+		Enabled  bool   `yaml:"enabled"`
+		Password string `yaml:"password" secret:"true"`
+	} `yaml:"tls"`
+}
+
+// noDebugInProd rejects debug mode in the prod environment
+var noDebugInProd = ValidatorFunc(func(doc map[string]any) error {
+	if doc["env"] == "prod" && doc["debug"] == true {
+		return errors.New("prod must not enable debug")
+	}
+	return nil
+})
+
+// tlsRequiredInProd requires TLS in the prod environment
+var tlsRequiredInProd = ValidatorFunc(func(doc map[string]any) error {
+	if tls, _ := doc["tls"].(map[string]any); doc["env"] == "prod" && tls["enabled"] != true {
+		return errors.New("TLS required in prod")
+	}
+	return nil
+})
+
+// Test that policies see env overrides and report every violation
+func TestLoadConfig_PolicyViolations(t *testing.T) {
+	setEnvVar(t, "POL_DEBUG", "true")
+
+	var cfg PolicyTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("env: prod\n")),
+		EnvPrefix:  "POL_",
+		Delimiter:  "__",
+		Policies:   []Validator{noDebugInProd, tlsRequiredInProd},
+		Target:     &cfg,
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "policy violation")
+	assert.Contains(t, err.Error(), "prod must not enable debug")
+	assert.Contains(t, err.Error(), "TLS required in prod")
+}
+
+// Test that compliant configs load and secrets are redacted for policies
+func TestLoadConfig_PolicyPasses(t *testing.T) {
+	var seen map[string]any
+	capture := ValidatorFunc(func(doc map[string]any) error {
+		seen = doc
+		return nil
+	})
+
+	var cfg PolicyTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("env: prod\ntls:\n  enabled: true\n  password: hunter2\n")),
+		Policies:   []Validator{noDebugInProd, tlsRequiredInProd, capture},
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", cfg.TLS.Password)
+	assert.Equal(t, "***", seen["tls"].(map[string]any)["password"])
+}
+
+// Test that runtime overrides violating a policy are rejected
+func TestLoader_PolicyRejectsOverride(t *testing.T) {
+	file := createTempYAML(t, "env: prod\ntls:\n  enabled: true\n")
+
+	var cfg PolicyTestConfig
+	loader, err := NewLoader(LoaderOptions{
+		BaseSource: FileSource(file),
+		Policies:   []Validator{noDebugInProd},
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+
+	_, err = loader.ApplyOverride("debug", true, 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "prod must not enable debug")
+	assert.False(t, cfg.Debug)
+	assert.Empty(t, loader.Overrides())
+}
@@ -0,0 +1,187 @@
+package yamlenv
+
+import (
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LayerKind identifies a configuration layer for Precedence
+type LayerKind int
+
+const (
+	LayerBase     LayerKind = iota // BaseSource
+	LayerLocal                     // LocalSource
+	LayerSecrets                   // SecretsSource
+	LayerRuntime                   // RuntimeSource
+	LayerEnv                       // environment variable overrides
+	LayerDefaults                  // Defaults
+)
+
+// defaultPrecedence is the layer order used when Precedence is empty
+var defaultPrecedence = []LayerKind{LayerDefaults, LayerBase, LayerLocal, LayerSecrets, LayerRuntime, LayerEnv}
+
+// String returns the layer name used in LoadResult.Sources
+func (k LayerKind) String() string {
+	switch k {
+	case LayerBase:
+		return "base"
+	case LayerLocal:
+		return "local"
+	case LayerSecrets:
+		return "secrets"
+	case LayerRuntime:
+		return "runtime"
+	case LayerEnv:
+		return "env"
+	case LayerDefaults:
+		return "defaults"
+	default:
+		return fmt.Sprintf("LayerKind(%d)", int(k))
+	}
+}
+
+// validatePrecedence checks that Precedence lists each kind at most once and
+// every configured source, including the required base
+func validatePrecedence(opts LoaderOptions) error {
+	if len(opts.Precedence) == 0 {
+		return nil
+	}
+	seen := map[LayerKind]bool{}
+	for _, kind := range opts.Precedence {
+		if kind < LayerBase || kind > LayerDefaults {
+			return fmt.Errorf("precedence: unknown layer kind %v", kind)
+		}
+		if seen[kind] {
+			return fmt.Errorf("precedence: %s listed more than once", kind)
+		}
+		seen[kind] = true
+	}
+	configured := map[LayerKind]bool{
+		LayerBase:     true,
+		LayerLocal:    opts.LocalSource != nil,
+		LayerSecrets:  opts.SecretsSource != nil,
+		LayerRuntime:  opts.RuntimeSource != nil,
+		LayerDefaults: opts.Defaults != nil,
+	}
+	for _, kind := range defaultPrecedence {
+		if configured[kind] && !seen[kind] {
+			return fmt.Errorf("precedence: %s layer is configured but not listed", kind)
+		}
+	}
+	return nil
+}
+
+// layerOrder returns the layer order, lowest first
+func layerOrder(opts LoaderOptions) []LayerKind {
+	if len(opts.Precedence) == 0 {
+		return defaultPrecedence
+	}
+	return opts.Precedence
+}
+
+// layerRanks returns the position of each layer in the precedence order.
+// Layers that aren't one of the kinds, like tenant overlays, rank with the
+// layer before them.
+func layerRanks(opts LoaderOptions, layers []configLayer) []int {
+	rank := map[string]int{}
+	for i, kind := range layerOrder(opts) {
+		rank[kind.String()] = i
+	}
+	ranks := make([]int, len(layers))
+	for i, layer := range layers {
+		r, ok := rank[layer.name]
+		if !ok && i > 0 {
+			r = ranks[i-1]
+		}
+		ranks[i] = r
+	}
+	return ranks
+}
+
+// orderLayers sorts freshly loaded layers by precedence
+func orderLayers(opts LoaderOptions, layers []configLayer) []configLayer {
+	if len(opts.Precedence) == 0 {
+		return layers
+	}
+	ranks := layerRanks(opts, layers)
+	idx := make([]int, len(layers))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return ranks[idx[a]] < ranks[idx[b]] })
+	out := make([]configLayer, len(layers))
+	for i, j := range idx {
+		out[i] = layers[j]
+	}
+	return out
+}
+
+// envPosition returns how many of the ordered layers rank below the
+// environment; len(layers) when env comes last or isn't listed
+func envPosition(opts LoaderOptions, layers []configLayer) int {
+	envRank := -1
+	for i, kind := range layerOrder(opts) {
+		if kind == LayerEnv {
+			envRank = i
+		}
+	}
+	if envRank < 0 {
+		return len(layers)
+	}
+	for i, r := range layerRanks(opts, layers) {
+		if r > envRank {
+			return i
+		}
+	}
+	return len(layers)
+}
+
+// envEnabled reports whether environment overrides apply at all
+func envEnabled(opts LoaderOptions) bool {
+	for _, kind := range layerOrder(opts) {
+		if kind == LayerEnv {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneNode returns a copy of the mapping tree n holding only the leaf paths
+// in keep (as recorded by layerSources) and the mappings leading to them
+func pruneNode(n *yaml.Node, path string, keep map[string]string) *yaml.Node {
+	n = resolveAlias(n)
+	if n == nil {
+		return nil
+	}
+	if _, ok := keep[path]; ok || n.Kind != yaml.MappingNode {
+		return n
+	}
+	out := *n
+	out.Content = nil
+	content := flattenMapping(n)
+	for i := 0; i+1 < len(content); i += 2 {
+		childPath := joinPath(path, content[i].Value)
+		if !hasPathBelow(keep, childPath) {
+			continue
+		}
+		if child := pruneNode(content[i+1], childPath, keep); child != nil {
+			out.Content = append(out.Content, content[i], child)
+		}
+	}
+	return &out
+}
+
+// hasPathBelow reports whether keep holds path or a path below it
+func hasPathBelow(keep map[string]string, path string) bool {
+	if _, ok := keep[path]; ok {
+		return true
+	}
+	for key := range keep {
+		if len(key) > len(path) && key[:len(path)] == path && key[len(path)] == '.' {
+			return true
+		}
+	}
+	return false
+}
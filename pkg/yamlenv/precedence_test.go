@@ -0,0 +1,104 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that files listed above the environment override it
+func TestLoad_PrecedenceFilesOverrideEnv(t *testing.T) {
+	setEnvVar(t, "PREC_APP__NAME", "from-env")
+	setEnvVar(t, "PREC_APP__PORT", "1111")
+
+	var cfg TestConfig
+	result, err := Load(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader("app:\n  name: base\n  port: 8080\n")),
+		LocalSource: ReaderSource(strings.NewReader("app:\n  port: 9090\n")),
+		EnvPrefix:   "PREC_",
+		Delimiter:   "__",
+		Precedence:  []LayerKind{LayerBase, LayerEnv, LayerLocal},
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", cfg.App.Name)
+	assert.Equal(t, 9090, cfg.App.Port)
+	assert.Equal(t, "env:PREC_APP__NAME", result.Sources["app.name"])
+	assert.Equal(t, "local", result.Sources["app.port"])
+}
+
+// Test that env-first precedence treats the environment as bootstrap defaults
+func TestLoad_PrecedenceEnvLowest(t *testing.T) {
+	setEnvVar(t, "PREC_APP__NAME", "from-env")
+	setEnvVar(t, "PREC_DB__HOST", "env-host")
+
+	var cfg TestConfig
+	result, err := Load(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("app:\n  name: pinned\n")),
+		EnvPrefix:  "PREC_",
+		Delimiter:  "__",
+		Precedence: []LayerKind{LayerEnv, LayerBase},
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "pinned", cfg.App.Name)
+	assert.Equal(t, "env-host", cfg.DB.Host)
+	assert.Equal(t, "base", result.Sources["app.name"])
+	assert.Equal(t, "env:PREC_DB__HOST", result.Sources["db.host"])
+}
+
+// Test that file layers are reordered and env is off when not listed
+func TestLoad_PrecedenceReorderFiles(t *testing.T) {
+	setEnvVar(t, "PREC_APP__NAME", "from-env")
+
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader("app:\n  port: 8080\n")),
+		LocalSource: ReaderSource(strings.NewReader("app:\n  name: local\n  port: 9090\n")),
+		EnvPrefix:   "PREC_",
+		Delimiter:   "__",
+		Precedence:  []LayerKind{LayerLocal, LayerBase},
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "local", cfg.App.Name)
+	assert.Equal(t, 8080, cfg.App.Port)
+}
+
+// Test that lists merged across the env boundary keep the merge strategy
+func TestLoad_PrecedenceListMerge(t *testing.T) {
+	var cfg PlanTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader("tags: [a]\n")),
+		LocalSource: ReaderSource(strings.NewReader("tags: [b]\n")),
+		ArrayMerge:  MergeAppend,
+		Precedence:  []LayerKind{LayerBase, LayerEnv, LayerLocal},
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+}
+
+// Test that invalid precedence lists are rejected
+func TestLoad_PrecedenceValidation(t *testing.T) {
+	base := func() ConfigSource { return ReaderSource(strings.NewReader("app:\n  name: x\n")) }
+	var cfg TestConfig
+
+	err := LoadConfig(LoaderOptions{BaseSource: base(), Precedence: []LayerKind{LayerBase, LayerBase}, Target: &cfg})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "precedence: base listed more than once")
+
+	err = LoadConfig(LoaderOptions{BaseSource: base(), LocalSource: base(), Precedence: []LayerKind{LayerBase, LayerEnv}, Target: &cfg})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "precedence: local layer is configured but not listed")
+
+	err = LoadConfig(LoaderOptions{BaseSource: base(), Precedence: []LayerKind{LayerEnv}, Target: &cfg})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "precedence: base layer is configured but not listed")
+}
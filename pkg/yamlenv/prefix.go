@@ -0,0 +1,52 @@
+package yamlenv
+
+import (
+	"reflect"
+	"strings"
+)
+
+// envPrefix returns the prefix variable names start with: EnvPrefix
+// followed by PrefixSeparator, unless EnvPrefix already ends with it
+func envPrefix(opts LoaderOptions) string {
+	if opts.EnvPrefix == "" || strings.HasSuffix(opts.EnvPrefix, opts.PrefixSeparator) {
+		return opts.EnvPrefix
+	}
+	return opts.EnvPrefix + opts.PrefixSeparator
+}
+
+// warnNearMisses logs a warning for each variable that almost starts with
+// the prefix and would set a field of target if it did, like MYAPPDB__HOST,
+// MYAPP__DB__HOST or myapp_db__host for MYAPP_DB__HOST
+func (b *envBinder) warnNearMisses(target any, section string) {
+	stem := strings.TrimRight(b.prefix, "_-.")
+	if stem == "" {
+		return
+	}
+	var misses []string
+	for _, name := range b.environNames() {
+		if rest, ok := b.cutVarPrefix(name, b.prefix); ok {
+			// A doubled separator, as in MYAPP__DB__HOST for "MYAPP_"
+			if rest != "" && strings.ContainsAny(rest[:1], "_-.") {
+				misses = append(misses, name)
+			}
+			continue
+		}
+		if len(name) > len(stem) && strings.EqualFold(name[:len(stem)], stem) {
+			misses = append(misses, name)
+		}
+	}
+	if len(misses) == 0 {
+		return
+	}
+
+	honored := map[string]string{} // upper-case name -> name
+	for _, v := range b.vars(reflect.ValueOf(target), section) {
+		honored[strings.ToUpper(v.Name)] = v.Name
+	}
+	for _, name := range misses {
+		rest := strings.TrimLeft(name[len(stem):], "_-.")
+		if want, ok := honored[strings.ToUpper(b.prefix+rest)]; ok {
+			b.logger.Printf("[yamlenv] warning: %s is not read with prefix %q; did you mean %s?", name, b.prefix, want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package yamlenv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvPrefix(t *testing.T) {
+	tests := []struct{ prefix, sep, want string }{
+		{"MYAPP", "_", "MYAPP_"},
+		{"MYAPP_", "_", "MYAPP_"},
+		{"MYAPP_", "", "MYAPP_"},
+		{"MYAPP", "__", "MYAPP__"},
+		{"", "_", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, envPrefix(LoaderOptions{EnvPrefix: tt.prefix, PrefixSeparator: tt.sep}), tt.prefix+" "+tt.sep)
+	}
+}
+
+// Test that PrefixSeparator supplies the separator after EnvPrefix
+func TestLoad_PrefixSeparator(t *testing.T) {
+	t.Setenv("PSEP_APP__NAME", "from-env")
+
+	var cfg TestConfig
+	result, err := Load(LoaderOptions{
+		BaseSource:      StringSource("app:\n  name: base\n"),
+		EnvPrefix:       "PSEP",
+		PrefixSeparator: "_",
+		Delimiter:       "__",
+		Target:          &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", cfg.App.Name)
+	assert.Equal(t, "env:PSEP_APP__NAME", result.Sources["app.name"])
+
+	vars, err := EnvVars(LoaderOptions{EnvPrefix: "PSEP", PrefixSeparator: "_", Delimiter: "__", Target: &TestConfig{}})
+	require.NoError(t, err)
+	assert.Equal(t, "PSEP_APP__NAME", vars[0].Name)
+}
+
+// Test that variables just missing the prefix are warned about when they
+// would set a field, and others are not
+func TestLoad_PrefixNearMiss(t *testing.T) {
+	t.Setenv("NEARAPP__NAME", "missing-underscore")
+	t.Setenv("NEAR__APP__PORT", "1")
+	t.Setenv("near_db__host", "lower-case")
+	t.Setenv("NEAR_APP__DEBUG", "true")
+	t.Setenv("NEARBY_THING", "unrelated")
+
+	logger := &recordingLogger{}
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: StringSource("app:\n  name: base\n"),
+		EnvPrefix:  "NEAR_",
+		Delimiter:  "__",
+		Logger:     logger,
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "base", cfg.App.Name)
+	assert.True(t, cfg.App.Debug)
+	assert.ElementsMatch(t, []string{
+		`[yamlenv] warning: NEARAPP__NAME is not read with prefix "NEAR_"; did you mean NEAR_APP__NAME?`,
+		`[yamlenv] warning: NEAR__APP__PORT is not read with prefix "NEAR_"; did you mean NEAR_APP__PORT?`,
+		`[yamlenv] warning: near_db__host is not read with prefix "NEAR_"; did you mean NEAR_DB__HOST?`,
+	}, logger.lines)
+}
@@ -0,0 +1,195 @@
+package yamlenv
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PropertiesSource adapts a Java .properties source to a ConfigSource.
+// Dotted keys become nested keys ("db.host=localhost" sets db.host), values
+// are typed like plain YAML scalars, and "key=value", "key: value" and
+// "key value" forms, "#"/"!" comments, backslash line continuations and
+// \uXXXX escapes are supported. When a key is also used as a prefix
+// ("db=x" and "db.host=y") the later line wins.
+func PropertiesSource(source ConfigSource) ConfigSource {
+	return flatSource(source, parseProperties)
+}
+
+// INISource adapts an INI source to a ConfigSource. Each [section] becomes a
+// mapping with its keys below it, dots in section and key names nest further
+// ("[db.primary]" then "host=x" sets db.primary.host), and keys before the
+// first section are top-level. Lines starting with ";" or "#" are comments
+// and values wrapped in matching quotes are kept as strings.
+func INISource(source ConfigSource) ConfigSource {
+	return flatSource(source, parseINI)
+}
+
+// flatSource reads source, parses it into a mapping node with parse and
+// re-encodes it as YAML, with MaxSourceSize limiting the data read
+func flatSource(source ConfigSource, parse func([]byte) (*yaml.Node, error)) ConfigSource {
+	return decodingSource(source, func(data []byte) ([]byte, error) {
+		root, err := parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("decode config source: %w", err)
+		}
+		out, err := yaml.Marshal(root)
+		if err != nil {
+			return nil, fmt.Errorf("marshal config source: %w", err)
+		}
+		return out, nil
+	})
+}
+
+// parseProperties parses .properties data into a mapping node. A
+// continuation on the last line ends the entry there, as in Java.
+func parseProperties(data []byte) (*yaml.Node, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	set := func(lineNo int, logical string) error {
+		key, value := splitProperty(logical)
+		k, err := unescapeProperty(key)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		v, err := unescapeProperty(value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		setNodePath(root, strings.Split(k, "."), dirValueNode(v))
+		return nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo, logical := 0, ""
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if logical == "" && (line == "" || line[0] == '#' || line[0] == '!') {
+			continue
+		}
+		// An odd number of trailing backslashes continues the line
+		if trailing := len(line) - len(strings.TrimRight(line, `\`)); trailing%2 == 1 {
+			logical += line[:len(line)-1]
+			continue
+		}
+		if err := set(lineNo, logical+line); err != nil {
+			return nil, err
+		}
+		logical = ""
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if logical != "" {
+		if err := set(lineNo, logical); err != nil {
+			return nil, err
+		}
+	}
+	return root, nil
+}
+
+// splitProperty splits a logical line at the first unescaped '=', ':' or
+// whitespace, skipping whitespace and one separator between key and value
+func splitProperty(line string) (string, string) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+	key, rest := line[:end], strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	return key, rest
+}
+
+// unescapeProperty resolves .properties backslash escapes
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+4 >= len(s) {
+				return "", fmt.Errorf("malformed \\u escape in %q", s)
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\u escape in %q", s)
+			}
+			b.WriteRune(rune(r))
+			i += 4
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// parseINI parses INI data into a mapping node
+func parseINI(data []byte) (*yaml.Node, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	var section []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+		if line[0] == '[' {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: unterminated section header", lineNo)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if name == "" {
+				return nil, fmt.Errorf("line %d: empty section name", lineNo)
+			}
+			section = strings.Split(name, ".")
+			continue
+		}
+
+		idx := strings.IndexAny(line, "=:")
+		if idx <= 0 {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		key, value := strings.TrimSpace(line[:idx]), strings.TrimSpace(line[idx+1:])
+		node := dirValueNode(value)
+		// Quoted values are always strings
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			node = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value[1 : len(value)-1]}
+		}
+		keys := append(append([]string{}, section...), strings.Split(key, ".")...)
+		setNodePath(root, keys, node)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
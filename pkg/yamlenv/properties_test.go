@@ -0,0 +1,131 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that .properties files nest dotted keys and layer with YAML and env
+func TestLoadConfig_PropertiesSource(t *testing.T) {
+	setEnvVar(t, "PROPS_DB__NAME", "fromenv")
+
+	props := `# JVM-style config
+! another comment
+app.name = legacy \
+  service
+app.port: 8080
+app.debug true
+db.host=db\u002einternal
+db.port=5432
+version=1.2
+`
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:  PropertiesSource(ReaderSource(strings.NewReader(props))),
+		LocalSource: ReaderSource(strings.NewReader("db:\n  port: 6543\n")),
+		EnvPrefix:   "PROPS_",
+		Delimiter:   "__",
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "legacy service", cfg.App.Name)
+	assert.Equal(t, 8080, cfg.App.Port)
+	assert.True(t, cfg.App.Debug)
+	assert.Equal(t, "db.internal", cfg.DB.Host)
+	assert.Equal(t, 6543, cfg.DB.Port)
+	assert.Equal(t, "fromenv", cfg.DB.Name)
+	assert.Equal(t, "1.2", cfg.Version)
+}
+
+// Test that escaped separators stay part of the key and empty values are strings
+func TestParseProperties_Escapes(t *testing.T) {
+	root, err := parseProperties([]byte("a\\=b=c\nempty=\n"))
+	require.NoError(t, err)
+
+	var out map[string]any
+	require.NoError(t, root.Decode(&out))
+	assert.Equal(t, map[string]any{"a=b": "c", "empty": ""}, out)
+
+	_, err = parseProperties([]byte("bad=\\uZZZZ\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 1")
+}
+
+// Test that a continuation on the last line keeps the entry it continues
+func TestParseProperties_ContinuationAtEOF(t *testing.T) {
+	for _, data := range []string{"a=1\nb=two \\\n  parts\\", "a=1\nb=two \\\n  parts\\\n"} {
+		root, err := parseProperties([]byte(data))
+		require.NoError(t, err)
+
+		var out map[string]any
+		require.NoError(t, root.Decode(&out))
+		assert.Equal(t, map[string]any{"a": 1, "b": "two parts"}, out, data)
+	}
+}
+
+// Test that MaxSourceSize limits the .properties input
+func TestPropertiesSource_MaxSourceSize(t *testing.T) {
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:    PropertiesSource(StringSource("# " + strings.Repeat("-", 1024) + "\napp.name=x\n")),
+		MaxSourceSize: 128,
+		Target:        &cfg,
+	})
+	assert.ErrorContains(t, err, "config source exceeds maximum size of 128 bytes")
+}
+
+// Test that INI sections become nested mappings
+func TestLoadConfig_INISource(t *testing.T) {
+	ini := `; top-level keys come first
+version = "2.0"
+
+[app]
+name = inisvc
+port = 8080
+
+[db]
+host: localhost
+port = 5432
+
+[app]
+debug = true
+`
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: INISource(ReaderSource(strings.NewReader(ini))),
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "2.0", cfg.Version)
+	assert.Equal(t, "inisvc", cfg.App.Name)
+	assert.Equal(t, 8080, cfg.App.Port)
+	assert.True(t, cfg.App.Debug)
+	assert.Equal(t, "localhost", cfg.DB.Host)
+	assert.Equal(t, 5432, cfg.DB.Port)
+}
+
+// Test that dotted section names nest and quoted values stay strings
+func TestParseINI_DottedSections(t *testing.T) {
+	root, err := parseINI([]byte("[db.primary]\nhost = a\nport = '5432'\n"))
+	require.NoError(t, err)
+
+	var out map[string]any
+	require.NoError(t, root.Decode(&out))
+	assert.Equal(t, map[string]any{"db": map[string]any{"primary": map[string]any{"host": "a", "port": "5432"}}}, out)
+}
+
+// Test that malformed INI lines report their line number
+func TestParseINI_Errors(t *testing.T) {
+	_, err := parseINI([]byte("[app]\nnot a pair\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2: expected key = value")
+
+	_, err = parseINI([]byte("[app\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unterminated section header")
+}
@@ -0,0 +1,48 @@
+package yamlenv
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// layerSources records, for every leaf path below section, the name of the
+// last layer that set it. Sequences count as leaves; keys removed with null
+// or !unset drop out together with everything below them.
+func layerSources(layers []configLayer, section string) map[string]string {
+	sources := map[string]string{}
+	for _, layer := range layers {
+		recordSources(lookupPath(layer.node, section), section, layer.name, sources)
+	}
+	return sources
+}
+
+func recordSources(n *yaml.Node, path, layer string, sources map[string]string) {
+	n = resolveAlias(n)
+	if n == nil {
+		return
+	}
+	if isUnsetNode(n) {
+		removeSources(sources, path)
+		return
+	}
+	if n.Kind != yaml.MappingNode {
+		removeSources(sources, path)
+		sources[path] = layer
+		return
+	}
+	content := flattenMapping(n)
+	for i := 0; i+1 < len(content); i += 2 {
+		recordSources(content[i+1], joinPath(path, content[i].Value), layer, sources)
+	}
+}
+
+// removeSources deletes path and every path below it
+func removeSources(sources map[string]string, path string) {
+	delete(sources, path)
+	for key := range sources {
+		if path == "" || strings.HasPrefix(key, path+".") {
+			delete(sources, key)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that LoadResult.Sources records the layer or variable behind each value
+func TestLoad_Sources(t *testing.T) {
+	setEnvVar(t, "SRC_DB__PORT", "6543")
+
+	var cfg TestConfig
+	result, err := Load(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader("app:\n  name: base\n  port: 8080\ndb:\n  host: db\n  port: 5432\nversion: \"1.0\"\n")),
+		LocalSource: ReaderSource(strings.NewReader("app:\n  port: 9090\nversion: !unset\n")),
+		EnvPrefix:   "SRC_",
+		Delimiter:   "__",
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"app.name": "base",
+		"app.port": "local",
+		"db.host":  "base",
+		"db.port":  "env:SRC_DB__PORT",
+	}, result.Sources)
+}
+
+// Test that sources use full paths when loading a section
+func TestLoad_SourcesForSection(t *testing.T) {
+	var cfg DBSection
+	result, err := Load(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("app:\n  name: svc\ndb:\n  host: db\n")),
+		Section:    "db",
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.host": "base"}, result.Sources)
+}
+
+// Test that replacing a mapping with a scalar drops the nested sources
+func TestLayerSources_ReplacedSubtree(t *testing.T) {
+	base, err := loadNodeFromSource(ReaderSource(strings.NewReader("cache:\n  driver: redis\n  addr: x\n")), parseLimits{})
+	require.NoError(t, err)
+	local, err := loadNodeFromSource(ReaderSource(strings.NewReader("cache: [a]\n")), parseLimits{})
+	require.NoError(t, err)
+
+	sources := layerSources([]configLayer{{name: "base", node: base}, {name: "local", node: local}}, "")
+
+	assert.Equal(t, map[string]string{"cache": "local"}, sources)
+}
@@ -0,0 +1,89 @@
+package yamlenv
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Raw captures a config subtree verbatim, after layers are merged, so a
+// plugin can decode it later into a type the loader doesn't know about:
+//
+//	type Config struct {
+//	    Plugins map[string]yamlenv.Raw `yaml:"plugins"`
+//	}
+//
+//	var authCfg auth.Config
+//	err := cfg.Plugins["auth"].Decode(&authCfg)
+//
+// An environment override for a Raw field is parsed as YAML (or JSON) and
+// replaces the whole subtree. Fields of type yaml.Node work the same way
+// for YAML input but are not overridden from the environment.
+type Raw struct {
+	node *yaml.Node
+}
+
+// IsSet reports whether any layer provided the subtree
+func (r Raw) IsSet() bool {
+	return r.node != nil
+}
+
+// Decode decodes the captured subtree into v. It does nothing if the
+// subtree wasn't set.
+func (r Raw) Decode(v any) error {
+	if r.node == nil {
+		return nil
+	}
+	return r.node.Decode(v)
+}
+
+// Node returns a copy of the captured subtree, or nil if it wasn't set
+func (r Raw) Node() *yaml.Node {
+	if r.node == nil {
+		return nil
+	}
+	return cloneNode(r.node)
+}
+
+// UnmarshalYAML captures node
+func (r *Raw) UnmarshalYAML(node *yaml.Node) error {
+	r.node = rawNode(node)
+	return nil
+}
+
+// MarshalYAML encodes the captured subtree, or null if unset
+func (r Raw) MarshalYAML() (any, error) {
+	if r.node == nil {
+		return nil, nil
+	}
+	return r.node, nil
+}
+
+// unmarshalEnv parses an environment override as a YAML document
+func (r *Raw) unmarshalEnv(value string) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &doc); err != nil {
+		return fmt.Errorf("parse raw value %q: %w", value, err)
+	}
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		r.node = rawNode(doc.Content[0])
+		return nil
+	}
+	r.node = nil
+	return nil
+}
+
+// rawNode copies n without source positions, so equal subtrees from
+// different reloads compare equal
+func rawNode(n *yaml.Node) *yaml.Node {
+	out := cloneNode(n)
+	var strip func(*yaml.Node)
+	strip = func(n *yaml.Node) {
+		n.Line, n.Column = 0, 0
+		for _, child := range n.Content {
+			strip(child)
+		}
+	}
+	strip(out)
+	return out
+}
@@ -0,0 +1,98 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type RawTestConfig struct {
+	Plugins map[string]Raw `yaml:"plugins"`
+	Hook    Raw            `yaml:"hook"`
+	Node    yaml.Node      `yaml:"node"`
+	Missing Raw            `yaml:"missing"`
+}
+
+const rawBaseYAML = `
+plugins:
+  auth:
+    provider: oidc
+    scopes: [openid]
+hook:
+  url: http://base
+node:
+  kind: custom
+`
+
+// Test that Raw and yaml.Node fields capture the merged subtree
+func TestLoadConfig_RawPassthrough(t *testing.T) {
+	type AuthConfig struct {
+		Provider string   `yaml:"provider"`
+		Scopes   []string `yaml:"scopes"`
+		Issuer   string   `yaml:"issuer"`
+	}
+
+	var cfg RawTestConfig
+	result, err := Load(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader(rawBaseYAML)),
+		LocalSource: ReaderSource(strings.NewReader("plugins:\n  auth:\n    issuer: https://idp\n")),
+		Target:      &cfg,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, result.UnusedKeys)
+
+	var auth AuthConfig
+	require.NoError(t, cfg.Plugins["auth"].Decode(&auth))
+	assert.Equal(t, AuthConfig{Provider: "oidc", Scopes: []string{"openid"}, Issuer: "https://idp"}, auth)
+
+	var kind map[string]string
+	require.NoError(t, cfg.Node.Decode(&kind))
+	assert.Equal(t, map[string]string{"kind": "custom"}, kind)
+
+	assert.False(t, cfg.Missing.IsSet())
+	assert.NoError(t, cfg.Missing.Decode(&kind))
+	assert.Nil(t, cfg.Missing.Node())
+}
+
+// Test that Raw fields can be replaced from the environment while yaml.Node fields aren't walked
+func TestLoadConfig_RawEnvOverride(t *testing.T) {
+	setEnvVar(t, "RAW_HOOK", `{"url": "http://env", "retries": 3}`)
+	setEnvVar(t, "RAW_NODE__VALUE", "ignored")
+
+	var cfg RawTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader(rawBaseYAML)),
+		EnvPrefix:  "RAW_",
+		Delimiter:  "__",
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+
+	var hook map[string]any
+	require.NoError(t, cfg.Hook.Decode(&hook))
+	assert.Equal(t, map[string]any{"url": "http://env", "retries": 3}, hook)
+	assert.Equal(t, "", cfg.Node.Value)
+}
+
+// Test that reloading unchanged Raw subtrees reports no changes
+func TestLoader_RawStableAcrossReloads(t *testing.T) {
+	file := createTempYAML(t, rawBaseYAML)
+
+	var cfg RawTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(file), Target: &cfg})
+	require.NoError(t, err)
+
+	var changes []Change
+	loader.OnChange(func(c []Change) { changes = append(changes, c...) })
+
+	writeFile(t, file, "\n\n"+rawBaseYAML)
+	require.NoError(t, loader.Reload())
+
+	for _, c := range changes {
+		assert.NotContains(t, c.Path, "plugins")
+		assert.NotContains(t, c.Path, "hook")
+	}
+}
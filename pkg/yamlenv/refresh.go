@@ -0,0 +1,47 @@
+package yamlenv
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// RefreshOptions configures Loader.Refresh
+type RefreshOptions struct {
+	Interval time.Duration // time between refreshes; default 1m
+	Jitter   time.Duration // random extra delay up to Jitter added to each interval, to spread fleet load
+	OnError  func(error)   // optional; called when a refresh fails
+}
+
+// Refresh periodically reloads the configuration, for remote sources such
+// as HTTPSource that can't be watched. It blocks until ctx is done and
+// returns ctx.Err(). A failed refresh keeps the current configuration.
+func (l *Loader) Refresh(ctx context.Context, opts RefreshOptions) error {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	timer := time.NewTimer(nextRefresh(interval, opts.Jitter))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			if err := l.Reload(); err != nil && opts.OnError != nil {
+				opts.OnError(err)
+			}
+			timer.Reset(nextRefresh(interval, opts.Jitter))
+		}
+	}
+}
+
+// nextRefresh returns interval plus a random delay in [0, jitter)
+func nextRefresh(interval, jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return interval
+	}
+	return interval + time.Duration(rand.Int64N(int64(jitter)))
+}
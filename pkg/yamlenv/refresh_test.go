@@ -0,0 +1,81 @@
+package yamlenv
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that Refresh picks up remote changes periodically
+func TestLoader_RefreshRemote(t *testing.T) {
+	srv := &configServer{body: "app:\n  port: 1\n", etag: `"v1"`}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	var cfg LoaderTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: HTTPSource(ts.URL), Target: &cfg})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go loader.Refresh(ctx, RefreshOptions{Interval: 5 * time.Millisecond, Jitter: 5 * time.Millisecond})
+
+	srv.set("app:\n  port: 2\n", `"v2"`)
+	require.Eventually(t, func() bool {
+		return loader.Current().(*LoaderTestConfig).App.Port == 2
+	}, 2*time.Second, 5*time.Millisecond)
+}
+
+// Test that refresh failures are reported and the current config is kept
+func TestLoader_RefreshErrors(t *testing.T) {
+	fail := false
+	source := func() (io.ReadCloser, error) {
+		if fail {
+			return nil, errors.New("remote down")
+		}
+		return ReaderSource(strings.NewReader("app:\n  port: 1\n"))()
+	}
+
+	var cfg LoaderTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: source, Target: &cfg})
+	require.NoError(t, err)
+	fail = true
+
+	errs := make(chan error, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- loader.Refresh(ctx, RefreshOptions{Interval: 5 * time.Millisecond, OnError: func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		}})
+	}()
+
+	select {
+	case err := <-errs:
+		assert.Contains(t, err.Error(), "remote down")
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected refresh error")
+	}
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Equal(t, 1, loader.Current().(*LoaderTestConfig).App.Port)
+}
+
+func TestNextRefresh(t *testing.T) {
+	assert.Equal(t, time.Second, nextRefresh(time.Second, 0))
+	for i := 0; i < 20; i++ {
+		d := nextRefresh(time.Second, 100*time.Millisecond)
+		assert.GreaterOrEqual(t, d, time.Second)
+		assert.Less(t, d, 1100*time.Millisecond)
+	}
+}
@@ -0,0 +1,168 @@
+package yamlenv
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"time"
+)
+
+// Lease is a credential returned by a RotateFunc
+type Lease struct {
+	Value any           // the credential; strings are parsed like env values, others must fit the field
+	TTL   time.Duration // time until the credential expires; <= 0 means it doesn't
+}
+
+// RotateFunc fetches a fresh credential for the config value at path, e.g.
+// from Vault or AWS SSM
+type RotateFunc func(ctx context.Context, path string) (Lease, error)
+
+// RotateOptions configures Loader.Rotate
+type RotateOptions struct {
+	Paths   []string      // dot-separated paths of the values to rotate, e.g. "db.password"
+	Fetch   RotateFunc    // fetches a new lease for one path
+	Before  time.Duration // renew this long before a lease expires; default a third of its TTL
+	Retry   time.Duration // delay before retrying a failed fetch; default 30s
+	OnError func(error)   // optional; called when a fetch or update fails
+}
+
+// Rotate keeps short-lived credentials current. It fetches a lease for each
+// path right away and again ahead of every expiry, and updates just those
+// values: the sources aren't read again, and subscribers of a changed path
+// are notified as after a reload. Rotated values take precedence over every
+// layer and runtime override, and survive later reloads. A failed fetch
+// keeps the current value and is retried after Retry.
+//
+// Rotate blocks until ctx is done and returns ctx.Err().
+func (l *Loader) Rotate(ctx context.Context, opts RotateOptions) error {
+	if opts.Fetch == nil {
+		return fmt.Errorf("rotate: Fetch cannot be nil")
+	}
+	retry := opts.Retry
+	if retry <= 0 {
+		retry = 30 * time.Second
+	}
+
+	due := make(map[string]time.Time, len(opts.Paths))
+	for _, path := range opts.Paths {
+		due[path] = time.Now()
+	}
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-timer.C:
+			for _, path := range opts.Paths {
+				at, ok := due[path]
+				if !ok || at.After(now) {
+					continue
+				}
+				if next, renew := l.rotate(ctx, path, opts, retry); renew {
+					due[path] = time.Now().Add(next)
+				} else {
+					delete(due, path)
+				}
+			}
+			if len(due) == 0 {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			var next time.Time
+			for _, at := range due {
+				if next.IsZero() || at.Before(next) {
+					next = at
+				}
+			}
+			timer.Reset(time.Until(next))
+		}
+	}
+}
+
+// rotate fetches and applies one lease, returning the delay until the next
+// fetch; renew is false for a lease that doesn't expire
+func (l *Loader) rotate(ctx context.Context, path string, opts RotateOptions, retry time.Duration) (next time.Duration, renew bool) {
+	lease, err := opts.Fetch(ctx, path)
+	if err == nil {
+		err = l.setRotated(path, lease)
+	}
+	if err != nil {
+		if opts.OnError != nil && ctx.Err() == nil {
+			opts.OnError(fmt.Errorf("rotate %s: %w", path, err))
+		}
+		return retry, true
+	}
+	if lease.TTL <= 0 {
+		return 0, false
+	}
+	before := opts.Before
+	if before <= 0 {
+		before = lease.TTL / 3
+	}
+	if next = lease.TTL - before; next <= 0 {
+		next = lease.TTL / 2
+	}
+	return next, true
+}
+
+// setRotated sets a rotated value on a copy of the current config, publishes
+// it and notifies subscribers
+func (l *Loader) setRotated(path string, lease Lease) error {
+	l.notifyMu.Lock()
+	defer l.notifyMu.Unlock()
+	l.mu.Lock()
+	previous := l.current
+	fresh := deepCopy(previous)
+	field, ok := lookupField(fresh, path)
+	if !ok || !field.CanSet() {
+		l.mu.Unlock()
+		return fmt.Errorf("no settable config value at this path")
+	}
+	if err := setOverrideValue(field, lease.Value); err != nil {
+		l.mu.Unlock()
+		return err
+	}
+	result := *l.result
+	result.Sources = maps.Clone(l.result.Sources)
+	result.Sources[path] = "rotation"
+
+	l.rotated[path] = lease.Value
+	if lease.TTL > 0 {
+		l.leases[path] = time.Now().Add(lease.TTL)
+	} else {
+		delete(l.leases, path)
+	}
+	l.current, l.result = fresh, &result
+	reflect.ValueOf(l.opts.Target).Elem().Set(deepCopy(fresh).Elem())
+	l.mu.Unlock()
+
+	l.notify(previous, fresh)
+	return nil
+}
+
+// applyRotated sets the rotated values on a freshly loaded config; l.mu
+// must be held
+func (l *Loader) applyRotated(cfg reflect.Value, result *LoadResult) error {
+	for path, value := range l.rotated {
+		field, ok := lookupField(cfg, path)
+		if !ok || !field.CanSet() {
+			continue
+		}
+		if err := setOverrideValue(field, value); err != nil {
+			return fmt.Errorf("rotated %s: %w", path, err)
+		}
+		result.Sources[path] = "rotation"
+	}
+	return nil
+}
+
+// Leases returns when each rotated value expires, for paths whose current
+// lease has a TTL
+func (l *Loader) Leases() map[string]time.Time {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return maps.Clone(l.leases)
+}
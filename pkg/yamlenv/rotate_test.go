@@ -0,0 +1,117 @@
+package yamlenv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RotateTestConfig holds a rotated credential
+type RotateTestConfig struct {
+	DB struct {
+		User     string         `yaml:"user"`
+		Password Secret[string] `yaml:"password"`
+	} `yaml:"db"`
+}
+
+// Test that leases are fetched up front, renewed before expiry and kept across reloads
+func TestLoader_Rotate(t *testing.T) {
+	var cfg RotateTestConfig
+	loader, err := NewLoader(LoaderOptions{
+		BaseSource: FileSource(createTempYAML(t, "db:\n  user: app\n  password: static\n")),
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var seen []string
+	loader.Subscribe("db.password", func(old, new any) {
+		mu.Lock()
+		defer mu.Unlock()
+		seen = append(seen, new.(Secret[string]).Value())
+	})
+
+	var fetches atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() {
+		done <- loader.Rotate(ctx, RotateOptions{
+			Paths: []string{"db.password"},
+			Fetch: func(ctx context.Context, path string) (Lease, error) {
+				n := fetches.Add(1)
+				return Lease{Value: fmt.Sprintf("pass-%d", n), TTL: 30 * time.Millisecond}, nil
+			},
+		})
+	}()
+
+	require.Eventually(t, func() bool { return fetches.Load() >= 3 }, 2*time.Second, 5*time.Millisecond)
+	require.NoError(t, loader.Reload())
+	current := loader.Current().(*RotateTestConfig)
+	assert.Equal(t, "app", current.DB.User)
+	assert.Contains(t, current.DB.Password.Value(), "pass-", "reloads keep the rotated value")
+	assert.Equal(t, "rotation", loader.Result().Sources["db.password"])
+	assert.Contains(t, loader.Leases(), "db.password")
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	mu.Lock()
+	defer mu.Unlock()
+	require.GreaterOrEqual(t, len(seen), 3)
+	assert.Equal(t, []string{"pass-1", "pass-2", "pass-3"}, seen[:3])
+}
+
+// Test that failed fetches are reported, keep the current value and are retried
+func TestLoader_RotateErrors(t *testing.T) {
+	var cfg RotateTestConfig
+	loader, err := NewLoader(LoaderOptions{
+		BaseSource: FileSource(createTempYAML(t, "db:\n  password: static\n")),
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+
+	var fetches atomic.Int32
+	errs := make(chan error, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go loader.Rotate(ctx, RotateOptions{
+		Paths: []string{"db.password", "db.missing"},
+		Fetch: func(ctx context.Context, path string) (Lease, error) {
+			if path == "db.missing" {
+				return Lease{Value: "x"}, nil
+			}
+			if fetches.Add(1) == 1 {
+				return Lease{}, errors.New("vault sealed")
+			}
+			return Lease{Value: "fresh"}, nil
+		},
+		Retry: 10 * time.Millisecond,
+		OnError: func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		},
+	})
+
+	require.Eventually(t, func() bool {
+		return loader.Current().(*RotateTestConfig).DB.Password.Value() == "fresh"
+	}, 2*time.Second, 5*time.Millisecond)
+	var messages []string
+	for len(errs) > 0 {
+		messages = append(messages, (<-errs).Error())
+	}
+	assert.Contains(t, messages, "rotate db.password: vault sealed")
+	assert.Contains(t, messages, "rotate db.missing: no settable config value at this path")
+	assert.Empty(t, loader.Leases(), "leases without TTL have no expiry")
+
+	err = loader.Rotate(ctx, RotateOptions{})
+	assert.EqualError(t, err, "rotate: Fetch cannot be nil")
+}
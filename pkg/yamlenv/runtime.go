@@ -0,0 +1,118 @@
+package yamlenv
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// runtimeKey is the reserved top-level key that RuntimeSource is merged under
+const runtimeKey = "runtime"
+
+// DownwardAPISource creates a ConfigSource from a Kubernetes Downward API
+// volume mounted at dir. Each file becomes a key named after the file, with
+// dashes replaced by underscores; the "labels" and "annotations" files are
+// parsed into maps. Use it as LoaderOptions.RuntimeSource:
+//
+//	# pod spec
+//	volumes:
+//	  - name: podinfo
+//	    downwardAPI:
+//	      items:
+//	        - path: namespace
+//	          fieldRef: {fieldPath: metadata.namespace}
+//	        - path: node-name
+//	          fieldRef: {fieldPath: spec.nodeName}
+//	        - path: labels
+//	          fieldRef: {fieldPath: metadata.labels}
+//
+// yields runtime.namespace, runtime.node_name and runtime.labels.<key>.
+func DownwardAPISource(dir string) ConfigSource {
+	return func() (io.ReadCloser, error) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("read downward API dir: %w", err)
+		}
+
+		values := map[string]any{}
+		for _, entry := range entries {
+			// Skip the ..data / ..<timestamp> entries Kubernetes uses for atomic updates
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("read downward API file %s: %w", entry.Name(), err)
+			}
+
+			key := strings.ReplaceAll(entry.Name(), "-", "_")
+			switch key {
+			case "labels", "annotations":
+				parsed, err := parseDownwardAPIMap(data)
+				if err != nil {
+					return nil, fmt.Errorf("parse downward API file %s: %w", entry.Name(), err)
+				}
+				values[key] = parsed
+			default:
+				values[key] = strings.TrimSpace(string(data))
+			}
+		}
+
+		data, err := yaml.Marshal(values)
+		if err != nil {
+			return nil, fmt.Errorf("marshal downward API data: %w", err)
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+}
+
+// parseDownwardAPIMap parses the key="value" lines written for labels and
+// annotations
+func parseDownwardAPIMap(data []byte) (map[string]string, error) {
+	out := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		key, quoted, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid line %q", line)
+		}
+		value, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		out[key] = value
+	}
+	return out, scanner.Err()
+}
+
+// nestUnder wraps a layer's root node in a mapping with the single key
+// key, so its values live in a reserved subtree
+func nestUnder(key string, n *yaml.Node) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	return &yaml.Node{
+		Kind: yaml.MappingNode,
+		Tag:  "!!map",
+		Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+			n,
+		},
+	}
+}
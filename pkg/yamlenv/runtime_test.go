@@ -0,0 +1,70 @@
+package yamlenv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type RuntimeTestConfig struct {
+	App struct {
+		Name string `yaml:"name"`
+	} `yaml:"app"`
+	Runtime struct {
+		Namespace string            `yaml:"namespace"`
+		NodeName  string            `yaml:"node_name"`
+		Labels    map[string]string `yaml:"labels"`
+	} `yaml:"runtime"`
+}
+
+func createPodInfo(t *testing.T) string {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "namespace"), "prod\n")
+	writeFile(t, filepath.Join(dir, "node-name"), "node-7")
+	writeFile(t, filepath.Join(dir, "labels"), "app=\"checkout\"\nteam=\"payments \\\"core\\\"\"\n")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "..data"), 0o755))
+	return dir
+}
+
+// Test that Downward API files are exposed under runtime.*
+func TestLoadConfig_RuntimeSource(t *testing.T) {
+	var cfg RuntimeTestConfig
+	result, err := Load(LoaderOptions{
+		BaseSource:    ReaderSource(strings.NewReader("app:\n  name: svc\nruntime:\n  namespace: from-yaml\n")),
+		RuntimeSource: DownwardAPISource(createPodInfo(t)),
+		Target:        &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "svc", cfg.App.Name)
+	assert.Equal(t, "prod", cfg.Runtime.Namespace)
+	assert.Equal(t, "node-7", cfg.Runtime.NodeName)
+	assert.Equal(t, map[string]string{"app": "checkout", "team": `payments "core"`}, cfg.Runtime.Labels)
+	assert.Equal(t, "runtime", result.Sources["runtime.namespace"])
+}
+
+// Test that Downward API errors are attributed to the runtime layer
+func TestLoadConfig_RuntimeSourceErrors(t *testing.T) {
+	var cfg RuntimeTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:    ReaderSource(strings.NewReader("app:\n  name: svc\n")),
+		RuntimeSource: DownwardAPISource(filepath.Join(t.TempDir(), "missing")),
+		Target:        &cfg,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "load runtime config")
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "labels"), "broken\n")
+	err = LoadConfig(LoaderOptions{
+		BaseSource:    ReaderSource(strings.NewReader("app:\n  name: svc\n")),
+		RuntimeSource: DownwardAPISource(dir),
+		Target:        &cfg,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parse downward API file labels")
+}
@@ -0,0 +1,43 @@
+package yamlenv
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Validator checks a configuration document decoded into plain Go values
+// (map[string]any, []any, string, int, float64, bool). It is the extension
+// point for schema languages such as CUE or JSON Schema: wrap the
+// evaluator's validate call and return its error unchanged so its
+// constraint messages reach the caller. The separate
+// github.com/tendant/yamlenv/pkg/yamlenvcue module provides one for CUE.
+type Validator interface {
+	Validate(doc map[string]any) error
+}
+
+// ValidatorFunc adapts a function to the Validator interface
+type ValidatorFunc func(doc map[string]any) error
+
+// Validate calls f(doc)
+func (f ValidatorFunc) Validate(doc map[string]any) error {
+	return f(doc)
+}
+
+// validateSchema checks the merged document (or its Section subtree)
+// against opts.Schema before it is bound into the target
+func validateSchema(opts LoaderOptions, merged *yaml.Node) error {
+	if opts.Schema == nil {
+		return nil
+	}
+	doc := map[string]any{}
+	if n := lookupPath(merged, opts.Section); n != nil {
+		if err := n.Decode(&doc); err != nil {
+			return fmt.Errorf("schema validation: %w", err)
+		}
+	}
+	if err := opts.Schema.Validate(doc); err != nil {
+		return fmt.Errorf("schema validation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package yamlenv
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the schema sees the merged document before binding
+func TestLoadConfig_SchemaSeesMergedDocument(t *testing.T) {
+	var seen map[string]any
+	schema := ValidatorFunc(func(doc map[string]any) error {
+		seen = doc
+		return nil
+	})
+
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader("app:\n  name: base\n  port: 8080\n")),
+		LocalSource: ReaderSource(strings.NewReader("app:\n  port: 9090\n")),
+		Schema:      schema,
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"app": map[string]any{"name": "base", "port": 9090}}, seen)
+}
+
+// Test that schema errors fail the load and are passed through unchanged
+func TestLoadConfig_SchemaViolation(t *testing.T) {
+	violation := errors.New("app.port: invalid value 80 (out of bound >1024)")
+	schema := ValidatorFunc(func(doc map[string]any) error {
+		if app, _ := doc["app"].(map[string]any); app["port"] == 80 {
+			return violation
+		}
+		return nil
+	})
+
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("app:\n  port: 80\n")),
+		Schema:     schema,
+		Target:     &cfg,
+	})
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, violation)
+	assert.Contains(t, err.Error(), "schema validation: app.port: invalid value 80")
+	assert.Zero(t, cfg.App.Port)
+}
+
+// Test that the schema is checked against the bound section only
+func TestLoadConfig_SchemaSection(t *testing.T) {
+	var seen map[string]any
+	schema := ValidatorFunc(func(doc map[string]any) error {
+		seen = doc
+		return nil
+	})
+
+	var db struct {
+		Host string `yaml:"host"`
+	}
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("app:\n  name: x\ndb:\n  host: h\n")),
+		Section:    "db",
+		Schema:     schema,
+		Target:     &db,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"host": "h"}, seen)
+}
@@ -0,0 +1,113 @@
+package yamlenv
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Secret wraps a sensitive configuration value so it can't be logged by
+// accident: fmt, encoding/json, YAML output and slog all print "***", and
+// only Value returns the real data. It is decoded from YAML and set from
+// environment variables like the wrapped type, and fields of type Secret are
+// treated as `secret:"true"` everywhere (Handler, Plan, Lint, EnvVars).
+//
+//	type Config struct {
+//	    DB struct {
+//	        Password yamlenv.Secret[string] `yaml:"password"`
+//	    } `yaml:"db"`
+//	}
+//
+//	db.Connect(cfg.DB.Password.Value())
+type Secret[T any] struct {
+	value T
+}
+
+// NewSecret returns a Secret holding v
+func NewSecret[T any](v T) Secret[T] {
+	return Secret[T]{value: v}
+}
+
+// Value returns the wrapped value
+func (s Secret[T]) Value() T {
+	return s.value
+}
+
+// String returns "***"
+func (s Secret[T]) String() string {
+	return redacted
+}
+
+// GoString returns "***", so %#v doesn't reveal the value either
+func (s Secret[T]) GoString() string {
+	return redacted
+}
+
+// Format prints "***" for every verb
+func (s Secret[T]) Format(f fmt.State, verb rune) {
+	fmt.Fprint(f, redacted)
+}
+
+// MarshalJSON encodes the secret as "***"
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + redacted + `"`), nil
+}
+
+// MarshalYAML encodes the secret as "***"
+func (s Secret[T]) MarshalYAML() (any, error) {
+	return redacted, nil
+}
+
+// LogValue logs the secret as "***" with log/slog
+func (s Secret[T]) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+// UnmarshalYAML decodes the wrapped value. A Secret[[]byte] takes scalars
+// as raw bytes; !!binary values are base64-decoded.
+func (s *Secret[T]) UnmarshalYAML(node *yaml.Node) error {
+	if b, ok := any(&s.value).(*[]byte); ok && node.Kind == yaml.ScalarNode {
+		var str string
+		if err := node.Decode(&str); err != nil {
+			return err
+		}
+		*b = []byte(str)
+		return nil
+	}
+	var v T
+	if err := node.Decode(&v); err != nil {
+		return err
+	}
+	s.value = v
+	return nil
+}
+
+// unmarshalEnv parses an environment override into the wrapped value; a
+// Secret[[]byte] takes the raw bytes of the variable
+func (s *Secret[T]) unmarshalEnv(value string) error {
+	if b, ok := any(&s.value).(*[]byte); ok {
+		*b = []byte(value)
+		return nil
+	}
+	v := reflect.New(reflect.TypeOf((*T)(nil)).Elem()).Elem()
+	if err := setFieldValue(v, value); err != nil {
+		return err
+	}
+	s.value = v.Interface().(T)
+	return nil
+}
+
+// secretValue returns the wrapped value for code that must see it, like the
+// Defaults layer
+func (s Secret[T]) secretValue() any {
+	return s.value
+}
+
+// secretHolder is implemented by Secret
+type secretHolder interface {
+	secretValue() any
+}
+
+var secretHolderType = reflect.TypeOf((*secretHolder)(nil)).Elem()
@@ -0,0 +1,160 @@
+package yamlenv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// SecretTestConfig holds Secret fields
+type SecretTestConfig struct {
+	DB struct {
+		User     string         `yaml:"user"`
+		Password Secret[string] `yaml:"password"`
+		Key      Secret[[]byte] `yaml:"key"`
+		Pin      Secret[int]    `yaml:"pin"`
+	} `yaml:"db"`
+}
+
+// Test that Secret values decode from YAML and env but print as ***
+func TestSecret_LoadAndRedact(t *testing.T) {
+	setEnvVar(t, "SEC_DB__KEY", "raw-key")
+	setEnvVar(t, "SEC_DB__PIN", "1234")
+
+	var cfg SecretTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("db:\n  user: app\n  password: hunter2\n  key: from-yaml\n")),
+		EnvPrefix:  "SEC_",
+		Delimiter:  "__",
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "hunter2", cfg.DB.Password.Value())
+	assert.Equal(t, []byte("raw-key"), cfg.DB.Key.Value())
+	assert.Equal(t, 1234, cfg.DB.Pin.Value())
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x", "%d"} {
+		out := fmt.Sprintf(format, cfg)
+		assert.NotContains(t, out, "hunter2", format)
+		assert.NotContains(t, out, "1234", format)
+	}
+	assert.Equal(t, "{{app *** *** ***}}", fmt.Sprintf("%v", cfg))
+
+	data, err := json.Marshal(cfg.DB)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"User":"app","Password":"***","Key":"***","Pin":"***"}`, string(data))
+
+	data, err = yaml.Marshal(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "db:\n    user: app\n    password: '***'\n    key: '***'\n    pin: '***'\n", string(data))
+
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("connect", "password", cfg.DB.Password)
+	assert.Contains(t, buf.String(), "password=***")
+}
+
+// Test that Secret fields count as secret without a tag
+func TestSecret_TreatedAsSecret(t *testing.T) {
+	var cfg SecretTestConfig
+	report, err := Plan(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("db:\n  password: hunter2\n")),
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "***", report.Values["db.password"])
+
+	cfg.DB.Password = NewSecret("hunter2")
+	vars, err := EnvVars(LoaderOptions{EnvPrefix: "SEC_", Delimiter: "__", Target: &cfg})
+	require.NoError(t, err)
+	require.Len(t, vars, 4)
+	assert.Equal(t, EnvVar{Name: "SEC_DB__PASSWORD", Path: "db.password", Type: "string", Secret: true, Value: "hunter2"}, vars[1])
+
+	issues := Lint(LintOptions{LoaderOptions: LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("db:\n  password: hunter2\n")),
+		Target:     &SecretTestConfig{},
+	}})
+	assert.Equal(t, []string{"secret-in-yaml db.password"}, issueKeys(issues))
+}
+
+// Test that a Secret in Defaults seeds the real value
+func TestSecret_Defaults(t *testing.T) {
+	var defaults SecretTestConfig
+	defaults.DB.Password = NewSecret("default-pass")
+
+	var cfg SecretTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("")),
+		Defaults:   defaults,
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "default-pass", cfg.DB.Password.Value())
+}
+
+// Test that Secret[[]byte] reads plain strings as bytes and !!binary as base64
+func TestSecret_Bytes(t *testing.T) {
+	var v struct {
+		Plain  Secret[[]byte] `yaml:"plain"`
+		Binary Secret[[]byte] `yaml:"binary"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte("plain: hi\nbinary: !!binary aGk=\n"), &v))
+	assert.Equal(t, []byte("hi"), v.Plain.Value())
+	assert.Equal(t, []byte("hi"), v.Binary.Value())
+}
+
+// Test that DebugKeys logs secret overrides as ***, like EnvOverrides
+func TestSecret_DebugKeysRedacted(t *testing.T) {
+	setEnvVar(t, "DBGSEC_DB__USER", "admin")
+	setEnvVar(t, "DBGSEC_DB__PASSWORD", "hunter2")
+	setEnvVar(t, "DBGSEC_TOKEN", "tok-123")
+	setEnvVar(t, "DBGSEC_PASSWORD", "gen-pass")
+
+	load := func(target any) []string {
+		logger := &recordingLogger{}
+		require.NoError(t, LoadConfig(LoaderOptions{
+			BaseSource: StringSource("db:\n  user: app\n"),
+			EnvPrefix:  "DBGSEC_",
+			Delimiter:  "__",
+			DebugKeys:  true,
+			Logger:     logger,
+			Target:     target,
+		}))
+		return logger.lines
+	}
+
+	var cfg struct {
+		SecretTestConfig `yaml:",inline"`
+		Token            string `yaml:"token" secret:"true"`
+	}
+	assert.ElementsMatch(t, []string{
+		"[yamlenv] applying env override: db.user = admin (from DBGSEC_DB__USER)",
+		"[yamlenv] applying env override: db.password = *** (from DBGSEC_DB__PASSWORD)",
+		"[yamlenv] applying env override: token = *** (from DBGSEC_TOKEN)",
+	}, load(&cfg))
+
+	// Generated BindEnv code
+	assert.Contains(t, load(&GenConfig{}), "[yamlenv] applying env override: password = *** (from DBGSEC_PASSWORD)")
+
+	// Values, without a Target, by key name
+	logger := &recordingLogger{}
+	_, err := LoadValues(LoaderOptions{
+		BaseSource: StringSource("db:\n  user: app\n  password: x\n"),
+		EnvPrefix:  "DBGSEC_",
+		Delimiter:  "__",
+		DebugKeys:  true,
+		Logger:     logger,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"[yamlenv] applying env override: db.user = admin (from DBGSEC_DB__USER)",
+		"[yamlenv] applying env override: db.password = *** (from DBGSEC_DB__PASSWORD)",
+	}, logger.lines)
+}
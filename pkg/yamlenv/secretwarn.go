@@ -0,0 +1,44 @@
+package yamlenv
+
+import (
+	"reflect"
+	"strings"
+)
+
+// warnSecretsInYAML logs a warning for each secret value in result that
+// was read from a plain YAML file, when opts.WarnSecretsInYAML is set.
+// Besides fields SecretsInYAMLRule reports, string fields whose name looks
+// like a credential ("password", "api_key", ...) count as secret here, so
+// teams are nudged towards SecretsSource before a field is ever tagged.
+func warnSecretsInYAML(opts LoaderOptions, result *LoadResult) {
+	if !opts.WarnSecretsInYAML {
+		return
+	}
+	logger := loggerOrDefault(opts.Logger)
+	c := &LintContext{Config: opts.Target, Result: result, section: opts.Section}
+	for _, path := range c.Paths() {
+		source := result.Sources[path]
+		if !isPlainYAMLLayer(source) {
+			continue
+		}
+		v, ok := c.Value(path)
+		if ok && reflect.ValueOf(v).IsZero() {
+			continue
+		}
+		if !c.isSecret(path) && !(ok && reflect.ValueOf(v).Kind() == reflect.String && isSensitiveKey(lastSegment(path))) {
+			continue
+		}
+		logger.Printf("[yamlenv] warning: %s: %s looks like a secret in plain YAML; move it to SecretsSource or the environment", layerTitle(source, result.Labels[source]), path)
+	}
+}
+
+// isPlainYAMLLayer reports whether source names a layer read from a YAML
+// file that is usually committed: base, local or a tenant file
+func isPlainYAMLLayer(source string) bool {
+	return source == "base" || source == "local" || strings.HasPrefix(source, "tenant:")
+}
+
+// lastSegment returns the last key of a dot-separated path
+func lastSegment(path string) string {
+	return path[strings.LastIndex(path, ".")+1:]
+}
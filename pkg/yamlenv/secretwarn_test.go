@@ -0,0 +1,74 @@
+package yamlenv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type SecretWarnConfig struct {
+	DB struct {
+		Host     string `yaml:"host"`
+		Password string `yaml:"password"`
+		Token    string `yaml:"token" secret:"true"`
+	} `yaml:"db"`
+	APIKey   string            `yaml:"api_key"`
+	TokenTTL time.Duration     `yaml:"token_ttl"`
+	Extra    map[string]string `yaml:"extra"`
+}
+
+const secretWarnYAML = `
+db:
+  host: db.internal
+  password: hunter2
+  token: abc
+api_key: ""
+token_ttl: 1h
+extra:
+  signing_key: k1
+  region: eu
+`
+
+// Test that secret-looking values from YAML files are warned about, and
+// values from the environment or secrets layer are not
+func TestLoad_WarnSecretsInYAML(t *testing.T) {
+	t.Setenv("SW_DB__TOKEN", "from-env")
+
+	logger := &recordingLogger{}
+	var cfg SecretWarnConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:        StringSource(secretWarnYAML),
+		SecretsSource:     StringSource("db:\n  password: from-secrets\n"),
+		LocalSource:       StringSource("extra:\n  signing_key: k2\n"),
+		EnvPrefix:         "SW_",
+		Delimiter:         "__",
+		WarnSecretsInYAML: true,
+		Logger:            logger,
+		Target:            &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`[yamlenv] warning: local: extra.signing_key looks like a secret in plain YAML; move it to SecretsSource or the environment`,
+	}, logger.lines)
+}
+
+// Test that tagged and name-matched fields are both reported, and that
+// nothing is logged unless enabled
+func TestLoad_WarnSecretsInYAMLFields(t *testing.T) {
+	opts := LoaderOptions{BaseSource: StringSource(secretWarnYAML), Target: &SecretWarnConfig{}}
+	logger := &recordingLogger{}
+	opts.Logger = logger
+	require.NoError(t, LoadConfig(opts))
+	assert.Empty(t, logger.lines)
+
+	opts.WarnSecretsInYAML = true
+	require.NoError(t, LoadConfig(opts))
+	assert.Equal(t, []string{
+		`[yamlenv] warning: base: db.password looks like a secret in plain YAML; move it to SecretsSource or the environment`,
+		`[yamlenv] warning: base: db.token looks like a secret in plain YAML; move it to SecretsSource or the environment`,
+		`[yamlenv] warning: base: extra.signing_key looks like a secret in plain YAML; move it to SecretsSource or the environment`,
+	}, logger.lines)
+}
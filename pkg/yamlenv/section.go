@@ -0,0 +1,40 @@
+package yamlenv
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadSection merges all layers like LoadConfig but binds only the subtree at
+// section (a dot-separated path such as "db" or "services.cache") into target.
+// Environment overrides keep their full names, so with EnvPrefix "MYAPP_" the
+// "db" section's host is still overridden by MYAPP_DB__HOST.
+//
+// This lets library packages load just their own section without knowing the
+// application's full config type.
+func LoadSection(opts LoaderOptions, section string, target any) error {
+	opts.Section = section
+	opts.Target = target
+	return LoadConfig(opts)
+}
+
+// lookupPath returns the node at a dot-separated path below root, or nil if
+// any segment is missing. An empty path returns root itself.
+func lookupPath(root *yaml.Node, path string) *yaml.Node {
+	if path == "" {
+		return root
+	}
+	node := resolveAlias(root)
+	for _, key := range strings.Split(path, ".") {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+		idx := mappingIndex(node, key)
+		if idx < 0 {
+			return nil
+		}
+		node = resolveAlias(node.Content[idx+1])
+	}
+	return node
+}
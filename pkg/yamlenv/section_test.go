@@ -0,0 +1,101 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type DBSection struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+	Name string `yaml:"name"`
+}
+
+const sectionBaseYAML = `
+app:
+  name: app
+db:
+  host: localhost
+  port: 5432
+  name: appdb
+services:
+  cache:
+    host: cache.local
+    port: 6379
+`
+
+// Test that only the named subtree is bound, merged across layers
+func TestLoadSection_MergesLayers(t *testing.T) {
+	var db DBSection
+	err := LoadSection(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader(sectionBaseYAML)),
+		LocalSource: ReaderSource(strings.NewReader("db:\n  host: dev-db\n")),
+	}, "db", &db)
+
+	require.NoError(t, err)
+	assert.Equal(t, "dev-db", db.Host)
+	assert.Equal(t, 5432, db.Port)
+	assert.Equal(t, "appdb", db.Name)
+}
+
+// Test that env overrides use the full path of the section
+func TestLoadSection_EnvOverrides(t *testing.T) {
+	setEnvVar(t, "SECTION_DB__PORT", "6543")
+	setEnvVar(t, "SECTION_PORT", "1")
+
+	var db DBSection
+	err := LoadSection(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader(sectionBaseYAML)),
+		EnvPrefix:  "SECTION_",
+		Delimiter:  "__",
+	}, "db", &db)
+
+	require.NoError(t, err)
+	assert.Equal(t, 6543, db.Port)
+	assert.Equal(t, "localhost", db.Host)
+}
+
+// Test nested section paths via the Section option
+func TestLoadConfig_NestedSection(t *testing.T) {
+	type CacheSection struct {
+		Host string `yaml:"host"`
+		Port int    `yaml:"port"`
+	}
+
+	var cache CacheSection
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader(sectionBaseYAML)),
+		Section:    "services.cache",
+		Target:     &cache,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "cache.local", cache.Host)
+	assert.Equal(t, 6379, cache.Port)
+}
+
+// Test that a missing section leaves the target untouched
+func TestLoadSection_MissingSection(t *testing.T) {
+	db := DBSection{Host: "default"}
+	err := LoadSection(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader(sectionBaseYAML)),
+	}, "nope", &db)
+
+	require.NoError(t, err)
+	assert.Equal(t, "default", db.Host)
+}
+
+// Test that type errors inside the section are attributed to the layer
+func TestLoadSection_TypeError(t *testing.T) {
+	var db DBSection
+	err := LoadSection(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader(sectionBaseYAML)),
+		LocalSource: ReaderSource(strings.NewReader("db:\n  port: not-a-port\n")),
+	}, "db", &db)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "load local config")
+}
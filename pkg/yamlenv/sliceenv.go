@@ -0,0 +1,86 @@
+package yamlenv
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxEnvIndex bounds the slice indexes variables may name, so a typo like
+// MYAPP_UPSTREAMS__99999999__HOST can't allocate a huge slice
+const maxEnvIndex = 10000
+
+// elements applies the variables that name single elements of the slice at
+// path, like MYAPP_UPSTREAMS__0__HOST or MYAPP_HOSTS__1, growing the slice
+// to reach them. New struct elements get their SetDefaults defaults.
+// Elements inherit the slice field's secrecy, envinvalid handling and
+// description from parent.
+func (b *envBinder) elements(field reflect.Value, parent fieldInfo, path string, depth int, visiting map[uintptr]bool) error {
+	if !field.CanSet() {
+		return nil
+	}
+	indexes, err := b.indexesBelow(path)
+	if err != nil || len(indexes) == 0 {
+		return err
+	}
+
+	elemType := field.Type().Elem()
+	info := fieldInfo{
+		Nested:     isNestedStruct(elemType),
+		NestedPtr:  elemType.Kind() == reflect.Ptr && isNestedStruct(elemType.Elem()),
+		Secret:     parent.Secret,
+		WarnBadEnv: parent.WarnBadEnv,
+		Desc:       parent.Desc,
+	}
+	for _, i := range indexes {
+		if i >= field.Len() {
+			grown := reflect.MakeSlice(field.Type(), i+1, i+1)
+			reflect.Copy(grown, field)
+			if elemType.Kind() == reflect.Struct {
+				for j := field.Len(); j <= i; j++ {
+					applyDefaults(grown.Index(j).Addr(), nil)
+				}
+			}
+			field.Set(grown)
+		}
+		if err := b.field(field.Index(i), info, path+"."+strconv.Itoa(i), depth, visiting); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexesBelow returns the sorted slice indexes that variables name right
+// below path
+func (b *envBinder) indexesBelow(path string) ([]int, error) {
+	sep := b.delimiter
+	if sep == "" {
+		sep = "."
+	}
+	prefix := b.varName(path) + sep
+
+	seen := map[int]bool{}
+	var indexes []int
+	for _, name := range b.environNames() {
+		rest, ok := b.cutVarPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		segment, _, _ := strings.Cut(rest, sep)
+		if segment == "" || strings.TrimLeft(segment, "0123456789") != "" {
+			continue
+		}
+		i, err := strconv.Atoi(segment)
+		if err != nil || i > maxEnvIndex {
+			return nil, fmt.Errorf("%s: index %s exceeds %d", name, segment, maxEnvIndex)
+		}
+		if !seen[i] {
+			seen[i] = true
+			indexes = append(indexes, i)
+		}
+	}
+	sort.Ints(indexes)
+	return indexes, nil
+}
@@ -0,0 +1,116 @@
+package yamlenv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Upstream struct {
+	Host   string `yaml:"host"`
+	Port   int    `yaml:"port"`
+	Weight int    `yaml:"weight"`
+}
+
+func (u *Upstream) SetDefaults() {
+	u.Weight = 1
+}
+
+type SliceEnvConfig struct {
+	Upstreams []Upstream  `yaml:"upstreams"`
+	Backups   []*Upstream `yaml:"backups"`
+	Hosts     []string    `yaml:"hosts"`
+}
+
+const sliceEnvYAML = `
+upstreams:
+  - host: a.internal
+    port: 80
+    weight: 5
+  - host: b.internal
+    port: 80
+hosts: [x, y]
+`
+
+// Test that variables with an index override single slice elements
+func TestEnvSliceIndex_OverridesElements(t *testing.T) {
+	t.Setenv("SL_UPSTREAMS__1__HOST", "pod-b.internal")
+	t.Setenv("SL_HOSTS__0", "z")
+
+	var cfg SliceEnvConfig
+	result, err := Load(LoaderOptions{BaseSource: StringSource(sliceEnvYAML), EnvPrefix: "SL_", Delimiter: "__", Target: &cfg})
+
+	require.NoError(t, err)
+	require.Len(t, cfg.Upstreams, 2)
+	assert.Equal(t, Upstream{Host: "a.internal", Port: 80, Weight: 5}, cfg.Upstreams[0])
+	assert.Equal(t, "pod-b.internal", cfg.Upstreams[1].Host)
+	assert.Equal(t, 80, cfg.Upstreams[1].Port)
+	assert.Equal(t, []string{"z", "y"}, cfg.Hosts)
+	assert.Equal(t, "env:SL_UPSTREAMS__1__HOST", result.Sources["upstreams.1.host"])
+}
+
+// Test that an index past the end grows the slice with defaulted elements
+func TestEnvSliceIndex_Grows(t *testing.T) {
+	t.Setenv("SL_UPSTREAMS__3__HOST", "d.internal")
+	t.Setenv("SL_UPSTREAMS__3__PORT", "8080")
+	t.Setenv("SL_BACKUPS__0__HOST", "backup.internal")
+
+	var cfg SliceEnvConfig
+	err := LoadConfig(LoaderOptions{BaseSource: StringSource(sliceEnvYAML), EnvPrefix: "SL_", Delimiter: "__", Target: &cfg})
+
+	require.NoError(t, err)
+	require.Len(t, cfg.Upstreams, 4)
+	assert.Equal(t, Upstream{Weight: 1}, cfg.Upstreams[2])
+	assert.Equal(t, Upstream{Host: "d.internal", Port: 8080, Weight: 1}, cfg.Upstreams[3])
+	require.Len(t, cfg.Backups, 1)
+	assert.Equal(t, &Upstream{Host: "backup.internal", Weight: 1}, cfg.Backups[0])
+}
+
+// Test that element variables apply on top of a whole-list JSON value
+func TestEnvSliceIndex_AfterJSON(t *testing.T) {
+	t.Setenv("SL_HOSTS", `["p", "q"]`)
+	t.Setenv("SL_HOSTS__1", "r")
+
+	var cfg SliceEnvConfig
+	err := LoadConfig(LoaderOptions{BaseSource: StringSource(sliceEnvYAML), EnvPrefix: "SL_", Delimiter: "__", EnvJSON: true, Target: &cfg})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"p", "r"}, cfg.Hosts)
+}
+
+func TestEnvSliceIndex_Errors(t *testing.T) {
+	t.Setenv("SL_UPSTREAMS__0__PORT", "eighty")
+	var cfg SliceEnvConfig
+	err := LoadConfig(LoaderOptions{BaseSource: StringSource(sliceEnvYAML), EnvPrefix: "SL_", Delimiter: "__", Target: &cfg})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `set field upstreams.0.port from SL_UPSTREAMS__0__PORT="eighty" (int): parse int "eighty"`)
+
+	t.Setenv("SL_UPSTREAMS__0__PORT", "80")
+	t.Setenv("SL_UPSTREAMS__99999__HOST", "far")
+	err = LoadConfig(LoaderOptions{BaseSource: StringSource(sliceEnvYAML), EnvPrefix: "SL_", Delimiter: "__", Target: &cfg})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SL_UPSTREAMS__99999__HOST: index 99999 exceeds 10000")
+}
+
+// Test that elements inherit the slice field's secret, envinvalid and desc tags
+func TestEnvSliceIndex_InheritsTags(t *testing.T) {
+	type tagged struct {
+		Pins  []int `yaml:"pins" secret:"true" desc:"unlock pins"`
+		Ports []int `yaml:"ports" envinvalid:"warn"`
+	}
+	t.Setenv("SLTAG_PINS__0", "12x4")
+	var cfg tagged
+	err := LoadConfig(LoaderOptions{BaseSource: StringSource("pins: [1]\n"), EnvPrefix: "SLTAG_", Delimiter: "__", Target: &cfg})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `set field pins.0 (unlock pins) from SLTAG_PINS__0="***" (int)`)
+	assert.NotContains(t, err.Error(), "12x4")
+
+	t.Setenv("SLTAG_PINS__0", "1234")
+	t.Setenv("SLTAG_PORTS__1", "eighty")
+	logger := &recordingLogger{}
+	require.NoError(t, LoadConfig(LoaderOptions{BaseSource: StringSource("ports: [80, 443]\n"), EnvPrefix: "SLTAG_", Delimiter: "__", Logger: logger, Target: &cfg}))
+	assert.Equal(t, []int{80, 443}, cfg.Ports)
+	require.Len(t, logger.lines, 1)
+	assert.Contains(t, logger.lines[0], "ignoring invalid override: set field ports.1 from SLTAG_PORTS__1=\"eighty\"")
+}
@@ -0,0 +1,56 @@
+package yamlenv
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// StringSource creates a ConfigSource from YAML text. Unlike ReaderSource,
+// it can be read any number of times, so it also works with a Loader.
+//
+//	base := yamlenv.StringSource("app:\n  port: 8080\n")
+func StringSource(yamlText string) ConfigSource {
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(yamlText)), nil
+	}
+}
+
+// MapSource creates a ConfigSource from nested maps, as for computed
+// defaults or programmatic overrides. The map is encoded at each load, so
+// changes made to it between reloads are picked up.
+//
+//	local := yamlenv.MapSource(map[string]any{"db": map[string]any{"host": host}})
+func MapSource(values map[string]any) ConfigSource {
+	return mapFuncSource(func() map[string]any { return values })
+}
+
+// StdinSource creates a ConfigSource that reads standard input, for tools
+// used as "cat config.yaml | mytool --config -". Standard input is read once
+// and kept, so every StdinSource, and FileSource("-"), sees the same data
+// however often it is loaded.
+func StdinSource() ConfigSource {
+	return stdin.source
+}
+
+// stdin caches standard input for StdinSource
+var stdin = &onceReader{open: func() io.Reader { return os.Stdin }}
+
+// onceReader reads a stream the first time its source is used and serves
+// the saved data afterwards
+type onceReader struct {
+	open func() io.Reader
+	once sync.Once
+	data []byte
+	err  error
+}
+
+func (o *onceReader) source() (io.ReadCloser, error) {
+	o.once.Do(func() { o.data, o.err = io.ReadAll(o.open()) })
+	if o.err != nil {
+		return nil, o.err
+	}
+	return io.NopCloser(bytes.NewReader(o.data)), nil
+}
@@ -0,0 +1,68 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that a source larger than MaxSourceSize is rejected
+func TestLoadConfig_MaxSourceSizeExceeded(t *testing.T) {
+	baseYAML := "app:\n  name: " + strings.Repeat("x", 1024) + "\n"
+
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:    ReaderSource(strings.NewReader(baseYAML)),
+		MaxSourceSize: 128,
+		Target:        &cfg,
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "load base config")
+	assert.Contains(t, err.Error(), "exceeds maximum size of 128 bytes")
+}
+
+// Test that a source exactly at MaxSourceSize is accepted
+func TestLoadConfig_MaxSourceSizeExact(t *testing.T) {
+	baseYAML := "app:\n  name: exact\n"
+
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:    ReaderSource(strings.NewReader(baseYAML)),
+		MaxSourceSize: int64(len(baseYAML)),
+		Target:        &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "exact", cfg.App.Name)
+}
+
+// Test that the size guard also applies to the local source
+func TestLoadConfig_MaxSourceSizeLocal(t *testing.T) {
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:    ReaderSource(strings.NewReader("app:\n  name: base\n")),
+		LocalSource:   ReaderSource(strings.NewReader("app:\n  name: " + strings.Repeat("y", 256) + "\n")),
+		MaxSourceSize: 64,
+		Target:        &cfg,
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "load local config")
+}
+
+// Test that zero MaxSourceSize leaves sources unlimited
+func TestLoadConfig_MaxSourceSizeUnlimited(t *testing.T) {
+	name := strings.Repeat("z", 64*1024)
+
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("app:\n  name: " + name + "\n")),
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, name, cfg.App.Name)
+}
@@ -0,0 +1,53 @@
+package yamlenv
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that string and map sources layer like files and can be read repeatedly
+func TestStringAndMapSource(t *testing.T) {
+	local := map[string]any{"db": map[string]any{"host": "computed", "port": 6543}}
+	loader, err := NewLoader(LoaderOptions{
+		BaseSource:  StringSource("app:\n  name: svc\n  port: 8080\ndb:\n  host: localhost\n  name: app\n"),
+		LocalSource: MapSource(local),
+		Target:      &TestConfig{},
+	})
+	require.NoError(t, err)
+	cfg := loader.Current().(*TestConfig)
+	assert.Equal(t, "svc", cfg.App.Name)
+	assert.Equal(t, "computed", cfg.DB.Host)
+	assert.Equal(t, 6543, cfg.DB.Port)
+	assert.Equal(t, "app", cfg.DB.Name)
+
+	// Reloading reads both sources again and sees changes to the map
+	local["db"].(map[string]any)["host"] = "updated"
+	require.NoError(t, loader.Reload())
+	cfg = loader.Current().(*TestConfig)
+	assert.Equal(t, "updated", cfg.DB.Host)
+	assert.Equal(t, "svc", cfg.App.Name)
+}
+
+// Test that standard input is read once and shared by every stdin source
+func TestStdinSource(t *testing.T) {
+	saved := stdin
+	t.Cleanup(func() { stdin = saved })
+	reads := 0
+	stdin = &onceReader{open: func() io.Reader {
+		reads++
+		return strings.NewReader("app:\n  name: piped\n")
+	}}
+
+	var cfg TestConfig
+	require.NoError(t, LoadConfig(LoaderOptions{BaseSource: FileSource("-"), Target: &cfg}))
+	assert.Equal(t, "piped", cfg.App.Name)
+
+	cfg = TestConfig{}
+	require.NoError(t, LoadConfig(LoaderOptions{BaseSource: StdinSource(), Target: &cfg}))
+	assert.Equal(t, "piped", cfg.App.Name)
+	assert.Equal(t, 1, reads)
+}
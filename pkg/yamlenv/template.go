@@ -0,0 +1,136 @@
+package yamlenv
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// templateSource renders source through text/template before it is parsed.
+// Missing keys in TemplateData are errors rather than "<no value>".
+func templateSource(opts LoaderOptions, name string, source ConfigSource) ConfigSource {
+	return func() (io.ReadCloser, error) {
+		reader, err := source()
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		if err := prepareSource(reader, opts.MaxSourceSize); err != nil {
+			return nil, err
+		}
+
+		var r io.Reader = reader
+		if opts.MaxSourceSize > 0 {
+			r = &sizeLimitReader{r: reader, limit: opts.MaxSourceSize, remaining: opts.MaxSourceSize}
+		}
+		text, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		tmpl, err := template.New(name).Option("missingkey=error").Funcs(templateFuncs(opts)).Parse(string(text))
+		if err != nil {
+			return nil, err
+		}
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, opts.TemplateData); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(&out), nil
+	}
+}
+
+// templateFuncs returns the built-in template functions, overridden and
+// extended by opts.TemplateFuncs
+func templateFuncs(opts LoaderOptions) template.FuncMap {
+	lookupEnv := newLookupEnv(opts.EnvIgnoreCase)
+	funcs := template.FuncMap{
+		"env": func(name string) string {
+			value, _ := lookupEnv(name)
+			return value
+		},
+		"file": func(path string) (string, error) {
+			data, err := os.ReadFile(path)
+			return strings.TrimRight(string(data), "\r\n"), err
+		},
+		"default": func(def, value any) any {
+			if isEmptyValue(value) {
+				return def
+			}
+			return value
+		},
+		"required": func(msg string, value any) (any, error) {
+			if isEmptyValue(value) {
+				return nil, fmt.Errorf("%s", msg)
+			}
+			return value, nil
+		},
+		"coalesce": func(values ...any) any {
+			for _, v := range values {
+				if !isEmptyValue(v) {
+					return v
+				}
+			}
+			return nil
+		},
+		"empty":      isEmptyValue,
+		"lower":      strings.ToLower,
+		"upper":      strings.ToUpper,
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"split":      func(sep, s string) []string { return strings.Split(s, sep) },
+		"join":       func(sep string, elems []string) string { return strings.Join(elems, sep) },
+		"quote": func(value any) string {
+			data, _ := json.Marshal(fmt.Sprint(value))
+			return string(data)
+		},
+		"squote": func(value any) string {
+			return "'" + strings.ReplaceAll(fmt.Sprint(value), "'", "''") + "'"
+		},
+		"indent": func(n int, s string) string {
+			pad := strings.Repeat(" ", n)
+			return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+		},
+		"nindent": func(n int, s string) string {
+			pad := strings.Repeat(" ", n)
+			return "\n" + pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+		},
+		"toJson": func(value any) (string, error) {
+			data, err := json.Marshal(value)
+			return string(data), err
+		},
+		"b64enc": func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+		"b64dec": func(s string) (string, error) {
+			data, err := base64.StdEncoding.DecodeString(s)
+			return string(data), err
+		},
+	}
+	for name, fn := range opts.TemplateFuncs {
+		funcs[name] = fn
+	}
+	return funcs
+}
+
+// isEmptyValue reports whether a template value is nil or its type's zero
+// value, including empty strings, slices and maps
+func isEmptyValue(value any) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
@@ -0,0 +1,86 @@
+package yamlenv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that sources are rendered with the built-in helpers before parsing
+func TestTemplate_Render(t *testing.T) {
+	setEnvVar(t, "TPL_DB_HOST", "db.internal")
+	password := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(password, []byte("s3cret\n"), 0o600))
+
+	var cfg struct {
+		App struct {
+			Name string `yaml:"name"`
+			Port int    `yaml:"port"`
+		} `yaml:"app"`
+		DB struct {
+			Host     string `yaml:"host"`
+			Password string `yaml:"password"`
+			User     string `yaml:"user"`
+		} `yaml:"db"`
+		Tags []string `yaml:"tags"`
+	}
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader(`
+app:
+  name: {{ .Name | upper | quote }}
+  port: {{ env "TPL_APP_PORT" | default "8080" }}
+db:
+  host: {{ env "TPL_DB_HOST" }}
+  password: {{ file "` + password + `" | squote }}
+  user: {{ shout "admin" }}
+tags: {{ split "," "a,b" | toJson }}
+`)),
+		Template:      true,
+		TemplateData:  map[string]any{"Name": "svc"},
+		TemplateFuncs: template.FuncMap{"shout": func(s string) string { return s + "!" }},
+		Target:        &cfg,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "SVC", cfg.App.Name)
+	assert.Equal(t, 8080, cfg.App.Port)
+	assert.Equal(t, "db.internal", cfg.DB.Host)
+	assert.Equal(t, "s3cret", cfg.DB.Password)
+	assert.Equal(t, "admin!", cfg.DB.User)
+	assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+}
+
+// Test that template errors fail the load and name the layer, and that
+// sources aren't rendered unless Template is set
+func TestTemplate_Errors(t *testing.T) {
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("app:\n  name: x\n")),
+		LocalSource: ReaderSource(strings.NewReader(`app:
+  name: {{ env "TPL_MISSING" | required "TPL_MISSING must be set" }}
+`)),
+		Template: true,
+		Target:   &cfg,
+	})
+	assert.ErrorContains(t, err, "load local config:")
+	assert.ErrorContains(t, err, "template: local:2:")
+	assert.ErrorContains(t, err, "TPL_MISSING must be set")
+
+	err = LoadConfig(LoaderOptions{
+		BaseSource:   ReaderSource(strings.NewReader("app:\n  name: {{ .Missing }}\n")),
+		Template:     true,
+		TemplateData: map[string]any{"Name": "svc"},
+		Target:       &cfg,
+	})
+	assert.ErrorContains(t, err, `map has no entry for key "Missing"`)
+
+	require.NoError(t, LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("app:\n  name: '{{ .Name }}'\n")),
+		Target:     &cfg,
+	}))
+	assert.Equal(t, "{{ .Name }}", cfg.App.Name)
+}
@@ -0,0 +1,52 @@
+package yamlenv
+
+import "fmt"
+
+// ForTenant derives a tenant's config by merging source over the layers of
+// the latest load, without reading or parsing the base and local sources
+// again. Environment overrides, runtime overrides and rotated credentials
+// apply on top, as for the loader's own config. It returns a pointer of the
+// same type as Target; the loader's config is not modified.
+//
+// Values from the tenant layer are reported as "tenant:<name>" in error
+// messages and LoadResult.Sources.
+func (l *Loader) ForTenant(name string, source ConfigSource) (any, error) {
+	if source == nil {
+		return nil, fmt.Errorf("tenant %s: source cannot be nil", name)
+	}
+	tenant := configLayer{name: "tenant:" + name}
+	node, err := loadNodeFromSource(captureLabel(source, &tenant.label), sourceLimits(l.opts))
+	if err != nil {
+		return nil, tenant.loadError(err)
+	}
+	tenant.node = node
+
+	l.mu.RLock()
+	base := l.layers
+	l.mu.RUnlock()
+
+	layers := make([]configLayer, 0, len(base)+1)
+	layers = append(layers, base...)
+	layers = append(layers, tenant)
+
+	cfg := deepCopy(l.template)
+	opts := l.opts
+	opts.Target = cfg.Interface()
+	result, err := bindLayers(opts, layers)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.applyOverrides(cfg, result); err != nil {
+		return nil, err
+	}
+	if err := checkConfig(opts, result); err != nil {
+		return nil, err
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if err := l.applyRotated(cfg, result); err != nil {
+		return nil, err
+	}
+	return cfg.Interface(), nil
+}
@@ -0,0 +1,99 @@
+package yamlenv
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that tenant configs reuse the parsed base and don't affect each other
+func TestLoader_ForTenant(t *testing.T) {
+	setEnvVar(t, "TENANT_APP__PORT", "7000")
+
+	reads := 0
+	base := func() (io.ReadCloser, error) {
+		reads++
+		return io.NopCloser(strings.NewReader("app:\n  name: base\n  port: 8080\ndb:\n  pool_size: 5\n  options:\n    ssl: on\n")), nil
+	}
+
+	var cfg LoaderTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: base, EnvPrefix: "TENANT_", Delimiter: "__", Target: &cfg})
+	require.NoError(t, err)
+
+	acme, err := loader.ForTenant("acme", ReaderSource(strings.NewReader("app:\n  name: acme\ndb:\n  options:\n    region: eu\n")))
+	require.NoError(t, err)
+	globex, err := loader.ForTenant("globex", ReaderSource(strings.NewReader("db:\n  pool_size: 50\n")))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, reads)
+
+	a := acme.(*LoaderTestConfig)
+	assert.Equal(t, "acme", a.App.Name)
+	assert.Equal(t, 7000, a.App.Port)
+	assert.Equal(t, map[string]string{"ssl": "on", "region": "eu"}, a.DB.Options)
+
+	g := globex.(*LoaderTestConfig)
+	assert.Equal(t, "base", g.App.Name)
+	assert.Equal(t, 50, g.DB.PoolSize)
+	assert.Equal(t, map[string]string{"ssl": "on"}, g.DB.Options)
+
+	assert.Equal(t, "base", cfg.App.Name)
+	assert.Equal(t, 5, cfg.DB.PoolSize)
+}
+
+// Test that tenant errors name the tenant layer
+func TestLoader_ForTenantErrors(t *testing.T) {
+	var cfg LoaderTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: ReaderSource(strings.NewReader("app:\n  port: 1\n")), Target: &cfg})
+	require.NoError(t, err)
+
+	_, err = loader.ForTenant("acme", ReaderSource(strings.NewReader("app:\n  port: not-a-number\n")))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "load tenant:acme config")
+
+	_, err = loader.ForTenant("acme", ReaderSource(strings.NewReader("app: [unclosed\n")))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "load tenant:acme config")
+
+	_, err = loader.ForTenant("acme", nil)
+	assert.Error(t, err)
+}
+
+// Test that tenant configs get the credentials Rotate set
+func TestLoader_ForTenantRotated(t *testing.T) {
+	var cfg RotateTestConfig
+	loader, err := NewLoader(LoaderOptions{
+		BaseSource: StringSource("db:\n  user: app\n  password: static\n"),
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- loader.Rotate(ctx, RotateOptions{
+			Paths: []string{"db.password"},
+			Fetch: func(ctx context.Context, path string) (Lease, error) {
+				return Lease{Value: "rotated", TTL: time.Hour}, nil
+			},
+		})
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	require.Eventually(t, func() bool {
+		return loader.Current().(*RotateTestConfig).DB.Password.Value() == "rotated"
+	}, 2*time.Second, 5*time.Millisecond)
+
+	acme, err := loader.ForTenant("acme", StringSource("db:\n  user: acme\n"))
+	require.NoError(t, err)
+	tenant := acme.(*RotateTestConfig)
+	assert.Equal(t, "acme", tenant.DB.User)
+	assert.Equal(t, "rotated", tenant.DB.Password.Value())
+}
@@ -0,0 +1,134 @@
+package yamlenv
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// TLSConfig is a ready-made config section for TLS, for servers and clients
+// alike. Certificates, keys and CAs are given as PEM files or inline PEM,
+// which suits secrets injected through env vars:
+//
+//	type Config struct {
+//	    Server struct {
+//	        TLS yamlenv.TLSConfig `yaml:"tls"`
+//	    } `yaml:"server"`
+//	}
+//
+//	// server:
+//	//   tls:
+//	//     cert_file: /etc/app/tls.crt
+//	//     key_file: /etc/app/tls.key
+//	//     client_auth: require_and_verify
+//	//     ca_file: /etc/app/clients-ca.crt
+//	tlsConfig, err := cfg.Server.TLS.Build()
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file" desc:"PEM certificate chain file"`
+	KeyFile  string `yaml:"key_file" desc:"PEM private key file"`
+	CAFile   string `yaml:"ca_file" desc:"PEM CA bundle that verifies peers"`
+
+	Cert string `yaml:"cert" desc:"inline PEM certificate chain, instead of cert_file"`
+	Key  string `yaml:"key" secret:"true" desc:"inline PEM private key, instead of key_file"`
+	CA   string `yaml:"ca" desc:"inline PEM CA bundle, instead of ca_file"`
+
+	MinVersion         string `yaml:"min_version" enum:"1.0,1.1,1.2,1.3" desc:"lowest TLS version accepted; default 1.2"`
+	ClientAuth         string `yaml:"client_auth" enum:"none,request,require,verify_if_given,require_and_verify" desc:"client certificate policy of a server; default none"`
+	ServerName         string `yaml:"server_name" desc:"name a client verifies the server certificate against"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify" desc:"skip verifying the server certificate (testing only)"`
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var tlsClientAuth = map[string]tls.ClientAuthType{
+	"":                   tls.NoClientCert,
+	"none":               tls.NoClientCert,
+	"request":            tls.RequestClientCert,
+	"require":            tls.RequireAnyClientCert,
+	"verify_if_given":    tls.VerifyClientCertIfGiven,
+	"require_and_verify": tls.RequireAndVerifyClientCert,
+}
+
+// Build returns the *tls.Config the section describes. The CA bundle, when
+// set, verifies both servers (RootCAs) and client certificates (ClientCAs);
+// without it clients use the system roots.
+func (c TLSConfig) Build() (*tls.Config, error) {
+	out := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.MinVersion != "" {
+		version, ok := tlsVersions[c.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("tls: invalid min_version %q; allowed: 1.0, 1.1, 1.2, 1.3", c.MinVersion)
+		}
+		out.MinVersion = version
+	}
+	clientAuth, ok := tlsClientAuth[c.ClientAuth]
+	if !ok {
+		return nil, fmt.Errorf("tls: invalid client_auth %q", c.ClientAuth)
+	}
+	out.ClientAuth = clientAuth
+
+	certPEM, err := pemValue("cert", c.Cert, c.CertFile)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := pemValue("key", c.Key, c.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case certPEM != nil && keyPEM != nil:
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("tls: load key pair: %w", err)
+		}
+		out.Certificates = []tls.Certificate{cert}
+	case certPEM != nil:
+		return nil, errors.New("tls: cert is set without a key")
+	case keyPEM != nil:
+		return nil, errors.New("tls: key is set without a cert")
+	}
+
+	caPEM, err := pemValue("ca", c.CA, c.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	if caPEM != nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("tls: ca contains no PEM certificates")
+		}
+		out.RootCAs, out.ClientCAs = pool, pool
+	} else if clientAuth == tls.VerifyClientCertIfGiven || clientAuth == tls.RequireAndVerifyClientCert {
+		return nil, fmt.Errorf("tls: client_auth %q needs a ca or ca_file to verify clients with", c.ClientAuth)
+	}
+	return out, nil
+}
+
+// pemValue returns inline PEM, or the contents of file; nil if neither is
+// set
+func pemValue(name, inline, file string) ([]byte, error) {
+	switch {
+	case inline != "" && file != "":
+		return nil, fmt.Errorf("tls: both %s and %s_file are set", name, name)
+	case inline != "":
+		return []byte(inline), nil
+	case file != "":
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("tls: read %s_file: %w", name, err)
+		}
+		return data, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,108 @@
+package yamlenv
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// selfSigned returns a PEM certificate and key for localhost
+func selfSigned(t *testing.T) (certPEM, keyPEM string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+type TLSSectionTestConfig struct {
+	TLS TLSConfig `yaml:"tls"`
+}
+
+// Test a server section with files from YAML and the key inline from env
+func TestTLSConfig_Build(t *testing.T) {
+	certPEM, keyPEM := selfSigned(t)
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	require.NoError(t, os.WriteFile(certFile, []byte(certPEM), 0o600))
+	setEnvVar(t, "TLSCFG_TLS__KEY", keyPEM)
+
+	var cfg TLSSectionTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: StringSource("tls:\n  cert_file: " + certFile + "\n  ca_file: " + certFile + "\n  min_version: \"1.3\"\n  client_auth: require_and_verify\n"),
+		EnvPrefix:  "TLSCFG_",
+		Delimiter:  "__",
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+
+	tlsConfig, err := cfg.TLS.Build()
+	require.NoError(t, err)
+	assert.Len(t, tlsConfig.Certificates, 1)
+	assert.Equal(t, uint16(tls.VersionTLS13), tlsConfig.MinVersion)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, tlsConfig.ClientAuth)
+	assert.NotNil(t, tlsConfig.ClientCAs)
+	assert.Equal(t, "***", Flatten(&cfg, ".")["tls.key"])
+}
+
+// Test that an empty section gives a client config with TLS 1.2 and system roots
+func TestTLSConfig_BuildDefaults(t *testing.T) {
+	tlsConfig, err := TLSConfig{ServerName: "api.internal"}.Build()
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+	assert.Nil(t, tlsConfig.RootCAs)
+	assert.Empty(t, tlsConfig.Certificates)
+	assert.Equal(t, "api.internal", tlsConfig.ServerName)
+}
+
+func TestTLSConfig_BuildErrors(t *testing.T) {
+	certPEM, _ := selfSigned(t)
+	for name, tc := range map[string]struct {
+		cfg  TLSConfig
+		want string
+	}{
+		"cert without key":  {TLSConfig{Cert: certPEM}, "cert is set without a key"},
+		"inline and file":   {TLSConfig{CA: certPEM, CAFile: "ca.crt"}, "both ca and ca_file are set"},
+		"missing file":      {TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.crt")}, "read ca_file"},
+		"bad ca":            {TLSConfig{CA: "not pem"}, "ca contains no PEM certificates"},
+		"verify without ca": {TLSConfig{ClientAuth: "require_and_verify"}, "needs a ca or ca_file"},
+		"bad version":       {TLSConfig{MinVersion: "1.4"}, `invalid min_version "1.4"`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := tc.cfg.Build()
+			assert.ErrorContains(t, err, tc.want)
+		})
+	}
+}
+
+// Test that enum tags reject bad values at load time
+func TestTLSConfig_InvalidAtLoad(t *testing.T) {
+	var cfg TLSSectionTestConfig
+	err := LoadConfig(LoaderOptions{BaseSource: StringSource("tls:\n  client_auth: always\n"), Target: &cfg})
+	assert.ErrorContains(t, err, `invalid value "always"`)
+}
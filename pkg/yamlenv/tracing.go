@@ -0,0 +1,74 @@
+package yamlenv
+
+import (
+	"context"
+	"io"
+)
+
+// Tracer starts spans around the phases of a load, so slow startups, such
+// as ones waiting on remote sources, show up in distributed traces. It is
+// the extension point for OpenTelemetry: wrap a trace.Tracer, adding attrs
+// with attribute.String and recording the error passed to End. Loads
+// running concurrently call it from several goroutines.
+//
+// Spans are "yamlenv.load" for the whole load, with children
+// "yamlenv.fetch" (opening a source) and "yamlenv.parse" per source, both
+// with a "layer" attribute, then "yamlenv.merge", "yamlenv.schema",
+// "yamlenv.env" and "yamlenv.validate". LoadContext's context is the
+// parent of "yamlenv.load".
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs map[string]string) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer
+type Span interface {
+	End(err error) // err is the phase's error; nil if it succeeded
+}
+
+// startSpan starts a span named name under opts.ctx, with attributes given
+// as key, value pairs, and returns its context and the function ending it.
+// Without a Tracer it does nothing.
+func startSpan(opts LoaderOptions, name string, attrs ...string) (context.Context, func(error)) {
+	ctx := opts.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if opts.Tracer == nil {
+		return ctx, func(error) {}
+	}
+	var m map[string]string
+	if len(attrs) > 0 {
+		m = make(map[string]string, len(attrs)/2)
+		for i := 0; i+1 < len(attrs); i += 2 {
+			m[attrs[i]] = attrs[i+1]
+		}
+	}
+	ctx, span := opts.Tracer.Start(ctx, name, m)
+	return ctx, span.End
+}
+
+// traceSource returns source with a "yamlenv.fetch" span around opening
+// it. Once it opened, a "yamlenv.parse" span is started for reading and
+// parsing the data; the caller ends it through endParse.
+func traceSource(opts LoaderOptions, layer string, source ConfigSource, endParse *func(error)) ConfigSource {
+	return func() (io.ReadCloser, error) {
+		_, endFetch := startSpan(opts, "yamlenv.fetch", "layer", layer)
+		rc, err := source()
+		endFetch(err)
+		if err == nil {
+			_, *endParse = startSpan(opts, "yamlenv.parse", "layer", layer)
+		}
+		return rc, err
+	}
+}
+
+// checkConfig checks the effective config against the tag constraints and
+// opts.Policies
+func checkConfig(opts LoaderOptions, result *LoadResult) (err error) {
+	_, end := startSpan(opts, "yamlenv.validate")
+	defer func() { end(err) }()
+	if err := checkConstraints(opts, result); err != nil {
+		return err
+	}
+	return checkPolicies(opts)
+}
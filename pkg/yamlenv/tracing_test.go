@@ -0,0 +1,127 @@
+package yamlenv
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingTracer keeps the spans it starts
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+type recordedSpan struct {
+	name   string
+	parent string
+	attrs  map[string]string
+	ended  bool
+	err    error
+}
+
+type spanKey struct{}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attrs map[string]string) (context.Context, Span) {
+	parent, _ := ctx.Value(spanKey{}).(string)
+	span := &recordedSpan{name: name, parent: parent, attrs: attrs}
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+	return context.WithValue(ctx, spanKey{}, name), span
+}
+
+func (s *recordedSpan) End(err error) {
+	s.ended, s.err = true, err
+}
+
+// find returns the spans named name
+func (t *recordingTracer) find(name string) []*recordedSpan {
+	var out []*recordedSpan
+	for _, span := range t.spans {
+		if span.name == name {
+			out = append(out, span)
+		}
+	}
+	return out
+}
+
+// Test that a load reports a span per phase under a root span
+func TestLoad_Tracer(t *testing.T) {
+	tracer := &recordingTracer{}
+	err := LoadConfig(LoaderOptions{
+		BaseSource:  StringSource("app:\n  name: base\n"),
+		LocalSource: StringSource("app:\n  port: 81\n"),
+		Tracer:      tracer,
+		Target:      &TestConfig{},
+	})
+	require.NoError(t, err)
+
+	for _, name := range []string{"yamlenv.load", "yamlenv.merge", "yamlenv.schema", "yamlenv.env", "yamlenv.validate"} {
+		require.Len(t, tracer.find(name), 1, name)
+	}
+	assert.Len(t, tracer.find("yamlenv.fetch"), 2)
+	parses := tracer.find("yamlenv.parse")
+	require.Len(t, parses, 2)
+	assert.ElementsMatch(t, []string{"base", "local"}, []string{parses[0].attrs["layer"], parses[1].attrs["layer"]})
+	for _, span := range tracer.spans {
+		assert.True(t, span.ended, span.name)
+		assert.NoError(t, span.err, span.name)
+		if span.name != "yamlenv.load" {
+			assert.Equal(t, "yamlenv.load", span.parent, span.name)
+		}
+	}
+}
+
+// Test that failing phases end their spans with the error, and that the
+// root span is a child of LoadContext's context
+func TestLoad_TracerErrors(t *testing.T) {
+	tracer := &recordingTracer{}
+	ctx := context.WithValue(context.Background(), spanKey{}, "request")
+	_, err := LoadContext(ctx, LoaderOptions{
+		BaseSource: StringSource("{}"),
+		LocalSource: func() (_ io.ReadCloser, err error) {
+			return nil, errors.New("unreachable")
+		},
+		LocalRequired: true,
+		Tracer:        tracer,
+		Target:        &TestConfig{},
+	})
+	require.Error(t, err)
+
+	root := tracer.find("yamlenv.load")
+	require.Len(t, root, 1)
+	assert.Equal(t, "request", root[0].parent)
+	assert.Equal(t, err, root[0].err)
+	var failed []string
+	for _, span := range tracer.find("yamlenv.fetch") {
+		if span.err != nil {
+			failed = append(failed, span.attrs["layer"])
+		}
+	}
+	assert.Equal(t, []string{"local"}, failed)
+
+	tracer = &recordingTracer{}
+	t.Setenv("TR_APP__PORT", "eighty")
+	err = LoadConfig(LoaderOptions{BaseSource: StringSource("{}"), EnvPrefix: "TR_", Delimiter: "__", Tracer: tracer, Target: &TestConfig{}})
+	require.Error(t, err)
+	env := tracer.find("yamlenv.env")
+	require.Len(t, env, 1)
+	var envErr *EnvError
+	assert.ErrorAs(t, env[0].err, &envErr)
+}
+
+// Test that a Loader traces its initial load and reloads
+func TestLoader_Tracer(t *testing.T) {
+	tracer := &recordingTracer{}
+	loader, err := NewLoader(LoaderOptions{BaseSource: StringSource("{}"), Tracer: tracer, Target: &TestConfig{}})
+	require.NoError(t, err)
+	require.NoError(t, loader.Reload())
+	assert.Len(t, tracer.find("yamlenv.load"), 2)
+	assert.Len(t, tracer.find("yamlenv.validate"), 2)
+}
@@ -0,0 +1,41 @@
+package yamlenv
+
+import "strings"
+
+// KeyTranslation normalizes a single key segment (a YAML key or struct
+// field name). With LoaderOptions.KeyTranslation set, a YAML key binds to a
+// field when both translate to the same string, and environment variable
+// names are built from translated segments. Unlike NormalizeDash, which only
+// affects env names, it applies in both directions.
+type KeyTranslation func(segment string) string
+
+// TranslateChars returns a KeyTranslation that replaces each old string with
+// the corresponding new one, like strings.NewReplacer:
+//
+//	TranslateChars("-", "_", ".", "") // kebab-case to snake_case, strip dots
+func TranslateChars(oldnew ...string) KeyTranslation {
+	return strings.NewReplacer(oldnew...).Replace
+}
+
+// DashToUnderscore treats "-" and "_" in keys as the same character, so
+// `app-name:`, `app_name:` and MYAPP_APP_NAME all reach a field tagged
+// `yaml:"app-name"`
+var DashToUnderscore = TranslateChars("-", "_")
+
+// varName returns the env var name for a dot-separated path, translating
+// each segment first
+func (b *envBinder) varName(path string) string {
+	if len(b.escapes) > 0 {
+		if name, ok := b.escapedName(path); ok {
+			return name
+		}
+	}
+	if b.translate != nil && path != "" {
+		segments := strings.Split(path, ".")
+		for i, segment := range segments {
+			segments[i] = b.translate(segment)
+		}
+		path = strings.Join(segments, ".")
+	}
+	return envVarName(b.prefix, b.delimiter, path, b.normalizeDash)
+}
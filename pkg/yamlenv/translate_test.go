@@ -0,0 +1,73 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type KebabConfig struct {
+	App struct {
+		AppName  string `yaml:"app-name"`
+		MaxConns int    `yaml:"max-conns"`
+		LogLevel string `yaml:"log_level"`
+	} `yaml:"app"`
+}
+
+// Test that DashToUnderscore matches YAML keys and env names in both directions
+func TestLoadConfig_KeyTranslationDashes(t *testing.T) {
+	setEnvVar(t, "KT_APP__MAX_CONNS", "50")
+
+	var cfg KebabConfig
+	result, err := Load(LoaderOptions{
+		BaseSource:     ReaderSource(strings.NewReader("app:\n  app_name: snake\n  log-level: debug\n")),
+		EnvPrefix:      "KT_",
+		Delimiter:      "__",
+		KeyTranslation: DashToUnderscore,
+		Target:         &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "snake", cfg.App.AppName)
+	assert.Equal(t, 50, cfg.App.MaxConns)
+	assert.Equal(t, "debug", cfg.App.LogLevel)
+	assert.Empty(t, result.UnusedKeys)
+	assert.Equal(t, "env:KT_APP__MAX_CONNS", result.Sources["app.max-conns"])
+}
+
+// Test that custom translations combine with case-insensitive keys
+func TestLoadConfig_KeyTranslationCustom(t *testing.T) {
+	var cfg KebabConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:          ReaderSource(strings.NewReader("App:\n  App.Name: dotted\n  MAX_CONNS: 5\n")),
+		KeyTranslation:      TranslateChars("-", "_", ".", "_"),
+		CaseInsensitiveKeys: true,
+		Target:              &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "dotted", cfg.App.AppName)
+	assert.Equal(t, 5, cfg.App.MaxConns)
+}
+
+// Test that NormalizeDash keeps its env-only behavior
+func TestLoadConfig_NormalizeDashEnvOnly(t *testing.T) {
+	var cfg KebabConfig
+	result, err := Load(LoaderOptions{
+		BaseSource:    ReaderSource(strings.NewReader("app:\n  app_name: snake\n")),
+		NormalizeDash: true,
+		Target:        &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "", cfg.App.AppName)
+	assert.Equal(t, []string{"app.app_name"}, result.UnusedKeys)
+}
+
+func TestEnvBinder_VarName(t *testing.T) {
+	b := newEnvBinder(LoaderOptions{EnvPrefix: "APP_", Delimiter: "__", KeyTranslation: TranslateChars("-", "_", ".", "")})
+	assert.Equal(t, "APP_DB__POOL_SIZE", b.varName("db.pool-size"))
+	assert.Equal(t, "APP_", b.varName(""))
+}
@@ -0,0 +1,87 @@
+package yamlenv
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// unusedKeys returns the paths of keys in n that don't map to any field of t,
+// sorted. Keys below map, interface and custom-unmarshaled fields are all
+// considered used.
+func unusedKeys(n *yaml.Node, t reflect.Type, path string) []string {
+	var out []string
+	collectUnusedKeys(n, t, path, &out)
+	sort.Strings(out)
+	return out
+}
+
+func collectUnusedKeys(n *yaml.Node, t reflect.Type, path string, out *[]string) {
+	n = resolveAlias(n)
+	if n == nil {
+		return
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	// Types with their own YAML handling consume the whole subtree
+	if t == yamlNodeType || reflect.PointerTo(t).Implements(yamlUnmarshalerType) {
+		return
+	}
+
+	switch {
+	case t.Kind() == reflect.Struct && n.Kind == yaml.MappingNode:
+		collectUnusedStructKeys(flattenMapping(n), t, path, out)
+	case t.Kind() == reflect.Map && n.Kind == yaml.MappingNode:
+		content := flattenMapping(n)
+		for i := 0; i+1 < len(content); i += 2 {
+			collectUnusedKeys(content[i+1], t.Elem(), joinPath(path, content[i].Value), out)
+		}
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && n.Kind == yaml.SequenceNode:
+		for i, elem := range n.Content {
+			collectUnusedKeys(elem, t.Elem(), joinPath(path, fmt.Sprint(i)), out)
+		}
+	}
+}
+
+// collectUnusedStructKeys checks mapping content against a struct's fields,
+// including fields of inline structs
+func collectUnusedStructKeys(content []*yaml.Node, t reflect.Type, path string, out *[]string) {
+	for i := 0; i+1 < len(content); i += 2 {
+		key := content[i].Value
+		field, ok := findField(t, key)
+		if !ok {
+			*out = append(*out, joinPath(path, key))
+			continue
+		}
+		collectUnusedKeys(content[i+1], field.Type, joinPath(path, key), out)
+	}
+}
+
+// findField looks up the field bound to a YAML key, searching inline structs.
+// An inline map accepts any key and is reported as a match.
+func findField(t reflect.Type, key string) (reflect.StructField, bool) {
+	fields := cachedFields(t)
+	for _, info := range fields {
+		if !info.Inline && info.Name == key {
+			return t.Field(info.Index), true
+		}
+	}
+	for _, info := range fields {
+		if !info.Inline {
+			continue
+		}
+		field := t.Field(info.Index)
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			if inner, ok := findField(field.Type, key); ok {
+				return inner, true
+			}
+		case reflect.Map:
+			return reflect.StructField{Type: field.Type.Elem()}, true
+		}
+	}
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,103 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// Test that keys without a matching field are reported, sorted, across layers
+func TestLoad_UnusedKeys(t *testing.T) {
+	baseYAML := `
+app:
+  name: testapp
+  port: 8080
+  legacy_flag: true
+db:
+  host: localhost
+old_section:
+  anything: 1
+`
+
+	var cfg TestConfig
+	result, err := Load(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader(baseYAML)),
+		LocalSource: ReaderSource(strings.NewReader("db:\n  pool: 5\n")),
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "testapp", cfg.App.Name)
+	assert.Equal(t, []string{"app.legacy_flag", "db.pool", "old_section"}, result.UnusedKeys)
+}
+
+// Test that maps, lists, inline structs and opaque fields are handled
+func TestLoad_UnusedKeysNestedTypes(t *testing.T) {
+	type Common struct {
+		Region string `yaml:"region"`
+	}
+	type Config struct {
+		Common  `yaml:",inline"`
+		Servers []struct {
+			Name string `yaml:"name"`
+		} `yaml:"servers"`
+		Labels map[string]struct {
+			Value string `yaml:"value"`
+		} `yaml:"labels"`
+		Plugins map[string]any `yaml:"plugins"`
+		Extra   any            `yaml:"extra"`
+		Raw     yaml.Node      `yaml:"raw"`
+	}
+
+	doc := `
+region: eu
+servers:
+  - name: a
+    weight: 3
+labels:
+  team:
+    value: core
+    color: red
+plugins:
+  cache: {size: 1}
+extra: {free: form}
+raw: {kept: verbatim}
+`
+
+	var cfg Config
+	result, err := Load(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader(doc)),
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"labels.team.color", "servers.0.weight"}, result.UnusedKeys)
+}
+
+// Test that a fully mapped document reports nothing
+func TestLoad_NoUnusedKeys(t *testing.T) {
+	var cfg TestConfig
+	result, err := Load(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("app:\n  name: x\nversion: \"1\"\n")),
+		Target:     &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, result.UnusedKeys)
+}
+
+// Test that unused keys are reported relative to the full path for sections
+func TestLoad_UnusedKeysSection(t *testing.T) {
+	var db DBSection
+	result, err := Load(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("db:\n  host: h\n  timeout: 1s\napp:\n  ignored: true\n")),
+		Section:    "db",
+		Target:     &db,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db.timeout"}, result.UnusedKeys)
+}
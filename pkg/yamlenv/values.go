@@ -0,0 +1,214 @@
+package yamlenv
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Values is a queryable view of the merged configuration for callers that
+// don't know the schema at compile time (plugin systems, generic tooling).
+// Paths are dot-separated YAML keys, e.g. "db.host".
+//
+// Typed getters return the zero value when the key is missing or cannot be
+// converted; use Get or Has to tell the cases apart.
+type Values struct {
+	root *yaml.Node
+}
+
+// LoadValues loads and merges the configured layers like LoadConfig, applies
+// environment overrides to the keys present in the merged document, and
+// returns the result as Values instead of binding it into a struct.
+// Target is ignored.
+func LoadValues(opts LoaderOptions) (*Values, error) {
+	if opts.EnvPrefix != "" && opts.Delimiter == "" {
+		return nil, fmt.Errorf("delimiter cannot be empty when EnvPrefix is provided - use a non-empty delimiter like '__' for proper environment variable mapping")
+	}
+	if opts.BaseSource == nil {
+		return nil, fmt.Errorf("BaseSource cannot be nil")
+	}
+
+	layers, err := loadLayers(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	root := cloneNode(newMerger(opts).mergeLayers(layers))
+	newEnvBinder(opts).applyToNode(root, "")
+
+	return &Values{root: lookupPath(root, opts.Section)}, nil
+}
+
+// node returns the node at path, or nil if it doesn't exist
+func (v *Values) node(path string) *yaml.Node {
+	if v == nil {
+		return nil
+	}
+	return lookupPath(v.root, path)
+}
+
+// Has reports whether a value exists at path
+func (v *Values) Has(path string) bool {
+	return v.node(path) != nil
+}
+
+// Get returns the value at path decoded into its natural Go type
+// (map[string]any, []any, string, int, float64, bool, ...)
+func (v *Values) Get(path string) (any, bool) {
+	n := v.node(path)
+	if n == nil {
+		return nil, false
+	}
+	var out any
+	if err := n.Decode(&out); err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+// Unmarshal decodes the value at path into target
+func (v *Values) Unmarshal(path string, target any) error {
+	n := v.node(path)
+	if n == nil {
+		return fmt.Errorf("key %q not found", path)
+	}
+	return n.Decode(target)
+}
+
+// Sub returns the subtree at path as Values, or nil if it doesn't exist
+func (v *Values) Sub(path string) *Values {
+	n := v.node(path)
+	if n == nil {
+		return nil
+	}
+	return &Values{root: n}
+}
+
+// Keys returns the sorted keys of the mapping at path ("" for the root)
+func (v *Values) Keys(path string) []string {
+	n := v.node(path)
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	keys := make([]string, 0, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		keys = append(keys, n.Content[i].Value)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// All returns the whole tree as a map
+func (v *Values) All() map[string]any {
+	out := map[string]any{}
+	if n := v.node(""); n != nil {
+		_ = n.Decode(&out)
+	}
+	return out
+}
+
+// String returns the value at path as a string
+func (v *Values) String(path string) string {
+	var out string
+	_ = v.Unmarshal(path, &out)
+	return out
+}
+
+// Int returns the value at path as an int
+func (v *Values) Int(path string) int {
+	var out int
+	_ = v.Unmarshal(path, &out)
+	return out
+}
+
+// Int64 returns the value at path as an int64
+func (v *Values) Int64(path string) int64 {
+	var out int64
+	_ = v.Unmarshal(path, &out)
+	return out
+}
+
+// Float64 returns the value at path as a float64
+func (v *Values) Float64(path string) float64 {
+	var out float64
+	_ = v.Unmarshal(path, &out)
+	return out
+}
+
+// Bool returns the value at path as a bool
+func (v *Values) Bool(path string) bool {
+	var out bool
+	_ = v.Unmarshal(path, &out)
+	return out
+}
+
+// Duration returns the value at path as a time.Duration
+func (v *Values) Duration(path string) time.Duration {
+	var out time.Duration
+	_ = v.Unmarshal(path, &out)
+	return out
+}
+
+// Strings returns the value at path as a string slice
+func (v *Values) Strings(path string) []string {
+	var out []string
+	_ = v.Unmarshal(path, &out)
+	return out
+}
+
+// applyToNode applies environment overrides to the scalar leaves of a
+// mapping tree, using the same naming rules as struct binding
+func (b *envBinder) applyToNode(n *yaml.Node, path string) {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		keyPath := n.Content[i].Value
+		if path != "" {
+			keyPath = path + "." + keyPath
+		}
+		value := n.Content[i+1]
+		switch value.Kind {
+		case yaml.MappingNode:
+			b.applyToNode(value, keyPath)
+		case yaml.ScalarNode:
+			envName := b.varName(keyPath)
+			if envValue, exists := b.lookupEnv(envName); exists {
+				b.logOverride(keyPath, envValue, envName, isSensitivePath(keyPath))
+				// Clear the tag so the value is resolved like a plain YAML scalar
+				value.Value, value.Tag, value.Style = envValue, "", 0
+				b.applied[keyPath] = envName
+			}
+		}
+	}
+}
+
+// isSensitivePath reports whether a key along path looks like it holds a
+// credential; without a Target this is how values count as secret
+func isSensitivePath(path string) bool {
+	for _, key := range strings.Split(path, ".") {
+		if isSensitiveKey(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// cloneNode deep-copies a node tree, replacing aliases with copies of the
+// nodes they reference so the result can be modified safely
+func cloneNode(n *yaml.Node) *yaml.Node {
+	n = resolveAlias(n)
+	if n == nil {
+		return nil
+	}
+	out := *n
+	out.Anchor = ""
+	out.Content = make([]*yaml.Node, len(n.Content))
+	for i, child := range n.Content {
+		out.Content[i] = cloneNode(child)
+	}
+	return &out
+}
@@ -0,0 +1,126 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const valuesBaseYAML = `
+app:
+  name: plugin-host
+  port: 8080
+  debug: false
+  ratio: 0.5
+  timeout: 30s
+  version: "1.0"
+plugins:
+  cache:
+    driver: redis
+    hosts: [a, b]
+`
+
+func loadTestValues(t *testing.T, opts LoaderOptions) *Values {
+	if opts.BaseSource == nil {
+		opts.BaseSource = ReaderSource(strings.NewReader(valuesBaseYAML))
+	}
+	v, err := LoadValues(opts)
+	require.NoError(t, err)
+	return v
+}
+
+// Test typed getters over the merged tree
+func TestValues_TypedGetters(t *testing.T) {
+	v := loadTestValues(t, LoaderOptions{
+		LocalSource: ReaderSource(strings.NewReader("app:\n  debug: true\n")),
+	})
+
+	assert.Equal(t, "plugin-host", v.String("app.name"))
+	assert.Equal(t, 8080, v.Int("app.port"))
+	assert.Equal(t, int64(8080), v.Int64("app.port"))
+	assert.True(t, v.Bool("app.debug"))
+	assert.Equal(t, 0.5, v.Float64("app.ratio"))
+	assert.Equal(t, 30*time.Second, v.Duration("app.timeout"))
+	assert.Equal(t, "1.0", v.String("app.version"))
+	assert.Equal(t, []string{"a", "b"}, v.Strings("plugins.cache.hosts"))
+}
+
+// Test Get/Has for present and missing keys
+func TestValues_GetAndHas(t *testing.T) {
+	v := loadTestValues(t, LoaderOptions{})
+
+	value, ok := v.Get("app.port")
+	require.True(t, ok)
+	assert.Equal(t, 8080, value)
+
+	_, ok = v.Get("app.missing")
+	assert.False(t, ok)
+	assert.False(t, v.Has("nope.nested"))
+	assert.True(t, v.Has("plugins.cache"))
+
+	assert.Equal(t, 0, v.Int("app.missing"))
+	assert.Equal(t, 0, v.Int("app.name"))
+}
+
+// Test Sub, Keys, Unmarshal and All
+func TestValues_SubAndUnmarshal(t *testing.T) {
+	v := loadTestValues(t, LoaderOptions{})
+
+	cache := v.Sub("plugins.cache")
+	require.NotNil(t, cache)
+	assert.Equal(t, "redis", cache.String("driver"))
+	assert.Equal(t, []string{"driver", "hosts"}, cache.Keys(""))
+	assert.Nil(t, v.Sub("plugins.missing"))
+
+	var cacheCfg struct {
+		Driver string   `yaml:"driver"`
+		Hosts  []string `yaml:"hosts"`
+	}
+	require.NoError(t, v.Unmarshal("plugins.cache", &cacheCfg))
+	assert.Equal(t, "redis", cacheCfg.Driver)
+
+	assert.Error(t, v.Unmarshal("plugins.missing", &cacheCfg))
+
+	all := v.All()
+	assert.Contains(t, all, "app")
+	assert.Contains(t, all, "plugins")
+}
+
+// Test that env overrides apply to keys present in the merged tree
+func TestValues_EnvOverrides(t *testing.T) {
+	setEnvVar(t, "VALUES_APP__PORT", "9090")
+	setEnvVar(t, "VALUES_PLUGINS__CACHE__DRIVER", "memcached")
+	setEnvVar(t, "VALUES_APP__UNKNOWN", "ignored")
+
+	v := loadTestValues(t, LoaderOptions{EnvPrefix: "VALUES_", Delimiter: "__"})
+
+	assert.Equal(t, 9090, v.Int("app.port"))
+	assert.Equal(t, "memcached", v.String("plugins.cache.driver"))
+	assert.False(t, v.Has("app.unknown"))
+}
+
+// Test that env overrides don't leak into the parsed source layers
+func TestValues_EnvDoesNotMutateLayers(t *testing.T) {
+	setEnvVar(t, "VALUESISO_APP__NAME", "env")
+
+	layers, err := loadLayers(LoaderOptions{BaseSource: ReaderSource(strings.NewReader(valuesBaseYAML))})
+	require.NoError(t, err)
+	root := cloneNode(newMerger(LoaderOptions{}).mergeLayers(layers))
+	newEnvBinder(LoaderOptions{EnvPrefix: "VALUESISO_", Delimiter: "__"}).applyToNode(root, "")
+
+	assert.Equal(t, "env", lookupPath(root, "app.name").Value)
+	assert.Equal(t, "plugin-host", lookupPath(layers[0].node, "app.name").Value)
+}
+
+func TestValues_Validation(t *testing.T) {
+	_, err := LoadValues(LoaderOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "BaseSource cannot be nil")
+
+	_, err = LoadValues(LoaderOptions{BaseSource: ReaderSource(strings.NewReader("")), EnvPrefix: "X_"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "delimiter cannot be empty")
+}
@@ -0,0 +1,48 @@
+package yamlenv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// VerifyFunc checks the raw bytes of a source before they are parsed, e.g.
+// against a checksum or a detached signature (cosign, minisign). A non-nil
+// error rejects the source.
+type VerifyFunc func(data []byte) error
+
+// VerifiedSource reads source in full and passes the bytes through each of
+// verify before handing them on, so tampered configs fail the load instead
+// of being parsed.
+//
+//	base := yamlenv.VerifiedSource(yamlenv.HTTPSource(url), yamlenv.VerifySHA256(pinned))
+func VerifiedSource(source ConfigSource, verify ...VerifyFunc) ConfigSource {
+	return func() (io.ReadCloser, error) {
+		reader, err := source()
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("read config source: %w", err)
+		}
+		for _, v := range verify {
+			if err := v(data); err != nil {
+				return nil, fmt.Errorf("verify config source: %w", err)
+			}
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+}
+
+// VerifySHA256 returns a VerifyFunc that accepts only data whose SHA-256
+// digest is expected, given as hex with or without a "sha256:" prefix
+func VerifySHA256(expected string) VerifyFunc {
+	digest := "sha256:" + strings.TrimPrefix(strings.ToLower(strings.TrimSpace(expected)), "sha256:")
+	return func(data []byte) error {
+		return verifyDigest(data, digest)
+	}
+}
@@ -0,0 +1,49 @@
+package yamlenv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that sources pass only when every check accepts their bytes
+func TestVerifiedSource(t *testing.T) {
+	content := "app:\n  name: verified\n"
+	sum := sha256.Sum256([]byte(content))
+	digest := hex.EncodeToString(sum[:])
+
+	var signed []byte
+	signature := func(data []byte) error {
+		signed = data
+		return nil
+	}
+	var cfg TestConfig
+	require.NoError(t, LoadConfig(LoaderOptions{
+		BaseSource: VerifiedSource(StringSource(content), VerifySHA256(strings.ToUpper(digest)), signature),
+		Target:     &cfg,
+	}))
+	assert.Equal(t, "verified", cfg.App.Name)
+	assert.Equal(t, content, string(signed))
+
+	require.NoError(t, LoadConfig(LoaderOptions{
+		BaseSource: VerifiedSource(StringSource(content), VerifySHA256("sha256:"+digest)),
+		Target:     &cfg,
+	}))
+
+	err := LoadConfig(LoaderOptions{
+		BaseSource: VerifiedSource(StringSource("app:\n  name: tampered\n"), VerifySHA256(digest)),
+		Target:     &cfg,
+	})
+	assert.ErrorContains(t, err, "load base config: open config source: verify config source: digest mismatch: expected sha256:"+digest+", got sha256:")
+
+	err = LoadConfig(LoaderOptions{
+		BaseSource: VerifiedSource(StringSource(content), func([]byte) error { return errors.New("bad signature") }),
+		Target:     &cfg,
+	})
+	assert.EqualError(t, err, "load base config: open config source: verify config source: bad signature")
+}
@@ -0,0 +1,41 @@
+package yamlenv
+
+import "fmt"
+
+// ViperSettings is the part of *viper.Viper read by ViperSource. It is
+// declared here so yamlenv doesn't depend on viper.
+type ViperSettings interface {
+	AllSettings() map[string]any
+}
+
+// ViperConfigMerger is the part of *viper.Viper written by ToViper
+type ViperConfigMerger interface {
+	MergeConfigMap(cfg map[string]any) error
+}
+
+// ViperSource creates a ConfigSource from a viper instance's settings
+// (defaults, config files and bound values), so a service migrating from viper
+// can use its existing setup as the base layer while moving to yamlenv:
+//
+//	err := yamlenv.LoadConfig(yamlenv.LoaderOptions{
+//	    BaseSource:  yamlenv.ViperSource(viper.GetViper()),
+//	    LocalSource: yamlenv.FileSource("config.local.yaml"),
+//	    Target:      &cfg,
+//	})
+func ViperSource(v ViperSettings) ConfigSource {
+	return mapFuncSource(v.AllSettings)
+}
+
+// ToViper loads opts through yamlenv (layers and env overrides) and merges
+// the result into a viper instance, so existing viper.GetString call sites
+// keep working while the service switches incrementally.
+func ToViper(v ViperConfigMerger, opts LoaderOptions) error {
+	values, err := LoadValues(opts)
+	if err != nil {
+		return err
+	}
+	if err := v.MergeConfigMap(values.All()); err != nil {
+		return fmt.Errorf("merge into viper: %w", err)
+	}
+	return nil
+}
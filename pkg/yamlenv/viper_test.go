@@ -0,0 +1,69 @@
+package yamlenv
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeViper mimics the *viper.Viper methods used by the adapter
+type fakeViper struct {
+	settings map[string]any
+	mergeErr error
+}
+
+func (f *fakeViper) AllSettings() map[string]any { return f.settings }
+
+func (f *fakeViper) MergeConfigMap(cfg map[string]any) error {
+	if f.mergeErr != nil {
+		return f.mergeErr
+	}
+	f.settings = cfg
+	return nil
+}
+
+// Test populating a viper instance from yamlenv layers
+func TestToViper(t *testing.T) {
+	setEnvVar(t, "TOVIPER_DB__PORT", "6543")
+
+	v := &fakeViper{}
+	err := ToViper(v, LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader("app:\n  name: base\ndb:\n  port: 5432\n")),
+		LocalSource: ReaderSource(strings.NewReader("app:\n  name: local\n")),
+		EnvPrefix:   "TOVIPER_",
+		Delimiter:   "__",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "local", v.settings["app"].(map[string]any)["name"])
+	assert.Equal(t, 6543, v.settings["db"].(map[string]any)["port"])
+}
+
+func TestToViper_MergeError(t *testing.T) {
+	v := &fakeViper{mergeErr: errors.New("boom")}
+	err := ToViper(v, LoaderOptions{BaseSource: ReaderSource(strings.NewReader("a: 1\n"))})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "merge into viper")
+}
+
+// Test using viper settings as the base layer
+func TestViperSource(t *testing.T) {
+	v := &fakeViper{settings: map[string]any{
+		"app": map[string]any{"name": "from-viper", "port": 8080},
+	}}
+
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:  ViperSource(v),
+		LocalSource: ReaderSource(strings.NewReader("app:\n  port: 3000\n")),
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "from-viper", cfg.App.Name)
+	assert.Equal(t, 3000, cfg.App.Port)
+}
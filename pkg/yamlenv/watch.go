@@ -0,0 +1,101 @@
+package yamlenv
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WatchOptions configures Loader.Watch
+type WatchOptions struct {
+	Paths    []string      // files to watch, usually the base and local config paths
+	Interval time.Duration // how often the files are checked; default 1s
+	Debounce time.Duration // quiet period after the last change before reloading; default 2*Interval
+	OnError  func(error)   // optional; called when a reload fails
+}
+
+// fileState identifies a version of a watched file
+type fileState struct {
+	resolved string // path after following symlinks
+	modTime  time.Time
+	size     int64
+	missing  bool
+}
+
+// Watch polls the given files and reloads the loader once per logical change.
+// It blocks until ctx is done and returns ctx.Err().
+//
+// Changes are debounced: a burst of writes, or an editor's
+// write-temp-then-rename, triggers a single reload after the files have been
+// stable for Debounce. Paths are resolved through symlinks on every check, so
+// Kubernetes ConfigMap volumes (where the "..data" symlink is flipped to a new
+// directory) are picked up like any other change.
+func (l *Loader) Watch(ctx context.Context, opts WatchOptions) error {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	debounce := opts.Debounce
+	if debounce <= 0 {
+		debounce = 2 * interval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := statFiles(opts.Paths)
+	pending := false
+	var changedAt time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			current := statFiles(opts.Paths)
+			if !sameFileStates(last, current) {
+				last, pending, changedAt = current, true, now
+				continue
+			}
+			if pending && now.Sub(changedAt) >= debounce {
+				pending = false
+				if err := l.Reload(); err != nil && opts.OnError != nil {
+					opts.OnError(err)
+				}
+			}
+		}
+	}
+}
+
+// statFiles captures the current state of each path
+func statFiles(paths []string) []fileState {
+	states := make([]fileState, len(paths))
+	for i, path := range paths {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			states[i] = fileState{missing: true}
+			continue
+		}
+		info, err := os.Stat(resolved)
+		if err != nil {
+			states[i] = fileState{missing: true}
+			continue
+		}
+		states[i] = fileState{resolved: resolved, modTime: info.ModTime(), size: info.Size()}
+	}
+	return states
+}
+
+func sameFileStates(a, b []fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].resolved != b[i].resolved || a[i].size != b[i].size ||
+			a[i].missing != b[i].missing || !a[i].modTime.Equal(b[i].modTime) {
+			return false
+		}
+	}
+	return true
+}
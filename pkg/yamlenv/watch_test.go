@@ -0,0 +1,135 @@
+package yamlenv
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startWatch runs Watch in the background with fast polling and counts reloads
+func startWatch(t *testing.T, loader *Loader, paths ...string) *atomic.Int32 {
+	var reloads atomic.Int32
+	loader.OnChange(func([]Change) { reloads.Add(1) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- loader.Watch(ctx, WatchOptions{Paths: paths, Interval: 5 * time.Millisecond, Debounce: 40 * time.Millisecond})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+	})
+	// Let the watcher record the initial file states
+	time.Sleep(20 * time.Millisecond)
+	return &reloads
+}
+
+// Test that a burst of writes results in a single reload
+func TestLoader_WatchDebouncesBursts(t *testing.T) {
+	file := createTempYAML(t, "app:\n  port: 1\n")
+
+	var cfg LoaderTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(file), Target: &cfg})
+	require.NoError(t, err)
+	reloads := startWatch(t, loader, file)
+
+	for port := 2; port <= 5; port++ {
+		writeFile(t, file, "app:\n  port: "+string(rune('0'+port))+"\n")
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	require.Eventually(t, func() bool {
+		return loader.Current().(*LoaderTestConfig).App.Port == 5
+	}, 2*time.Second, 5*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(1), reloads.Load())
+}
+
+// Test write-temp-then-rename as done by editors
+func TestLoader_WatchAtomicRename(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	writeFile(t, file, "app:\n  name: before\n")
+
+	var cfg LoaderTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(file), Target: &cfg})
+	require.NoError(t, err)
+	startWatch(t, loader, file)
+
+	tmp := filepath.Join(dir, ".config.yaml.tmp")
+	writeFile(t, tmp, "app:\n  name: after\n")
+	require.NoError(t, os.Rename(tmp, file))
+
+	require.Eventually(t, func() bool {
+		return loader.Current().(*LoaderTestConfig).App.Name == "after"
+	}, 2*time.Second, 5*time.Millisecond)
+}
+
+// Test a Kubernetes-style "..data" symlink flip
+func TestLoader_WatchSymlinkFlip(t *testing.T) {
+	dir := t.TempDir()
+	v1 := filepath.Join(dir, "..2024_01")
+	v2 := filepath.Join(dir, "..2024_02")
+	require.NoError(t, os.Mkdir(v1, 0o755))
+	require.NoError(t, os.Mkdir(v2, 0o755))
+	writeFile(t, filepath.Join(v1, "config.yaml"), "app:\n  name: v1\n")
+	writeFile(t, filepath.Join(v2, "config.yaml"), "app:\n  name: v2\n")
+
+	data := filepath.Join(dir, "..data")
+	require.NoError(t, os.Symlink(v1, data))
+	file := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.Symlink(filepath.Join("..data", "config.yaml"), file))
+
+	var cfg LoaderTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(file), Target: &cfg})
+	require.NoError(t, err)
+	assert.Equal(t, "v1", cfg.App.Name)
+	startWatch(t, loader, file)
+
+	tmpLink := filepath.Join(dir, "..data_tmp")
+	require.NoError(t, os.Symlink(v2, tmpLink))
+	require.NoError(t, os.Rename(tmpLink, data))
+
+	require.Eventually(t, func() bool {
+		return loader.Current().(*LoaderTestConfig).App.Name == "v2"
+	}, 2*time.Second, 5*time.Millisecond)
+}
+
+// Test that failed reloads are reported and the watcher keeps running
+func TestLoader_WatchReportsErrors(t *testing.T) {
+	file := createTempYAML(t, "app:\n  port: 1\n")
+
+	var cfg LoaderTestConfig
+	loader, err := NewLoader(LoaderOptions{BaseSource: FileSource(file), Target: &cfg})
+	require.NoError(t, err)
+
+	errs := make(chan error, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go loader.Watch(ctx, WatchOptions{
+		Paths:    []string{file},
+		Interval: 5 * time.Millisecond,
+		OnError:  func(err error) { errs <- err },
+	})
+	time.Sleep(20 * time.Millisecond)
+
+	writeFile(t, file, "app:\n  port: [broken\n")
+	select {
+	case err := <-errs:
+		assert.Contains(t, err.Error(), "reload config")
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected reload error")
+	}
+
+	writeFile(t, file, "app:\n  port: 2\n")
+	require.Eventually(t, func() bool {
+		return loader.Current().(*LoaderTestConfig).App.Port == 2
+	}, 2*time.Second, 5*time.Millisecond)
+}
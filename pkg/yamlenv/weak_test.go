@@ -0,0 +1,98 @@
+package yamlenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type WeakTestConfig struct {
+	Port    int      `yaml:"port"`
+	Ratio   float64  `yaml:"ratio"`
+	Workers uint     `yaml:"workers"`
+	Enabled bool     `yaml:"enabled"`
+	Verbose bool     `yaml:"verbose"`
+	Strict  bool     `yaml:"strict"`
+	Count   int      `yaml:"count"`
+	Hosts   []string `yaml:"hosts"`
+	Name    string   `yaml:"name"`
+	MaxConn *int     `yaml:"max_conn"`
+}
+
+const weakYAML = `
+port: "8080"
+ratio: "0.25"
+workers: " 4 "
+enabled: "yes"
+verbose: 1
+strict: off
+count: true
+hosts: single-host
+name: 42
+max_conn: "10"
+`
+
+// Test that loosely typed scalars bind when WeaklyTyped is enabled
+func TestLoadConfig_WeaklyTyped(t *testing.T) {
+	var cfg WeakTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader(weakYAML)),
+		WeaklyTyped: true,
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, 0.25, cfg.Ratio)
+	assert.Equal(t, uint(4), cfg.Workers)
+	assert.True(t, cfg.Enabled)
+	assert.True(t, cfg.Verbose)
+	assert.False(t, cfg.Strict)
+	assert.Equal(t, 1, cfg.Count)
+	assert.Equal(t, []string{"single-host"}, cfg.Hosts)
+	assert.Equal(t, "42", cfg.Name)
+	require.NotNil(t, cfg.MaxConn)
+	assert.Equal(t, 10, *cfg.MaxConn)
+}
+
+// Test that the same input fails without WeaklyTyped
+func TestLoadConfig_StrictTypesByDefault(t *testing.T) {
+	var cfg WeakTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader(weakYAML)),
+		Target:     &cfg,
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "load base config")
+}
+
+// Test that unconvertible values still fail in weak mode
+func TestLoadConfig_WeaklyTypedInvalid(t *testing.T) {
+	var cfg WeakTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader("port: eighty\n")),
+		WeaklyTyped: true,
+		Target:      &cfg,
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "eighty")
+}
+
+// Test that weak conversion composes with the local layer
+func TestLoadConfig_WeaklyTypedLocal(t *testing.T) {
+	var cfg WeakTestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:  ReaderSource(strings.NewReader("port: 80\nhosts: [a, b]\n")),
+		LocalSource: ReaderSource(strings.NewReader("port: \"3000\"\n")),
+		WeaklyTyped: true,
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3000, cfg.Port)
+	assert.Equal(t, []string{"a", "b"}, cfg.Hosts)
+}
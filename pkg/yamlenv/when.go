@@ -0,0 +1,403 @@
+package yamlenv
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// whenKey is the key holding a mapping's condition when LoaderOptions.When is set
+const whenKey = "when"
+
+// applyConditions evaluates the when: guards below n against vars, in place.
+// A mapping whose condition is false is dropped, along with the key or list
+// element holding it; one whose condition is true loses its when key. It
+// reports false if n itself is dropped.
+func applyConditions(n *yaml.Node, path string, vars map[string]any) (bool, error) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		if idx := mappingIndex(n, whenKey); idx >= 0 {
+			cond := n.Content[idx+1]
+			if cond.Kind != yaml.ScalarNode {
+				return false, fmt.Errorf("%s: when must be an expression", joinPath(path, whenKey))
+			}
+			ok, err := evalCondition(cond.Value, vars)
+			if err != nil {
+				return false, fmt.Errorf("%s: %w", joinPath(path, whenKey), err)
+			}
+			if !ok {
+				return false, nil
+			}
+			n.Content = append(n.Content[:idx], n.Content[idx+2:]...)
+		}
+		content := n.Content[:0]
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			keep, err := applyConditions(n.Content[i+1], joinPath(path, n.Content[i].Value), vars)
+			if err != nil {
+				return false, err
+			}
+			if keep {
+				content = append(content, n.Content[i], n.Content[i+1])
+			}
+		}
+		n.Content = content
+	case yaml.SequenceNode:
+		content := n.Content[:0]
+		for i, elem := range n.Content {
+			keep, err := applyConditions(elem, fmt.Sprintf("%s[%d]", path, i), vars)
+			if err != nil {
+				return false, err
+			}
+			if keep {
+				content = append(content, elem)
+			}
+		}
+		n.Content = content
+	}
+	return true, nil
+}
+
+// loadConditionalNode loads a source like loadNodeFromSource. With
+// opts.When set, it applies the when: guards in each document of the source
+// and merges the documents that remain, in order.
+func loadConditionalNode(opts LoaderOptions, source ConfigSource) (*yaml.Node, error) {
+	if opts.When == nil {
+		return loadNodeFromSource(source, sourceLimits(opts))
+	}
+	docs, err := loadDocumentsFromSource(source, sourceLimits(opts), true)
+	if err != nil {
+		return nil, err
+	}
+	m := newMerger(opts)
+	var merged *yaml.Node
+	for i, doc := range docs {
+		keep, err := applyConditions(doc, "", opts.When)
+		if err != nil {
+			if len(docs) > 1 {
+				return nil, fmt.Errorf("document %d: %w", i+1, err)
+			}
+			return nil, err
+		}
+		switch {
+		case !keep:
+		case merged == nil:
+			// Kept as is, so null markers still remove keys of lower layers
+			merged = doc
+		default:
+			merged = m.merge(merged, doc)
+		}
+	}
+	return merged, nil
+}
+
+// evalCondition evaluates a when: expression against vars. Expressions
+// compare names from vars with literals:
+//
+//	env == "prod"
+//	region in ["eu-west-1", "eu-central-1"] && !debug
+//	replicas >= 3 || (tier != "free" && beta)
+//
+// Names may be dotted to reach into nested maps; an unknown name is an error.
+func evalCondition(expr string, vars map[string]any) (bool, error) {
+	p := &condParser{vars: vars}
+	if err := p.tokenize(expr); err != nil {
+		return false, fmt.Errorf("condition %q: %w", expr, err)
+	}
+	v, err := p.or()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return false, fmt.Errorf("condition %q: %w", expr, err)
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("condition %q: result %v is not a boolean", expr, v)
+	}
+	return b, nil
+}
+
+// condToken is a lexical token of a condition
+type condToken struct {
+	kind byte // 's' string, 'n' number, 'i' name, 'o' operator or punctuation
+	text string
+}
+
+// condParser is a recursive descent parser that evaluates as it parses
+type condParser struct {
+	vars   map[string]any
+	tokens []condToken
+	pos    int
+}
+
+func (p *condParser) tokenize(s string) error {
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				return fmt.Errorf("unterminated string")
+			}
+			p.tokens = append(p.tokens, condToken{'s', s[i+1 : i+1+end]})
+			i += end + 2
+		case c >= '0' && c <= '9' || c == '-' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9':
+			j := i + 1
+			for j < len(s) && (s[j] >= '0' && s[j] <= '9' || s[j] == '.') {
+				j++
+			}
+			p.tokens = append(p.tokens, condToken{'n', s[i:j]})
+			i = j
+		case c == '_' || unicode.IsLetter(rune(c)):
+			j := i + 1
+			for j < len(s) && (s[j] == '_' || s[j] == '.' || s[j] == '-' || unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j]))) {
+				j++
+			}
+			p.tokens = append(p.tokens, condToken{'i', s[i:j]})
+			i = j
+		default:
+			op := ""
+			for _, candidate := range []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")", "[", "]", ","} {
+				if strings.HasPrefix(s[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return fmt.Errorf("unexpected %q", string(c))
+			}
+			p.tokens = append(p.tokens, condToken{'o', op})
+			i += len(op)
+		}
+	}
+	return nil
+}
+
+// accept consumes the next token if it is the operator or keyword op
+func (p *condParser) accept(op string) bool {
+	if p.pos < len(p.tokens) && (p.tokens[p.pos].kind == 'o' || p.tokens[p.pos].kind == 'i') && p.tokens[p.pos].text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *condParser) or() (any, error) {
+	left, err := p.and()
+	for err == nil && p.accept("||") {
+		var right any
+		if right, err = p.and(); err == nil {
+			left, err = logical(left, right, func(a, b bool) bool { return a || b })
+		}
+	}
+	return left, err
+}
+
+func (p *condParser) and() (any, error) {
+	left, err := p.not()
+	for err == nil && p.accept("&&") {
+		var right any
+		if right, err = p.not(); err == nil {
+			left, err = logical(left, right, func(a, b bool) bool { return a && b })
+		}
+	}
+	return left, err
+}
+
+func (p *condParser) not() (any, error) {
+	if !p.accept("!") {
+		return p.comparison()
+	}
+	v, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return nil, fmt.Errorf("! needs a boolean, got %v", v)
+	}
+	return !b, nil
+}
+
+func (p *condParser) comparison() (any, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">", "in"} {
+		if !p.accept(op) {
+			continue
+		}
+		right, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		return compare(op, left, right)
+	}
+	return left, nil
+}
+
+func (p *condParser) operand() (any, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+	switch tok.kind {
+	case 's':
+		return tok.text, nil
+	case 'n':
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", tok.text)
+		}
+		return f, nil
+	case 'i':
+		switch tok.text {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return p.lookup(tok.text)
+	}
+	switch tok.text {
+	case "(":
+		v, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing )")
+		}
+		return v, nil
+	case "[":
+		list := []any{}
+		for !p.accept("]") {
+			if len(list) > 0 && !p.accept(",") {
+				return nil, fmt.Errorf("missing , or ]")
+			}
+			v, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", tok.text)
+}
+
+// lookup resolves a dotted name in vars
+func (p *condParser) lookup(name string) (any, error) {
+	var v any = p.vars
+	for _, key := range strings.Split(name, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unknown name %q", name)
+		}
+		if v, ok = m[key]; !ok {
+			return nil, fmt.Errorf("unknown name %q", name)
+		}
+	}
+	return condValue(v), nil
+}
+
+// condValue converts a context value to the types conditions work with:
+// numbers become float64 and string slices []any
+func condValue(v any) any {
+	switch v := v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32:
+		f, _ := strconv.ParseFloat(fmt.Sprint(v), 64)
+		return f
+	case []string:
+		out := make([]any, len(v))
+		for i, s := range v {
+			out[i] = s
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, elem := range v {
+			out[i] = condValue(elem)
+		}
+		return out
+	}
+	return v
+}
+
+func logical(a, b any, fn func(a, b bool) bool) (any, error) {
+	x, ok1 := a.(bool)
+	y, ok2 := b.(bool)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("&& and || need booleans, got %v and %v", a, b)
+	}
+	return fn(x, y), nil
+}
+
+// checkComparable rejects the values == can't compare, such as lists and
+// maps, which would otherwise panic
+func checkComparable(op string, v any) error {
+	if v == nil || reflect.TypeOf(v).Comparable() {
+		return nil
+	}
+	kind := reflect.TypeOf(v).Kind().String()
+	if kind == "slice" {
+		kind = "list"
+	}
+	return fmt.Errorf("%s can't compare a %s", op, kind)
+}
+
+func compare(op string, a, b any) (any, error) {
+	if err := checkComparable(op, a); err != nil {
+		return nil, err
+	}
+	if _, ok := b.([]any); !ok || op != "in" {
+		if err := checkComparable(op, b); err != nil {
+			return nil, err
+		}
+	}
+	switch op {
+	case "==":
+		return a == b, nil
+	case "!=":
+		return a != b, nil
+	case "in":
+		list, ok := b.([]any)
+		if !ok {
+			return nil, fmt.Errorf("in needs a list, got %v", b)
+		}
+		for _, elem := range list {
+			if err := checkComparable(op, elem); err != nil {
+				return nil, err
+			}
+			if elem == a {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	x, ok1 := a.(float64)
+	y, ok2 := b.(float64)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("%s needs numbers, got %v and %v", op, a, b)
+	}
+	switch op {
+	case "<":
+		return x < y, nil
+	case "<=":
+		return x <= y, nil
+	case ">":
+		return x > y, nil
+	default:
+		return x >= y, nil
+	}
+}
@@ -0,0 +1,148 @@
+package yamlenv
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type WhenTestConfig struct {
+	DB struct {
+		Host string `yaml:"host"`
+		Pool int    `yaml:"pool"`
+	} `yaml:"db"`
+	Features []struct {
+		Name string `yaml:"name"`
+	} `yaml:"features"`
+	Tracing *struct {
+		Endpoint string `yaml:"endpoint"`
+	} `yaml:"tracing"`
+}
+
+const whenTestYAML = `
+db:
+  host: localhost
+  pool: 5
+features:
+  - name: search
+  - name: beta-ui
+    when: env != "prod" || beta
+tracing:
+  when: region in ["eu-west-1", "us-east-1"]
+  endpoint: collector:4317
+---
+when: env == "prod"
+db:
+  host: db.prod
+---
+when: env == "prod" && replicas >= 3
+db:
+  pool: 50
+`
+
+// Test that guarded mappings and documents are kept only when their condition holds
+func TestWhen_Conditions(t *testing.T) {
+	tests := []struct {
+		name     string
+		vars     map[string]any
+		host     string
+		pool     int
+		features int
+		tracing  bool
+	}{
+		{"dev", map[string]any{"env": "dev", "beta": false, "region": "eu-west-1", "replicas": 1}, "localhost", 5, 2, true},
+		{"prod", map[string]any{"env": "prod", "beta": false, "region": "ap-south-1", "replicas": 1}, "db.prod", 5, 1, false},
+		{"prod scaled", map[string]any{"env": "prod", "beta": true, "region": "us-east-1", "replicas": 3}, "db.prod", 50, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg WhenTestConfig
+			require.NoError(t, LoadConfig(LoaderOptions{
+				BaseSource: ReaderSource(strings.NewReader(whenTestYAML)),
+				When:       tt.vars,
+				Target:     &cfg,
+			}))
+			assert.Equal(t, tt.host, cfg.DB.Host)
+			assert.Equal(t, tt.pool, cfg.DB.Pool)
+			assert.Len(t, cfg.Features, tt.features)
+			assert.Equal(t, tt.tracing, cfg.Tracing != nil)
+		})
+	}
+}
+
+// Test that without a When context, when keys and later documents are ignored as before
+func TestWhen_Disabled(t *testing.T) {
+	var cfg WhenTestConfig
+	result, err := Load(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader(whenTestYAML)),
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", cfg.DB.Host)
+	assert.Contains(t, result.UnusedKeys, "tracing.when")
+}
+
+// Test expression evaluation and the errors for malformed conditions
+func TestWhen_Expressions(t *testing.T) {
+	vars := map[string]any{
+		"env":    "prod",
+		"debug":  false,
+		"count":  3,
+		"zones":  []string{"a", "b"},
+		"labels": map[string]any{"team": "core"},
+	}
+	for expr, want := range map[string]bool{
+		`env == 'prod'`:                 true,
+		`!(env == "prod")`:              false,
+		`count > 2 && count <= 3`:       true,
+		`count < -1 || debug`:           false,
+		`labels.team == "core"`:         true,
+		`"b" in zones`:                  true,
+		`env in ["dev", "staging"]`:     false,
+		`debug == false && env != null`: true,
+	} {
+		got, err := evalCondition(expr, vars)
+		require.NoError(t, err, expr)
+		assert.Equal(t, want, got, expr)
+	}
+
+	for expr, msg := range map[string]string{
+		`env = "prod"`:     `unexpected "="`,
+		`env == "prod`:     `unterminated string`,
+		`env`:              `result prod is not a boolean`,
+		`count > "x"`:      `> needs numbers, got 3 and x`,
+		`(env == "prod"`:   `missing )`,
+		`env == "prod" )`:  `unexpected ")"`,
+		`zones == zones`:   `== can't compare a list`,
+		`labels == labels`: `== can't compare a map`,
+		`labels != "x"`:    `!= can't compare a map`,
+		`labels in [1]`:    `in can't compare a map`,
+		`1 in [labels]`:    `in can't compare a map`,
+	} {
+		_, err := evalCondition(expr, vars)
+		assert.EqualError(t, err, "condition "+strconv.Quote(expr)+": "+msg, expr)
+	}
+
+	// Both sides are always evaluated, so unknown names are caught
+	_, err := evalCondition(`true || missing.name == 1`, vars)
+	assert.EqualError(t, err, `condition "true || missing.name == 1": unknown name "missing.name"`)
+
+	var cfg WhenTestConfig
+	err = LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("db:\n  host: x\n---\ndb:\n  when: zone == 1\n")),
+		When:       map[string]any{},
+		Target:     &cfg,
+	})
+	assert.EqualError(t, err, `load base config: document 2: db.when: condition "zone == 1": unknown name "zone"`)
+
+	// Map-valued vars fail the load instead of panicking
+	err = LoadConfig(LoaderOptions{
+		BaseSource: ReaderSource(strings.NewReader("db:\n  when: labels == labels\n  host: x\n")),
+		When:       map[string]any{"labels": map[string]any{"x": "y"}},
+		Target:     &cfg,
+	})
+	assert.EqualError(t, err, `load base config: db.when: condition "labels == labels": == can't compare a map`)
+}
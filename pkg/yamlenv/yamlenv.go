@@ -1,6 +1,9 @@
 package yamlenv
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -8,6 +11,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -17,18 +21,76 @@ import (
 type ConfigSource func() (io.ReadCloser, error)
 
 type LoaderOptions struct {
-	BaseSource     ConfigSource // required: function that returns base config reader
-	LocalSource    ConfigSource // optional: function that returns local override config reader
-	EnvPrefix      string       // e.g. "WORKING_"
-	Delimiter      string       // nesting delimiter in env, e.g. "__"; "" = no nesting
-	Target         any          // &cfg
-	NormalizeDash  bool         // if true, convert "_" in ENV path to "-" in YAML keys (for kebab-case YAML like "app-name")
-	ForceLowerYAML bool         // if true, normalize YAML keys to lowercase to match ENV mapping
-	DebugKeys      bool         // if true, print final keys for debugging
+	BaseSource     ConfigSource     // required: function that returns base config reader
+	LocalSource    ConfigSource     // optional: function that returns local override config reader
+	LocalRequired  bool             // if true, a LocalSource that doesn't exist is an error instead of being skipped
+	EnvPrefix      string           // e.g. "WORKING_"
+	Delimiter      string           // nesting delimiter in env, e.g. "__"; "" = no nesting
+	Target         any              // &cfg
+	NormalizeDash  bool             // if true, convert "_" in ENV path to "-" in YAML keys (for kebab-case YAML like "app-name")
+	ForceLowerYAML bool             // if true, normalize YAML keys to lowercase to match ENV mapping
+	DebugKeys      bool             // if true, log each env override applied to Logger
+	MaxSourceSize  int64            // maximum bytes read from a single source; 0 = unlimited
+	MaxAliasNodes  int              // maximum nodes a single source may expand to through aliases; 0 = 1,000,000, -1 = unlimited
+	MaxYAMLDepth   int              // maximum nesting of mappings and lists in a single source; 0 = 100, -1 = unlimited
+	ArrayMerge     MergeStrategy    // how lists from later layers combine with earlier ones; default MergeReplace
+	ArrayMergeKeys []string         // element fields matched by MergeByKey; default "name", "id"
+	Section        string           // optional dot-separated subtree to bind into Target, e.g. "db"; "" = whole document
+	EnvTagCompat   bool             // if true, also honor env:"NAME" (exact name) and envconfig:"NAME" (EnvPrefix+NAME) tags
+	DecodeHooks    []DecodeHookFunc // conversions applied to raw values while binding YAML into Target
+	WeaklyTyped    bool             // if true, accept "8080" for ints, 1/0/yes/no for bools and a single scalar for lists
+	SecretsSource  ConfigSource     // optional: secrets (e.g. DirSource, CredentialsSource) merged after LocalSource
+	RuntimeSource  ConfigSource     // optional: runtime metadata (e.g. DownwardAPISource) merged last under the reserved "runtime" key
+	EnvIgnoreCase  bool             // if true, match env names regardless of case, as Windows does
+
+	PrefixSeparator string // optional: appended to EnvPrefix unless it already ends with it, so "WORKING" and "_" read WORKING_APP__NAME
+
+	CaseInsensitiveKeys bool           // if true, YAML keys match struct fields regardless of case ("Host", "host", "HOST")
+	KeyTranslation      KeyTranslation // optional: normalizes key segments for YAML matching and env names, e.g. DashToUnderscore
+
+	ResolveAliasesAfterMerge bool // if true, aliases resolve against the merged tree, so local changes to an anchored block reach every alias
+	MaxDepth                 int  // maximum struct nesting walked for env overrides; 0 = 32
+	EnvJSON                  bool // if true, env values for any, map, slice and array fields are parsed as JSON (or YAML flow syntax)
+
+	EmptyEnvMeans  EmptyEnv // what a variable set to "" means: EmptyEnvEmpty (default) blanks the field, EmptyEnvUnset ignores it
+	LenientNumbers bool     // if true, env values for integer fields may use "_" separators, 0x/0o/0b prefixes and SI suffixes (10k, 2M, 512Ki)
+
+	Defaults   any         // optional: struct or map whose non-zero values form the lowest layer, reported as "defaults"
+	Precedence []LayerKind // optional: layer order from lowest to highest; default defaults, base, local, secrets, runtime, env
+
+	Schema   Validator   // optional: checks the merged document before it is bound into Target, e.g. a CUE adapter
+	Policies []Validator // optional: checks the effective config after all overrides, e.g. Rego rules; any violation fails the load
+
+	Metrics Metrics // optional: receives load durations and per-source fetch times and errors
+	Tracer  Tracer  // optional: receives spans for the fetch, parse, merge, env and validation phases of each load, e.g. an OpenTelemetry adapter
+	Logger  Logger  // optional: receives DebugKeys output and warnings; default standard output
+
+	WarnSecretsInYAML bool // if true, log a warning for each secret-looking value read from the base, local or tenant YAML files
+
+	Patches []ConfigSource // optional: JSON Merge Patches (a mapping) or JSON Patches (a list of operations) applied in order on top of the merged files, reported as "patch:<index>"
+
+	When map[string]any // optional: context for "when:" conditions; when set, mappings with a when key are kept only if it holds, and every document of a source is merged in order
+
+	Template      bool             // if true, sources are rendered with text/template before parsing, with env, file, default and other helpers
+	TemplateFuncs template.FuncMap // optional: extra template functions; they replace built-ins of the same name
+	TemplateData  any              // optional: value of "." in templates
+
+	FetchConcurrency int // maximum number of sources fetched at once; 0 = all at once, 1 = one after another
+
+	AllowPartial bool // if true, local, secrets, runtime and patch sources that fail to load are skipped and reported in LoadResult.Warnings; base, env and validation failures still fail the load
+
+	ctx context.Context // set by LoadContext; cancels waiting for sources
 }
 
-// FileSource creates a ConfigSource from a file path
+// defaultMaxDepth is the struct nesting limit used when MaxDepth is 0
+const defaultMaxDepth = 32
+
+// FileSource creates a ConfigSource from a file path. The path "-" means
+// standard input, as StdinSource.
 func FileSource(filename string) ConfigSource {
+	if filename == "-" {
+		return StdinSource()
+	}
 	return func() (io.ReadCloser, error) {
 		return os.Open(filename)
 	}
@@ -55,20 +117,98 @@ func ReaderSource(reader io.Reader) ConfigSource {
 	}
 }
 
-// loadYAMLFromSource loads YAML from a ConfigSource into the target struct
-func loadYAMLFromSource(source ConfigSource, target any) error {
+// mapFuncSource creates a ConfigSource that serializes the map returned by fn
+// at load time, so adapters always see the latest data
+func mapFuncSource(fn func() map[string]any) ConfigSource {
+	return func() (io.ReadCloser, error) {
+		data, err := yaml.Marshal(fn())
+		if err != nil {
+			return nil, fmt.Errorf("marshal map data: %w", err)
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+}
+
+// sizeLimitReader fails once more than limit bytes have been read from r
+type sizeLimitReader struct {
+	r         io.Reader
+	limit     int64
+	remaining int64
+}
+
+func (l *sizeLimitReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		// Probe for one more byte to tell "exactly at the limit" from "over it"
+		var probe [1]byte
+		n, err := l.r.Read(probe[:])
+		if n > 0 {
+			return 0, fmt.Errorf("config source exceeds maximum size of %d bytes", l.limit)
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+// loadNodeFromSource streams YAML from a ConfigSource into a node tree.
+// It returns a nil node for an empty document.
+func loadNodeFromSource(source ConfigSource, limits parseLimits) (*yaml.Node, error) {
+	docs, err := loadDocumentsFromSource(source, limits, false)
+	if err != nil || len(docs) == 0 {
+		return nil, err
+	}
+	return docs[0], nil
+}
+
+// loadDocumentsFromSource parses the documents of a source, or only the
+// first one unless all is set. Empty documents are skipped.
+func loadDocumentsFromSource(source ConfigSource, limits parseLimits, all bool) ([]*yaml.Node, error) {
 	reader, err := source()
 	if err != nil {
-		return fmt.Errorf("open config source: %w", err)
+		return nil, fmt.Errorf("open config source: %w", err)
 	}
 	defer reader.Close()
+	if err := prepareSource(reader, limits.maxSize); err != nil {
+		return nil, fmt.Errorf("open config source: %w", err)
+	}
 
-	data, err := io.ReadAll(reader)
-	if err != nil {
-		return fmt.Errorf("read config data: %w", err)
+	var r io.Reader = reader
+	if limits.maxSize > 0 {
+		r = &sizeLimitReader{r: reader, limit: limits.maxSize, remaining: limits.maxSize}
 	}
 
-	return yaml.Unmarshal(data, target)
+	var docs []*yaml.Node
+	dec := yaml.NewDecoder(r)
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if err == io.EOF {
+				return docs, nil
+			}
+			return nil, err
+		}
+		root := &doc
+		if doc.Kind == yaml.DocumentNode {
+			if len(doc.Content) == 0 {
+				continue
+			}
+			root = doc.Content[0]
+		}
+		if err := checkAliases(root); err != nil {
+			return nil, err
+		}
+		if err := limits.check(root); err != nil {
+			return nil, err
+		}
+		docs = append(docs, root)
+		if !all {
+			return docs, nil
+		}
+	}
 }
 
 // getStructPath builds a dot-separated path for a struct field
@@ -79,9 +219,8 @@ func getStructPath(field reflect.StructField, yamlTag string) string {
 	return strings.ToLower(field.Name)
 }
 
-// findEnvValue finds environment variables matching a struct path
-func findEnvValue(envPrefix, delimiter string, path string, normalizeDash bool) (string, bool) {
-	// Convert path back to env var format: app.name -> APP__NAME
+// envVarName converts a struct path to its env var name: app.name -> PREFIX_APP__NAME
+func envVarName(envPrefix, delimiter string, path string, normalizeDash bool) string {
 	envPath := strings.ToUpper(path)
 	if delimiter != "" {
 		envPath = strings.ReplaceAll(envPath, ".", delimiter)
@@ -90,10 +229,38 @@ func findEnvValue(envPrefix, delimiter string, path string, normalizeDash bool)
 		// Convert dashes back to underscores for env lookup
 		envPath = strings.ReplaceAll(envPath, "-", "_")
 	}
+	return envPrefix + envPath
+}
+
+// lookupEnvFunc looks up an environment variable by name
+type lookupEnvFunc func(name string) (string, bool)
 
-	envKey := envPrefix + envPath
-	value, exists := os.LookupEnv(envKey)
-	return value, exists
+// newLookupEnv returns os.LookupEnv, or with ignoreCase a lookup that
+// prefers an exact match and otherwise matches names case-insensitively
+// against a snapshot of the environment taken now
+func newLookupEnv(ignoreCase bool) lookupEnvFunc {
+	if !ignoreCase {
+		return os.LookupEnv
+	}
+	folded := map[string]string{}
+	for _, kv := range os.Environ() {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || name == "" {
+			// Windows keeps per-drive working directories in names like "=C:"
+			continue
+		}
+		key := strings.ToUpper(name)
+		if _, dup := folded[key]; !dup {
+			folded[key] = value
+		}
+	}
+	return func(name string) (string, bool) {
+		if value, exists := os.LookupEnv(name); exists {
+			return value, true
+		}
+		value, exists := folded[strings.ToUpper(name)]
+		return value, exists
+	}
 }
 
 // setFieldValue sets a struct field value from a string
@@ -102,6 +269,20 @@ func setFieldValue(field reflect.Value, value string) error {
 		return nil
 	}
 
+	if field.CanAddr() {
+		if u, ok := field.Addr().Interface().(envUnmarshaler); ok {
+			return u.unmarshalEnv(value)
+		}
+	}
+	if field.Type() == locationPtrType {
+		loc, err := loadLocation(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(loc))
+		return nil
+	}
+
 	switch field.Kind() {
 	case reflect.String:
 		field.SetString(value)
@@ -137,67 +318,470 @@ func setFieldValue(field reflect.Value, value string) error {
 			return fmt.Errorf("parse bool %q: %w", value, err)
 		}
 		field.SetBool(boolVal)
+	case reflect.Interface:
+		if field.NumMethod() > 0 {
+			return fmt.Errorf("unsupported field type %v", field.Type())
+		}
+		// any fields take the raw string; use EnvJSON for structured values
+		field.Set(reflect.ValueOf(value))
 	default:
 		return fmt.Errorf("unsupported field type %v", field.Type())
 	}
 	return nil
 }
 
-// applyEnvOverrides recursively applies environment variable overrides
-func applyEnvOverrides(val reflect.Value, envPrefix, delimiter string, normalizeDash bool, path string, debugKeys bool) error {
-	if val.Kind() == reflect.Ptr {
-		val = val.Elem()
+// isCompositeKind reports whether EnvJSON parses values of kind k as JSON
+func isCompositeKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Interface, reflect.Map, reflect.Slice, reflect.Array:
+		return true
 	}
+	return false
+}
 
-	if val.Kind() != reflect.Struct {
+// setJSONValue replaces field with a value parsed from JSON. JSON is read
+// as YAML, so integers stay integers in any fields and YAML flow syntax
+// like {a: 1} works too.
+func setJSONValue(field reflect.Value, value string) error {
+	if !field.CanSet() {
 		return nil
 	}
+	parsed := reflect.New(field.Type())
+	if err := yaml.Unmarshal([]byte(value), parsed.Interface()); err != nil {
+		return fmt.Errorf("parse JSON %q: %w", value, err)
+	}
+	field.Set(parsed.Elem())
+	return nil
+}
 
-	for i := 0; i < val.NumField(); i++ {
-		field := val.Field(i)
-		fieldType := val.Type().Field(i)
+// envBinder applies environment variable overrides to struct fields
+type envBinder struct {
+	prefix        string
+	delimiter     string
+	normalizeDash bool
+	debugKeys     bool
+	logger        Logger
+	tagCompat     bool
+	lookupEnv     lookupEnvFunc
+	translate     KeyTranslation
+	ignoreCase    bool
+	emptyUnset    bool
+	lenient       bool
+	maxDepth      int
+	json          bool
 
-		// Skip unexported fields
-		if !fieldType.IsExported() {
-			continue
-		}
+	applied  map[string]string // field path -> variable name of each override applied
+	escapes  map[string]string // path of a map entry whose key has the delimiter -> its variable name
+	envNames []string          // names in the environment, collected on first use
+}
 
-		// Get yaml tag or use field name
-		yamlTag := fieldType.Tag.Get("yaml")
-		if yamlTag == "-" {
-			continue
+func newEnvBinder(opts LoaderOptions) *envBinder {
+	maxDepth := opts.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = defaultMaxDepth
+	}
+	lookupEnv := newLookupEnv(opts.EnvIgnoreCase)
+	if opts.EmptyEnvMeans == EmptyEnvUnset {
+		lookupEnv = skipEmpty(lookupEnv)
+	}
+	return &envBinder{
+		prefix:        envPrefix(opts),
+		delimiter:     opts.Delimiter,
+		normalizeDash: opts.NormalizeDash,
+		debugKeys:     opts.DebugKeys,
+		logger:        loggerOrDefault(opts.Logger),
+		tagCompat:     opts.EnvTagCompat,
+		lookupEnv:     lookupEnv,
+		translate:     opts.KeyTranslation,
+		ignoreCase:    opts.EnvIgnoreCase,
+		emptyUnset:    opts.EmptyEnvMeans == EmptyEnvUnset,
+		lenient:       opts.LenientNumbers,
+		maxDepth:      maxDepth,
+		json:          opts.EnvJSON,
+		applied:       map[string]string{},
+		escapes:       map[string]string{},
+	}
+}
+
+// lookup finds the environment override for a field, returning the value and
+// the name of the variable that provided it
+func (b *envBinder) lookup(info fieldInfo, path string) (string, string, bool) {
+	if b.tagCompat {
+		// Legacy env-only tags take precedence over the derived name
+		if info.EnvTag != "" {
+			if value, exists := b.lookupEnv(info.EnvTag); exists {
+				return value, info.EnvTag, true
+			}
 		}
-		// Remove options like ",omitempty"
-		if idx := strings.Index(yamlTag, ","); idx >= 0 {
-			yamlTag = yamlTag[:idx]
+		if info.EnvconfigTag != "" {
+			name := b.prefix + info.EnvconfigTag
+			if value, exists := b.lookupEnv(name); exists {
+				return value, name, true
+			}
 		}
-		fieldPath := getStructPath(fieldType, yamlTag)
-		if path != "" {
+	}
+	name := b.varName(path)
+	value, exists := b.lookupEnv(name)
+	return value, name, exists
+}
+
+// apply recursively applies environment variable overrides
+func (b *envBinder) apply(val reflect.Value, path string) error {
+	return b.walk(val, path, 0, map[uintptr]bool{})
+}
+
+// walk applies overrides to the struct val points to. depth counts the
+// structs entered so far; visiting holds the pointers on the current path
+// so cyclic pointer graphs are walked only once.
+func (b *envBinder) walk(val reflect.Value, path string, depth int, visiting map[uintptr]bool) error {
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() || visiting[val.Pointer()] {
+			return nil
+		}
+		visiting[val.Pointer()] = true
+		defer delete(visiting, val.Pointer())
+		val = val.Elem()
+	}
+
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+	if depth >= b.maxDepth {
+		return fmt.Errorf("%s: struct nesting exceeds MaxDepth (%d)", path, b.maxDepth)
+	}
+
+	for _, info := range cachedFields(val.Type()) {
+		fieldPath := info.Name
+		if info.Inline {
+			fieldPath = path
+		} else if path != "" {
 			fieldPath = path + "." + fieldPath
 		}
+		if err := b.field(val.Field(info.Index), info, fieldPath, depth, visiting); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		if field.Kind() == reflect.Struct {
-			// Recursively handle nested structs
-			if err := applyEnvOverrides(field, envPrefix, delimiter, normalizeDash, fieldPath, debugKeys); err != nil {
+// field applies the override for a single field at fieldPath, walking into
+// nested structs
+func (b *envBinder) field(field reflect.Value, info fieldInfo, fieldPath string, depth int, visiting map[uintptr]bool) error {
+	switch {
+	case info.Nested:
+		if _, ok := field.Addr().Interface().(envComposite); ok {
+			// The variable for the whole value goes first, then its parts.
+			// It may embed secret parts, so errors redact it.
+			whole := info
+			whole.Secret = true
+			if err := b.value(field, whole, fieldPath); err != nil {
 				return err
 			}
-		} else {
-			// Check for environment variable override
-			if envValue, exists := findEnvValue(envPrefix, delimiter, fieldPath, normalizeDash); exists {
-				if debugKeys {
-					fmt.Printf("[yamlenv] applying env override: %s = %s\n", fieldPath, envValue)
-				}
-				if err := setFieldValue(field, envValue); err != nil {
-					return fmt.Errorf("set field %s: %w", fieldPath, err)
-				}
+		}
+		// Recursively handle nested structs
+		return b.walk(field, fieldPath, depth+1, visiting)
+	case info.NestedPtr:
+		// Allocate nil struct pointers only when a variable below them is set
+		if field.IsNil() {
+			if !b.hasVarsBelow(fieldPath) {
+				return nil
 			}
+			field.Set(reflect.New(field.Type().Elem()))
+			applyDefaults(field, nil)
+		}
+		return b.walk(field, fieldPath, depth+1, visiting)
+	}
+
+	if err := b.value(field, info, fieldPath); err != nil {
+		return err
+	}
+	switch field.Kind() {
+	case reflect.Slice:
+		// Variables naming an index reach into single elements
+		return b.elements(field, info, fieldPath, depth, visiting)
+	case reflect.Map:
+		// Variables naming a key reach into single entries
+		return b.entries(field, info, fieldPath, depth, visiting)
+	}
+	return nil
+}
+
+// logOverride logs an applied override when DebugKeys is set, with secret
+// values redacted as in LoadResult.EnvOverrides
+func (b *envBinder) logOverride(path, value, name string, secret bool) {
+	if !b.debugKeys {
+		return
+	}
+	if secret {
+		value = redacted
+	}
+	b.logger.Printf("[yamlenv] applying env override: %s = %s (from %s)", path, value, name)
+}
+
+// value sets field from the variable named for fieldPath, if it is set
+func (b *envBinder) value(field reflect.Value, info fieldInfo, fieldPath string) error {
+	// Check for environment variable override
+	envValue, envName, exists := b.lookup(info, fieldPath)
+	if !exists {
+		return nil
+	}
+	b.logOverride(fieldPath, envValue, envName, info.Secret)
+	set := setFieldValue
+	if b.json && isCompositeKind(field.Kind()) {
+		set = setJSONValue
+	} else if b.lenient {
+		set = setLenientValue
+	}
+	if info.WarnBadEnv {
+		// Parse into a copy, so a bad value leaves the field as it was
+		parsed := reflect.New(field.Type()).Elem()
+		parsed.Set(field)
+		if err := set(parsed, envValue); err != nil {
+			return b.badEnv(newEnvError(fieldPath, envName, envValue, field.Type(), info, err), info)
 		}
+		field.Set(parsed)
+	} else if err := set(field, envValue); err != nil {
+		return newEnvError(fieldPath, envName, envValue, field.Type(), info, err)
 	}
+	b.applied[fieldPath] = envName
 	return nil
 }
 
+// hasVarsBelow reports whether any environment variable is named like a
+// field below path
+func (b *envBinder) hasVarsBelow(path string) bool {
+	sep := b.delimiter
+	if sep == "" {
+		sep = "."
+	}
+	prefix := b.varName(path) + sep
+	for _, name := range b.environNames() {
+		if _, ok := b.cutVarPrefix(name, prefix); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// environNames returns the names of the variables in the environment,
+// collected on first use
+func (b *envBinder) environNames() []string {
+	if b.envNames == nil {
+		for _, kv := range os.Environ() {
+			if name, value, ok := strings.Cut(kv, "="); ok && (value != "" || !b.emptyUnset) {
+				b.envNames = append(b.envNames, name)
+			}
+		}
+	}
+	return b.envNames
+}
+
+// cutVarPrefix returns name without prefix, if it starts with it
+func (b *envBinder) cutVarPrefix(name, prefix string) (string, bool) {
+	if rest, ok := strings.CutPrefix(name, prefix); ok {
+		return rest, true
+	}
+	if b.ignoreCase && len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+		return name[len(prefix):], true
+	}
+	return "", false
+}
+
+// decodeLayers merges the layers and binds the result into opts.Target,
+// without environment overrides. It returns the merged document and the
+// layers as matched against the target's keys.
+func decodeLayers(opts LoaderOptions, layers []configLayer) (*yaml.Node, []configLayer, error) {
+	merged, layers, err := prepareLayers(opts, layers)
+	if err != nil {
+		return nil, nil, err
+	}
+	applyDefaults(reflect.ValueOf(opts.Target), lookupPath(merged, opts.Section))
+	if err := decodeMerged(newDecoder(opts), merged, layers, opts.Section, opts.Target); err != nil {
+		return nil, nil, err
+	}
+	return merged, layers, nil
+}
+
+// prepareLayers matches the layers' keys against the target, merges them
+// and checks the result against Schema
+func prepareLayers(opts LoaderOptions, layers []configLayer) (*yaml.Node, []configLayer, error) {
+	_, end := startSpan(opts, "yamlenv.merge")
+	match := newKeyMatcher(opts)
+	if match == nil && hasAliases(reflect.TypeOf(opts.Target), map[reflect.Type]bool{}) {
+		match = exactMatch
+	}
+	if match != nil {
+		var warnings []string
+		layers, warnings = canonicalLayers(layers, opts.Section, reflect.TypeOf(opts.Target), match)
+		logger := loggerOrDefault(opts.Logger)
+		for _, warning := range warnings {
+			logger.Printf("[yamlenv] warning: %s", warning)
+		}
+	}
+
+	merged := newMerger(opts).mergeLayers(layers)
+	end(nil)
+
+	_, end = startSpan(opts, "yamlenv.schema")
+	err := validateSchema(opts, merged)
+	end(err)
+	if err != nil {
+		return nil, nil, err
+	}
+	return merged, layers, nil
+}
+
+// loadLayers parses the defaults, base and optional sources into layers, in
+// merge order. The sources are fetched concurrently, see fetchAll.
+func loadLayers(opts LoaderOptions) ([]configLayer, error) {
+	layers, _, err := loadLayersPartial(opts)
+	return layers, err
+}
+
+// loadLayersPartial is loadLayers that also returns the failures of the
+// optional layers AllowPartial skipped
+func loadLayersPartial(opts LoaderOptions) ([]configLayer, []error, error) {
+	jobs := []fetchJob{{layer: "base", source: opts.BaseSource}}
+	if opts.LocalSource != nil {
+		jobs = append(jobs, fetchJob{layer: "local", source: opts.LocalSource})
+	}
+	if opts.SecretsSource != nil {
+		jobs = append(jobs, fetchJob{layer: "secrets", source: opts.SecretsSource})
+	}
+	if opts.RuntimeSource != nil {
+		jobs = append(jobs, fetchJob{layer: "runtime", source: opts.RuntimeSource})
+	}
+	for i, source := range opts.Patches {
+		jobs = append(jobs, fetchJob{layer: fmt.Sprintf("patch:%d", i), source: source})
+	}
+	if err := fetchAll(opts, jobs); err != nil {
+		return nil, nil, err
+	}
+	fetched := map[string]*fetchJob{}
+	for i := range jobs {
+		fetched[jobs[i].layer] = &jobs[i]
+	}
+
+	base := fetched["base"]
+	if base.err != nil {
+		return nil, nil, base.configLayer(nil).loadError(base.err)
+	}
+	var layers []configLayer
+	if opts.Defaults != nil {
+		defaults, err := nonZeroNode(opts.Defaults)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load defaults: %w", err)
+		}
+		layers = append(layers, configLayer{name: "defaults", node: defaults})
+	}
+	layers = append(layers, base.configLayer(base.node))
+	partial := &partialLoad{opts: opts}
+	for i := range jobs {
+		if jobs[i].err == nil && jobs[i].warning != nil {
+			partial.warn(jobs[i].configLayer(nil).loadError(jobs[i].warning))
+		}
+	}
+
+	if local := fetched["local"]; local != nil {
+		switch {
+		case errors.Is(local.err, fs.ErrNotExist) && !opts.LocalRequired:
+			// The local override is optional unless LocalRequired is set
+		case local.err != nil:
+			err := local.configLayer(nil).loadError(local.err)
+			if opts.LocalRequired || !partial.skip(err) {
+				return nil, nil, err
+			}
+		default:
+			layers = append(layers, local.configLayer(local.node))
+		}
+	}
+
+	if secrets := fetched["secrets"]; secrets != nil {
+		if err := secrets.err; err == nil {
+			layers = append(layers, secrets.configLayer(secrets.node))
+		} else if err := secrets.configLayer(nil).loadError(err); !partial.skip(err) {
+			return nil, nil, err
+		}
+	}
+
+	if runtime := fetched["runtime"]; runtime != nil {
+		if err := runtime.err; err == nil {
+			layers = append(layers, runtime.configLayer(nestUnder(runtimeKey, runtime.node)))
+		} else if err := runtime.configLayer(nil).loadError(err); !partial.skip(err) {
+			return nil, nil, err
+		}
+	}
+	layers, err := loadPatchLayers(opts, orderLayers(opts, layers), jobs[len(jobs)-len(opts.Patches):], partial)
+	if err != nil {
+		return nil, nil, err
+	}
+	return layers, partial.warnings, nil
+}
+
+// LoadResult reports details about a completed load
+type LoadResult struct {
+	UnusedKeys []string          // keys in the merged YAML that didn't map to any field of Target, sorted
+	Sources    map[string]string // leaf path -> where its value came from: "defaults", "base", "local" or "env:VAR"
+	Labels     map[string]string // layer name -> label of its Named source, for layers that have one
+
+	EnvOverrides []EnvOverride // environment variables that set a value of the config, sorted by path, with secret values redacted
+	Warnings     []error       // stale copies served by Cached sources, then failures of optional layers skipped because of AllowPartial, in layer order
+}
+
 // LoadConfig loads YAML + optional override + ENV into Target struct.
+// Loads only read the environment and keep no shared state, so LoadConfig
+// may be called from several goroutines at once as long as each has its
+// own Target.
 func LoadConfig(opts LoaderOptions) error {
+	_, err := Load(opts)
+	return err
+}
+
+// LoadContext is Load that stops waiting for sources when ctx is done and
+// returns ctx's error. A ConfigSource can't be interrupted, so fetches
+// already running finish in the background.
+func LoadContext(ctx context.Context, opts LoaderOptions) (*LoadResult, error) {
+	opts.ctx = ctx
+	return Load(opts)
+}
+
+// Load is LoadConfig that also returns a LoadResult describing the load
+func Load(opts LoaderOptions) (*LoadResult, error) {
+	start := time.Now()
+	ctx, end := startSpan(opts, "yamlenv.load")
+	opts.ctx = ctx
+	result, err := load(opts)
+	end(err)
+	if opts.Metrics != nil {
+		opts.Metrics.Loaded(time.Since(start), err)
+	}
+	return result, err
+}
+
+// load performs a complete load for Load
+func load(opts LoaderOptions) (*LoadResult, error) {
+	if err := validateOptions(opts); err != nil {
+		return nil, err
+	}
+
+	// 1) Load base YAML and 2) optional local YAML
+	layers, warnings, err := loadLayersPartial(opts)
+	if err != nil {
+		return nil, err
+	}
+	result, err := bindLayers(opts, layers)
+	if err != nil {
+		return nil, err
+	}
+	result.Warnings = warnings
+	if err := checkConfig(opts, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// validateOptions checks the options shared by every load
+func validateOptions(opts LoaderOptions) error {
 	// Validate that delimiter is not empty when EnvPrefix is provided
 	if opts.EnvPrefix != "" && opts.Delimiter == "" {
 		return fmt.Errorf("delimiter cannot be empty when EnvPrefix is provided - use a non-empty delimiter like '__' for proper environment variable mapping")
@@ -219,23 +803,68 @@ func LoadConfig(opts LoaderOptions) error {
 	if opts.BaseSource == nil {
 		return fmt.Errorf("BaseSource cannot be nil")
 	}
+	if err := validatePrecedence(opts); err != nil {
+		return err
+	}
+	return nil
+}
 
-	// 1) Load base YAML
-	if err := loadYAMLFromSource(opts.BaseSource, opts.Target); err != nil {
-		return fmt.Errorf("load base config: %w", err)
+// bindLayers merges parsed layers, binds the result into opts.Target and
+// applies environment overrides. The layers are not modified, so callers
+// may reuse them.
+//
+// Layers that Precedence ranks above the environment are decoded again
+// after the overrides, limited to the keys they set, so env values are
+// parsed the same way wherever the environment sits.
+func bindLayers(opts LoaderOptions, layers []configLayer) (*LoadResult, error) {
+	targetValue := reflect.ValueOf(opts.Target)
+	merged, layers, err := prepareLayers(opts, layers)
+	if err != nil {
+		return nil, err
 	}
+	applyDefaults(targetValue, lookupPath(merged, opts.Section))
 
-	// 2) Load optional local YAML (merges with base)
-	if opts.LocalSource != nil {
-		if err := loadYAMLFromSource(opts.LocalSource, opts.Target); err != nil {
-			return fmt.Errorf("load local config: %w", err)
-		}
+	split := envPosition(opts, layers)
+	below := merged
+	if split < len(layers) {
+		below = newMerger(opts).mergeLayers(layers[:split])
+	}
+	if err := decodeMerged(newDecoder(opts), below, layers[:split], opts.Section, opts.Target); err != nil {
+		return nil, err
 	}
 
 	// 3) Apply environment variable overrides
-	if err := applyEnvOverrides(targetValue, opts.EnvPrefix, opts.Delimiter, opts.NormalizeDash, "", opts.DebugKeys); err != nil {
-		return fmt.Errorf("apply env overrides: %w", err)
+	// (env names keep the full path, so a "db" section still reads MYAPP_DB__HOST)
+	binder := newEnvBinder(opts)
+	if envEnabled(opts) {
+		_, end := startSpan(opts, "yamlenv.env")
+		err := binder.bind(opts.Target, opts.Section)
+		end(err)
+		if err != nil {
+			return nil, fmt.Errorf("apply env overrides: %w", err)
+		}
+		binder.warnNearMisses(opts.Target, opts.Section)
 	}
 
-	return nil
-}
\ No newline at end of file
+	result := &LoadResult{
+		UnusedKeys: unusedKeys(lookupPath(merged, opts.Section), targetValue.Elem().Type(), opts.Section),
+		Sources:    layerSources(layers, opts.Section),
+		Labels:     layerLabels(layers),
+	}
+	for path, envName := range binder.applied {
+		result.Sources[path] = "env:" + envName
+	}
+
+	if split < len(layers) {
+		above := layerSources(layers[split:], opts.Section)
+		if err := decodeMerged(newDecoder(opts), pruneNode(merged, "", above), layers[split:], opts.Section, opts.Target); err != nil {
+			return nil, err
+		}
+		for path, layer := range above {
+			result.Sources[path] = layer
+		}
+	}
+	result.EnvOverrides = envOverrides(binder, result.Sources, opts.Target, opts.Section)
+	warnSecretsInYAML(opts, result)
+	return result, nil
+}
@@ -1,7 +1,9 @@
 package yamlenv
 
 import (
+	"io/fs"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -217,6 +219,41 @@ app:
 	assert.Equal(t, 8080, cfg.App.Port)
 }
 
+// Test that a LocalSource that doesn't exist is skipped by default
+func TestLoadConfig_LocalSourceNotExist(t *testing.T) {
+	baseFile := createTempYAML(t, "app:\n  name: testapp\n")
+
+	var cfg TestConfig
+	result, err := Load(LoaderOptions{
+		BaseSource:  FileSource(baseFile),
+		LocalSource: FileSource(filepath.Join(t.TempDir(), "config.local.yaml")),
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "testapp", cfg.App.Name)
+	assert.Equal(t, "base", result.Sources["app.name"])
+}
+
+// Test that LocalRequired turns a missing local file into an error
+func TestLoadConfig_LocalRequired(t *testing.T) {
+	baseFile := createTempYAML(t, "app:\n  name: testapp\n")
+	localFile := filepath.Join(t.TempDir(), "config.locl.yaml")
+
+	var cfg TestConfig
+	err := LoadConfig(LoaderOptions{
+		BaseSource:    FileSource(baseFile),
+		LocalSource:   FileSource(localFile),
+		LocalRequired: true,
+		Target:        &cfg,
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "load local config")
+	assert.Contains(t, err.Error(), "config.locl.yaml")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
 // Test nil target error
 func TestLoadConfig_NilTarget(t *testing.T) {
 	baseYAML := `
@@ -0,0 +1,89 @@
+// Package yamlenvcue validates yamlenv configurations against CUE schemas.
+// It is a separate module so that yamlenv itself doesn't depend on CUE.
+//
+// Schema compiles a CUE source into a yamlenv.Validator. The merged
+// configuration is unified with the schema before it is bound into Target,
+// and must then be concrete: a field the schema requires but no layer sets
+// is reported as an error, as is any value outside its constraints.
+//
+//	//go:embed schema.cue
+//	var schemaCUE []byte
+//
+//	err := yamlenv.LoadConfig(yamlenv.LoaderOptions{
+//		BaseSource: yamlenv.FileSource("config.yaml"),
+//		Schema:     yamlenvcue.MustSchema(schemaCUE),
+//		Target:     &cfg,
+//	})
+package yamlenvcue
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	cueerrors "cuelang.org/go/cue/errors"
+	"github.com/tendant/yamlenv/pkg/yamlenv"
+)
+
+// filename names the schema in error messages
+const filename = "schema.cue"
+
+// validator checks documents against a compiled schema. A cue.Context is
+// not safe for concurrent use, so validations are serialized.
+type validator struct {
+	mu     sync.Mutex
+	ctx    *cue.Context
+	schema cue.Value
+}
+
+// Schema compiles a CUE schema into a yamlenv.Validator for
+// LoaderOptions.Schema
+func Schema(src []byte) (yamlenv.Validator, error) {
+	ctx := cuecontext.New()
+	schema := ctx.CompileBytes(src, cue.Filename(filename))
+	if err := schema.Err(); err != nil {
+		return nil, fmt.Errorf("compile CUE schema: %w", &Error{err: err})
+	}
+	return &validator{ctx: ctx, schema: schema}, nil
+}
+
+// MustSchema is like Schema but panics if the schema doesn't compile. It
+// is meant for schemas embedded in the program.
+func MustSchema(src []byte) yamlenv.Validator {
+	v, err := Schema(src)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Validate unifies doc with the schema and checks that the result is
+// concrete
+func (v *validator) Validate(doc map[string]any) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	value := v.schema.Unify(v.ctx.Encode(doc))
+	if err := value.Validate(cue.Concrete(true)); err != nil {
+		return &Error{err: err}
+	}
+	return nil
+}
+
+// Error is a CUE evaluation error. Its message lists every violated
+// constraint with its position in the schema; errors.As reaches the
+// underlying cue/errors.Error.
+type Error struct {
+	err error
+}
+
+// Error returns the details of all CUE errors, one per line
+func (e *Error) Error() string {
+	return strings.TrimSpace(cueerrors.Details(e.err, nil))
+}
+
+// Unwrap returns the CUE error
+func (e *Error) Unwrap() error {
+	return e.err
+}
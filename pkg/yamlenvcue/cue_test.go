@@ -0,0 +1,105 @@
+package yamlenvcue
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	cueerrors "cuelang.org/go/cue/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendant/yamlenv/pkg/yamlenv"
+)
+
+const testSchema = `
+app: {
+	name: string
+	port: int & >0 & <65536
+	env:  *"dev" | "staging" | "prod"
+}
+db?: host: string
+`
+
+type testConfig struct {
+	App struct {
+		Name string `yaml:"name"`
+		Port int    `yaml:"port"`
+		Env  string `yaml:"env"`
+	} `yaml:"app"`
+}
+
+// Test that documents within the schema pass and others report every
+// violated constraint
+func TestSchema_Validate(t *testing.T) {
+	schema, err := Schema([]byte(testSchema))
+	require.NoError(t, err)
+
+	assert.NoError(t, schema.Validate(map[string]any{
+		"app": map[string]any{"name": "svc", "port": 8080, "env": "prod"},
+	}))
+
+	err = schema.Validate(map[string]any{
+		"app": map[string]any{"name": "svc", "port": 0, "env": "qa"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "app.port: invalid value 0 (out of bound >0)")
+	assert.Contains(t, err.Error(), "app.env")
+	assert.Contains(t, err.Error(), "schema.cue:")
+
+	var cueErr cueerrors.Error
+	assert.True(t, errors.As(err, &cueErr))
+
+	err = schema.Validate(map[string]any{"app": map[string]any{"port": 8080}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "app.name: incomplete value string")
+}
+
+// Test that schemas which don't compile are rejected
+func TestSchema_CompileError(t *testing.T) {
+	_, err := Schema([]byte("app: {\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "compile CUE schema")
+
+	assert.Panics(t, func() { MustSchema([]byte("app: {\n")) })
+}
+
+// Test that LoadConfig validates the merged layers, defaults included,
+// before binding
+func TestSchema_LoadConfig(t *testing.T) {
+	var cfg testConfig
+	err := yamlenv.LoadConfig(yamlenv.LoaderOptions{
+		BaseSource:  yamlenv.StringSource("app:\n  name: svc\n  port: 80\n"),
+		LocalSource: yamlenv.StringSource("app:\n  port: 8080\n  env: dev\n"),
+		Schema:      MustSchema([]byte(testSchema)),
+		Target:      &cfg,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 8080, cfg.App.Port)
+
+	cfg = testConfig{}
+	err = yamlenv.LoadConfig(yamlenv.LoaderOptions{
+		BaseSource:  yamlenv.StringSource("app:\n  name: svc\n  port: 80\n  env: dev\n"),
+		LocalSource: yamlenv.StringSource("app:\n  port: 70000\n"),
+		Schema:      MustSchema([]byte(testSchema)),
+		Target:      &cfg,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "schema validation: app.port: invalid value 70000 (out of bound <65536)")
+	assert.Zero(t, cfg.App.Port)
+}
+
+// Test that one validator can be shared by concurrent loads
+func TestSchema_Concurrent(t *testing.T) {
+	schema := MustSchema([]byte(testSchema))
+	var wg sync.WaitGroup
+	for i := 1; i <= 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, schema.Validate(map[string]any{
+				"app": map[string]any{"name": "svc", "port": i, "env": "dev"},
+			}))
+		}()
+	}
+	wg.Wait()
+}
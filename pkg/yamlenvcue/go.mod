@@ -0,0 +1,11 @@
+module github.com/tendant/yamlenv/pkg/yamlenvcue
+
+go 1.24.2
+
+require (
+	cuelang.org/go v0.11.0
+	github.com/stretchr/testify v1.11.1
+	github.com/tendant/yamlenv v0.0.0
+)
+
+replace github.com/tendant/yamlenv => ../..
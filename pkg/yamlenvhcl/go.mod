@@ -0,0 +1,12 @@
+module github.com/tendant/yamlenv/pkg/yamlenvhcl
+
+go 1.24.2
+
+require (
+	github.com/hashicorp/hcl/v2 v2.23.0
+	github.com/stretchr/testify v1.11.1
+	github.com/tendant/yamlenv v0.0.0
+	github.com/zclconf/go-cty v1.13.0
+)
+
+replace github.com/tendant/yamlenv => ../..
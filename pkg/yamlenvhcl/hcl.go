@@ -0,0 +1,191 @@
+// Package yamlenvhcl reads HCL2 (github.com/hashicorp/hcl/v2) configuration
+// files for yamlenv. It is a separate module so that yamlenv itself doesn't
+// depend on HCL.
+//
+// Attributes become keys and objects nest as usual, so db = { host = "x" }
+// sets db.host. Blocks nest by type and then by label: a block db "primary"
+// { host = "x" } sets db.primary.host, and repeated unlabeled blocks of the
+// same type become a list. Expressions are evaluated without variables or
+// functions; an expression that needs them is reported as an error.
+//
+//	err := yamlenv.LoadConfig(yamlenv.LoaderOptions{
+//		BaseSource: yamlenvhcl.Source(yamlenv.FileSource("config.hcl")),
+//		EnvPrefix:  "APP_",
+//		Delimiter:  "__",
+//		Target:     &cfg,
+//	})
+package yamlenvhcl
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/tendant/yamlenv/pkg/yamlenv"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// filename names the document in error messages
+const filename = "config.hcl"
+
+// Source adapts a source of HCL2 data to a yamlenv.ConfigSource
+func Source(source yamlenv.ConfigSource) yamlenv.ConfigSource {
+	return yamlenv.FormatSource(source, Unmarshal)
+}
+
+// Unmarshal decodes an HCL2 document into v, which must be a
+// *map[string]any. It is a yamlenv.UnmarshalFunc.
+func Unmarshal(data []byte, v any) error {
+	out, ok := v.(*map[string]any)
+	if !ok {
+		return fmt.Errorf("yamlenvhcl: cannot decode into %T, want *map[string]any", v)
+	}
+	file, diags := hclsyntax.ParseConfig(data, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return diags
+	}
+	values, err := decodeBody(file.Body.(*hclsyntax.Body))
+	if err != nil {
+		return err
+	}
+	if *out == nil {
+		*out = map[string]any{}
+	}
+	for key, value := range values {
+		(*out)[key] = value
+	}
+	return nil
+}
+
+// decodeBody converts the attributes and blocks of a body to a map
+func decodeBody(body *hclsyntax.Body) (map[string]any, error) {
+	out := map[string]any{}
+	names := make([]string, 0, len(body.Attributes))
+	for name := range body.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		attr := body.Attributes[name]
+		value, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		decoded, err := goValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", attr.SrcRange, name, err)
+		}
+		out[name] = decoded
+	}
+
+	// Unlabeled blocks of a type that repeats become a list
+	unlabeled := map[string]int{}
+	for _, block := range body.Blocks {
+		if len(block.Labels) == 0 {
+			unlabeled[block.Type]++
+		}
+	}
+	for _, block := range body.Blocks {
+		inner, err := decodeBody(block.Body)
+		if err != nil {
+			return nil, err
+		}
+		if len(block.Labels) == 0 && unlabeled[block.Type] > 1 {
+			list, ok := out[block.Type].([]any)
+			if _, exists := out[block.Type]; exists && !ok {
+				return nil, fmt.Errorf("%s: %s is defined more than once", block.DefRange(), block.Type)
+			}
+			out[block.Type] = append(list, inner)
+			continue
+		}
+		if err := setBlock(out, append([]string{block.Type}, block.Labels...), inner); err != nil {
+			return nil, fmt.Errorf("%s: %w", block.DefRange(), err)
+		}
+	}
+	return out, nil
+}
+
+// setBlock stores a block's body at the path of its type and labels
+func setBlock(out map[string]any, path []string, inner map[string]any) error {
+	for i, key := range path[:len(path)-1] {
+		existing, exists := out[key]
+		if !exists {
+			next := map[string]any{}
+			out[key] = next
+			out = next
+			continue
+		}
+		next, ok := existing.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s is defined more than once", joinKeys(path[:i+1]))
+		}
+		out = next
+	}
+	key := path[len(path)-1]
+	if _, exists := out[key]; exists {
+		return fmt.Errorf("%s is defined more than once", joinKeys(path))
+	}
+	out[key] = inner
+	return nil
+}
+
+// joinKeys formats a block path like a yamlenv key
+func joinKeys(path []string) string {
+	s := path[0]
+	for _, key := range path[1:] {
+		s += "." + key
+	}
+	return s
+}
+
+// goValue converts an evaluated value to the plain Go values yamlenv
+// re-encodes as YAML: whole numbers become int64 and other numbers float64
+func goValue(v cty.Value) (any, error) {
+	if v.IsNull() {
+		return nil, nil
+	}
+	if !v.IsWhollyKnown() {
+		return nil, fmt.Errorf("value is not known without variables")
+	}
+	t := v.Type()
+	switch {
+	case t == cty.String:
+		return v.AsString(), nil
+	case t == cty.Bool:
+		return v.True(), nil
+	case t == cty.Number:
+		f := v.AsBigFloat()
+		if f.IsInt() {
+			if i, acc := f.Int64(); acc == big.Exact {
+				return i, nil
+			}
+		}
+		out, _ := f.Float64()
+		return out, nil
+	case t.IsListType(), t.IsSetType(), t.IsTupleType():
+		out := []any{}
+		for it := v.ElementIterator(); it.Next(); {
+			_, elem := it.Element()
+			decoded, err := goValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, decoded)
+		}
+		return out, nil
+	case t.IsMapType(), t.IsObjectType():
+		out := map[string]any{}
+		for it := v.ElementIterator(); it.Next(); {
+			key, elem := it.Element()
+			decoded, err := goValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			out[key.AsString()] = decoded
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unsupported value of type %s", t.FriendlyName())
+}
@@ -0,0 +1,144 @@
+package yamlenvhcl
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendant/yamlenv/pkg/yamlenv"
+)
+
+type testConfig struct {
+	App struct {
+		Name  string   `yaml:"name"`
+		Port  int      `yaml:"port"`
+		Debug bool     `yaml:"debug"`
+		Ratio float64  `yaml:"ratio"`
+		Tags  []string `yaml:"tags"`
+	} `yaml:"app"`
+	DB struct {
+		Primary struct {
+			Host string `yaml:"host"`
+		} `yaml:"primary"`
+		Options map[string]string `yaml:"options"`
+	} `yaml:"db"`
+	Listeners []struct {
+		Port int `yaml:"port"`
+	} `yaml:"listeners"`
+}
+
+const testHCL = `
+app = {
+  name  = "svc"
+  port  = 8000 + 80
+  debug = true
+  ratio = 0.5
+  tags  = ["a", "b"]
+}
+
+db "primary" {
+  host = "db.internal"
+}
+
+db {
+  options = { sslmode = "disable" }
+}
+
+listeners {
+  port = 80
+}
+
+listeners {
+  port = 443
+}
+`
+
+// Test that attributes, objects and blocks decode to nested keys
+func TestUnmarshal(t *testing.T) {
+	values := map[string]any{}
+	require.NoError(t, Unmarshal([]byte(testHCL), &values))
+
+	assert.Equal(t, map[string]any{
+		"app": map[string]any{
+			"name":  "svc",
+			"port":  int64(8080),
+			"debug": true,
+			"ratio": 0.5,
+			"tags":  []any{"a", "b"},
+		},
+		"db": map[string]any{
+			"primary": map[string]any{"host": "db.internal"},
+			"options": map[string]any{"sslmode": "disable"},
+		},
+		"listeners": []any{
+			map[string]any{"port": int64(80)},
+			map[string]any{"port": int64(443)},
+		},
+	}, values)
+}
+
+// Test that documents needing variables, redefining blocks or with syntax
+// errors are rejected
+func TestUnmarshal_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		hcl  string
+		want string
+	}{
+		{"variable", "port = var.port\n", "Variables not allowed"},
+		{"function", "name = upper(\"x\")\n", "Function calls not allowed"},
+		{"duplicate block", "db \"a\" {\n}\ndb \"a\" {\n}\n", "db.a is defined more than once"},
+		{"block over attribute", "db = 1\ndb \"a\" {\n}\n", "db is defined more than once"},
+		{"syntax", "port = \n", "config.hcl:1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := map[string]any{}
+			err := Unmarshal([]byte(tt.hcl), &values)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+
+	assert.ErrorContains(t, Unmarshal([]byte("a = 1\n"), map[string]any{}), "want *map[string]any")
+}
+
+// Test loading an HCL2 source with an environment override on top
+func TestSource_LoadConfig(t *testing.T) {
+	t.Setenv("HCLTEST_DB__PRIMARY__HOST", "db.override")
+
+	var cfg testConfig
+	err := yamlenv.LoadConfig(yamlenv.LoaderOptions{
+		BaseSource: Source(yamlenv.StringSource(testHCL)),
+		EnvPrefix:  "HCLTEST_",
+		Delimiter:  "__",
+		Target:     &cfg,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "svc", cfg.App.Name)
+	assert.Equal(t, 8080, cfg.App.Port)
+	assert.True(t, cfg.App.Debug)
+	assert.Equal(t, 0.5, cfg.App.Ratio)
+	assert.Equal(t, []string{"a", "b"}, cfg.App.Tags)
+	assert.Equal(t, "db.override", cfg.DB.Primary.Host)
+	assert.Equal(t, map[string]string{"sslmode": "disable"}, cfg.DB.Options)
+	require.Len(t, cfg.Listeners, 2)
+	assert.Equal(t, 443, cfg.Listeners[1].Port)
+}
+
+// Test that MaxSourceSize applies to the HCL input
+func TestSource_MaxSourceSize(t *testing.T) {
+	path := t.TempDir() + "/config.hcl"
+	require.NoError(t, os.WriteFile(path, []byte(testHCL), 0o644))
+
+	var cfg testConfig
+	err := yamlenv.LoadConfig(yamlenv.LoaderOptions{
+		BaseSource:    Source(yamlenv.FileSource(path)),
+		MaxSourceSize: 16,
+		Target:        &cfg,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds maximum size of 16 bytes")
+}
@@ -0,0 +1,213 @@
+// Package yamlenvk8s provides yamlenv config sources that read ConfigMaps
+// and Secrets straight from the Kubernetes API, and a watcher that reloads a
+// yamlenv.Loader as soon as they change, without waiting for volume
+// propagation. WriteManifests scaffolds a ConfigMap and Secret from a
+// config struct.
+//
+// It talks to the API server over plain HTTP(S) with a bearer token and does
+// not depend on client-go.
+package yamlenvk8s
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/tendant/yamlenv/pkg/yamlenv"
+)
+
+// Service account files mounted into every pod
+const (
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	tokenFile         = serviceAccountDir + "/token"
+	caFile            = serviceAccountDir + "/ca.crt"
+)
+
+// Kind selects the type of object a Ref points to
+type Kind int
+
+const (
+	ConfigMap Kind = iota
+	Secret
+)
+
+// String returns the kind name
+func (k Kind) String() string {
+	switch k {
+	case ConfigMap:
+		return "configmap"
+	case Secret:
+		return "secret"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// resource returns the API resource name for the kind
+func (k Kind) resource() string {
+	if k == Secret {
+		return "secrets"
+	}
+	return "configmaps"
+}
+
+// Ref identifies one key of a ConfigMap or Secret
+type Ref struct {
+	Kind      Kind
+	Namespace string
+	Name      string
+	Key       string // data key holding the YAML document, e.g. "config.yaml"
+}
+
+// String formats the ref as "kind namespace/name[key]"
+func (r Ref) String() string {
+	return fmt.Sprintf("%s %s/%s[%s]", r.Kind, r.Namespace, r.Name, r.Key)
+}
+
+// Client is a minimal Kubernetes API client
+type Client struct {
+	Host       string       // API server URL, e.g. "https://10.96.0.1:443"
+	Token      string       // bearer token; ignored if TokenFile is set
+	TokenFile  string       // file re-read on every request, for rotated service account tokens
+	HTTPClient *http.Client // defaults to http.DefaultClient
+}
+
+// InCluster returns a Client for the API server of the cluster the process
+// runs in, authenticated with the pod's service account
+func InCluster() (*Client, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("not running in a cluster: KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT must be set")
+	}
+	ca, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read service account CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("parse service account CA %s", caFile)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+
+	return &Client{
+		Host:       "https://" + net.JoinHostPort(host, port),
+		TokenFile:  tokenFile,
+		HTTPClient: &http.Client{Transport: transport},
+	}, nil
+}
+
+// Source returns a ConfigSource that reads the YAML document stored under
+// ref.Key. Secret values are base64-decoded.
+func (c *Client) Source(ref Ref) yamlenv.ConfigSource {
+	return func() (io.ReadCloser, error) {
+		obj, err := c.get(context.Background(), ref)
+		if err != nil {
+			return nil, err
+		}
+		data, err := obj.value(ref)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+}
+
+// object is the subset of a ConfigMap or Secret that yamlenvk8s reads
+type object struct {
+	Metadata struct {
+		ResourceVersion string `json:"resourceVersion"`
+	} `json:"metadata"`
+	Data map[string]string `json:"data"`
+}
+
+// value returns the data stored under ref.Key
+func (o *object) value(ref Ref) ([]byte, error) {
+	value, ok := o.Data[ref.Key]
+	if !ok {
+		return nil, fmt.Errorf("%s: key not found", ref)
+	}
+	if ref.Kind != Secret {
+		return []byte(value), nil
+	}
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: decode secret value: %w", ref, err)
+	}
+	return data, nil
+}
+
+// get fetches the object ref points to
+func (c *Client) get(ctx context.Context, ref Ref) (*object, error) {
+	resp, err := c.do(ctx, objectPath(ref, false), nil)
+	if err != nil {
+		return nil, fmt.Errorf("get %s: %w", ref, err)
+	}
+	defer resp.Body.Close()
+
+	var obj object
+	if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil {
+		return nil, fmt.Errorf("get %s: decode response: %w", ref, err)
+	}
+	return &obj, nil
+}
+
+// do sends a GET request and fails on non-2xx responses
+func (c *Client) do(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	u := strings.TrimSuffix(c.Host, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	token := c.Token
+	if c.TokenFile != "" {
+		data, err := os.ReadFile(c.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("read token: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// objectPath returns the API path of the object, or of its collection when
+// collection is true
+func objectPath(ref Ref, collection bool) string {
+	path := "/api/v1/namespaces/" + url.PathEscape(ref.Namespace) + "/" + ref.Kind.resource()
+	if !collection {
+		path += "/" + url.PathEscape(ref.Name)
+	}
+	return path
+}
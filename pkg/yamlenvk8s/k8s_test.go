@@ -0,0 +1,172 @@
+package yamlenvk8s
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendant/yamlenv/pkg/yamlenv"
+)
+
+type AppConfig struct {
+	App struct {
+		Name string `yaml:"name"`
+		Port int    `yaml:"port"`
+	} `yaml:"app"`
+	DB struct {
+		Password string `yaml:"password"`
+	} `yaml:"db"`
+}
+
+// fakeAPI serves one ConfigMap and one Secret and streams watch events
+type fakeAPI struct {
+	mu      sync.Mutex
+	version int
+	data    map[string]map[string]string // resource -> data
+	events  chan string                  // resource of each change
+}
+
+func newFakeAPI() *fakeAPI {
+	return &fakeAPI{
+		version: 1,
+		data: map[string]map[string]string{
+			"configmaps": {"config.yaml": "app:\n  name: svc\n  port: 8080\n"},
+			"secrets":    {"secret.yaml": base64.StdEncoding.EncodeToString([]byte("db:\n  password: hunter2\n"))},
+		},
+		events: make(chan string, 10),
+	}
+}
+
+func (f *fakeAPI) object(resource string) map[string]any {
+	return map[string]any{
+		"metadata": map[string]any{"resourceVersion": fmt.Sprint(f.version)},
+		"data":     f.data[resource],
+	}
+}
+
+func (f *fakeAPI) update(resource, key, value string) {
+	f.mu.Lock()
+	f.version++
+	f.data[resource][key] = value
+	f.mu.Unlock()
+	f.events <- resource
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer test-token" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	switch r.URL.Path {
+	case "/api/v1/namespaces/prod/configmaps/app", "/api/v1/namespaces/prod/secrets/app":
+		resource := "configmaps"
+		if r.URL.Path == "/api/v1/namespaces/prod/secrets/app" {
+			resource = "secrets"
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		json.NewEncoder(w).Encode(f.object(resource))
+	case "/api/v1/namespaces/prod/configmaps":
+		if r.URL.Query().Get("watch") != "true" || r.URL.Query().Get("fieldSelector") != "metadata.name=app" {
+			http.Error(w, "bad watch", http.StatusBadRequest)
+			return
+		}
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case resource := <-f.events:
+				f.mu.Lock()
+				json.NewEncoder(w).Encode(map[string]any{"type": "MODIFIED", "object": f.object(resource)})
+				f.mu.Unlock()
+				w.(http.Flusher).Flush()
+			}
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func testClient(t *testing.T, api *fakeAPI) *Client {
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	return &Client{Host: srv.URL, Token: "test-token"}
+}
+
+var (
+	configRef = Ref{Kind: ConfigMap, Namespace: "prod", Name: "app", Key: "config.yaml"}
+	secretRef = Ref{Kind: Secret, Namespace: "prod", Name: "app", Key: "secret.yaml"}
+)
+
+// Test that ConfigMap and Secret sources feed the loader
+func TestSource(t *testing.T) {
+	client := testClient(t, newFakeAPI())
+
+	var cfg AppConfig
+	err := yamlenv.LoadConfig(yamlenv.LoaderOptions{
+		BaseSource:  client.Source(configRef),
+		LocalSource: client.Source(secretRef),
+		Target:      &cfg,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "svc", cfg.App.Name)
+	assert.Equal(t, "hunter2", cfg.DB.Password)
+}
+
+// Test that missing keys and API errors are reported
+func TestSource_Errors(t *testing.T) {
+	client := testClient(t, newFakeAPI())
+
+	_, err := client.Source(Ref{Kind: ConfigMap, Namespace: "prod", Name: "app", Key: "missing.yaml"})()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "configmap prod/app[missing.yaml]: key not found")
+
+	_, err = client.Source(Ref{Kind: ConfigMap, Namespace: "prod", Name: "other", Key: "config.yaml"})()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "404")
+
+	client.Token = "wrong"
+	_, err = client.Source(configRef)()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+}
+
+// Test that edits to a watched ConfigMap reload the loader
+func TestWatch(t *testing.T) {
+	api := newFakeAPI()
+	client := testClient(t, api)
+
+	var cfg AppConfig
+	loader, err := yamlenv.NewLoader(yamlenv.LoaderOptions{BaseSource: client.Source(configRef), Target: &cfg})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- client.Watch(ctx, loader, WatchOptions{Refs: []Ref{configRef}, OnError: func(err error) { t.Error(err) }})
+	}()
+
+	api.update("configmaps", "config.yaml", "app:\n  name: svc\n  port: 9090\n")
+	require.Eventually(t, func() bool {
+		return loader.Current().(*AppConfig).App.Port == 9090
+	}, 2*time.Second, 5*time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestKind_String(t *testing.T) {
+	assert.Equal(t, "configmap", ConfigMap.String())
+	assert.Equal(t, "secret", Secret.String())
+	assert.Equal(t, "Kind(7)", Kind(7).String())
+}
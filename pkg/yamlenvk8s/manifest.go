@@ -0,0 +1,138 @@
+package yamlenvk8s
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tendant/yamlenv/pkg/yamlenv"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultManifestKey is the ConfigMap data key used when ManifestOptions.Key is empty
+const defaultManifestKey = "config.yaml"
+
+// ManifestOptions names the generated objects
+type ManifestOptions struct {
+	Name      string            // metadata.name of both the ConfigMap and the Secret
+	Namespace string            // optional metadata.namespace
+	Key       string            // ConfigMap data key holding the YAML; "" = "config.yaml"
+	Labels    map[string]string // optional labels added to both objects
+}
+
+// manifest is the subset of a Kubernetes object written by WriteManifests
+type manifest struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   metadata          `yaml:"metadata"`
+	Type       string            `yaml:"type,omitempty"`
+	Data       map[string]string `yaml:"data,omitempty"`
+	StringData map[string]string `yaml:"stringData,omitempty"`
+}
+
+type metadata struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+// WriteManifests writes a ConfigMap holding Target as YAML under opts.Key,
+// with secret fields left out, followed by a Secret holding the secret
+// fields as environment variables named by EnvPrefix and Delimiter, ready
+// for envFrom. The Secret is omitted when Target has no secret fields.
+//
+// The values written are Target's current ones: pass a config returned by
+// yamlenv.Load to scaffold from the effective config, or a zero or
+// defaulted struct to scaffold from the schema alone. Sources are not read.
+func WriteManifests(w io.Writer, load yamlenv.LoaderOptions, opts ManifestOptions) error {
+	if opts.Name == "" {
+		return fmt.Errorf("manifest name cannot be empty")
+	}
+	vars, err := yamlenv.EnvVars(load)
+	if err != nil {
+		return err
+	}
+
+	doc, err := configDocument(load, vars)
+	if err != nil {
+		return err
+	}
+	key := opts.Key
+	if key == "" {
+		key = defaultManifestKey
+	}
+	meta := metadata{Name: opts.Name, Namespace: opts.Namespace, Labels: opts.Labels}
+	objects := []manifest{{APIVersion: "v1", Kind: "ConfigMap", Metadata: meta, Data: map[string]string{key: doc}}}
+
+	secrets := map[string]string{}
+	for _, v := range vars {
+		if v.Secret {
+			secrets[v.Name] = v.Value
+		}
+	}
+	if len(secrets) > 0 {
+		objects = append(objects, manifest{APIVersion: "v1", Kind: "Secret", Metadata: meta, Type: "Opaque", StringData: secrets})
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	for _, obj := range objects {
+		if err := enc.Encode(obj); err != nil {
+			return fmt.Errorf("encode %s: %w", strings.ToLower(obj.Kind), err)
+		}
+	}
+	return enc.Close()
+}
+
+// configDocument renders Target as YAML without the secret fields, nested
+// under Section when one is set
+func configDocument(load yamlenv.LoaderOptions, vars []yamlenv.EnvVar) (string, error) {
+	data, err := yaml.Marshal(load.Target)
+	if err != nil {
+		return "", fmt.Errorf("marshal config: %w", err)
+	}
+	var doc any = map[string]any{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return "", fmt.Errorf("marshal config: %w", err)
+	}
+
+	var secretPaths []string
+	for _, v := range vars {
+		if v.Secret {
+			secretPaths = append(secretPaths, strings.TrimPrefix(strings.TrimPrefix(v.Path, load.Section), "."))
+		}
+	}
+	for _, path := range secretPaths {
+		deletePath(doc, strings.Split(path, "."))
+	}
+
+	if load.Section != "" {
+		segments := strings.Split(load.Section, ".")
+		for i := len(segments) - 1; i >= 0; i-- {
+			doc = map[string]any{segments[i]: doc}
+		}
+	}
+	var out strings.Builder
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return "", fmt.Errorf("marshal config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("marshal config: %w", err)
+	}
+	return out.String(), nil
+}
+
+// deletePath removes the key at path from nested maps, if present
+func deletePath(doc any, path []string) {
+	m, ok := doc.(map[string]any)
+	if !ok || len(path) == 0 {
+		return
+	}
+	if len(path) == 1 {
+		delete(m, path[0])
+		return
+	}
+	deletePath(m[path[0]], path[1:])
+}
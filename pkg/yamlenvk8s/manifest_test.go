@@ -0,0 +1,81 @@
+package yamlenvk8s
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendant/yamlenv/pkg/yamlenv"
+)
+
+type ManifestConfig struct {
+	App struct {
+		Name string `yaml:"name"`
+		Port int    `yaml:"port"`
+	} `yaml:"app"`
+	DB struct {
+		Host     string `yaml:"host"`
+		Password string `yaml:"password" secret:"true"`
+	} `yaml:"db"`
+}
+
+// Test that the effective config is split into a ConfigMap and a Secret
+func TestWriteManifests(t *testing.T) {
+	var cfg ManifestConfig
+	load := yamlenv.LoaderOptions{
+		BaseSource: yamlenv.ReaderSource(strings.NewReader("app:\n  name: svc\n  port: 8080\ndb:\n  host: db\n  password: hunter2\n")),
+		EnvPrefix:  "APP_",
+		Delimiter:  "__",
+		Target:     &cfg,
+	}
+	require.NoError(t, yamlenv.LoadConfig(load))
+
+	var out bytes.Buffer
+	err := WriteManifests(&out, load, ManifestOptions{Name: "svc", Namespace: "prod", Labels: map[string]string{"app": "svc"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: svc
+  namespace: prod
+  labels:
+    app: svc
+data:
+  config.yaml: |
+    app:
+      name: svc
+      port: 8080
+    db:
+      host: db
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: svc
+  namespace: prod
+  labels:
+    app: svc
+type: Opaque
+stringData:
+  APP_DB__PASSWORD: hunter2
+`, out.String())
+}
+
+// Test that a zero struct scaffolds from the schema and sections nest
+func TestWriteManifests_Schema(t *testing.T) {
+	var db struct {
+		Host string `yaml:"host"`
+	}
+	var out bytes.Buffer
+	err := WriteManifests(&out, yamlenv.LoaderOptions{Section: "db", Target: &db}, ManifestOptions{Name: "db", Key: "app.yaml"})
+	require.NoError(t, err)
+
+	assert.Contains(t, out.String(), "  app.yaml: |\n    db:\n      host: \"\"\n")
+	assert.NotContains(t, out.String(), "kind: Secret")
+
+	err = WriteManifests(&out, yamlenv.LoaderOptions{Target: &db}, ManifestOptions{})
+	require.Error(t, err)
+}
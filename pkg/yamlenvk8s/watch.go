@@ -0,0 +1,139 @@
+package yamlenvk8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/tendant/yamlenv/pkg/yamlenv"
+)
+
+// WatchOptions configures Client.Watch
+type WatchOptions struct {
+	Refs    []Ref         // objects to watch; usually the refs passed to Source
+	Backoff time.Duration // delay before reconnecting a failed watch; default 1s
+	OnError func(error)   // optional; called when a watch or reload fails
+}
+
+// watchEvent is one line of a Kubernetes watch stream
+type watchEvent struct {
+	Type   string `json:"type"`
+	Object object `json:"object"`
+}
+
+// Watch follows the objects in opts.Refs with the Kubernetes watch API and
+// reloads loader whenever one of them changes. It blocks until ctx is done
+// and returns ctx.Err(). Dropped or expired watches are re-established
+// automatically.
+func (c *Client) Watch(ctx context.Context, loader *yamlenv.Loader, opts WatchOptions) error {
+	backoff := opts.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	report := func(err error) {
+		if opts.OnError != nil && ctx.Err() == nil {
+			opts.OnError(err)
+		}
+	}
+
+	// Reloads from several objects are serialized so each one sees the latest state
+	var reloadMu sync.Mutex
+	reload := func() {
+		reloadMu.Lock()
+		defer reloadMu.Unlock()
+		if err := loader.Reload(); err != nil {
+			report(err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, ref := range opts.Refs {
+		wg.Add(1)
+		go func(ref Ref) {
+			defer wg.Done()
+			c.watchObject(ctx, ref, backoff, reload, report)
+		}(ref)
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// watchObject runs watch streams for one object until ctx is done
+func (c *Client) watchObject(ctx context.Context, ref Ref, backoff time.Duration, reload func(), report func(error)) {
+	version := ""
+	for ctx.Err() == nil {
+		if version == "" {
+			// (Re)list to learn the current version; reload in case we missed changes
+			obj, err := c.get(ctx, ref)
+			if err != nil {
+				report(err)
+				sleep(ctx, backoff)
+				continue
+			}
+			version = obj.Metadata.ResourceVersion
+		}
+
+		next, err := c.stream(ctx, ref, version, reload)
+		if err != nil {
+			report(fmt.Errorf("watch %s: %w", ref, err))
+			sleep(ctx, backoff)
+		}
+		if next == "" && ctx.Err() == nil {
+			version = ""
+			reload()
+			continue
+		}
+		version = next
+	}
+}
+
+// stream consumes one watch connection, calling reload for every change.
+// It returns the last resource version seen, or "" if the watch expired
+// and the object must be listed again.
+func (c *Client) stream(ctx context.Context, ref Ref, version string, reload func()) (string, error) {
+	query := url.Values{
+		"watch":           {"true"},
+		"fieldSelector":   {"metadata.name=" + ref.Name},
+		"resourceVersion": {version},
+	}
+	resp, err := c.do(ctx, objectPath(ref, true), query)
+	if err != nil {
+		return version, err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var event watchEvent
+		if err := dec.Decode(&event); err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return version, nil
+			}
+			return version, err
+		}
+		switch event.Type {
+		case "ADDED", "MODIFIED", "DELETED":
+			version = event.Object.Metadata.ResourceVersion
+			reload()
+		case "BOOKMARK":
+			version = event.Object.Metadata.ResourceVersion
+		case "ERROR":
+			// Usually 410 Gone: the version is too old to resume from
+			return "", nil
+		}
+	}
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
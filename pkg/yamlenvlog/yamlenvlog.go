@@ -0,0 +1,242 @@
+// Package yamlenvlog is a standard logging section for yamlenv configs that
+// builds a log/slog logger, so every service configures logging the same
+// way:
+//
+//	type Config struct {
+//	    Log yamlenvlog.Config `yaml:"log"`
+//	}
+//
+//	// log:
+//	//   level: info
+//	//   format: json
+//	//   output: stderr
+//	//   sampling: {initial: 100, thereafter: 10}
+//	logger, err := yamlenvlog.Bind(loader, "log")
+//	defer logger.Close()
+//	slog.SetDefault(logger.Logger)
+//
+// With Bind the level follows reloads and runtime overrides; format, output
+// and sampling are read once.
+package yamlenvlog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tendant/yamlenv/pkg/yamlenv"
+)
+
+// Config is the logging section
+type Config struct {
+	Level     string   `yaml:"level" desc:"minimum level: debug, info, warn, error or e.g. WARN+2; default info"`
+	Format    string   `yaml:"format" enum:"json,text" desc:"record format; default json"`
+	Output    string   `yaml:"output" desc:"stderr, stdout or a file path to append to; default stderr"`
+	AddSource bool     `yaml:"add_source" desc:"include the source file and line of each record"`
+	Sampling  Sampling `yaml:"sampling"`
+}
+
+// Sampling limits repeated records: within each tick, the first Initial
+// records with the same level and message are logged, then every
+// Thereafter-th. Errors and above are never sampled.
+type Sampling struct {
+	Initial    int           `yaml:"initial" desc:"records per message and tick logged before sampling starts; 0 disables sampling"`
+	Thereafter int           `yaml:"thereafter" desc:"log every Nth record after initial; 0 drops them"`
+	Tick       time.Duration `yaml:"tick" desc:"sampling window; default 1s"`
+}
+
+// Logger is an *slog.Logger built from a Config, with the level it filters
+// by and the output it holds open
+type Logger struct {
+	*slog.Logger
+
+	level       slog.LevelVar
+	output      io.Closer
+	unsubscribe func()
+}
+
+// Build returns a logger for the section; its level is fixed. Close it to
+// close a file output.
+func (c Config) Build() (*Logger, error) {
+	level, err := parseLevel(c.Level)
+	if err != nil {
+		return nil, err
+	}
+	l := &Logger{}
+	l.level.Set(level)
+
+	var w io.Writer
+	switch c.Output {
+	case "", "stderr":
+		w = os.Stderr
+	case "stdout":
+		w = os.Stdout
+	default:
+		file, err := os.OpenFile(c.Output, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("log: open output: %w", err)
+		}
+		w, l.output = file, file
+	}
+
+	handler, err := c.handler(w, &l.level)
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	l.Logger = slog.New(handler)
+	return l, nil
+}
+
+// handler returns the handler for the section writing to w
+func (c Config) handler(w io.Writer, level slog.Leveler) (slog.Handler, error) {
+	opts := &slog.HandlerOptions{Level: level, AddSource: c.AddSource}
+	var handler slog.Handler
+	switch c.Format {
+	case "", "json":
+		handler = slog.NewJSONHandler(w, opts)
+	case "text":
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("log: invalid format %q; allowed: json, text", c.Format)
+	}
+
+	s := c.Sampling
+	if s.Initial < 0 || s.Thereafter < 0 || s.Tick < 0 {
+		return nil, fmt.Errorf("log: sampling values must not be negative")
+	}
+	if s.Initial > 0 {
+		tick := s.Tick
+		if tick == 0 {
+			tick = time.Second
+		}
+		handler = &samplingHandler{Handler: handler, sampler: &sampler{
+			initial:    uint64(s.Initial),
+			thereafter: uint64(s.Thereafter),
+			tick:       tick,
+			counts:     map[samplingKey]uint64{},
+		}}
+	}
+	return handler, nil
+}
+
+// Bind builds a logger from the Config at path in the loader's config (the
+// field must have type Config) and keeps its level in sync with path.level.
+// Levels that fail to parse on reload are ignored.
+func Bind(loader *yamlenv.Loader, path string) (*Logger, error) {
+	value := loader.Get(path)
+	cfg, ok := value.(Config)
+	if !ok {
+		return nil, fmt.Errorf("log: %s is %T, want yamlenvlog.Config", path, value)
+	}
+	l, err := cfg.Build()
+	if err != nil {
+		return nil, err
+	}
+	levelPath := "level"
+	if path != "" {
+		levelPath = path + ".level"
+	}
+	l.unsubscribe = loader.Subscribe(levelPath, func(_, new any) {
+		name, _ := new.(string)
+		if level, err := parseLevel(name); err == nil {
+			l.level.Set(level)
+		}
+	})
+	return l, nil
+}
+
+// Level returns the level records are currently filtered by
+func (l *Logger) Level() slog.Level {
+	return l.level.Level()
+}
+
+// Close stops following the config and closes a file output
+func (l *Logger) Close() error {
+	if l.unsubscribe != nil {
+		l.unsubscribe()
+	}
+	if l.output != nil {
+		return l.output.Close()
+	}
+	return nil
+}
+
+// parseLevel converts a level name into a slog.Level; "" is info
+func parseLevel(name string) (slog.Level, error) {
+	if name == "" {
+		return slog.LevelInfo, nil
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("log: invalid level %q; want debug, info, warn, error or e.g. WARN+2", name)
+	}
+	return level, nil
+}
+
+// samplingKey groups records that sampling counts together
+type samplingKey struct {
+	level   slog.Level
+	message string
+}
+
+// sampler counts records per key within the current tick. It is shared by
+// the handlers WithAttrs and WithGroup derive.
+type sampler struct {
+	initial, thereafter uint64
+	tick                time.Duration
+
+	mu     sync.Mutex
+	start  time.Time
+	counts map[samplingKey]uint64
+}
+
+// allow reports whether the record is logged
+func (s *sampler) allow(r slog.Record) bool {
+	if r.Level >= slog.LevelError {
+		return true
+	}
+	now := r.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.start) >= s.tick || now.Before(s.start) {
+		s.start = now
+		clear(s.counts)
+	}
+	key := samplingKey{r.Level, r.Message}
+	s.counts[key]++
+	n := s.counts[key]
+	if n <= s.initial {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.initial)%s.thereafter == 0
+}
+
+// samplingHandler drops the records its sampler doesn't allow
+type samplingHandler struct {
+	slog.Handler
+	sampler *sampler
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.sampler.allow(r) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithAttrs(attrs), sampler: h.sampler}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithGroup(name), sampler: h.sampler}
+}
@@ -0,0 +1,154 @@
+package yamlenvlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendant/yamlenv/pkg/yamlenv"
+	"github.com/tendant/yamlenv/pkg/yamlenvtest"
+)
+
+type AppConfig struct {
+	Log Config `yaml:"log"`
+}
+
+// readLines returns the lines of a log file
+func readLines(t *testing.T, path string) []string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+// Test that Build writes records in the configured format and level to a file
+func TestConfig_Build(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "app.log")
+	logger, err := Config{Level: "warn", Output: out}.Build()
+	require.NoError(t, err)
+
+	logger.Info("dropped")
+	logger.Warn("kept", "user", "ada")
+	require.NoError(t, logger.Close())
+
+	lines := readLines(t, out)
+	require.Len(t, lines, 1)
+	var record map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "kept", record["msg"])
+	assert.Equal(t, "ada", record["user"])
+	assert.Equal(t, slog.LevelWarn, logger.Level())
+}
+
+func TestConfig_BuildErrors(t *testing.T) {
+	tests := []struct {
+		cfg  Config
+		want string
+	}{
+		{Config{Level: "loud"}, `invalid level "loud"`},
+		{Config{Format: "xml"}, `invalid format "xml"`},
+		{Config{Sampling: Sampling{Initial: -1}}, "must not be negative"},
+		{Config{Output: filepath.Join(t.TempDir(), "missing", "app.log")}, "open output"},
+	}
+	for _, tt := range tests {
+		_, err := tt.cfg.Build()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), tt.want)
+	}
+}
+
+// Test that sampling logs the first records of a message, then every Nth,
+// and starts over each tick
+func TestSampling(t *testing.T) {
+	var buf bytes.Buffer
+	handler, err := Config{Format: "text", Sampling: Sampling{Initial: 2, Thereafter: 3, Tick: time.Minute}}.
+		handler(&buf, slog.LevelInfo)
+	require.NoError(t, err)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	log := func(at time.Time, level slog.Level, msg string) {
+		require.NoError(t, handler.Handle(t.Context(), slog.NewRecord(at, level, msg, 0)))
+	}
+	for i := 0; i < 8; i++ {
+		log(start, slog.LevelInfo, "busy")
+	}
+	log(start, slog.LevelInfo, "other")
+	log(start, slog.LevelError, "busy")
+	log(start, slog.LevelError, "busy")
+	log(start.Add(time.Minute), slog.LevelInfo, "busy")
+
+	// 1, 2, 5 and 8 of the first tick, then the first of the next
+	assert.Equal(t, 5, strings.Count(buf.String(), "level=INFO msg=busy"))
+	assert.Equal(t, 1, strings.Count(buf.String(), "msg=other"))
+	assert.Equal(t, 2, strings.Count(buf.String(), "level=ERROR"))
+
+	// Derived handlers share the counts: the second tick is at its second
+	// and third record
+	buf.Reset()
+	derived := handler.WithAttrs([]slog.Attr{slog.String("k", "v")})
+	for i := 0; i < 2; i++ {
+		require.NoError(t, derived.Handle(t.Context(), slog.NewRecord(start.Add(time.Minute), slog.LevelInfo, "busy", 0)))
+	}
+	assert.Equal(t, 1, strings.Count(buf.String(), "k=v"))
+}
+
+// Test that a bound logger follows level changes on reload
+func TestBind(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "app.log")
+	file := yamlenvtest.TempYAML(t, "log:\n  level: info\n  output: "+out+"\n")
+
+	var cfg AppConfig
+	loader, err := yamlenv.NewLoader(yamlenv.LoaderOptions{BaseSource: yamlenv.FileSource(file), Target: &cfg})
+	require.NoError(t, err)
+	logger, err := Bind(loader, "log")
+	require.NoError(t, err)
+	defer logger.Close()
+
+	logger.Debug("hidden")
+	require.NoError(t, os.WriteFile(file, []byte("log:\n  level: debug\n  output: "+out+"\n"), 0o644))
+	require.NoError(t, loader.Reload())
+	assert.Equal(t, slog.LevelDebug, logger.Level())
+	logger.Debug("shown")
+
+	// Invalid levels keep the previous one
+	require.NoError(t, os.WriteFile(file, []byte("log:\n  level: loud\n  output: "+out+"\n"), 0o644))
+	require.NoError(t, loader.Reload())
+	assert.Equal(t, slog.LevelDebug, logger.Level())
+
+	lines := readLines(t, out)
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], `"msg":"shown"`)
+}
+
+func TestBind_WrongType(t *testing.T) {
+	file := yamlenvtest.TempYAML(t, "app:\n  name: svc\n")
+
+	var cfg struct {
+		App struct {
+			Name string `yaml:"name"`
+		} `yaml:"app"`
+	}
+	loader, err := yamlenv.NewLoader(yamlenv.LoaderOptions{BaseSource: yamlenv.FileSource(file), Target: &cfg})
+	require.NoError(t, err)
+
+	_, err = Bind(loader, "app")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "want yamlenvlog.Config")
+}
+
+// Test that the format is validated when the config loads
+func TestConfig_FormatValidatedAtLoad(t *testing.T) {
+	file := yamlenvtest.TempYAML(t, "log:\n  format: xml\n")
+
+	var cfg AppConfig
+	_, err := yamlenv.Load(yamlenv.LoaderOptions{BaseSource: yamlenv.FileSource(file), Target: &cfg})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "xml")
+}
@@ -0,0 +1,229 @@
+// Package yamlenvmetrics collects the load metrics yamlenv reports and
+// exposes them to Prometheus, in the text exposition format, and to expvar.
+//
+// A Collector is a yamlenv.Metrics: set it as LoaderOptions.Metrics and
+// mount it as an HTTP handler. It does not depend on the Prometheus client
+// library and does not implement prometheus.Collector; to register the
+// metrics with an existing registry, build a small collector around Snapshot
+// (see the README).
+//
+//	collector := yamlenvmetrics.New()
+//	opts.Metrics = collector
+//	loader, err := yamlenv.NewLoader(opts)
+//	collector.Attach(loader)
+//	mux.Handle("/metrics/config", collector)
+//	collector.Publish("config")
+package yamlenvmetrics
+
+import (
+	"bufio"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tendant/yamlenv/pkg/yamlenv"
+)
+
+// Collector accumulates load and source fetch metrics
+type Collector struct {
+	mu      sync.Mutex
+	loads   Counts
+	sources map[string]*Counts
+	drift   DriftStatus
+	loader  *yamlenv.Loader // set by Attach; reports the config checksum
+}
+
+// Counts summarizes a series of loads or fetches
+type Counts struct {
+	Total       int       // attempts
+	Failures    int       // attempts that returned an error
+	Seconds     float64   // total time spent, in seconds
+	LastSuccess time.Time // time of the latest success; zero if none
+	LastFailure time.Time // time of the latest failure; zero if none
+	LastError   string    // message of the latest failure
+}
+
+// DriftStatus summarizes the drift checks of Loader.WatchDrift
+type DriftStatus struct {
+	Checks   int // checks run
+	Failures int // checks whose reference couldn't be read
+	Drifted  int // values that differed from the reference at the latest successful check
+}
+
+// Snapshot is a copy of a Collector's metrics
+type Snapshot struct {
+	Loads    Counts            // complete loads and reloads
+	Sources  map[string]Counts // fetches by layer name ("base", "local", ...)
+	Drift    DriftStatus       // drift checks, if the collector is the Metrics of a loader running WatchDrift
+	Checksum string            // checksum of the attached loader's current config; "" if none is attached
+}
+
+var (
+	_ yamlenv.Metrics      = (*Collector)(nil)
+	_ yamlenv.DriftMetrics = (*Collector)(nil)
+)
+
+// New returns an empty Collector
+func New() *Collector {
+	return &Collector{sources: map[string]*Counts{}}
+}
+
+// Attach adds the checksum of loader's current config to the metrics, so
+// dashboards can tell which config each instance runs. The loader is
+// usually created with the collector as its Metrics.
+func (c *Collector) Attach(loader *yamlenv.Loader) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loader = loader
+}
+
+// SourceFetched implements yamlenv.Metrics
+func (c *Collector) SourceFetched(layer string, d time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counts, ok := c.sources[layer]
+	if !ok {
+		counts = &Counts{}
+		c.sources[layer] = counts
+	}
+	counts.record(d, err)
+}
+
+// Loaded implements yamlenv.Metrics
+func (c *Collector) Loaded(d time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loads.record(d, err)
+}
+
+// DriftChecked implements yamlenv.DriftMetrics
+func (c *Collector) DriftChecked(drifted int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.drift.Checks++
+	if err != nil {
+		c.drift.Failures++
+		return
+	}
+	c.drift.Drifted = drifted
+}
+
+// record adds one attempt
+func (s *Counts) record(d time.Duration, err error) {
+	now := time.Now()
+	s.Total++
+	s.Seconds += d.Seconds()
+	if err != nil {
+		s.Failures++
+		s.LastFailure, s.LastError = now, err.Error()
+	} else {
+		s.LastSuccess = now
+	}
+}
+
+// Snapshot returns a copy of the current metrics
+func (c *Collector) Snapshot() Snapshot {
+	c.mu.Lock()
+	snap := Snapshot{Loads: c.loads, Sources: make(map[string]Counts, len(c.sources)), Drift: c.drift}
+	for layer, counts := range c.sources {
+		snap.Sources[layer] = *counts
+	}
+	loader := c.loader
+	c.mu.Unlock()
+
+	if loader != nil {
+		snap.Checksum = loader.Checksum()
+	}
+	return snap
+}
+
+// Publish exposes the snapshot as an expvar variable, served as JSON on
+// /debug/vars. Like expvar.Publish, it panics if name is already in use.
+func (c *Collector) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() any { return c.Snapshot() }))
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = c.WritePrometheus(w)
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format
+func (c *Collector) WritePrometheus(w io.Writer) error {
+	snap := c.Snapshot()
+	layers := make([]string, 0, len(snap.Sources))
+	for layer := range snap.Sources {
+		layers = append(layers, layer)
+	}
+	sort.Strings(layers)
+
+	bw := bufio.NewWriter(w)
+	header(bw, "yamlenv_loads_total", "counter", "Config loads and reloads attempted.")
+	fmt.Fprintf(bw, "yamlenv_loads_total %d\n", snap.Loads.Total)
+	header(bw, "yamlenv_load_failures_total", "counter", "Config loads and reloads that failed.")
+	fmt.Fprintf(bw, "yamlenv_load_failures_total %d\n", snap.Loads.Failures)
+	header(bw, "yamlenv_load_duration_seconds_total", "counter", "Time spent loading config.")
+	fmt.Fprintf(bw, "yamlenv_load_duration_seconds_total %g\n", snap.Loads.Seconds)
+	header(bw, "yamlenv_last_load_success_timestamp_seconds", "gauge", "Unix time of the latest successful load; 0 if none.")
+	fmt.Fprintf(bw, "yamlenv_last_load_success_timestamp_seconds %d\n", unixSeconds(snap.Loads.LastSuccess))
+	header(bw, "yamlenv_last_load_failure_timestamp_seconds", "gauge", "Unix time of the latest failed load; 0 if none.")
+	fmt.Fprintf(bw, "yamlenv_last_load_failure_timestamp_seconds %d\n", unixSeconds(snap.Loads.LastFailure))
+
+	if snap.Checksum != "" {
+		header(bw, "yamlenv_config_info", "gauge", "Checksum of the current config, as a label.")
+		fmt.Fprintf(bw, "yamlenv_config_info{checksum=\"%s\"} 1\n", labelValue(snap.Checksum))
+	}
+
+	if snap.Drift.Checks > 0 {
+		header(bw, "yamlenv_drift_checks_total", "counter", "Checks of the config against its deployed reference.")
+		fmt.Fprintf(bw, "yamlenv_drift_checks_total %d\n", snap.Drift.Checks)
+		header(bw, "yamlenv_drift_check_failures_total", "counter", "Drift checks whose reference couldn't be read.")
+		fmt.Fprintf(bw, "yamlenv_drift_check_failures_total %d\n", snap.Drift.Failures)
+		header(bw, "yamlenv_config_drifted_values", "gauge", "Config values that differed from the reference at the latest check.")
+		fmt.Fprintf(bw, "yamlenv_config_drifted_values %d\n", snap.Drift.Drifted)
+	}
+
+	if len(layers) > 0 {
+		header(bw, "yamlenv_source_fetches_total", "counter", "Config source fetches, by layer.")
+		for _, layer := range layers {
+			fmt.Fprintf(bw, "yamlenv_source_fetches_total{layer=\"%s\"} %d\n", labelValue(layer), snap.Sources[layer].Total)
+		}
+		header(bw, "yamlenv_source_fetch_failures_total", "counter", "Config source fetches that failed, by layer.")
+		for _, layer := range layers {
+			fmt.Fprintf(bw, "yamlenv_source_fetch_failures_total{layer=\"%s\"} %d\n", labelValue(layer), snap.Sources[layer].Failures)
+		}
+		header(bw, "yamlenv_source_fetch_duration_seconds_total", "counter", "Time spent fetching config sources, by layer.")
+		for _, layer := range layers {
+			fmt.Fprintf(bw, "yamlenv_source_fetch_duration_seconds_total{layer=\"%s\"} %g\n", labelValue(layer), snap.Sources[layer].Seconds)
+		}
+	}
+	return bw.Flush()
+}
+
+// header writes the HELP and TYPE lines of a metric
+func header(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// labelEscaper escapes label values as the text exposition format requires:
+// only backslash, double quote and newline
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labelValue returns s escaped for use between the quotes of a label value
+func labelValue(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+// unixSeconds returns t as Unix seconds, or 0 for the zero time
+func unixSeconds(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
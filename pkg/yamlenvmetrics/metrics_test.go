@@ -0,0 +1,145 @@
+package yamlenvmetrics
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendant/yamlenv/pkg/yamlenv"
+)
+
+type testConfig struct {
+	App struct {
+		Port int `yaml:"port"`
+	} `yaml:"app"`
+}
+
+// newLoader returns a loader reporting to c and the path of its base file
+func newLoader(t *testing.T, c *Collector) (*yamlenv.Loader, string) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("app:\n  port: 1\n"), 0o644))
+	var cfg testConfig
+	loader, err := yamlenv.NewLoader(yamlenv.LoaderOptions{
+		BaseSource:  yamlenv.FileSource(path),
+		LocalSource: yamlenv.FileSource(path + ".local"),
+		Target:      &cfg,
+		Metrics:     c,
+	})
+	require.NoError(t, err)
+	return loader, path
+}
+
+// Test that loads and fetches are counted, including failures
+func TestCollector_Snapshot(t *testing.T) {
+	c := New()
+	loader, path := newLoader(t, c)
+	require.NoError(t, os.WriteFile(path, []byte("app: ["), 0o644))
+	require.Error(t, loader.Reload())
+
+	snap := c.Snapshot()
+	assert.Equal(t, 2, snap.Loads.Total)
+	assert.Equal(t, 1, snap.Loads.Failures)
+	assert.False(t, snap.Loads.LastSuccess.IsZero())
+	assert.Contains(t, snap.Loads.LastError, "load base config")
+	assert.Equal(t, 2, snap.Sources["base"].Total)
+	assert.Equal(t, 1, snap.Sources["base"].Failures)
+	assert.Equal(t, 2, snap.Sources["local"].Total, "sources are fetched concurrently, so local is fetched too")
+	assert.Zero(t, snap.Sources["local"].Failures, "a missing local source is not a failure")
+}
+
+// Test the Prometheus text output
+func TestCollector_ServeHTTP(t *testing.T) {
+	c := New()
+	newLoader(t, c)
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE yamlenv_loads_total counter",
+		"yamlenv_loads_total 1",
+		"yamlenv_load_failures_total 0",
+		"yamlenv_last_load_failure_timestamp_seconds 0",
+		`yamlenv_source_fetches_total{layer="base"} 1`,
+		`yamlenv_source_fetches_total{layer="local"} 1`,
+		`yamlenv_source_fetch_failures_total{layer="base"} 0`,
+	} {
+		assert.Contains(t, body, line+"\n")
+	}
+	assert.NotContains(t, body, "yamlenv_last_load_success_timestamp_seconds 0\n")
+	assert.Less(t, strings.Index(body, `{layer="base"} 1`), strings.Index(body, `{layer="local"} 1`), "layers are sorted")
+}
+
+// publishRuns numbers the expvar names of TestCollector_Publish, since
+// expvar names can't be reused within a process (go test -count=2)
+var publishRuns atomic.Int64
+
+// Test that Publish exposes the snapshot through expvar
+func TestCollector_Publish(t *testing.T) {
+	c := New()
+	newLoader(t, c)
+	name := fmt.Sprintf("yamlenv_test_config_%d", publishRuns.Add(1))
+	c.Publish(name)
+
+	var snap Snapshot
+	require.NoError(t, json.Unmarshal([]byte(expvar.Get(name).String()), &snap))
+	assert.Equal(t, 1, snap.Loads.Total)
+	assert.Equal(t, 1, snap.Sources["base"].Total)
+}
+
+// Test that an attached loader's checksum is exported
+func TestCollector_Attach(t *testing.T) {
+	c := New()
+	loader, _ := newLoader(t, c)
+	assert.Empty(t, c.Snapshot().Checksum)
+
+	c.Attach(loader)
+	assert.Equal(t, loader.Checksum(), c.Snapshot().Checksum)
+
+	var out strings.Builder
+	require.NoError(t, c.WritePrometheus(&out))
+	assert.Contains(t, out.String(), `yamlenv_config_info{checksum="`+loader.Checksum()+`"} 1`+"\n")
+}
+
+// Test that label values escape only what the exposition format requires
+func TestCollector_LabelEscaping(t *testing.T) {
+	c := New()
+	c.SourceFetched("tenant \"ü\"\\eu\nwest\t", 0, nil)
+
+	var out strings.Builder
+	require.NoError(t, c.WritePrometheus(&out))
+	assert.Contains(t, out.String(), `yamlenv_source_fetches_total{layer="tenant \"ü\"\\eu\nwest`+"\t"+`"} 1`+"\n")
+}
+
+// Test that drift checks are counted and exported once there are any
+func TestCollector_DriftChecked(t *testing.T) {
+	c := New()
+	var out strings.Builder
+	require.NoError(t, c.WritePrometheus(&out))
+	assert.NotContains(t, out.String(), "yamlenv_drift")
+
+	c.DriftChecked(2, nil)
+	c.DriftChecked(0, errors.New("reference missing"))
+	assert.Equal(t, DriftStatus{Checks: 2, Failures: 1, Drifted: 2}, c.Snapshot().Drift)
+
+	out.Reset()
+	require.NoError(t, c.WritePrometheus(&out))
+	for _, line := range []string{
+		"yamlenv_drift_checks_total 2",
+		"yamlenv_drift_check_failures_total 1",
+		"yamlenv_config_drifted_values 2",
+	} {
+		assert.Contains(t, out.String(), line+"\n")
+	}
+}
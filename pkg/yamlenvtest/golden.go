@@ -0,0 +1,70 @@
+package yamlenvtest
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tendant/yamlenv/pkg/yamlenv"
+)
+
+// Golden compares cfg, rendered with yamlenv.RenderYAML, with the golden
+// file at path and fails the test with both versions if they differ.
+//
+// To accept a change, rerun the test with -update, which rewrites the file
+// instead of comparing. The flag belongs to your test binary, so declare it
+// in your test package (yamlenvtest doesn't, to avoid clashing with an
+// existing one):
+//
+//	var _ = flag.Bool("update", false, "update golden files")
+//
+//	func TestConfigProd(t *testing.T) {
+//		cfg := yamlenvtest.MustLoad[Config](t, prodOptions)
+//		yamlenvtest.Golden(t, "testdata/config.prod.golden.yaml", cfg)
+//	}
+//
+// YAMLENV_UPDATE_GOLDEN=1 has the same effect as -update.
+func Golden(t testing.TB, path string, cfg any) {
+	t.Helper()
+	got, err := yamlenv.RenderYAML(cfg)
+	if err != nil {
+		t.Fatalf("golden %s: %v", path, err)
+	}
+
+	if updateGolden() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("golden %s: %v", path, err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("golden %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("golden %s: %v (run with -update to create it)", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("config differs from golden file %s (run with -update to accept)\n--- want\n%s--- got\n%s", path, want, got)
+	}
+}
+
+// updateGolden reports whether golden files should be rewritten
+func updateGolden() bool {
+	if os.Getenv("YAMLENV_UPDATE_GOLDEN") == "1" {
+		return true
+	}
+	f := flag.Lookup("update")
+	if f == nil {
+		return false
+	}
+	getter, ok := f.Value.(flag.Getter)
+	if !ok {
+		return false
+	}
+	update, _ := getter.Get().(bool)
+	return update
+}
@@ -0,0 +1,39 @@
+package yamlenvtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that Golden writes the file when updating and passes against it
+func TestGolden(t *testing.T) {
+	var cfg testConfig
+	cfg.App.Name, cfg.App.Port = "svc", 8080
+	path := filepath.Join(t.TempDir(), "testdata", "config.golden.yaml")
+
+	t.Setenv("YAMLENV_UPDATE_GOLDEN", "1")
+	Golden(t, path, cfg)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "app:\n  name: svc\n  port: 8080\n", string(data))
+
+	t.Setenv("YAMLENV_UPDATE_GOLDEN", "")
+	Golden(t, path, &cfg)
+
+	cfg.App.Port = 9090
+	inner := &failureRecorder{TB: t}
+	Golden(inner, path, cfg)
+	assert.True(t, inner.failed, "a changed config fails the comparison")
+}
+
+// failureRecorder records Errorf instead of failing the test
+type failureRecorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *failureRecorder) Errorf(format string, args ...any) { r.failed = true }
@@ -0,0 +1,111 @@
+// Package yamlenvtest has helpers for testing code that loads its config
+// with yamlenv: temporary YAML files, scoped environment variables, a
+// one-line load and a ConfigSource that can be made to fail.
+//
+//	func TestServer(t *testing.T) {
+//		yamlenvtest.WithEnv(t, map[string]string{"APP_DB__HOST": "db.test"})
+//		cfg := yamlenvtest.MustLoad[Config](t, yamlenv.LoaderOptions{
+//			BaseSource: yamlenv.FileSource(yamlenvtest.TempYAML(t, "app:\n  port: 8080\n")),
+//			EnvPrefix:  "APP_",
+//			Delimiter:  "__",
+//		})
+//		...
+//	}
+package yamlenvtest
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tendant/yamlenv/pkg/yamlenv"
+)
+
+// TempYAML writes content to a file in a temporary directory that is
+// removed when the test ends, and returns its path
+func TempYAML(t testing.TB, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write temp YAML: %v", err)
+	}
+	return path
+}
+
+// WithEnv sets environment variables for the rest of the test and restores
+// their previous values when it ends. Like t.Setenv, it can't be used in
+// parallel tests.
+func WithEnv(t testing.TB, vars map[string]string) {
+	t.Helper()
+	for key, value := range vars {
+		t.Setenv(key, value)
+	}
+}
+
+// MustLoad loads a new T with opts, whose Target is ignored, and fails the
+// test if loading fails
+func MustLoad[T any](t testing.TB, opts yamlenv.LoaderOptions) *T {
+	t.Helper()
+	cfg := new(T)
+	opts.Target = cfg
+	if _, err := yamlenv.Load(opts); err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	return cfg
+}
+
+// Source is a ConfigSource whose content and failures are set by the test,
+// for exercising reloads and error handling. It is safe for concurrent use.
+//
+//	src := yamlenvtest.NewSource("app:\n  port: 8080\n")
+//	loader, err := yamlenv.NewLoader(yamlenv.LoaderOptions{BaseSource: src.Source(), Target: &cfg})
+//	src.Fail(errors.New("connection refused"))
+//	err = loader.Reload() // fails, the previous config is kept
+type Source struct {
+	mu      sync.Mutex
+	content string
+	err     error
+	opens   int
+}
+
+// NewSource returns a Source serving content
+func NewSource(content string) *Source {
+	return &Source{content: content}
+}
+
+// Set replaces the content served from the next open on
+func (s *Source) Set(content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.content = content
+}
+
+// Fail makes every following open return err, until Fail(nil)
+func (s *Source) Fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// Opens returns how often the source has been opened, including failures
+func (s *Source) Opens() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.opens
+}
+
+// Source returns the ConfigSource to put into LoaderOptions
+func (s *Source) Source() yamlenv.ConfigSource {
+	return func() (io.ReadCloser, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.opens++
+		if s.err != nil {
+			return nil, s.err
+		}
+		return io.NopCloser(strings.NewReader(s.content)), nil
+	}
+}
@@ -0,0 +1,49 @@
+package yamlenvtest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendant/yamlenv/pkg/yamlenv"
+)
+
+type testConfig struct {
+	App struct {
+		Name string `yaml:"name"`
+		Port int    `yaml:"port"`
+	} `yaml:"app"`
+}
+
+// Test that the helpers combine into a load with a file and env overrides
+func TestMustLoad(t *testing.T) {
+	WithEnv(t, map[string]string{"YT_APP__PORT": "9090"})
+
+	cfg := MustLoad[testConfig](t, yamlenv.LoaderOptions{
+		BaseSource: yamlenv.FileSource(TempYAML(t, "app:\n  name: svc\n  port: 8080\n")),
+		EnvPrefix:  "YT_",
+		Delimiter:  "__",
+	})
+
+	assert.Equal(t, "svc", cfg.App.Name)
+	assert.Equal(t, 9090, cfg.App.Port)
+}
+
+// Test that a Source serves its content and fails when told to
+func TestSource(t *testing.T) {
+	src := NewSource("app:\n  port: 1\n")
+	var cfg testConfig
+	loader, err := yamlenv.NewLoader(yamlenv.LoaderOptions{BaseSource: src.Source(), Target: &cfg})
+	require.NoError(t, err)
+
+	src.Fail(errors.New("connection refused"))
+	assert.ErrorContains(t, loader.Reload(), "connection refused")
+	assert.Equal(t, 1, cfg.App.Port)
+
+	src.Fail(nil)
+	src.Set("app:\n  port: 2\n")
+	require.NoError(t, loader.Reload())
+	assert.Equal(t, 2, cfg.App.Port)
+	assert.Equal(t, 3, src.Opens())
+}